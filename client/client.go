@@ -2,9 +2,10 @@ package client
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
 )
 
 type Geom struct {
@@ -18,19 +19,46 @@ const (
 	TypeUnknown Type = iota
 	TypeNormal
 	TypeDock
+	TypeDesktop // window drawing the desktop background and icons, kept below everything else
 )
 
+// States of the ICCCM WM_STATE property
+const (
+	normalState = 1
+	iconicState = 3
+)
+
+// argbDepth is the depth of the windows with an ARGB visual, i.e. with an alpha channel
+const argbDepth = 32
+
 type Client struct {
 	x11    x11
 	window xproto.Window
 	parent xproto.Window
 	mapped bool
 
+	// viewable is whether the window was last mapped (rather than unmapped) by the WM, and ignoreUnmaps
+	// the number of the UnmapNotify events caused by the WM itself that are yet to be received
+	viewable     bool
+	ignoreUnmaps int
+
 	geom Geom
 	cfg  *Config
 	typ  Type
+	role Role
 
-	title string
+	title          string
+	class          string // class and instance of WM_CLASS, which can be shown in the title
+	instance       string
+	marks          string  // labels of the window's marks drawn before the title, e.g. "[a] "
+	position       string  // position of the window among the ones sharing its place, e.g. "(2/5) ", drawn first
+	borderColor    uint32  // background of the parent window, which shows around the client window
+	opacity        float64 // opacity set on the parent window, 0 until it's set
+	opacityFixed   float64 // opacity overriding the ones of the config regardless of the focus, 0 if not set
+	focused        bool
+	urgent         bool
+	titlebarHidden bool
+	argb           bool // whether the window, and so its parent, has an ARGB visual
 }
 
 func New(x11 x11, cfg *Config, window xproto.Window, typ Type) (*Client, error) {
@@ -41,26 +69,180 @@ func New(x11 x11, cfg *Config, window xproto.Window, typ Type) (*Client, error)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create parent: %w", err)
 		}
+		c.borderColor = c.stateBorderColor()
+		c.opacity = 1 // a window without the property is opaque
 		if err := c.reparent(parent); err != nil {
 			return nil, err
 		}
+		c.updateClassProperty()
 		c.updateTitleProperty()
+		if err := c.drawOpacity(); err != nil {
+			return nil, err
+		}
 	}
 
 	return c, nil
 }
 
 func (c *Client) Type() Type            { return c.typ }
+func (c *Client) Role() Role            { return c.role }
 func (c *Client) Window() xproto.Window { return c.window }
 func (c *Client) Parent() xproto.Window { return c.parent }
 func (c *Client) Geom() Geom            { return c.geom }
 func (c *Client) Mapped() bool          { return c.mapped }
+func (c *Client) Title() string         { return c.title }
+func (c *Client) Class() string         { return c.class }
+func (c *Client) Instance() string      { return c.instance }
+func (c *Client) Focused() bool         { return c.focused }
+func (c *Client) Urgent() bool          { return c.urgent }
 func (c *Client) SetGeom(geom Geom)     { c.geom = geom }
 
+// SetConfig replaces the config of the client, e.g. with one scaled for the output it moved to, returning
+// false if it already had it. The client is redrawn with it by Draw
+func (c *Client) SetConfig(cfg *Config) bool {
+	if c.cfg == cfg {
+		return false
+	}
+	c.cfg = cfg
+	return true
+}
+
+// SetRole changes the role of the window, which decides whether it's decorated
+func (c *Client) SetRole(role Role) { c.role = role }
+
+// TitlebarHidden returns true if the titlebar of this client is not drawn, either disabled or because
+// the window is not decorated
+func (c *Client) TitlebarHidden() bool { return c.titlebarHidden || !c.role.Decorated() }
+
+// SetTitlebarHidden disables (or re-enables) drawing the titlebar of this client
+func (c *Client) SetTitlebarHidden(hidden bool) { c.titlebarHidden = hidden }
+
+// SetMarks changes the marks shown in the titlebar, before the title
+func (c *Client) SetMarks(marks []string) error {
+	label := markLabel(marks)
+	if c.marks == label {
+		return nil
+	}
+	c.marks = label
+	return c.drawTitlebar()
+}
+
+// SetPosition shows the position of the window among the n windows sharing its place in the titlebar,
+// 1 being the first one, nothing for less than two windows
+func (c *Client) SetPosition(i, n int) error {
+	label := positionLabel(i, n)
+	if c.position == label {
+		return nil
+	}
+	c.position = label
+	return c.drawTitlebar()
+}
+
+func positionLabel(i, n int) string {
+	if n < 2 {
+		return ""
+	}
+	return fmt.Sprintf("(%d/%d) ", i, n)
+}
+
+// markLabel puts each mark in brackets, e.g. "[a] [b] " for the marks a and b
+func markLabel(marks []string) string {
+	var label string
+	for _, m := range marks {
+		label += "[" + m + "] "
+	}
+	return label
+}
+
+// Draw paints the border and the titlebar in the colors of the current state and updates the opacity
 func (c *Client) Draw() error {
+	if err := c.drawBorder(); err != nil {
+		return err
+	}
+	if err := c.drawOpacity(); err != nil {
+		return err
+	}
 	return c.drawTitlebar()
 }
 
+// SetFocused changes the focus state of the client, redrawing the border and titlebar in the matching colors
+func (c *Client) SetFocused(focused bool) error {
+	if c.focused == focused {
+		return nil
+	}
+	c.focused = focused
+	return c.Draw()
+}
+
+// SetUrgent changes whether the window demands attention and redraws its border and titlebar
+func (c *Client) SetUrgent(urgent bool) error {
+	if c.urgent == urgent {
+		return nil
+	}
+	c.urgent = urgent
+	return c.Draw()
+}
+
+// Opacity returns the current opacity of the frame, between 0 (transparent) and 1 (opaque).
+// Unset opacities of the config make the windows opaque
+func (c *Client) Opacity() float64 {
+	opacity := c.cfg.OpacityInactive
+	if c.focused {
+		opacity = c.cfg.Opacity
+	}
+	if c.opacityFixed > 0 {
+		opacity = c.opacityFixed
+	}
+	if opacity <= 0 {
+		return 1
+	}
+	return opacity
+}
+
+// SetOpacity fixes the opacity of the frame, regardless of whether it's focused. The opacity of 0 restores
+// the ones of the config
+func (c *Client) SetOpacity(opacity float64) error {
+	c.opacityFixed = opacity
+	return c.drawOpacity()
+}
+
+// drawOpacity sets the opacity of the parent window, which is applied by the compositor
+func (c *Client) drawOpacity() error {
+	opacity := c.Opacity()
+	if c.parent == 0 || opacity == c.opacity {
+		return nil
+	}
+	if err := c.x11.SetWindowOpacity(c.parent, opacity); err != nil {
+		return fmt.Errorf("could not set the opacity: %w", err)
+	}
+	c.opacity = opacity
+	return nil
+}
+
+// drawBorder sets the background of the parent window, i.e. the color of the border, to the one of
+// the current state. The X server keeps painting it, so it's only changed when the color differs
+func (c *Client) drawBorder() error {
+	color := c.stateBorderColor()
+	if c.parent == 0 || color == c.borderColor {
+		return nil
+	}
+	if err := c.x11.SetWindowBackground(c.parent, color); err != nil {
+		return fmt.Errorf("could not set the border color: %w", err)
+	}
+	c.borderColor = color
+	return nil
+}
+
+func (c *Client) stateBorderColor() uint32 {
+	if c.focused {
+		return c.cfg.BorderColor
+	}
+	if c.urgent {
+		return c.cfg.BorderColorUrgent
+	}
+	return c.cfg.BorderColorInactive
+}
+
 // Update compares the desired state of the client against the actual state and executes updates
 // aimed at reaching the desired state
 func (c *Client) Update() error {
@@ -78,15 +260,26 @@ func (c *Client) Map() error {
 		return fmt.Errorf("could not map window: %w", err)
 	}
 	c.mapped = true
+	c.viewable = true
+	if err := c.x11.SetWMState(c.window, normalState); err != nil {
+		return fmt.Errorf("could not set WM_STATE: %w", err)
+	}
 	return nil
 }
 
-// Unmap causes the client window to be unmapped. This in turn sends the UnmapNotify event
-// that is then handled by (*Client).OnUnmap
+// Unmap causes the client window to be unmapped, moving it to the iconic state. This in turn sends
+// the UnmapNotify event that is then handled by (*Client).OnUnmap
 func (c *Client) Unmap() error {
 	if err := c.x11.UnmapWindow(c.window); err != nil {
 		return fmt.Errorf("could not unmap window: %w", err)
 	}
+	if c.viewable {
+		c.viewable = false
+		c.ignoreUnmaps++
+	}
+	if err := c.x11.SetWMState(c.window, iconicState); err != nil {
+		return fmt.Errorf("could not set WM_STATE: %w", err)
+	}
 	return nil
 }
 
@@ -101,45 +294,73 @@ func (c *Client) OnDestroy() error {
 }
 
 // OnUnmap is called when the WM receives the UnmapNotify event (e.g. when the client window
-// is closed by user action or when requested by the program itself)
-func (c *Client) OnUnmap() error {
+// is closed by user action or when requested by the program itself). It reports whether the client
+// withdrew the window, as opposed to the WM hiding it
+func (c *Client) OnUnmap() (bool, error) {
+	withdrawn := c.ignoreUnmaps == 0
+	if withdrawn {
+		c.viewable = false
+	} else {
+		c.ignoreUnmaps--
+	}
 	if !c.mapped {
-		return nil
+		return withdrawn, nil
 	}
 	if c.parent != 0 {
 		if err := c.x11.UnmapWindow(c.parent); err != nil {
-			return fmt.Errorf("could not unmap parent: %w", err)
+			return withdrawn, fmt.Errorf("could not unmap parent: %w", err)
 		}
 	}
 	c.mapped = false
-	return nil
+	return withdrawn, nil
 }
 
 func (c *Client) OnProperty(atom xproto.Atom) {
 	switch atom {
-	case c.x11.Atom("_NET_WM_NAME"):
+	case c.x11.Atom("_NET_WM_NAME"), xproto.AtomWmName:
 		c.updateTitleProperty()
+	case xproto.AtomWmClass:
+		c.updateClassProperty()
+		if err := c.drawTitlebar(); err != nil {
+			logger.Errorf("Failed to draw titlebar of client %v: %v", c.window, err)
+		}
 	}
 }
 
-// createParent generates an X window and sets it up so that it can be used for reparenting
+// createParent generates an X window and sets it up so that it can be used for reparenting. The parent of
+// a window with an ARGB visual gets the same visual, depth and colormap, otherwise the transparent parts
+// of the window would show black instead of what's below it
 func (c *Client) createParent() (xproto.Window, error) {
+	events := uint32(xproto.EventMaskSubstructureRedirect |
+		xproto.EventMaskExposure |
+		xproto.EventMaskButtonPress |
+		xproto.EventMaskButtonRelease |
+		xproto.EventMaskPointerMotion |
+		xproto.EventMaskFocusChange)
+	if visual, depth, colormap, err := c.x11.GetWindowVisual(c.window); err == nil && depth == argbDepth {
+		c.argb = true
+		return c.x11.CreateVisualWindow(c.x11.GetRootWindow(),
+			0, 0, 1, 1, 0, xproto.WindowClassInputOutput, visual, depth,
+			xproto.CwBackPixel|xproto.CwBorderPixel|xproto.CwOverrideRedirect|xproto.CwEventMask|xproto.CwColormap,
+			[]uint32{c.stateBorderColor(), 0, 1, events, uint32(colormap)},
+		)
+	}
 	return c.x11.CreateWindow(c.x11.GetRootWindow(),
 		0, 0, 1, 1, 0, xproto.WindowClassInputOutput,
 		xproto.CwBackPixel|xproto.CwOverrideRedirect|xproto.CwEventMask,
-		[]uint32{
-			0xffa1d1cf,
-			1,
-			xproto.EventMaskSubstructureRedirect |
-				xproto.EventMaskExposure |
-				xproto.EventMaskButtonPress |
-				xproto.EventMaskButtonRelease |
-				xproto.EventMaskFocusChange,
-		},
+		[]uint32{c.stateBorderColor(), 1, events},
 	)
 }
 
 func (c *Client) reparent(parent xproto.Window) error {
+	// the X server gives the window back to the root window if the WM exits (or restarts) without doing so
+	if err := c.x11.AddToSaveSet(c.window); err != nil {
+		return fmt.Errorf("could not add window to the save-set: %w", err)
+	}
+	// reparenting a mapped window unmaps it first
+	if c.x11.IsMapped(c.window) {
+		c.ignoreUnmaps++
+	}
 	if err := c.x11.ReparentWindow(c.window, parent, 0, 0); err != nil {
 		return fmt.Errorf("could not reparent window: %w", err)
 	}
@@ -151,7 +372,13 @@ func (c *Client) updateTitleProperty() {
 	if v, err := c.x11.GetWindowTitle(c.window); err == nil {
 		c.title = v
 		if err := c.drawTitlebar(); err != nil {
-			log.Printf("Failed to draw titlebar of client %v: %v\n", c.window, err)
+			logger.Errorf("Failed to draw titlebar of client %v: %v", c.window, err)
 		}
 	}
 }
+
+func (c *Client) updateClassProperty() {
+	if instance, class, err := c.x11.GetWMClass(c.window); err == nil {
+		c.instance, c.class = instance, class
+	}
+}
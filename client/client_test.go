@@ -27,4 +27,206 @@ func TestNew(t *testing.T) {
 			t.Errorf("got = %v, want = %v", got, want)
 		}
 	})
+	t.Run("ARGB", func(t *testing.T) {
+		x11 := &mockX11{t: t, depth: 32}
+		c, err := New(x11, &Config{}, window, TypeNormal)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []byte{32}; !reflect.DeepEqual(x11.createdDepths, want) || !c.argb {
+			t.Errorf("got = %v %v, want = %v %v", x11.createdDepths, c.argb, want, true)
+		}
+	})
+}
+
+func TestBorderColor(t *testing.T) {
+	x11 := &mockX11{t: t}
+	cfg := &Config{BorderColor: 1, BorderColorInactive: 2, BorderColorUrgent: 3}
+	c, err := New(x11, cfg, xproto.Window(50), TypeNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name    string
+		focused bool
+		urgent  bool
+		want    uint32
+	}{
+		{"urgent", false, true, 3},
+		{"focused urgent", true, true, 1},
+		{"focused", true, false, 1},
+		{"inactive", false, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.SetUrgent(tt.urgent); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := c.SetFocused(tt.focused); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := x11.backgrounds[c.Parent()]; got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpacity(t *testing.T) {
+	x11 := &mockX11{t: t}
+	cfg := &Config{Opacity: 0.9, OpacityInactive: 0.6}
+	c, err := New(x11, cfg, xproto.Window(50), TypeNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name    string
+		focused bool
+		fixed   float64
+		want    float64
+	}{
+		{"focused", true, 0, 0.9},
+		{"inactive", false, 0, 0.6},
+		{"fixed inactive", false, 0.3, 0.3},
+		{"fixed focused", true, 0.3, 0.3},
+		{"reset", true, 0, 0.9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.SetFocused(tt.focused); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := c.SetOpacity(tt.fixed); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := x11.opacities[c.Parent()]; got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnUnmap(t *testing.T) {
+	x11 := &mockX11{t: t}
+	c, err := New(x11, &Config{}, xproto.Window(50), TypeNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Map(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// hidden by the WM twice in a row, which unmaps it only once
+	if err := c.Unmap(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Unmap(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if withdrawn, _ := c.OnUnmap(); withdrawn {
+		t.Errorf("expected the unmap caused by the WM not to withdraw the window")
+	}
+	if err := c.Map(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if withdrawn, _ := c.OnUnmap(); !withdrawn {
+		t.Errorf("expected the unmap caused by the client to withdraw the window")
+	}
+}
+
+func TestPositionLabel(t *testing.T) {
+	tests := []struct {
+		i, n int
+		want string
+	}{
+		{0, 0, ""},
+		{1, 1, ""},
+		{2, 5, "(2/5) "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := positionLabel(tt.i, tt.n); got != tt.want {
+				t.Errorf("positionLabel() got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkLabel(t *testing.T) {
+	tests := []struct {
+		marks []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "[a] "},
+		{[]string{"a", "work"}, "[a] [work] "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := markLabel(tt.marks); got != tt.want {
+				t.Errorf("markLabel() got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTitle(t *testing.T) {
+	fields := map[string]string{"title": "README.md - Vim", "class": "XTerm", "instance": "xterm"}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"{title}", "README.md - Vim"},
+		{"{class} — {title}", "XTerm — README.md - Vim"},
+		{"[{instance}] {title}", "[xterm] README.md - Vim"},
+		{"{unknown} {title", "{unknown} {title"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := formatTitle(tt.format, fields); got != tt.want {
+				t.Errorf("formatTitle() got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"Firefox", 0, "Firefox"},
+		{"Firefox", 7, "Firefox"},
+		{"Firefox", 5, "Fire…"},
+		{"Žluťoučký kůň", 6, "Žluťo…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := truncate(tt.s, tt.max); got != tt.want {
+				t.Errorf("truncate() got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitLabel(t *testing.T) {
+	// every character is 10 pixels wide
+	measure := func(s string) int { return 10 * len([]rune(s)) }
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{100, "Terminal"},
+		{80, "Terminal"},
+		{50, "Term…"},
+		{5, "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := fitLabel("Terminal", tt.width, measure); got != tt.want {
+				t.Errorf("fitLabel() got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
 }
@@ -1,9 +1,24 @@
 package client
 
+import "github.com/patrislav/marwind/fonts"
+
 type Config struct {
-	TitlebarHeight uint8
-	BorderWidth    uint8
-	BgColor        uint32
-	FontColor      uint32
-	FontSize       float64
+	TitlebarHeight      uint8
+	BorderWidth         uint8
+	BorderColor         uint32 // Border of the focused window
+	BorderColorInactive uint32 // Border of the other windows
+	BorderColorUrgent   uint32 // Border of the windows demanding attention
+	BgColor             uint32 // Titlebar background of the focused window
+	BgColorInactive     uint32 // Titlebar background of the other windows
+	BgColorUrgent       uint32 // Titlebar background of the windows demanding attention
+	FontColor           uint32
+	FontColorInactive   uint32
+	FontSize            float64
+	Fonts               *fonts.Set // Fonts of the titles, the embedded Go font alone if nil
+	TitleFormat         string     // Template of the titles, e.g. "{class} — {title}", DefaultTitleFormat if empty
+	TitleAlign          string     // AlignLeft, AlignCenter (the default) or AlignRight
+	TitlePadding        uint16     // Space kept free at both ends of the title, in pixels
+	TitleMaxLength      int        // Maximum number of characters of the formatted title, 0 for no limit
+	Opacity             float64    // Opacity of the focused window, from 0 (transparent) to 1 (opaque)
+	OpacityInactive     float64    // Opacity of the other windows
 }
@@ -0,0 +1,31 @@
+package client
+
+// Role is the purpose of a normal window announced in its _NET_WM_WINDOW_TYPE, which decides how
+// the window is treated once it's managed
+type Role uint8
+
+const (
+	RoleNormal Role = iota
+	RoleDialog
+	RoleUtility // utility windows and toolbars, e.g. palettes torn off the main window
+	RoleSplash
+	RoleMenu // torn off, dropdown and popup menus
+	RoleTooltip
+	RoleNotification
+)
+
+// Floating returns true if the window starts floating instead of being tiled
+func (r Role) Floating() bool { return r != RoleNormal }
+
+// Decorated returns true if the window gets a border and a titlebar
+func (r Role) Decorated() bool { return r == RoleNormal || r == RoleDialog || r == RoleUtility }
+
+// Focusable returns true if the window can receive the focus, the others are only there to be looked at
+func (r Role) Focusable() bool { return r.Decorated() }
+
+// Above returns true if the window is stacked above the other floating windows
+func (r Role) Above() bool { return r == RoleMenu || r == RoleTooltip || r == RoleNotification }
+
+// OwnPosition returns true if the window is kept where the client placed it rather than centered,
+// e.g. a menu opened under the pointer
+func (r Role) OwnPosition() bool { return r.Above() }
@@ -0,0 +1,28 @@
+package client
+
+import "testing"
+
+func TestRole(t *testing.T) {
+	tests := []struct {
+		role                                  Role
+		floating, decorated, focusable, above bool
+	}{
+		{RoleNormal, false, true, true, false},
+		{RoleDialog, true, true, true, false},
+		{RoleUtility, true, true, true, false},
+		{RoleSplash, true, false, false, false},
+		{RoleMenu, true, false, false, true},
+		{RoleTooltip, true, false, false, true},
+		{RoleNotification, true, false, false, true},
+	}
+	for _, tt := range tests {
+		got := []bool{tt.role.Floating(), tt.role.Decorated(), tt.role.Focusable(), tt.role.Above()}
+		want := []bool{tt.floating, tt.decorated, tt.focusable, tt.above}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("role %d: got = %v, want = %v", tt.role, got, want)
+				break
+			}
+		}
+	}
+}
@@ -4,30 +4,34 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"strings"
 
-	"github.com/BurntSushi/freetype-go/freetype"
-	"github.com/BurntSushi/xgbutil/xgraphics"
-	"golang.org/x/image/font/gofont/goregular"
+	"github.com/patrislav/marwind/fonts"
 )
 
+// Alignments of the title within the titlebar, see Config.TitleAlign
+const (
+	AlignLeft   = "left"
+	AlignCenter = "center"
+	AlignRight  = "right"
+)
+
+// DefaultTitleFormat is the template of the titles used when none is configured
+const DefaultTitleFormat = "{title}"
+
+const ellipsis = "…"
+
 func (c *Client) drawTitlebar() error {
-	width := c.geom.W
-	bg := color.RGBA{
-		A: uint8((c.cfg.BgColor & 0xFF000000) >> 24),
-		R: uint8((c.cfg.BgColor & 0x00FF0000) >> 16),
-		G: uint8((c.cfg.BgColor & 0x0000FF00) >> 8),
-		B: uint8(c.cfg.BgColor & 0x000000FF),
-	}
-	fg := color.RGBA{
-		A: uint8((c.cfg.FontColor & 0xFF000000) >> 24),
-		R: uint8((c.cfg.FontColor & 0x00FF0000) >> 16),
-		G: uint8((c.cfg.FontColor & 0x0000FF00) >> 8),
-		B: uint8(c.cfg.FontColor & 0x000000FF),
+	width := c.titlebarWidth()
+	// nothing to draw before the client is given a size or when titlebars are disabled
+	if width == 0 || c.cfg.TitlebarHeight == 0 || c.TitlebarHidden() {
+		return nil
 	}
-
-	// title should never be zero-length
-	if len(c.title) == 0 {
-		c.title = " "
+	bg, fg := rgba(c.cfg.BgColorInactive), rgba(c.cfg.FontColorInactive)
+	if c.focused {
+		bg, fg = rgba(c.cfg.BgColor), rgba(c.cfg.FontColor)
+	} else if c.urgent {
+		bg, fg = rgba(c.cfg.BgColorUrgent), rgba(c.cfg.FontColor)
 	}
 
 	img := c.x11.NewImage(image.Rect(0, 0, int(width), int(c.cfg.TitlebarHeight)))
@@ -36,13 +40,21 @@ func (c *Client) drawTitlebar() error {
 		return bg.R, bg.G, bg.B, bg.A
 	})
 
-	font, err := freetype.ParseFont(goregular.TTF)
-	if err != nil {
-		return err
+	font := c.cfg.Fonts
+	if font == nil {
+		font = fonts.Default()
 	}
 
+	// the title is placed within the space left of the close button, shortened if it doesn't fit
+	button := c.closeButtonRect()
+	pad := int(c.cfg.TitlePadding)
+	label := fitLabel(c.titleLabel(), button.Min.X-2*pad, func(s string) int {
+		w, _ := font.Extents(c.cfg.FontSize, s)
+		return w
+	})
+
 	// Over estimate the extents
-	ew, eh := xgraphics.Extents(font, c.cfg.FontSize, c.title)
+	ew, eh := font.Extents(c.cfg.FontSize, label)
 
 	// Create an image using the overestimated extents
 	text := c.x11.NewImage(image.Rect(0, 0, ew, eh))
@@ -50,18 +62,32 @@ func (c *Client) drawTitlebar() error {
 	text.ForExp(func(x, y int) (uint8, uint8, uint8, uint8) {
 		return bg.R, bg.G, bg.B, bg.A
 	})
-	_, _, err = text.Text(0, 0, fg, c.cfg.FontSize, font, c.title)
-	if err != nil {
+	if _, err := font.Draw(text, 0, 0, fg, c.cfg.FontSize, label); err != nil {
 		return err
 	}
 
 	bounds := text.Bounds().Size()
 	w, h := bounds.X, bounds.Y
-	x := int(width/2) - w/2
+	var x int
+	switch c.cfg.TitleAlign {
+	case AlignLeft:
+		x = pad
+	case AlignRight:
+		x = button.Min.X - pad - w
+	default:
+		x = button.Min.X/2 - w/2
+	}
+	if x < pad {
+		x = pad
+	}
 	y := int(c.cfg.TitlebarHeight/2) - h/2
-	dstRect := image.Rect(x, y, x+w, y+h)
+	dstRect := image.Rect(x, y, x+w, y+h).Intersect(image.Rect(0, 0, button.Min.X, int(c.cfg.TitlebarHeight)))
 	draw.Draw(img, dstRect, text, image.Point{}, draw.Src)
+	drawCross(img, button, fg)
 
+	if c.argb {
+		return c.x11.PaintARGBImage(c.parent, img, int(c.cfg.BorderWidth), int(c.cfg.BorderWidth))
+	}
 	if err := img.CreatePixmap(); err != nil {
 		return err
 	}
@@ -69,3 +95,133 @@ func (c *Client) drawTitlebar() error {
 	img.XExpPaint(c.parent, int(c.cfg.BorderWidth), int(c.cfg.BorderWidth))
 	return nil
 }
+
+// titleLabel returns the text of the titlebar: the position and the marks of the window followed by the title
+// formatted with the template of the config and shortened to its maximum length
+func (c *Client) titleLabel() string {
+	format := c.cfg.TitleFormat
+	if format == "" {
+		format = DefaultTitleFormat
+	}
+	title := formatTitle(format, map[string]string{
+		"title":    c.title,
+		"class":    c.class,
+		"instance": c.instance,
+	})
+	title = truncate(title, c.cfg.TitleMaxLength)
+	label := c.position + c.marks + title
+	// the label should never be zero-length
+	if label == "" {
+		label = " "
+	}
+	return label
+}
+
+// formatTitle replaces the placeholders of the template, e.g. "{class}", with the values of the fields.
+// Unknown placeholders are left as they are
+func formatTitle(format string, fields map[string]string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(format, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(format[:start])
+		if v, ok := fields[format[start+1:end]]; ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(format[start : end+1])
+		}
+		format = format[end+1:]
+	}
+	b.WriteString(format)
+	return b.String()
+}
+
+// truncate shortens the text to at most max characters, the last of them being an ellipsis, 0 for no limit
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if max <= 0 || len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + ellipsis
+}
+
+// fitLabel shortens the label, ending it with an ellipsis, until its width measured with the function
+// doesn't exceed the given one
+func fitLabel(label string, width int, measure func(string) int) string {
+	if measure(label) <= width {
+		return label
+	}
+	runes := []rune(label)
+	for n := len(runes) - 1; n > 0; n-- {
+		if s := string(runes[:n]) + ellipsis; measure(s) <= width {
+			return s
+		}
+	}
+	return ellipsis
+}
+
+// CloseButtonContains checks whether the point, relative to the frame (parent) window, lies within the close button
+func (c *Client) CloseButtonContains(x, y int16) bool {
+	if c.parent == 0 || c.titlebarWidth() == 0 || c.cfg.TitlebarHeight == 0 || c.TitlebarHidden() {
+		return false
+	}
+	border := int(c.cfg.BorderWidth)
+	pt := image.Pt(int(x)-border, int(y)-border)
+	return pt.In(c.closeButtonRect())
+}
+
+// TitlebarContains checks whether the point, relative to the frame (parent) window, lies within the titlebar
+func (c *Client) TitlebarContains(x, y int16) bool {
+	if c.parent == 0 || c.titlebarWidth() == 0 || c.cfg.TitlebarHeight == 0 || c.TitlebarHidden() {
+		return false
+	}
+	border := int(c.cfg.BorderWidth)
+	pt := image.Pt(int(x)-border, int(y)-border)
+	return pt.In(image.Rect(0, 0, int(c.titlebarWidth()), int(c.cfg.TitlebarHeight)))
+}
+
+// titlebarWidth returns the width of the titlebar, i.e. the width of the frame without the borders
+func (c *Client) titlebarWidth() uint16 {
+	borders := 2 * uint16(c.cfg.BorderWidth)
+	if c.geom.W <= borders {
+		return 0
+	}
+	return c.geom.W - borders
+}
+
+// closeButtonRect returns the area of the close button: a square at the right end of the titlebar
+func (c *Client) closeButtonRect() image.Rectangle {
+	width := int(c.titlebarWidth())
+	size := int(c.cfg.TitlebarHeight)
+	if size > width {
+		size = width
+	}
+	return image.Rect(width-size, 0, width, size)
+}
+
+// drawCross draws an "x" sign in the middle of the given square
+func drawCross(img draw.Image, rect image.Rectangle, c color.Color) {
+	pad := rect.Dx() / 4
+	size := rect.Dx() - 2*pad
+	for i := 0; i < size; i++ {
+		img.Set(rect.Min.X+pad+i, rect.Min.Y+pad+i, c)
+		img.Set(rect.Max.X-pad-1-i, rect.Min.Y+pad+i, c)
+	}
+}
+
+// rgba converts a color given as 0xAARRGGBB
+func rgba(c uint32) color.RGBA {
+	return color.RGBA{
+		A: uint8((c & 0xFF000000) >> 24),
+		R: uint8((c & 0x00FF0000) >> 16),
+		G: uint8((c & 0x0000FF00) >> 8),
+		B: uint8(c & 0x000000FF),
+	}
+}
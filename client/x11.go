@@ -15,14 +15,28 @@ type x11 interface {
 		borderWidth uint16,
 		class uint16, valueMask uint32, valueList []uint32,
 	) (xproto.Window, error)
+	CreateVisualWindow(
+		parent xproto.Window,
+		x int16, y int16, width uint16, height uint16,
+		borderWidth uint16,
+		class uint16, visual xproto.Visualid, depth byte, valueMask uint32, valueList []uint32,
+	) (xproto.Window, error)
+	GetWindowVisual(window xproto.Window) (xproto.Visualid, byte, xproto.Colormap, error)
 
 	MapWindow(window xproto.Window) error
 	UnmapWindow(window xproto.Window) error
 	DestroyWindow(window xproto.Window) error
 	ReparentWindow(window, parent xproto.Window, x, y int16) error
+	AddToSaveSet(window xproto.Window) error
+	SetWindowBackground(window xproto.Window, pixel uint32) error
+	SetWindowOpacity(window xproto.Window, opacity float64) error
+	SetWMState(window xproto.Window, state uint32) error
+	IsMapped(window xproto.Window) bool
 
 	GetWindowTitle(window xproto.Window) (string, error)
+	GetWMClass(window xproto.Window) (instance, class string, err error)
 	Atom(name string) xproto.Atom
 
 	NewImage(rect image.Rectangle) *xgraphics.Image
+	PaintARGBImage(win xproto.Window, img *xgraphics.Image, x, y int) error
 }
@@ -20,6 +20,10 @@ type mockX11 struct {
 	// unmappedWins   []xproto.Window
 	// destroyedWins  []xproto.Window
 	reparentedWins []mockReparented
+	backgrounds    map[xproto.Window]uint32
+	opacities      map[xproto.Window]float64
+	depth          byte   // depth of the client windows, 24 if not set
+	createdDepths  []byte // depths of the created windows
 }
 
 func (mx *mockX11) GetRootWindow() xproto.Window {
@@ -31,9 +35,27 @@ func (mx *mockX11) CreateWindow(
 	borderWidth uint16,
 	class uint16, valueMask uint32, valueList []uint32,
 ) (xproto.Window, error) {
+	mx.createdDepths = append(mx.createdDepths, 24)
 	return 1, nil
 }
 
+func (mx *mockX11) CreateVisualWindow(
+	parent xproto.Window,
+	x int16, y int16, width uint16, height uint16,
+	borderWidth uint16,
+	class uint16, visual xproto.Visualid, depth byte, valueMask uint32, valueList []uint32,
+) (xproto.Window, error) {
+	mx.createdDepths = append(mx.createdDepths, depth)
+	return 1, nil
+}
+
+func (mx *mockX11) GetWindowVisual(window xproto.Window) (xproto.Visualid, byte, xproto.Colormap, error) {
+	if mx.depth == 0 {
+		return 0, 24, 0, nil
+	}
+	return 0, mx.depth, 0, nil
+}
+
 func (mx *mockX11) MapWindow(window xproto.Window) error {
 	return nil
 }
@@ -53,9 +75,40 @@ func (mx *mockX11) ReparentWindow(window, parent xproto.Window, x, y int16) erro
 	return nil
 }
 
+func (mx *mockX11) AddToSaveSet(window xproto.Window) error {
+	return nil
+}
+
+func (mx *mockX11) SetWindowBackground(window xproto.Window, pixel uint32) error {
+	if mx.backgrounds == nil {
+		mx.backgrounds = make(map[xproto.Window]uint32)
+	}
+	mx.backgrounds[window] = pixel
+	return nil
+}
+
+func (mx *mockX11) SetWindowOpacity(window xproto.Window, opacity float64) error {
+	if mx.opacities == nil {
+		mx.opacities = make(map[xproto.Window]float64)
+	}
+	mx.opacities[window] = opacity
+	return nil
+}
+
+func (mx *mockX11) SetWMState(window xproto.Window, state uint32) error {
+	return nil
+}
+
+func (mx *mockX11) IsMapped(window xproto.Window) bool {
+	return false
+}
+
 func (mx *mockX11) GetWindowTitle(window xproto.Window) (string, error) {
 	return "", nil
 }
+func (mx *mockX11) GetWMClass(window xproto.Window) (string, string, error) {
+	return "", "", nil
+}
 func (mx *mockX11) Atom(name string) xproto.Atom {
 	return 0
 }
@@ -63,3 +116,7 @@ func (mx *mockX11) Atom(name string) xproto.Atom {
 func (mx *mockX11) NewImage(rect image.Rectangle) *xgraphics.Image {
 	return nil
 }
+
+func (mx *mockX11) PaintARGBImage(win xproto.Window, img *xgraphics.Image, x, y int) error {
+	return nil
+}
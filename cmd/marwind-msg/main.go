@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/patrislav/marwind/ipc"
+)
+
+var (
+	version   string // program version
+	buildTime string // when the executable was built
+)
+
+var (
+	flagVersion bool
+	msgType     string
+	socketPath  string
+)
+
+func main() {
+	flag.BoolVar(&flagVersion, "version", false, "show version and exit")
+	flag.StringVarP(&msgType, "type", "t", ipc.TypeCommand, "type of the message: command, get_tree, get_workspaces, get_outputs, get_minimized, get_marks, subscribe")
+	flag.StringVarP(&socketPath, "socket", "s", "", "path to the IPC socket (taken from the environment by default)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] [message]\n\nExamples:\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s workspace 2\n  %s move to workspace 3\n  %s -t get_tree\n  %s -t subscribe workspace window\n\n", os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flagVersion {
+		fmt.Printf("marwind-msg version:\t%s (%s)\n", version, buildTime)
+		fmt.Printf("go version:\t%s\n", runtime.Version())
+		os.Exit(0)
+	}
+	if socketPath == "" {
+		socketPath = ipc.SocketPath()
+	}
+
+	c, err := ipc.Dial(socketPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer c.Close()
+
+	if msgType == ipc.TypeSubscribe {
+		if err := printEvents(c, flag.Args()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	resp, err := c.Send(ipc.Request{Type: msgType, Payload: strings.Join(flag.Args(), " ")})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := printResponse(resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !resp.Success {
+		os.Exit(2)
+	}
+}
+
+// printEvents subscribes to the given types of events and writes them, one JSON object per line,
+// until the connection is closed
+func printEvents(c *ipc.Client, types []string) error {
+	if err := c.Subscribe(types...); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		ev, err := c.ReadEvent()
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+}
+
+// printResponse writes the response's data, or the entire response if it carries no data, as indented JSON
+func printResponse(resp *ipc.Response) error {
+	var out bytes.Buffer
+	if resp.Success && len(resp.Data) > 0 {
+		if err := json.Indent(&out, resp.Data, "", "  "); err != nil {
+			return err
+		}
+	} else {
+		b, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			return err
+		}
+		out.Write(b)
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(os.Stdout)
+	return err
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"runtime"
@@ -10,6 +9,8 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/patrislav/marwind"
+	"github.com/patrislav/marwind/config"
+	"github.com/patrislav/marwind/logger"
 	"github.com/patrislav/marwind/wm"
 )
 
@@ -21,11 +22,23 @@ var (
 var (
 	flagVersion bool
 	initCmd     string
+	configPath  string
+	replace     bool
+	checkConfig bool
+	logLevel    string
+	display     string
+	session     bool
 )
 
 func main() {
 	flag.BoolVar(&flagVersion, "version", false, "show version and exit")
 	flag.StringVar(&initCmd, "init", "", "run this executable at startup")
+	flag.StringVarP(&configPath, "config", "c", config.DefaultPath(), "path to the config file")
+	flag.BoolVar(&replace, "replace", false, "replace the currently running window manager")
+	flag.BoolVar(&checkConfig, "check-config", false, "check the config file for errors and exit")
+	flag.StringVar(&logLevel, "log-level", "", "log level (debug, info, warn or error), overriding the config file")
+	flag.StringVarP(&display, "display", "d", "", "X display to manage, instead of $DISPLAY")
+	flag.BoolVar(&session, "session", false, "set up the session environment, when started by a display manager")
 	flag.Parse()
 
 	if flagVersion {
@@ -34,20 +47,62 @@ func main() {
 		os.Exit(0)
 	}
 
-	mgr, err := wm.New(marwind.Config)
+	if checkConfig {
+		if err := config.Check(configPath, marwind.Config); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: OK\n", configPath)
+		os.Exit(0)
+	}
+
+	if logLevel != "" {
+		if _, err := logger.ParseLevel(logLevel); err != nil {
+			logger.Fatalf("Invalid --log-level: %v", err)
+		}
+	}
+	if display != "" {
+		if err := os.Setenv("DISPLAY", display); err != nil {
+			logger.Fatalf("Failed to set DISPLAY: %v", err)
+		}
+	}
+	// after a restart the environment was already set up by the previous instance
+	if session && !wm.Restarted() {
+		setupSession()
+	}
+
+	loader := func() (wm.Config, error) {
+		cfg, err := config.Load(configPath, marwind.Config)
+		if logLevel != "" {
+			cfg.LogLevel = logLevel
+		}
+		return cfg, err
+	}
+	cfg, err := loader()
+	if err != nil {
+		logger.Errorf("Failed to load config, using the defaults: %v", err)
+		cfg = marwind.Config
+		if logLevel != "" {
+			cfg.LogLevel = logLevel
+		}
+	}
+
+	mgr, err := wm.New(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatalf("%v", err)
 	}
+	mgr.SetConfigLoader(loader)
+	mgr.SetReplace(replace)
 	defer mgr.Close()
 	if err := mgr.Init(); err != nil {
-		log.Fatal(err)
+		logger.Fatalf("%v", err)
 	}
 
 	if initCmd != "" {
 		cmd := exec.Command(initCmd)
 		err = cmd.Start()
 		if err != nil {
-			log.Fatal(err)
+			logger.Fatalf("%v", err)
 		}
 		go func() {
 			_ = cmd.Wait()
@@ -55,6 +110,6 @@ func main() {
 	}
 
 	if err := mgr.Run(); err != nil {
-		log.Fatal(err)
+		logger.Fatalf("%v", err)
 	}
 }
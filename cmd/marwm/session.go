@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// desktopName identifies the session in the XDG_* variables, e.g. for the desktop-specific autostart entries
+const desktopName = "marwind"
+
+// sessionVars are exported to the session if the display manager didn't set them
+var sessionVars = [][2]string{
+	{"XDG_CURRENT_DESKTOP", desktopName},
+	{"XDG_SESSION_DESKTOP", desktopName},
+	{"DESKTOP_SESSION", desktopName},
+	{"XDG_SESSION_TYPE", "x11"},
+}
+
+// activationVars are passed on to the D-Bus and systemd user services, which are started outside the session
+var activationVars = []string{"DISPLAY", "XAUTHORITY", "XDG_CURRENT_DESKTOP", "XDG_SESSION_DESKTOP", "XDG_SESSION_TYPE"}
+
+// setupSession prepares the environment of a session started by a display manager, which doesn't go
+// through the user's ~/.xinitrc: it exports the variables describing the session, runs the xprofile
+// hooks (/etc/xprofile and ~/.xprofile) keeping the variables they set, and makes the environment
+// available to the D-Bus activated and systemd user services
+func setupSession() {
+	for _, v := range sessionVars {
+		if os.Getenv(v[0]) == "" {
+			if err := os.Setenv(v[0], v[1]); err != nil {
+				logger.Errorf("Failed to set %s: %v", v[0], err)
+			}
+		}
+	}
+	profiles := []string{"/etc/xprofile"}
+	if home, err := os.UserHomeDir(); err == nil {
+		profiles = append(profiles, filepath.Join(home, ".xprofile"))
+	}
+	for _, path := range profiles {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := sourceProfile(path); err != nil {
+			logger.Errorf("Failed to run %s: %v", path, err)
+		}
+	}
+	if _, err := exec.LookPath("dbus-update-activation-environment"); err == nil {
+		args := append([]string{"--systemd"}, activationVars...)
+		if out, err := exec.Command("dbus-update-activation-environment", args...).CombinedOutput(); err != nil {
+			logger.Errorf("Failed to update the activation environment: %v: %s", err, bytes.TrimSpace(out))
+		}
+	}
+}
+
+// sourceProfile runs the shell script in a shell and exports the environment it ends with, so that
+// the variables it sets reach the programs started by the WM
+func sourceProfile(path string) error {
+	// the script's output is discarded so that the programs it starts in the background don't hold
+	// the pipe open
+	cmd := exec.Command("/bin/sh", "-c", `. "$0" >/dev/null 2>&1 </dev/null && env -0`, path)
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	for _, v := range strings.Split(string(out), "\x00") {
+		i := strings.IndexByte(v, '=')
+		if i <= 0 {
+			continue
+		}
+		name, value := v[:i], v[i+1:]
+		// variables of the shell itself
+		if name == "_" || name == "PWD" || name == "OLDPWD" || name == "SHLVL" {
+			continue
+		}
+		if os.Getenv(name) != value {
+			if err := os.Setenv(name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
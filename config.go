@@ -1,30 +1,154 @@
 package marwind
 
 import (
-	"github.com/BurntSushi/xgb/xproto"
-	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/client"
 	"github.com/patrislav/marwind/wm"
 )
 
 var Config = wm.Config{
-	InnerGap:                4,
-	OuterGap:                4,
-	Shell:                   "/bin/sh",
-	LauncherCommand:         "rofi -show drun",
-	TerminalCommand:         "alacritty",
-	BorderWidth:             0,
-	BorderColor:             0xffa1d1cf,
-	TitleBarHeight:          18,
-	TitleBarBgColor:         0xffa1d1cf,
-	TitleBarFontColorActive: 0xff000000,
-	TitleBarFontSize:        12,
-	Keybindings: map[xproto.Keysym]string{
+	InnerGap:                  4,
+	OuterGap:                  4,
+	SmartGaps:                 true,
+	FocusFollowsMouse:         true,
+	MouseWarping:              wm.WarpFocus,
+	MouseWarpingPosition:      wm.WarpCenter,
+	EdgeDelay:                 300,
+	DockHideDelay:             500,
+	FocusOnActivation:         wm.ActivationSmart,
+	FocusStealingPrevention:   true,
+	Placement:                 wm.PlacementAuto,
+	FloatingStep:              10,
+	SnapDistance:              10,
+	FloatingRemember:          true,
+	FullscreenNotifications:   wm.NotificationsAbove,
+	NotificationCorner:        wm.NotificationCornerTopRight,
+	NotificationMax:           5,
+	IdleInhibitFullscreen:     true,
+	Shell:                     "/bin/sh",
+	Mod:                       "mod4",
+	BorderWidth:               0,
+	BorderColor:               0xffa1d1cf,
+	BorderColorInactive:       0xff5f7a79,
+	BorderColorUrgent:         0xffd0705e,
+	Opacity:                   1,
+	OpacityInactive:           1,
+	TitleBarHeight:            18,
+	TitleBarBgColor:           0xffa1d1cf,
+	TitleBarBgColorInactive:   0xff5f7a79,
+	TitleBarBgColorUrgent:     0xffd0705e,
+	TitleBarFontColorActive:   0xff000000,
+	TitleBarFontColorInactive: 0xffd8e6e5,
+	TitleBarFontSize:          12,
+	TitleBarShowMarks:         true,
+	TitleBarFormat:            client.DefaultTitleFormat,
+	TitleBarAlign:             client.AlignCenter,
+	TitleBarPadding:           4,
+	TrayIconSize:              20,
+	TrayBgColor:               0xff5f7a79,
+	OSDFontSize:               24,
+	OSDFontColor:              0xffd8e6e5,
+	OSDBgColor:                0xe0303a3a,
+	OSDTimeout:                1000,
+	SwallowClasses:            []string{"Alacritty", "XTerm", "URxvt", "kitty", "st-256color"},
+	Modes: map[string]map[string]string{
+		"resize": {
+			"h":      "resize shrink width 5",
+			"j":      "resize grow height 5",
+			"k":      "resize shrink height 5",
+			"l":      "resize grow width 5",
+			"Return": "mode default",
+		},
+	},
+	Mousebindings: map[string]string{
+		"mod+button2":   "kill",
+		"mod+wheelup":   "workspace prev",
+		"mod+wheeldown": "workspace next",
+	},
+	Keybindings: map[string]string{
+		"mod+shift+q":      "kill",
+		"mod+shift+alt+t":  "quit",
+		"mod+u":            "focus urgent",
+		"mod+shift+c":      "reload",
+		"mod+shift+r":      "restart",
+		"mod+d":            "exec rofi -show drun",
+		"mod+shift+d":      "launcher",
+		"mod+shift+Return": "exec alacritty",
+		// Moving the focus
+		"mod+h": "focus left",
+		"mod+j": "focus down",
+		"mod+k": "focus up",
+		"mod+l": "focus right",
+		// Cycling the focus in the most recently used order, committed when mod is released
+		"mod+Tab":       "focus cycle",
+		"mod+shift+Tab": "focus cycle back",
+		// Picking a workspace or a window from their thumbnails
+		"mod+o":      "overview",
+		"mod+ctrl+o": "overview windows",
+		"mod+g":      "goto",
+		// Moving windows
+		"mod+shift+h": "move left",
+		"mod+shift+j": "move down",
+		"mod+shift+k": "move up",
+		"mod+shift+l": "move right",
+		"mod+ctrl+h":  "swap left",
+		"mod+ctrl+j":  "swap down",
+		"mod+ctrl+k":  "swap up",
+		"mod+ctrl+l":  "swap right",
+		// Window state
+		"mod+shift+space": "floating toggle",
+		"mod+shift+s":     "sticky toggle",
+		"mod+n":           "minimize",
+		"mod+shift+n":     "restore",
+		"mod+f":           "fullscreen toggle",
+		"mod+m":           "maximize toggle",
+		"mod+alt+equal":   "opacity plus 0.1",
+		"mod+alt+minus":   "opacity minus 0.1",
+		// Column layout
+		"mod+s":            "layout toggle",
+		"mod+w":            "monocle toggle",
+		"mod+b":            "split horizontal",
+		"mod+v":            "split vertical",
+		"mod+bracketright": "tab next",
+		"mod+bracketleft":  "tab prev",
+		// Scratchpad
+		"mod+shift+minus": "move scratchpad",
+		"mod+minus":       "scratchpad show",
+		// Resizing windows
+		"mod+shift+y": "resize shrink width 5",
+		"mod+shift+u": "resize grow height 5",
+		"mod+shift+i": "resize shrink height 5",
+		"mod+shift+o": "resize grow width 5",
+		"mod+r":       "mode resize",
+		// Workspaces
+		"mod+1":       "workspace 1",
+		"mod+2":       "workspace 2",
+		"mod+3":       "workspace 3",
+		"mod+4":       "workspace 4",
+		"mod+5":       "workspace 5",
+		"mod+6":       "workspace 6",
+		"mod+7":       "workspace 7",
+		"mod+8":       "workspace 8",
+		"mod+9":       "workspace 9",
+		"mod+0":       "workspace 10",
+		"mod+grave":   "workspace back_and_forth",
+		"mod+comma":   "workspace prev",
+		"mod+period":  "workspace next",
+		"mod+shift+1": "move to workspace 1",
+		"mod+shift+2": "move to workspace 2",
+		"mod+shift+3": "move to workspace 3",
+		"mod+shift+4": "move to workspace 4",
+		"mod+shift+5": "move to workspace 5",
+		"mod+shift+6": "move to workspace 6",
+		"mod+shift+7": "move to workspace 7",
+		"mod+shift+8": "move to workspace 8",
+		"mod+shift+9": "move to workspace 9",
+		"mod+shift+0": "move to workspace 10",
 		// Brightness control
-		keysym.XF86MonBrightnessDown: "light -U 5",
-		keysym.XF86MonBrightnessUp:   "light -A 5",
+		"XF86MonBrightnessDown": "exec light -U 5",
+		"XF86MonBrightnessUp":   "exec light -A 5",
 		// Volume control
-		keysym.XF86AudioMute:        "pactl set-sink-mute @DEFAULT_SINK@ toggle",
-		keysym.XF86AudioLowerVolume: "pactl set-sink-volume @DEFAULT_SINK@ -5%",
-		keysym.XF86AudioRaiseVolume: "pactl set-sink-volume @DEFAULT_SINK@ +5%",
+		"XF86AudioMute":        "exec pactl set-sink-mute @DEFAULT_SINK@ toggle",
+		"XF86AudioLowerVolume": "exec pactl set-sink-volume @DEFAULT_SINK@ -5%",
+		"XF86AudioRaiseVolume": "exec pactl set-sink-volume @DEFAULT_SINK@ +5%",
 	},
 }
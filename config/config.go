@@ -0,0 +1,792 @@
+// Package config loads the WM's configuration from a TOML file
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/fonts"
+	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/wm"
+	"github.com/patrislav/marwind/xdg"
+)
+
+// file mirrors the structure of the config file. Values that are not set in the file are left nil
+// so that the defaults can be used instead
+type file struct {
+	InnerGap     *uint16  `toml:"inner_gap"`
+	OuterGap     *uint16  `toml:"outer_gap"`
+	SmartGaps    *bool    `toml:"smart_gaps"`
+	SmartBorders *bool    `toml:"smart_borders"`
+	Shell        *string  `toml:"shell"`
+	Mod          *string  `toml:"mod"`
+	Startup      []string `toml:"startup"`
+	Fonts        []string `toml:"fonts"`
+
+	StartupAlways []string `toml:"startup_always"`
+	XDGAutostart  *bool    `toml:"xdg_autostart"`
+
+	KillTimeout *uint16 `toml:"kill_timeout"`
+	FocusWrap   *bool   `toml:"focus_wrap"`
+
+	FocusFollowsMouse *bool       `toml:"focus_follows_mouse"`
+	MouseWarping      interface{} `toml:"mouse_warping"`
+	FocusOnActivation *string     `toml:"focus_on_activation"`
+
+	MouseWarpingPosition *string `toml:"mouse_warping_position"`
+
+	FocusStealingPrevention *bool   `toml:"focus_stealing_prevention"`
+	Placement               *string `toml:"placement"`
+
+	WorkspaceIncludeEmpty     *bool `toml:"workspace_include_empty"`
+	WorkspaceAutoBackAndForth *bool `toml:"workspace_auto_back_and_forth"`
+
+	Edges struct {
+		Left   *string `toml:"left"`
+		Right  *string `toml:"right"`
+		Top    *string `toml:"top"`
+		Bottom *string `toml:"bottom"`
+		Delay  *uint16 `toml:"delay"`
+	} `toml:"edges"`
+
+	Floating struct {
+		Step         *uint16 `toml:"step"`
+		SnapDistance *uint16 `toml:"snap_distance"`
+		Remember     *bool   `toml:"remember"`
+	} `toml:"floating"`
+
+	Docks struct {
+		AutoHide  *bool    `toml:"auto_hide"`
+		HideDelay *uint16  `toml:"hide_delay"`
+		Order     []string `toml:"order"`
+	} `toml:"docks"`
+
+	Fullscreen struct {
+		FocusLock     *bool   `toml:"focus_lock"`
+		Notifications *string `toml:"notifications"`
+	} `toml:"fullscreen"`
+
+	Notifications struct {
+		Corner *string `toml:"corner"`
+		Max    *uint16 `toml:"max"`
+	} `toml:"notifications"`
+
+	Idle struct {
+		Hooks             []idleHook `toml:"hooks"`
+		InhibitFullscreen *bool      `toml:"inhibit_fullscreen"`
+		DPMS              struct {
+			Standby *uint16 `toml:"standby"`
+			Suspend *uint16 `toml:"suspend"`
+			Off     *uint16 `toml:"off"`
+		} `toml:"dpms"`
+	} `toml:"idle"`
+
+	Border struct {
+		Width         *uint8  `toml:"width"`
+		Color         *string `toml:"color"`
+		ColorInactive *string `toml:"color_inactive"`
+		ColorUrgent   *string `toml:"color_urgent"`
+	} `toml:"border"`
+
+	Titlebar struct {
+		Height            *uint8   `toml:"height"`
+		BgColor           *string  `toml:"bg_color"`
+		BgColorInactive   *string  `toml:"bg_color_inactive"`
+		BgColorUrgent     *string  `toml:"bg_color_urgent"`
+		FontColorActive   *string  `toml:"font_color_active"`
+		FontColorInactive *string  `toml:"font_color_inactive"`
+		FontSize          *float64 `toml:"font_size"`
+		ShowMarks         *bool    `toml:"show_marks"`
+		Format            *string  `toml:"format"`
+		Align             *string  `toml:"align"`
+		Padding           *uint16  `toml:"padding"`
+		MaxLength         *int     `toml:"max_length"`
+	} `toml:"titlebar"`
+
+	Tray struct {
+		Enabled  *bool   `toml:"enabled"`
+		IconSize *uint16 `toml:"icon_size"`
+		BgColor  *string `toml:"bg_color"`
+	} `toml:"tray"`
+
+	Cursor struct {
+		Theme *string `toml:"theme"`
+		Size  *uint16 `toml:"size"`
+	} `toml:"cursor"`
+
+	DPI struct {
+		Detect  *bool                  `toml:"detect"`
+		Outputs map[string]interface{} `toml:"outputs"`
+	} `toml:"dpi"`
+
+	OSD struct {
+		Enabled   *bool    `toml:"enabled"`
+		FontSize  *float64 `toml:"font_size"`
+		FontColor *string  `toml:"font_color"`
+		BgColor   *string  `toml:"bg_color"`
+		Timeout   *uint16  `toml:"timeout"`
+		Font      *string  `toml:"font"`
+	} `toml:"osd"`
+
+	Swallow struct {
+		Enabled *bool    `toml:"enabled"`
+		Classes []string `toml:"classes"`
+	} `toml:"swallow"`
+
+	Opacity struct {
+		Focused  interface{} `toml:"focused"`
+		Inactive interface{} `toml:"inactive"`
+	} `toml:"opacity"`
+
+	Log struct {
+		Level  *string `toml:"level"`
+		File   *string `toml:"file"`
+		Events *bool   `toml:"events"`
+	} `toml:"log"`
+
+	Keybindings      map[string]string            `toml:"keybindings"`
+	Mousebindings    map[string]string            `toml:"mousebindings"`
+	Modes            map[string]map[string]string `toml:"modes"`
+	WorkspaceOutputs map[string]string            `toml:"workspace_outputs"`
+	Wallpapers       map[string]wallpaper         `toml:"wallpapers"`
+	Rules            []rule                       `toml:"rules"`
+}
+
+// rule is a single [[rules]] entry of the config file
+type rule struct {
+	Class     string      `toml:"class"`
+	Instance  string      `toml:"instance"`
+	Title     string      `toml:"title"`
+	Type      string      `toml:"type"`
+	Process   string      `toml:"process"`
+	Workspace interface{} `toml:"workspace"` // number or name
+	Floating  bool        `toml:"floating"`
+	Titlebar  *bool       `toml:"titlebar"`
+	Opacity   interface{} `toml:"opacity"`
+	Ignore    bool        `toml:"ignore"`
+	Output    string      `toml:"output"` // of a dock
+}
+
+// wallpaper is a [wallpapers.<output>] entry of the config file
+type wallpaper struct {
+	Color string `toml:"color"`
+	Image string `toml:"image"`
+	Mode  string `toml:"mode"`
+}
+
+// idleHook is a single [[idle.hooks]] entry of the config file
+type idleHook struct {
+	After   uint16 `toml:"after"` // minutes
+	Command string `toml:"command"`
+}
+
+// windowTypes lists the accepted values of the rule type, i.e. the suffixes of the _NET_WM_WINDOW_TYPE atoms
+var windowTypes = map[string]bool{
+	"normal": true, "dialog": true, "utility": true, "toolbar": true, "splash": true, "menu": true,
+	"dropdown_menu": true, "popup_menu": true, "tooltip": true, "notification": true, "dock": true, "desktop": true,
+}
+
+// DefaultPath returns the path of the config file: $XDG_CONFIG_HOME/marwind/config.toml (~/.config by
+// default), falling back to the system-wide one in $XDG_CONFIG_DIRS (/etc/xdg/marwind/config.toml)
+func DefaultPath() string {
+	return xdg.ConfigFile("config.toml")
+}
+
+// Load reads the config file at the given path and applies its values over the defaults.
+// A missing file is not an error - the defaults are returned in such case. The other errors are
+// of type *Error, pointing at the line of the file they were found at
+func Load(path string, defaults wm.Config) (wm.Config, error) {
+	cfg := copyConfig(defaults)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, &Error{Path: path, Err: err}
+	}
+	var f file
+	if _, err := toml.Decode(string(content), &f); err != nil {
+		return cfg, parseError(path, err)
+	}
+	if err := f.apply(&cfg); err != nil {
+		return cfg, valueError(path, string(content), err)
+	}
+	return cfg, nil
+}
+
+// Check validates the config file at the given path without applying it: its syntax, keybindings, colors,
+// rules and workspace names. Unlike with Load, a missing file is an error
+func Check(path string, defaults wm.Config) error {
+	if _, err := os.Stat(path); err != nil {
+		return &Error{Path: path, Err: err}
+	}
+	_, err := Load(path, defaults)
+	return err
+}
+
+func (f *file) apply(cfg *wm.Config) error {
+	setUint16(&cfg.InnerGap, f.InnerGap)
+	setUint16(&cfg.OuterGap, f.OuterGap)
+	if f.SmartGaps != nil {
+		cfg.SmartGaps = *f.SmartGaps
+	}
+	if f.SmartBorders != nil {
+		cfg.SmartBorders = *f.SmartBorders
+	}
+	setString(&cfg.Shell, f.Shell)
+	setString(&cfg.Mod, f.Mod)
+	setUint16(&cfg.KillTimeout, f.KillTimeout)
+	if f.FocusWrap != nil {
+		cfg.FocusWrap = *f.FocusWrap
+	}
+	if f.FocusFollowsMouse != nil {
+		cfg.FocusFollowsMouse = *f.FocusFollowsMouse
+	}
+	if f.MouseWarping != nil {
+		warping, err := parseMouseWarping(f.MouseWarping)
+		if err != nil {
+			return fmt.Errorf("mouse_warping: %v", err)
+		}
+		cfg.MouseWarping = warping
+	}
+	if f.MouseWarpingPosition != nil {
+		switch *f.MouseWarpingPosition {
+		case wm.WarpCenter, wm.WarpLast:
+			cfg.MouseWarpingPosition = *f.MouseWarpingPosition
+		default:
+			return fmt.Errorf("mouse_warping_position: invalid position %q, expected center or last", *f.MouseWarpingPosition)
+		}
+	}
+	if f.FocusOnActivation != nil {
+		switch *f.FocusOnActivation {
+		case wm.ActivationSmart, wm.ActivationFocus, wm.ActivationUrgent, wm.ActivationNone:
+			cfg.FocusOnActivation = *f.FocusOnActivation
+		default:
+			return fmt.Errorf("focus_on_activation: invalid policy %q, expected smart, focus, urgent or none", *f.FocusOnActivation)
+		}
+	}
+	if f.FocusStealingPrevention != nil {
+		cfg.FocusStealingPrevention = *f.FocusStealingPrevention
+	}
+	if f.Placement != nil {
+		switch *f.Placement {
+		case wm.PlacementAuto, wm.PlacementNewColumn, wm.PlacementFocusedColumn, wm.PlacementAfterFocused:
+			cfg.Placement = *f.Placement
+		default:
+			return fmt.Errorf("placement: invalid policy %q, expected auto, new_column, focused_column or after_focused", *f.Placement)
+		}
+	}
+	if f.WorkspaceIncludeEmpty != nil {
+		cfg.WorkspaceIncludeEmpty = *f.WorkspaceIncludeEmpty
+	}
+	if f.WorkspaceAutoBackAndForth != nil {
+		cfg.WorkspaceAutoBackAndForth = *f.WorkspaceAutoBackAndForth
+	}
+	setString(&cfg.EdgeLeft, f.Edges.Left)
+	setString(&cfg.EdgeRight, f.Edges.Right)
+	setString(&cfg.EdgeTop, f.Edges.Top)
+	setString(&cfg.EdgeBottom, f.Edges.Bottom)
+	setUint16(&cfg.EdgeDelay, f.Edges.Delay)
+	if f.Floating.Step != nil && *f.Floating.Step == 0 {
+		return fmt.Errorf("floating.step: must be greater than 0")
+	}
+	setUint16(&cfg.FloatingStep, f.Floating.Step)
+	setUint16(&cfg.SnapDistance, f.Floating.SnapDistance)
+	if f.Floating.Remember != nil {
+		cfg.FloatingRemember = *f.Floating.Remember
+	}
+	if f.Docks.AutoHide != nil {
+		cfg.DockAutoHide = *f.Docks.AutoHide
+	}
+	setUint16(&cfg.DockHideDelay, f.Docks.HideDelay)
+	if f.Docks.Order != nil {
+		cfg.DockOrder = f.Docks.Order
+	}
+	if f.Fullscreen.FocusLock != nil {
+		cfg.FullscreenFocusLock = *f.Fullscreen.FocusLock
+	}
+	if f.Fullscreen.Notifications != nil {
+		switch *f.Fullscreen.Notifications {
+		case wm.NotificationsAbove, wm.NotificationsOtherOutput:
+			cfg.FullscreenNotifications = *f.Fullscreen.Notifications
+		default:
+			return fmt.Errorf("fullscreen.notifications: invalid placement %q, expected above or other_output", *f.Fullscreen.Notifications)
+		}
+	}
+	if f.Notifications.Corner != nil {
+		switch *f.Notifications.Corner {
+		case wm.NotificationCornerTopRight, wm.NotificationCornerTopLeft, wm.NotificationCornerBottomRight,
+			wm.NotificationCornerBottomLeft, wm.NotificationCornerNone:
+			cfg.NotificationCorner = *f.Notifications.Corner
+		default:
+			return fmt.Errorf("notifications.corner: invalid corner %q, expected top_right, top_left, bottom_right, bottom_left or none", *f.Notifications.Corner)
+		}
+	}
+	setUint16(&cfg.NotificationMax, f.Notifications.Max)
+	if f.Idle.Hooks != nil {
+		cfg.IdleHooks = nil
+	}
+	for i, h := range f.Idle.Hooks {
+		if h.After == 0 {
+			return fmt.Errorf("idle.hooks[%d]: after must be greater than 0 minutes", i)
+		}
+		if strings.TrimSpace(h.Command) == "" {
+			return fmt.Errorf("idle.hooks[%d]: command is required", i)
+		}
+		cfg.IdleHooks = append(cfg.IdleHooks, wm.IdleHook{After: time.Duration(h.After) * time.Minute, Command: h.Command})
+	}
+	if f.Idle.InhibitFullscreen != nil {
+		cfg.IdleInhibitFullscreen = *f.Idle.InhibitFullscreen
+	}
+	if dpms := f.Idle.DPMS; dpms.Standby != nil || dpms.Suspend != nil || dpms.Off != nil {
+		cfg.DPMS = true
+		setUint16(&cfg.DPMSStandby, dpms.Standby)
+		setUint16(&cfg.DPMSSuspend, dpms.Suspend)
+		setUint16(&cfg.DPMSOff, dpms.Off)
+	}
+	if f.Startup != nil {
+		cfg.StartupCommands = f.Startup
+	}
+	if f.StartupAlways != nil {
+		cfg.StartupAlwaysCommands = f.StartupAlways
+	}
+	if f.XDGAutostart != nil {
+		cfg.XDGAutostart = *f.XDGAutostart
+	}
+
+	setUint8(&cfg.BorderWidth, f.Border.Width)
+	if err := setColor(&cfg.BorderColor, f.Border.Color); err != nil {
+		return fmt.Errorf("border.color: %v", err)
+	}
+	if err := setColor(&cfg.BorderColorInactive, f.Border.ColorInactive); err != nil {
+		return fmt.Errorf("border.color_inactive: %v", err)
+	}
+	if err := setColor(&cfg.BorderColorUrgent, f.Border.ColorUrgent); err != nil {
+		return fmt.Errorf("border.color_urgent: %v", err)
+	}
+
+	setUint8(&cfg.TitleBarHeight, f.Titlebar.Height)
+	if err := setColor(&cfg.TitleBarBgColor, f.Titlebar.BgColor); err != nil {
+		return fmt.Errorf("titlebar.bg_color: %v", err)
+	}
+	if err := setColor(&cfg.TitleBarBgColorInactive, f.Titlebar.BgColorInactive); err != nil {
+		return fmt.Errorf("titlebar.bg_color_inactive: %v", err)
+	}
+	if err := setColor(&cfg.TitleBarBgColorUrgent, f.Titlebar.BgColorUrgent); err != nil {
+		return fmt.Errorf("titlebar.bg_color_urgent: %v", err)
+	}
+	if err := setColor(&cfg.TitleBarFontColorActive, f.Titlebar.FontColorActive); err != nil {
+		return fmt.Errorf("titlebar.font_color_active: %v", err)
+	}
+	if err := setColor(&cfg.TitleBarFontColorInactive, f.Titlebar.FontColorInactive); err != nil {
+		return fmt.Errorf("titlebar.font_color_inactive: %v", err)
+	}
+	if f.Titlebar.FontSize != nil {
+		cfg.TitleBarFontSize = *f.Titlebar.FontSize
+	}
+	if f.Titlebar.ShowMarks != nil {
+		cfg.TitleBarShowMarks = *f.Titlebar.ShowMarks
+	}
+	if f.Titlebar.Format != nil {
+		cfg.TitleBarFormat = *f.Titlebar.Format
+	}
+	if f.Titlebar.Align != nil {
+		switch *f.Titlebar.Align {
+		case client.AlignLeft, client.AlignCenter, client.AlignRight:
+			cfg.TitleBarAlign = *f.Titlebar.Align
+		default:
+			return fmt.Errorf("titlebar.align: invalid alignment %q, expected left, center or right", *f.Titlebar.Align)
+		}
+	}
+	setUint16(&cfg.TitleBarPadding, f.Titlebar.Padding)
+	if f.Titlebar.MaxLength != nil {
+		if *f.Titlebar.MaxLength < 0 {
+			return fmt.Errorf("titlebar.max_length: must not be negative")
+		}
+		cfg.TitleBarMaxLength = *f.Titlebar.MaxLength
+	}
+
+	if f.Fonts != nil {
+		for _, name := range f.Fonts {
+			if name == "" {
+				return fmt.Errorf("fonts: must not be empty")
+			}
+			if !fonts.IsPath(name) {
+				// the patterns are resolved by fontconfig when the fonts are loaded
+				continue
+			}
+			if _, err := os.Stat(name); err != nil {
+				return fmt.Errorf("fonts: %v", err)
+			}
+		}
+		cfg.Fonts = f.Fonts
+	}
+
+	if f.Tray.Enabled != nil {
+		cfg.Tray = *f.Tray.Enabled
+	}
+	if f.Tray.IconSize != nil && *f.Tray.IconSize == 0 {
+		return fmt.Errorf("tray.icon_size: must be greater than 0")
+	}
+	setUint16(&cfg.TrayIconSize, f.Tray.IconSize)
+	if err := setColor(&cfg.TrayBgColor, f.Tray.BgColor); err != nil {
+		return fmt.Errorf("tray.bg_color: %v", err)
+	}
+
+	if f.Cursor.Theme != nil {
+		cfg.CursorTheme = *f.Cursor.Theme
+	}
+	setUint16(&cfg.CursorSize, f.Cursor.Size)
+	if f.DPI.Detect != nil {
+		cfg.DetectDPI = *f.DPI.Detect
+	}
+
+	if f.OSD.Enabled != nil {
+		cfg.OSD = *f.OSD.Enabled
+	}
+	if f.OSD.FontSize != nil {
+		if *f.OSD.FontSize <= 0 {
+			return fmt.Errorf("osd.font_size: must be greater than 0")
+		}
+		cfg.OSDFontSize = *f.OSD.FontSize
+	}
+	if err := setColor(&cfg.OSDFontColor, f.OSD.FontColor); err != nil {
+		return fmt.Errorf("osd.font_color: %v", err)
+	}
+	if err := setColor(&cfg.OSDBgColor, f.OSD.BgColor); err != nil {
+		return fmt.Errorf("osd.bg_color: %v", err)
+	}
+	if f.OSD.Timeout != nil {
+		if *f.OSD.Timeout == 0 {
+			return fmt.Errorf("osd.timeout: must be greater than 0")
+		}
+		cfg.OSDTimeout = *f.OSD.Timeout
+	}
+	if f.OSD.Font != nil {
+		if fonts.IsPath(*f.OSD.Font) {
+			if _, err := os.Stat(*f.OSD.Font); err != nil {
+				return fmt.Errorf("osd.font: %v", err)
+			}
+		}
+		cfg.OSDFont = *f.OSD.Font
+	}
+
+	if f.Swallow.Enabled != nil {
+		cfg.Swallow = *f.Swallow.Enabled
+	}
+	if f.Swallow.Classes != nil {
+		cfg.SwallowClasses = f.Swallow.Classes
+	}
+
+	if f.Opacity.Focused != nil {
+		opacity, err := parseOpacity(f.Opacity.Focused)
+		if err != nil {
+			return fmt.Errorf("opacity.focused: %v", err)
+		}
+		cfg.Opacity = opacity
+	}
+	if f.Opacity.Inactive != nil {
+		opacity, err := parseOpacity(f.Opacity.Inactive)
+		if err != nil {
+			return fmt.Errorf("opacity.inactive: %v", err)
+		}
+		cfg.OpacityInactive = opacity
+	}
+	if f.Log.Level != nil {
+		if _, err := logger.ParseLevel(*f.Log.Level); err != nil {
+			return fmt.Errorf("log.level: %v", err)
+		}
+		cfg.LogLevel = *f.Log.Level
+	}
+	setString(&cfg.LogFile, f.Log.File)
+	if f.Log.Events != nil {
+		cfg.LogEvents = *f.Log.Events
+	}
+
+	mod, err := keysym.ParseModifier(cfg.Mod)
+	if err != nil {
+		return fmt.Errorf("mod: %v", err)
+	}
+	for combo, command := range f.Keybindings {
+		if _, _, err := keysym.ParseBinding(combo, mod); err != nil {
+			return fmt.Errorf("keybindings.%q: %v", combo, err)
+		}
+		cfg.Keybindings[combo] = command
+	}
+	for combo, command := range f.Mousebindings {
+		if _, _, err := keysym.ParseButtonBinding(combo, mod); err != nil {
+			return fmt.Errorf("mousebindings.%q: %v", combo, err)
+		}
+		cfg.Mousebindings[combo] = command
+	}
+	for name, bindings := range f.Modes {
+		if name == "" || name == wm.DefaultMode {
+			return fmt.Errorf("modes: invalid mode name %q", name)
+		}
+		if cfg.Modes[name] == nil {
+			cfg.Modes[name] = make(map[string]string, len(bindings))
+		}
+		for combo, command := range bindings {
+			if _, _, err := keysym.ParseBinding(combo, mod); err != nil {
+				return fmt.Errorf("modes.%q.%q: %v", name, combo, err)
+			}
+			cfg.Modes[name][combo] = command
+		}
+	}
+	for ws, name := range f.WorkspaceOutputs {
+		if err := wm.ValidWorkspaceName(ws); err != nil {
+			return fmt.Errorf("workspace_outputs.%q: %v", ws, err)
+		}
+		cfg.WorkspaceOutputs[ws] = name
+	}
+	for name, w := range f.Wallpapers {
+		parsed, err := w.parse()
+		if err != nil {
+			return fmt.Errorf("wallpapers.%s: %v", name, err)
+		}
+		cfg.Wallpapers[name] = parsed
+	}
+	for name, v := range f.DPI.Outputs {
+		dpi, err := parseDPI(v)
+		if err != nil {
+			return fmt.Errorf("dpi.outputs.%s: %v", name, err)
+		}
+		cfg.OutputDPI[name] = dpi
+	}
+	for i, r := range f.Rules {
+		parsed, err := r.parse()
+		if err != nil {
+			return fmt.Errorf("rules[%d]: %v", i, err)
+		}
+		cfg.Rules = append(cfg.Rules, parsed)
+	}
+	return nil
+}
+
+func (w wallpaper) parse() (wm.Wallpaper, error) {
+	parsed := wm.Wallpaper{Color: 0xff000000, Image: w.Image, Mode: wm.WallpaperFill}
+	if w.Color != "" {
+		c, err := ParseColor(w.Color)
+		if err != nil {
+			return parsed, fmt.Errorf("color: %v", err)
+		}
+		parsed.Color = c
+	}
+	if w.Image != "" {
+		if _, err := os.Stat(w.Image); err != nil {
+			return parsed, fmt.Errorf("image: %v", err)
+		}
+	}
+	switch w.Mode {
+	case "":
+	case wm.WallpaperFill, wm.WallpaperFit, wm.WallpaperCenter, wm.WallpaperTile:
+		parsed.Mode = w.Mode
+	default:
+		return parsed, fmt.Errorf("invalid mode %q, expected fill, fit, center or tile", w.Mode)
+	}
+	return parsed, nil
+}
+
+func (r rule) parse() (wm.Rule, error) {
+	parsed := wm.Rule{
+		Class:    r.Class,
+		Instance: r.Instance,
+		Type:     strings.ToLower(r.Type),
+		Process:  r.Process,
+		Floating: r.Floating,
+		Ignore:   r.Ignore,
+		Output:   r.Output,
+	}
+	if r.Class == "" && r.Instance == "" && r.Title == "" && r.Type == "" && r.Process == "" {
+		return parsed, fmt.Errorf("at least one of class, instance, title, type or process is required")
+	}
+	if r.Title != "" {
+		re, err := regexp.Compile(r.Title)
+		if err != nil {
+			return parsed, fmt.Errorf("invalid title pattern: %v", err)
+		}
+		parsed.Title = re
+	}
+	if parsed.Type != "" && !windowTypes[parsed.Type] {
+		return parsed, fmt.Errorf("unknown window type %q", r.Type)
+	}
+	switch ws := r.Workspace.(type) {
+	case nil:
+	case int64:
+		if ws < 1 {
+			return parsed, fmt.Errorf("invalid workspace number %d", ws)
+		}
+		parsed.Workspace = strconv.FormatInt(ws, 10)
+	case string:
+		if err := wm.ValidWorkspaceName(ws); err != nil {
+			return parsed, fmt.Errorf("workspace: %v", err)
+		}
+		parsed.Workspace = ws
+	default:
+		return parsed, fmt.Errorf("invalid workspace %v, expected a number or a name", ws)
+	}
+	if r.Titlebar != nil {
+		parsed.NoTitlebar = !*r.Titlebar
+	}
+	if r.Opacity != nil {
+		opacity, err := parseOpacity(r.Opacity)
+		if err != nil {
+			return parsed, err
+		}
+		parsed.Opacity = opacity
+	}
+	return parsed, nil
+}
+
+// parseMouseWarping converts the pointer warping policy given as a boolean (true for WarpFocus, false for
+// WarpNone) or the name of the policy
+func parseMouseWarping(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return wm.WarpFocus, nil
+		}
+		return wm.WarpNone, nil
+	case string:
+		switch v {
+		case wm.WarpFocus, wm.WarpOutput, wm.WarpNone:
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid policy %v, expected focus, output, none or a boolean", v)
+}
+
+// parseOpacity converts an opacity given as an integer (0 or 1) or a float between 0 (exclusive) and 1
+func parseOpacity(v interface{}) (float64, error) {
+	var opacity float64
+	switch v := v.(type) {
+	case int64:
+		opacity = float64(v)
+	case float64:
+		opacity = v
+	default:
+		return 0, fmt.Errorf("invalid opacity %v, expected a number", v)
+	}
+	if opacity <= 0 || opacity > 1 {
+		return 0, fmt.Errorf("invalid opacity %v, expected a value greater than 0 and at most 1", v)
+	}
+	return opacity, nil
+}
+
+// parseDPI reads the DPI of an output, given as an integer or a float
+func parseDPI(v interface{}) (float64, error) {
+	var dpi float64
+	switch v := v.(type) {
+	case int64:
+		dpi = float64(v)
+	case float64:
+		dpi = v
+	default:
+		return 0, fmt.Errorf("invalid DPI %v, expected a number", v)
+	}
+	if dpi <= 0 {
+		return 0, fmt.Errorf("invalid DPI %v, expected a positive number", v)
+	}
+	return dpi, nil
+}
+
+// ParseColor converts a color in one of the "#rrggbb" or "#aarrggbb" formats to its numeric value.
+// Colors without the alpha channel are fully opaque
+func ParseColor(s string) (uint32, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return 0, fmt.Errorf("invalid color %q, expected #rrggbb or #aarrggbb", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid color %q, expected #rrggbb or #aarrggbb", s)
+	}
+	if len(hex) == 6 {
+		v |= 0xff000000
+	}
+	return uint32(v), nil
+}
+
+// copyConfig returns a copy of the config that doesn't share its maps and slices with the original
+func copyConfig(c wm.Config) wm.Config {
+	keybindings := make(map[string]string, len(c.Keybindings))
+	for k, v := range c.Keybindings {
+		keybindings[k] = v
+	}
+	c.Keybindings = keybindings
+	mousebindings := make(map[string]string, len(c.Mousebindings))
+	for k, v := range c.Mousebindings {
+		mousebindings[k] = v
+	}
+	c.Mousebindings = mousebindings
+	modes := make(map[string]map[string]string, len(c.Modes))
+	for name, bindings := range c.Modes {
+		modes[name] = make(map[string]string, len(bindings))
+		for k, v := range bindings {
+			modes[name][k] = v
+		}
+	}
+	c.Modes = modes
+	outputs := make(map[string]string, len(c.WorkspaceOutputs))
+	for k, v := range c.WorkspaceOutputs {
+		outputs[k] = v
+	}
+	c.WorkspaceOutputs = outputs
+	wallpapers := make(map[string]wm.Wallpaper, len(c.Wallpapers))
+	for k, v := range c.Wallpapers {
+		wallpapers[k] = v
+	}
+	c.Wallpapers = wallpapers
+	dpi := make(map[string]float64, len(c.OutputDPI))
+	for k, v := range c.OutputDPI {
+		dpi[k] = v
+	}
+	c.OutputDPI = dpi
+	c.Fonts = append([]string(nil), c.Fonts...)
+	c.StartupCommands = append([]string(nil), c.StartupCommands...)
+	c.StartupAlwaysCommands = append([]string(nil), c.StartupAlwaysCommands...)
+	c.SwallowClasses = append([]string(nil), c.SwallowClasses...)
+	c.DockOrder = append([]string(nil), c.DockOrder...)
+	c.IdleHooks = append([]wm.IdleHook(nil), c.IdleHooks...)
+	c.Rules = append([]wm.Rule(nil), c.Rules...)
+	return c
+}
+
+func setUint8(dst *uint8, v *uint8) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setUint16(dst *uint16, v *uint16) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setColor(dst *uint32, v *string) error {
+	if v == nil {
+		return nil
+	}
+	c, err := ParseColor(*v)
+	if err != nil {
+		return err
+	}
+	*dst = c
+	return nil
+}
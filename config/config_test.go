@@ -0,0 +1,350 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/wm"
+)
+
+func TestLoad(t *testing.T) {
+	defaults := wm.Config{
+		InnerGap:         4,
+		OuterGap:         4,
+		Shell:            "/bin/sh",
+		Mod:              "mod4",
+		BorderColor:      0xffa1d1cf,
+		Keybindings:      map[string]string{"XF86AudioMute": "exec mute"},
+		WorkspaceOutputs: map[string]string{},
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		got, err := Load(filepath.Join(t.TempDir(), "config.toml"), defaults)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, copyConfig(defaults)) {
+			t.Errorf("got = %v, want = %v", got, defaults)
+		}
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		path := writeConfig(t, `
+inner_gap = 8
+startup = ["dunst"]
+kill_timeout = 5
+workspace_auto_back_and_forth = true
+mouse_warping = "output"
+mouse_warping_position = "last"
+
+[border]
+color = "#80ff0000"
+
+[titlebar]
+format = "{class} — {title}"
+align = "left"
+max_length = 40
+
+[keybindings]
+"mod+shift+q" = "kill"
+XF86AudioMute = ""
+
+[mousebindings]
+"mod+middle" = "kill"
+
+[workspace_outputs]
+"2" = "HDMI-1"
+
+[wallpapers."*"]
+color = "#1e1e1e"
+
+[wallpapers.HDMI-1]
+mode = "tile"
+
+[tray]
+enabled = true
+icon_size = 24
+
+[cursor]
+theme = "Adwaita"
+size = 32
+
+[dpi]
+detect = true
+
+[dpi.outputs]
+"*" = 96
+eDP-1 = 192
+
+[osd]
+enabled = true
+bg_color = "#202020"
+timeout = 1500
+font = "DejaVu Sans:bold"
+
+[opacity]
+focused = 1
+inactive = 0.85
+
+[edges]
+left = "workspace prev"
+delay = 500
+
+[floating]
+step = 20
+snap_distance = 16
+remember = false
+
+[docks]
+auto_hide = true
+order = ["Polybar", "tint2"]
+
+[fullscreen]
+focus_lock = true
+notifications = "other_output"
+
+[notifications]
+corner = "bottom_left"
+max = 3
+
+[idle]
+inhibit_fullscreen = false
+
+[[idle.hooks]]
+after = 10
+command = "exec i3lock -n"
+
+[idle.dpms]
+off = 900
+`)
+		got, err := Load(path, defaults)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := copyConfig(defaults)
+		want.InnerGap = 8
+		want.StartupCommands = []string{"dunst"}
+		want.KillTimeout = 5
+		want.WorkspaceAutoBackAndForth = true
+		want.MouseWarping = wm.WarpOutput
+		want.MouseWarpingPosition = wm.WarpLast
+		want.BorderColor = 0x80ff0000
+		want.TitleBarFormat = "{class} — {title}"
+		want.TitleBarAlign = client.AlignLeft
+		want.TitleBarMaxLength = 40
+		want.Keybindings["mod+shift+q"] = "kill"
+		want.Keybindings["XF86AudioMute"] = ""
+		want.Mousebindings["mod+middle"] = "kill"
+		want.WorkspaceOutputs["2"] = "HDMI-1"
+		want.Wallpapers = map[string]wm.Wallpaper{
+			"*":      {Color: 0xff1e1e1e, Mode: wm.WallpaperFill},
+			"HDMI-1": {Color: 0xff000000, Mode: wm.WallpaperTile},
+		}
+		want.Tray = true
+		want.TrayIconSize = 24
+		want.CursorTheme = "Adwaita"
+		want.CursorSize = 32
+		want.DetectDPI = true
+		want.OutputDPI = map[string]float64{"*": 96, "eDP-1": 192}
+		want.OSD = true
+		want.OSDBgColor = 0xff202020
+		want.OSDTimeout = 1500
+		want.OSDFont = "DejaVu Sans:bold"
+		want.Opacity = 1
+		want.OpacityInactive = 0.85
+		want.EdgeLeft = "workspace prev"
+		want.EdgeDelay = 500
+		want.FloatingStep = 20
+		want.SnapDistance = 16
+		want.FloatingRemember = false
+		want.DockAutoHide = true
+		want.DockOrder = []string{"Polybar", "tint2"}
+		want.IdleHooks = []wm.IdleHook{{After: 10 * time.Minute, Command: "exec i3lock -n"}}
+		want.FullscreenFocusLock = true
+		want.FullscreenNotifications = wm.NotificationsOtherOutput
+		want.NotificationCorner = wm.NotificationCornerBottomLeft
+		want.NotificationMax = 3
+		want.DPMS = true
+		want.DPMSOff = 900
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+		if len(defaults.Keybindings) != 1 {
+			t.Errorf("expected the defaults to be left unchanged")
+		}
+	})
+
+	t.Run("Rules", func(t *testing.T) {
+		path := writeConfig(t, `
+[[rules]]
+class = "Firefox"
+workspace = 2
+
+[[rules]]
+title = "^Picture-in-Picture$"
+type = "Utility"
+floating = true
+titlebar = false
+opacity = 0.8
+
+[[rules]]
+process = "slack"
+workspace = 9
+
+[[rules]]
+class = "Polybar"
+output = "HDMI-1"
+`)
+		got, err := Load(path, defaults)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []wm.Rule{
+			{Class: "Firefox", Workspace: "2"},
+			{Title: regexp.MustCompile("^Picture-in-Picture$"), Type: "utility", Floating: true, NoTitlebar: true, Opacity: 0.8},
+			{Process: "slack", Workspace: "9"},
+			{Class: "Polybar", Output: "HDMI-1"},
+		}
+		if !reflect.DeepEqual(got.Rules, want) {
+			t.Errorf("got = %v, want = %v", got.Rules, want)
+		}
+	})
+
+	t.Run("Modes", func(t *testing.T) {
+		path := writeConfig(t, `
+[modes.resize]
+h = "resize shrink width 5"
+"shift+l" = "resize grow width 20"
+`)
+		got, err := Load(path, defaults)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]map[string]string{
+			"resize": {"h": "resize shrink width 5", "shift+l": "resize grow width 20"},
+		}
+		if !reflect.DeepEqual(got.Modes, want) {
+			t.Errorf("got = %v, want = %v", got.Modes, want)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, content := range []string{
+			`inner_gap = "big"`,
+			"[border]\ncolor = \"red\"",
+			"[keybindings]\n\"mod+Foo\" = \"kill\"",
+			"[mousebindings]\n\"mod+button0\" = \"kill\"",
+			"mod = \"hyper\"",
+			"[workspace_outputs]\n\"next\" = \"HDMI-1\"",
+			"[[rules]]\nfloating = true",
+			"[[rules]]\ntitle = \"(\"",
+			"[[rules]]\ntype = \"window\"",
+			"[[rules]]\nclass = \"Gimp\"\nworkspace = 0",
+			"[tray]\nicon_size = 0",
+			"[wallpapers.\"*\"]\nmode = \"stretch\"",
+			"[wallpapers.\"*\"]\nimage = \"/nonexistent/wallpaper.png\"",
+			"fonts = [\"/nonexistent/font.ttf\"]",
+			"fonts = [\"\"]",
+			"[titlebar]\nalign = \"justify\"",
+			"[titlebar]\nmax_length = -1",
+			"[osd]\nfont_size = 0",
+			"[osd]\ntimeout = 0",
+			"[osd]\nfont = \"/nonexistent/font.ttf\"",
+			"[modes.default]\nh = \"kill\"",
+			"[modes.resize]\n\"mod+Foo\" = \"kill\"",
+			"[log]\nlevel = \"verbose\"",
+			"focus_on_activation = \"always\"",
+			"placement = \"left\"",
+			"mouse_warping = \"always\"",
+			"mouse_warping = 1",
+			"mouse_warping_position = \"corner\"",
+			"[fullscreen]\nnotifications = \"hidden\"",
+			"[notifications]\ncorner = \"center\"",
+			"[opacity]\ninactive = 1.5",
+			"[[rules]]\nclass = \"mpv\"\nopacity = 0",
+			"[[idle.hooks]]\ncommand = \"exec i3lock\"",
+			"[[idle.hooks]]\nafter = 5",
+			"[[rules]]\nclass = \"Gimp\"\nworkspace = \"next\"",
+			"[dpi.outputs]\neDP-1 = 0",
+			"[floating]\nstep = 0",
+			"[dpi.outputs]\neDP-1 = \"high\"",
+		} {
+			if _, err := Load(writeConfig(t, content), defaults); err == nil {
+				t.Errorf("expected an error for config %q", content)
+			}
+		}
+	})
+
+	t.Run("Lines", func(t *testing.T) {
+		tests := []struct {
+			content string
+			want    int
+		}{
+			{"inner_gap = 5\n\n[border]\nwidth = 2\ncolor = \"red\"", 5},
+			{"[keybindings]\n\"mod+q\" = \"kill\"\n\"mod+Foo\" = \"kill\"", 3},
+			{"[[rules]]\nclass = \"Gimp\"\n\n[[rules]]\nclass = \"mpv\"\ntitle = \"(\"", 4},
+			{"[border\nwidth = 2", 1},
+		}
+		for _, tt := range tests {
+			_, err := Load(writeConfig(t, tt.content), defaults)
+			cerr, ok := err.(*Error)
+			if !ok {
+				t.Errorf("expected an *Error for config %q, got %v", tt.content, err)
+				continue
+			}
+			if cerr.Line != tt.want {
+				t.Errorf("line of %q got = %v, want = %v", err, cerr.Line, tt.want)
+			}
+		}
+	})
+}
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]uint32{
+		"#a1d1cf":   0xffa1d1cf,
+		"a1d1cf":    0xffa1d1cf,
+		"#00a1d1cf": 0x00a1d1cf,
+	}
+	for s, want := range tests {
+		got, err := ParseColor(s)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", s, err)
+		}
+		if got != want {
+			t.Errorf("got = %x, want = %x", got, want)
+		}
+	}
+	if _, err := ParseColor("#fff"); err == nil {
+		t.Errorf("expected an error for a short color")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestParseMouseWarping(t *testing.T) {
+	tests := map[interface{}]string{
+		true:     wm.WarpFocus,
+		false:    wm.WarpNone,
+		"output": wm.WarpOutput,
+		"none":   wm.WarpNone,
+	}
+	for v, want := range tests {
+		got, err := parseMouseWarping(v)
+		if err != nil {
+			t.Errorf("parseMouseWarping(%v) unexpected error: %v", v, err)
+		} else if got != want {
+			t.Errorf("parseMouseWarping(%v) got = %v, want = %v", v, got, want)
+		}
+	}
+}
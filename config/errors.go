@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Error is an error in the config file, with the number of the line it was found at if known
+type Error struct {
+	Path string // of the config file
+	Line int    // 0 if unknown
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// syntaxError matches the errors of the TOML parser, which mention the line
+var syntaxError = regexp.MustCompile(`^Near line (\d+) \(last key parsed '[^']*'\): (.*)$`)
+
+// parseError turns an error of the TOML decoder into an Error with its line
+func parseError(path string, err error) *Error {
+	m := syntaxError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return &Error{Path: path, Err: err}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &Error{Path: path, Line: line, Err: fmt.Errorf("%s", m[2])}
+}
+
+// valueError turns an error of an invalid value into an Error with the line setting the value. The messages
+// of such errors start with the path of the key, e.g. "border.color: invalid color", see errorKey
+func valueError(path, content string, err error) *Error {
+	return &Error{Path: path, Line: keyLine(content, errorKey(err.Error())), Err: err}
+}
+
+// errorKey returns the path of the key an error message starts with, e.g. "wallpapers.HDMI-1.image" for
+// "wallpapers.HDMI-1: image: no such file": the prefixes without spaces ending with a colon
+func errorKey(msg string) string {
+	var parts []string
+	for {
+		i := strings.Index(msg, ": ")
+		if i <= 0 || strings.ContainsAny(msg[:i], " \t") {
+			break
+		}
+		parts = append(parts, msg[:i])
+		msg = msg[i+2:]
+	}
+	return strings.Join(parts, ".")
+}
+
+var (
+	tableHeader = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	arrayHeader = regexp.MustCompile(`^\s*\[\[\s*([^\[\]]+?)\s*\]\]\s*(#.*)?$`)
+	keyValue    = regexp.MustCompile(`^\s*("[^"]*"|'[^']*'|[A-Za-z0-9_-]+)\s*=`)
+)
+
+// keyLine returns the number of the line of the config file setting the key with the given path, e.g.
+// "border.color", `keybindings."mod+q"` or "rules[1].title". If the key itself can't be found, it's the line
+// of the closest table containing it, e.g. the second [[rules]] header. 0 if there's none
+func keyLine(content, path string) int {
+	want := splitKey(path)
+	best, bestLen := 0, 0
+	var table []string
+	arrays := make(map[string]int)
+	match := func(key []string, line int) {
+		if len(key) > bestLen && len(key) <= len(want) && equalKeys(key, want[:len(key)]) {
+			best, bestLen = line, len(key)
+		}
+	}
+	for i, line := range strings.Split(content, "\n") {
+		if m := arrayHeader.FindStringSubmatch(line); m != nil {
+			table = splitKey(m[1])
+			name := strings.Join(table, ".")
+			table[len(table)-1] += fmt.Sprintf("[%d]", arrays[name])
+			arrays[name]++
+			match(table, i+1)
+		} else if m := tableHeader.FindStringSubmatch(line); m != nil {
+			table = splitKey(m[1])
+			match(table, i+1)
+		} else if m := keyValue.FindStringSubmatch(line); m != nil {
+			key := append(append([]string(nil), table...), unquote(m[1]))
+			match(key, i+1)
+		}
+	}
+	return best
+}
+
+// splitKey splits a dotted key path into its parts, unquoting the quoted ones
+func splitKey(path string) []string {
+	var parts []string
+	var b strings.Builder
+	var quote rune
+	for _, c := range path {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				b.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if path != "" {
+		parts = append(parts, strings.TrimSpace(b.String()))
+	}
+	return parts
+}
+
+func unquote(key string) string {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+func equalKeys(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package config
+
+import "testing"
+
+func TestErrorKey(t *testing.T) {
+	tests := map[string]string{
+		`border.color: invalid color "red"`:               "border.color",
+		`wallpapers.HDMI-1: image: no such file`:          "wallpapers.HDMI-1.image",
+		`keybindings."mod+Foo": invalid keybinding "mod"`: `keybindings."mod+Foo"`,
+		`rules[1]: invalid title pattern: missing )`:      "rules[1]",
+		`at least one of class is required`:               "",
+	}
+	for msg, want := range tests {
+		if got := errorKey(msg); got != want {
+			t.Errorf("errorKey(%q) got = %v, want = %v", msg, got, want)
+		}
+	}
+}
+
+func TestKeyLine(t *testing.T) {
+	content := `mod = "super"
+
+[border]
+width = 2 # pixels
+color = "#000000"
+
+[keybindings]
+"mod+q" = "kill"
+'mod+1' = "workspace 1"
+
+[[rules]]
+class = "Gimp"
+
+[[rules]]
+class = "mpv"
+opacity = 0.9
+
+[wallpapers."*"]
+image = "/tmp/bg.png"
+`
+	tests := map[string]int{
+		"mod":                     1,
+		"border.color":            5,
+		"border.height":           3,
+		`keybindings."mod+q"`:     8,
+		`keybindings."mod+1"`:     9,
+		"rules[0]":                11,
+		"rules[1].opacity":        16,
+		"rules[1].title":          14,
+		`wallpapers."*".image`:    19,
+		"wallpapers.HDMI-1.image": 0,
+		"inner_gap":               0,
+	}
+	for path, want := range tests {
+		if got := keyLine(content, path); got != want {
+			t.Errorf("keyLine(%q) got = %v, want = %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package fonts draws text with a list of TrueType fonts, each character with the first font of the list
+// that has a glyph for it, so that characters missing from the main font (e.g. symbols) are drawn with
+// a fallback font instead of empty boxes
+package fonts
+
+import (
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/BurntSushi/freetype-go/freetype"
+	"github.com/BurntSushi/freetype-go/freetype/truetype"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Set is a list of fonts in the order of preference, ending with the embedded Go font
+type Set struct {
+	fonts []*truetype.Font
+}
+
+// run is a part of a text drawn with a single font
+type run struct {
+	font *truetype.Font
+	text string
+}
+
+var goFont *truetype.Font
+
+func init() {
+	var err error
+	if goFont, err = freetype.ParseFont(goregular.TTF); err != nil {
+		panic(err)
+	}
+}
+
+// Default returns the set of the embedded Go font alone
+func Default() *Set {
+	return &Set{fonts: []*truetype.Font{goFont}}
+}
+
+// Load reads the TrueType fonts, in the order of preference, given as the paths of their files or as
+// fontconfig patterns such as family names, e.g. "DejaVu Sans". The embedded Go font is used for
+// the characters missing from all of them
+func Load(names []string) (*Set, error) {
+	s := &Set{}
+	for _, name := range names {
+		path, err := resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := freetype.ParseFont(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse font %s: %v", path, err)
+		}
+		s.fonts = append(s.fonts, f)
+	}
+	s.fonts = append(s.fonts, goFont)
+	return s, nil
+}
+
+// resolve returns the path of the font file given by the name: the name itself if it's a path, otherwise
+// the TrueType file fontconfig matches best to the pattern, found with fc-match
+func resolve(name string) (string, error) {
+	if IsPath(name) {
+		return name, nil
+	}
+	out, err := exec.Command("fc-match", "--format=%{file}", name+":fontformat=TrueType").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to match font %q: %v", name, err)
+	}
+	path := strings.TrimSpace(string(out))
+	if path == "" {
+		return "", fmt.Errorf("no font matches %q", name)
+	}
+	return path, nil
+}
+
+// IsPath tells the paths of font files apart from the fontconfig patterns in a list of fonts
+func IsPath(name string) bool {
+	return strings.Contains(name, "/") || strings.HasSuffix(strings.ToLower(name), ".ttf")
+}
+
+// Extents returns the width and height of the text drawn at the given size
+func (s *Set) Extents(size float64, text string) (int, int) {
+	var w, h int
+	for _, r := range s.runs(text) {
+		rw, rh := xgraphics.Extents(r.font, size, r.text)
+		w += rw
+		if rh > h {
+			h = rh
+		}
+	}
+	return w, h
+}
+
+// Draw writes the text on the image with its top left corner at the given position, returning
+// the horizontal position at which the text ends
+func (s *Set) Draw(img *xgraphics.Image, x, y int, clr color.Color, size float64, text string) (int, error) {
+	for _, r := range s.runs(text) {
+		var err error
+		if x, _, err = img.Text(x, y, clr, size, r.font, r.text); err != nil {
+			return x, err
+		}
+	}
+	return x, nil
+}
+
+// runs splits the text into the parts drawn with the same font
+func (s *Set) runs(text string) []run {
+	var runs []run
+	start := 0
+	var current *truetype.Font
+	for i, c := range text {
+		f := s.fontFor(c)
+		if f != current && i > start {
+			runs = append(runs, run{current, text[start:i]})
+			start = i
+		}
+		current = f
+	}
+	if start < len(text) {
+		runs = append(runs, run{current, text[start:]})
+	}
+	return runs
+}
+
+// fontFor returns the first font with a glyph for the character, the last font of the set if none has one
+func (s *Set) fontFor(c rune) *truetype.Font {
+	for _, f := range s.fonts {
+		if f.Index(c) != 0 {
+			return f
+		}
+	}
+	return s.fonts[len(s.fonts)-1]
+}
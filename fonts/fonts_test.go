@@ -0,0 +1,68 @@
+package fonts
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/freetype-go/freetype"
+	"github.com/BurntSushi/freetype-go/freetype/truetype"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+func TestRuns(t *testing.T) {
+	mono, err := freetype.ParseFont(gomono.TTF)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &Set{fonts: []*truetype.Font{mono, goFont}}
+	tests := []struct {
+		text string
+		want []run
+	}{
+		{"", nil},
+		{"vim", []run{{mono, "vim"}}},
+		{"漢字", []run{{goFont, "漢字"}}},
+		{"vim 漢字 x", []run{{mono, "vim "}, {goFont, "漢字"}, {mono, " x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := s.runs(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runs() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPath(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"/usr/share/fonts/TTF/DejaVuSans.ttf", true},
+		{"fonts/custom.ttf", true},
+		{"Custom.TTF", true},
+		{"DejaVu Sans", false},
+		{"monospace:bold", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPath(tt.name); got != tt.want {
+				t.Errorf("IsPath() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Load([]string{filepath.Join(t.TempDir(), "missing.ttf")}); err == nil {
+		t.Errorf("expected an error for a missing font")
+	}
+	s, err := Load(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.fonts) != 1 || s.fonts[0] != goFont {
+		t.Errorf("expected the Go font alone")
+	}
+}
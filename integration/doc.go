@@ -0,0 +1,9 @@
+// Package integration holds the end-to-end tests of the WM, which run marwm on a virtual X server (Xvfb),
+// drive it with xdotool and wmctrl as a user or a pager would, and check the EWMH properties, the geometry
+// of the windows and the focus. The tests are only built with the integration tag:
+//
+//	go test -tags integration ./integration
+//
+// They're skipped when Xvfb, xdotool or wmctrl is not installed. New tests open their windows with
+// newClient and wait for the WM with waitFor, as it handles the requests asynchronously
+package integration
@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSupportingWM(t *testing.T) {
+	out := command(t, "wmctrl", "-m")
+	if !strings.Contains(out, "Name: Marwind") {
+		t.Errorf("wmctrl -m got = %q, want the name Marwind", out)
+	}
+}
+
+func TestTiledLayout(t *testing.T) {
+	first := newClient(t, "Tiled", "first")
+	second := newClient(t, "Tiled", "second")
+	want := map[*client]geometry{
+		first:  {X: 0, Y: 0, W: screenWidth / 2, H: screenHeight},
+		second: {X: screenWidth / 2, Y: 0, W: screenWidth / 2, H: screenHeight},
+	}
+	for c, g := range want {
+		waitFor(t, "the windows to be tiled side by side", func() bool { return windowGeometry(t, c.win) == g })
+	}
+
+	second.close()
+	full := geometry{X: 0, Y: 0, W: screenWidth, H: screenHeight}
+	waitFor(t, "the remaining window to take the whole screen", func() bool { return windowGeometry(t, first.win) == full })
+}
+
+func TestFocus(t *testing.T) {
+	first := newClient(t, "Tiled", "first")
+	second := newClient(t, "Tiled", "second")
+	waitFor(t, "the new window to be focused", func() bool { return activeWindow() == second.win })
+
+	command(t, "xdotool", "windowactivate", "--sync", first.id())
+	waitFor(t, "the activated window to be focused", func() bool { return activeWindow() == first.win })
+
+	command(t, "xdotool", "key", "super+l")
+	waitFor(t, "focus right to focus the second window", func() bool { return activeWindow() == second.win })
+}
+
+func TestDesktops(t *testing.T) {
+	first := newClient(t, "Tiled", "first")
+	command(t, "xdotool", "key", "super+2")
+	waitFor(t, "workspace 2 to be shown", func() bool { return !mapped(first.win) })
+	second := newClient(t, "Tiled", "second")
+
+	count := rootProp("_NET_NUMBER_OF_DESKTOPS")
+	if len(count) == 0 || count[0] != 2 {
+		t.Errorf("_NET_NUMBER_OF_DESKTOPS got = %v, want = 2", count)
+	}
+	if got := parseInt(t, command(t, "xdotool", "get_desktop")); got != 1 {
+		t.Errorf("xdotool get_desktop got = %d, want = 1", got)
+	}
+
+	command(t, "wmctrl", "-s", "0")
+	waitFor(t, "workspace 1 to be shown", func() bool { return currentDesktop() == 0 && mapped(first.win) })
+	if mapped(second.win) {
+		t.Errorf("expected the window of workspace 2 to be hidden")
+	}
+}
+
+func TestCloseWindow(t *testing.T) {
+	c := newClient(t, "Tiled", "closed")
+	command(t, "wmctrl", "-i", "-c", c.id())
+	waitFor(t, "the window to be closed", func() bool { return !containsWindow(rootProp("_NET_CLIENT_LIST"), c.win) })
+}
+
+func TestMoveResizeFloating(t *testing.T) {
+	c := newClient(t, "Floating", "floating")
+	command(t, "wmctrl", "-i", "-r", c.id(), "-e", "0,100,150,300,200")
+	want := geometry{X: 100, Y: 150, W: 300, H: 200}
+	waitFor(t, "the window to be moved and resized", func() bool { return windowGeometry(t, c.win) == want })
+}
@@ -0,0 +1,363 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// Size of the screen of the virtual X server
+const (
+	screenWidth  = 1280
+	screenHeight = 720
+)
+
+// waitTimeout is how long waitFor waits for the WM to reach the expected state
+const waitTimeout = 5 * time.Second
+
+// testConfig is the configuration of the WM under test: no gaps and decorations, so that the geometry
+// of the windows is easy to predict, and the focus only moved by the WM's own rules
+const testConfig = `
+inner_gap = 0
+outer_gap = 0
+focus_follows_mouse = false
+mouse_warping = false
+focus_stealing_prevention = false
+
+[titlebar]
+height = 0
+
+[[rules]]
+class = "Floating"
+floating = true
+`
+
+// conn is the connection of the tests to the X server, used for checking the state of the windows
+var conn *xgb.Conn
+
+// binDir is the directory marwm and marwind-msg are built in
+var binDir string
+
+func TestMain(m *testing.M) {
+	for _, tool := range []string{"Xvfb", "xdotool", "wmctrl"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			fmt.Printf("skipping the integration tests: %s is not installed\n", tool)
+			os.Exit(0)
+		}
+	}
+	os.Exit(run(m))
+}
+
+// run starts the X server and the WM, runs the tests and stops them again
+func run(m *testing.M) int {
+	dir, err := ioutil.TempDir("", "marwind-integration")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	xvfb, err := startXvfb()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer stopProcess(xvfb)
+
+	// keep the WM away from the runtime files and the autostart entries of the user
+	os.Setenv("XDG_RUNTIME_DIR", dir)
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("MARWIND_SOCKET", filepath.Join(dir, "marwind.sock"))
+
+	conn, err = xgb.NewConn()
+	if err != nil {
+		fmt.Printf("failed to connect to Xvfb: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+
+	wm, err := startWM(dir)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer stopProcess(wm)
+	return m.Run()
+}
+
+// startXvfb starts the virtual X server on a free display, which is then set in DISPLAY
+func startXvfb() (*exec.Cmd, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	size := fmt.Sprintf("%dx%dx24", screenWidth, screenHeight)
+	cmd := exec.Command("Xvfb", "-displayfd", "3", "-screen", "0", size, "-nolisten", "tcp")
+	cmd.ExtraFiles = []*os.File{w}
+	if err := cmd.Start(); err != nil {
+		w.Close()
+		return nil, fmt.Errorf("failed to start Xvfb: %v", err)
+	}
+	w.Close()
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		stopProcess(cmd)
+		return nil, fmt.Errorf("failed to read the display of Xvfb: %v", err)
+	}
+	os.Setenv("DISPLAY", ":"+strings.TrimSpace(line))
+	return cmd, nil
+}
+
+// startWM builds marwm and marwind-msg and starts the WM with the test configuration, waiting until it manages
+// the screen
+func startWM(dir string) (*exec.Cmd, error) {
+	binDir = dir
+	for _, name := range []string{"marwm", "marwind-msg"} {
+		build := exec.Command("go", "build", "-o", filepath.Join(dir, name), "github.com/patrislav/marwind/cmd/"+name)
+		if out, err := build.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to build %s: %v\n%s", name, err, out)
+		}
+	}
+	bin := filepath.Join(dir, "marwm")
+	path := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(path, []byte(testConfig), 0644); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(bin, "--config", path)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start marwm: %v", err)
+	}
+	deadline := time.Now().Add(waitTimeout)
+	for time.Now().Before(deadline) {
+		if len(rootProp("_NET_SUPPORTING_WM_CHECK")) > 0 {
+			return cmd, nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	stopProcess(cmd)
+	return nil, fmt.Errorf("marwm did not start within %v", waitTimeout)
+}
+
+func stopProcess(cmd *exec.Cmd) {
+	_ = cmd.Process.Signal(os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(waitTimeout):
+		_ = cmd.Process.Kill()
+	}
+}
+
+// client is a synthetic X client with a single top-level window. Every client has a connection of its own,
+// so that the WM can kill it like any other program
+type client struct {
+	conn   *xgb.Conn
+	win    xproto.Window
+	closed sync.Once
+}
+
+// newClient opens a window of the given WM_CLASS and title, which supports WM_DELETE_WINDOW,
+// and waits until the WM manages it. The window is closed at the end of the test, which then waits until
+// the WM lets go of it so that the next test starts with an empty screen
+func newClient(t *testing.T, class, title string) *client {
+	t.Helper()
+	c, err := xgb.NewConn()
+	if err != nil {
+		t.Fatalf("failed to connect the client: %v", err)
+	}
+	screen := xproto.Setup(c).DefaultScreen(c)
+	win, err := xproto.NewWindowId(c)
+	if err != nil {
+		t.Fatalf("failed to allocate a window: %v", err)
+	}
+	err = xproto.CreateWindowChecked(c, screen.RootDepth, win, screen.Root, 0, 0, 200, 100, 0,
+		xproto.WindowClassInputOutput, screen.RootVisual, xproto.CwBackPixel, []uint32{screen.WhitePixel}).Check()
+	if err != nil {
+		t.Fatalf("failed to create the window: %v", err)
+	}
+	wmClass := strings.ToLower(class) + "\x00" + class + "\x00"
+	setProp(c, win, "WM_CLASS", xproto.AtomString, 8, []byte(wmClass))
+	setProp(c, win, "WM_NAME", xproto.AtomString, 8, []byte(title))
+	setProp(c, win, "WM_PROTOCOLS", xproto.AtomAtom, 32, atomBytes(atom(c, "WM_DELETE_WINDOW")))
+	if err := xproto.MapWindowChecked(c, win).Check(); err != nil {
+		t.Fatalf("failed to map the window: %v", err)
+	}
+	cl := &client{conn: c, win: win}
+	go cl.handleEvents()
+	t.Cleanup(func() {
+		cl.close()
+		waitFor(t, "the window to be unmanaged", func() bool { return !containsWindow(rootProp("_NET_CLIENT_LIST"), win) })
+	})
+	waitFor(t, "the window to be managed", func() bool { return containsWindow(rootProp("_NET_CLIENT_LIST"), win) })
+	return cl
+}
+
+// handleEvents destroys the window when the WM asks to close it
+func (c *client) handleEvents() {
+	deleteWindow := atom(c.conn, "WM_DELETE_WINDOW")
+	for {
+		ev, err := c.conn.WaitForEvent()
+		if ev == nil && err == nil {
+			return
+		}
+		if msg, ok := ev.(xproto.ClientMessageEvent); ok && xproto.Atom(msg.Data.Data32[0]) == deleteWindow {
+			c.close()
+			return
+		}
+	}
+}
+
+func (c *client) close() {
+	c.closed.Do(func() {
+		_ = xproto.DestroyWindowChecked(c.conn, c.win).Check()
+		c.conn.Close()
+	})
+}
+
+// id returns the window ID in the form accepted by xdotool and wmctrl
+func (c *client) id() string {
+	return fmt.Sprintf("0x%x", uint32(c.win))
+}
+
+// geometry is the position of a window relative to the root window and its size
+type geometry struct {
+	X, Y int
+	W, H int
+}
+
+// windowGeometry returns the geometry of the window on the screen, without decorations
+func windowGeometry(t *testing.T, win xproto.Window) geometry {
+	t.Helper()
+	g, err := xproto.GetGeometry(conn, xproto.Drawable(win)).Reply()
+	if err != nil {
+		t.Fatalf("failed to get the geometry of window %d: %v", win, err)
+	}
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	pos, err := xproto.TranslateCoordinates(conn, win, root, 0, 0).Reply()
+	if err != nil {
+		t.Fatalf("failed to get the position of window %d: %v", win, err)
+	}
+	return geometry{X: int(pos.DstX), Y: int(pos.DstY), W: int(g.Width), H: int(g.Height)}
+}
+
+// mapped returns true if the window is viewable
+func mapped(win xproto.Window) bool {
+	attrs, err := xproto.GetWindowAttributes(conn, win).Reply()
+	return err == nil && attrs.MapState == xproto.MapStateViewable
+}
+
+// waitFor polls the condition until it holds, failing the test after waitTimeout
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(waitTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// command runs one of the tools driving the WM and returns its output
+func command(t *testing.T, name string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s %s failed: %v\n%s", name, strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+// msg runs a command of the WM through marwind-msg
+func msg(t *testing.T, args ...string) {
+	t.Helper()
+	command(t, filepath.Join(binDir, "marwind-msg"), args...)
+}
+
+// rootProp returns the values of a 32-bit property of the root window, nil if it's not set
+func rootProp(name string) []uint32 {
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	reply, err := xproto.GetProperty(conn, false, root, atom(conn, name), xproto.GetPropertyTypeAny, 0, 1024).Reply()
+	if err != nil || reply.Format != 32 {
+		return nil
+	}
+	vals := make([]uint32, 0, len(reply.Value)/4)
+	for v := reply.Value; len(v) >= 4; v = v[4:] {
+		vals = append(vals, xgb.Get32(v))
+	}
+	return vals
+}
+
+// activeWindow returns the window in _NET_ACTIVE_WINDOW, 0 if there's none
+func activeWindow() xproto.Window {
+	if vals := rootProp("_NET_ACTIVE_WINDOW"); len(vals) > 0 {
+		return xproto.Window(vals[0])
+	}
+	return 0
+}
+
+// currentDesktop returns the index in _NET_CURRENT_DESKTOP, -1 if it's not set
+func currentDesktop() int {
+	if vals := rootProp("_NET_CURRENT_DESKTOP"); len(vals) > 0 {
+		return int(vals[0])
+	}
+	return -1
+}
+
+func containsWindow(vals []uint32, win xproto.Window) bool {
+	for _, v := range vals {
+		if xproto.Window(v) == win {
+			return true
+		}
+	}
+	return false
+}
+
+func atom(c *xgb.Conn, name string) xproto.Atom {
+	reply, err := xproto.InternAtom(c, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return xproto.AtomNone
+	}
+	return reply.Atom
+}
+
+func atomBytes(a xproto.Atom) []byte {
+	buf := make([]byte, 4)
+	xgb.Put32(buf, uint32(a))
+	return buf
+}
+
+func setProp(c *xgb.Conn, win xproto.Window, name string, typ xproto.Atom, format byte, data []byte) {
+	_ = xproto.ChangePropertyChecked(c, xproto.PropModeReplace, win, atom(c, name), typ, format,
+		uint32(len(data)*8/int(format)), data).Check()
+}
+
+// parseInt converts a number in the output of the tools, failing the test if it's not one
+func parseInt(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		t.Fatalf("invalid number %q: %v", s, err)
+	}
+	return n
+}
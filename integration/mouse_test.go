@@ -0,0 +1,27 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDragBoundary(t *testing.T) {
+	first := newClient(t, "Tiled", "first")
+	second := newClient(t, "Tiled", "second")
+	msg(t, "gaps", "inner", "current", "set", "10")
+	t.Cleanup(func() { msg(t, "gaps", "inner", "current", "reset") })
+	waitFor(t, "the gap between the windows", func() bool {
+		left, right := windowGeometry(t, first.win), windowGeometry(t, second.win)
+		return right.X-(left.X+left.W) >= 10
+	})
+	width := windowGeometry(t, first.win).W
+
+	// the pointer is pressed in the gap, over the bare root window, without the mod key
+	x, y := strconv.Itoa(screenWidth/2), strconv.Itoa(screenHeight/2)
+	command(t, "xdotool", "mousemove", "--sync", x, y, "mousedown", "1")
+	command(t, "xdotool", "mousemove", "--sync", strconv.Itoa(screenWidth/2+100), y, "mouseup", "1")
+	waitFor(t, "the boundary to be dragged", func() bool { return windowGeometry(t, first.win).W >= width+90 })
+}
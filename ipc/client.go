@@ -0,0 +1,101 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Client is a connection to the WM's socket
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	events  []Event // events received while waiting for a response
+}
+
+// Dial connects to the socket at the given path
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", path, err)
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &Client{conn: conn, scanner: scanner}, nil
+}
+
+// Send sends the request and waits for the response. Events received in the meantime are kept for ReadEvent
+func (c *Client) Send(req Request) (*Response, error) {
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	for {
+		resp, ev, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		if ev != nil {
+			c.events = append(c.events, *ev)
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// Subscribe asks the WM to send the events of the given types over this connection
+func (c *Client) Subscribe(types ...string) error {
+	resp, err := c.Send(Request{Type: TypeSubscribe, Payload: strings.Join(types, " ")})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("failed to subscribe: %s", resp.Error)
+	}
+	return nil
+}
+
+// ReadEvent waits for the next event the connection is subscribed to
+func (c *Client) ReadEvent() (*Event, error) {
+	if len(c.events) > 0 {
+		ev := c.events[0]
+		c.events = c.events[1:]
+		return &ev, nil
+	}
+	for {
+		_, ev, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		if ev != nil {
+			return ev, nil
+		}
+	}
+}
+
+// read reads the next message, which is either a response or an event
+func (c *Client) read() (*Response, *Event, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return nil, nil, fmt.Errorf("connection closed by the WM")
+	}
+	var msg struct {
+		Response
+		Event
+	}
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		return nil, nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if msg.Event.Type != "" {
+		return nil, &msg.Event, nil
+	}
+	return &msg.Response, nil, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,102 @@
+package ipc
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "marwind.sock")
+	srv, err := Listen(path, func(req Request) Response {
+		switch req.Type {
+		case TypeCommand:
+			return Response{Success: true}
+		case TypeGetTree:
+			return DataResponse(Node{Type: "root", Nodes: []Node{{Type: "output", Name: req.Payload}}})
+		}
+		return ErrorResponse(errors.New("unknown"))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	go srv.Serve()
+	defer srv.Close()
+
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	t.Run("Command", func(t *testing.T) {
+		resp, err := c.Send(Request{Type: TypeCommand, Payload: "workspace 2"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success {
+			t.Errorf("expected the response to be successful")
+		}
+	})
+
+	t.Run("Data", func(t *testing.T) {
+		resp, err := c.Send(Request{Type: TypeGetTree, Payload: "HDMI-1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := string(resp.Data)
+		want := `{"type":"root","rect":{"x":0,"y":0,"width":0,"height":0},"nodes":[{"type":"output","name":"HDMI-1","rect":{"x":0,"y":0,"width":0,"height":0}}]}`
+		if got != want {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		got, err := c.Send(Request{Type: "foo"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := &Response{Success: false, Error: "unknown"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+	})
+
+	t.Run("Subscribe", func(t *testing.T) {
+		if err := c.Subscribe(EventWindow, EventMode); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		srv.Broadcast(Event{Type: EventWorkspace, Change: "focus"})
+		srv.Broadcast(Event{Type: EventWindow, Change: "new", Node: &Node{Type: "frame", Window: 42}})
+		// the events are received even while waiting for a response
+		if _, err := c.Send(Request{Type: TypeCommand}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		srv.Broadcast(Event{Type: EventMode, Change: "resize"})
+		for _, want := range []Event{
+			{Type: EventWindow, Change: "new", Node: &Node{Type: "frame", Window: 42}},
+			{Type: EventMode, Change: "resize"},
+		} {
+			got, err := c.ReadEvent()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("got = %v, want = %v", *got, want)
+			}
+		}
+	})
+
+	t.Run("SubscribeUnknown", func(t *testing.T) {
+		if err := c.Subscribe("foo"); err == nil {
+			t.Errorf("expected an error for an unknown event type")
+		}
+	})
+
+	t.Run("SocketInUse", func(t *testing.T) {
+		if _, err := Listen(path, nil); err == nil {
+			t.Errorf("expected an error when the socket is in use")
+		}
+	})
+}
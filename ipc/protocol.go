@@ -0,0 +1,111 @@
+// Package ipc implements the protocol used for communicating with a running instance of marwind.
+// Messages are JSON objects sent over a unix socket, one per line.
+package ipc
+
+import (
+	"encoding/json"
+)
+
+// Types of requests accepted by the WM
+const (
+	TypeCommand       = "command"        // execute the command(s) given in the payload, e.g. "workspace 2"
+	TypeGetTree       = "get_tree"       // dump the tree of outputs, workspaces, columns and frames
+	TypeGetWorkspaces = "get_workspaces" // list the workspaces
+	TypeGetOutputs    = "get_outputs"    // list the outputs
+	TypeGetMinimized  = "get_minimized"  // list the minimized windows, the most recently minimized first
+	TypeGetMarks      = "get_marks"      // list the marks of the windows in alphabetical order
+	TypeSubscribe     = "subscribe"      // receive the events of the types listed in the payload, e.g. "workspace window"
+)
+
+// Types of events sent to the subscribed clients
+const (
+	EventWorkspace = "workspace" // changes: focus, init, empty, layout
+	EventWindow    = "window"    // changes: new, close, focus, title, move, floating, fullscreen_mode, urgent, minimize, restore, mark
+	EventOutput    = "output"    // changes: change (an output was added, removed or its geometry changed)
+	EventMode      = "mode"      // the change is the name of the new binding mode
+)
+
+var eventTypes = map[string]bool{EventWorkspace: true, EventWindow: true, EventOutput: true, EventMode: true}
+
+// Request is a single message sent to the WM
+type Request struct {
+	Type    string `json:"type"`
+	Payload string `json:"payload,omitempty"`
+}
+
+// Response is the WM's reply to a Request
+type Response struct {
+	Success bool            `json:"success"`
+	Error   string          `json:"error,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Event is a message sent by the WM to the clients subscribed to its type. Once subscribed, a connection
+// receives the events interleaved with the responses to its requests, told apart by the "event" key
+type Event struct {
+	Type   string `json:"event"`
+	Change string `json:"change"`
+	Node   *Node  `json:"node,omitempty"` // the workspace or window the event is about
+	Old    *Node  `json:"old,omitempty"`  // the previously focused workspace, set for workspace focus events
+}
+
+// ErrorResponse creates an unsuccessful response carrying the given error
+func ErrorResponse(err error) Response {
+	return Response{Success: false, Error: err.Error()}
+}
+
+// DataResponse creates a successful response with the value encoded as its data
+func DataResponse(v interface{}) Response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ErrorResponse(err)
+	}
+	return Response{Success: true, Data: data}
+}
+
+// Rect is the geometry of a node of the tree
+type Rect struct {
+	X int16  `json:"x"`
+	Y int16  `json:"y"`
+	W uint16 `json:"width"`
+	H uint16 `json:"height"`
+}
+
+// Workspace is an element of the list returned by the get_workspaces request
+type Workspace struct {
+	Name    string `json:"name"`
+	Num     int    `json:"num"` // number of a numbered workspace, -1 for the named ones
+	Output  string `json:"output"`
+	Rect    Rect   `json:"rect"`
+	Visible bool   `json:"visible"`
+	Focused bool   `json:"focused"`
+	Urgent  bool   `json:"urgent"`
+	Layout  string `json:"layout"` // "columns" or "monocle"
+}
+
+// Output is an element of the list returned by the get_outputs request
+type Output struct {
+	Name             string `json:"name"`
+	Rect             Rect   `json:"rect"`
+	CurrentWorkspace string `json:"current_workspace"`
+	Primary          bool   `json:"primary"`
+	Focused          bool   `json:"focused"`
+}
+
+// Node is a single element of the tree returned by the get_tree request
+type Node struct {
+	Type        string   `json:"type"` // one of "root", "output", "workspace", "column", "split", "frame", "placeholder", "dock", "desktop"
+	Name        string   `json:"name,omitempty"`
+	Layout      string   `json:"layout,omitempty"`      // "split" or "stacked" for columns and splits, "columns" or "monocle" for workspaces
+	Orientation string   `json:"orientation,omitempty"` // "horizontal" or "vertical", set for splits
+	Window      uint32   `json:"window,omitempty"`
+	Rect        Rect     `json:"rect"`
+	Focused     bool     `json:"focused,omitempty"`
+	Urgent      bool     `json:"urgent,omitempty"` // set for frames demanding attention and their workspaces
+	Visible     bool     `json:"visible,omitempty"`
+	Floating    bool     `json:"floating,omitempty"`
+	Fullscreen  bool     `json:"fullscreen,omitempty"`
+	Minimized   bool     `json:"minimized,omitempty"`
+	Marks       []string `json:"marks,omitempty"`
+	Nodes       []Node   `json:"nodes,omitempty"`
+}
@@ -0,0 +1,188 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// Handler processes a single request and returns the response sent back to the client
+type Handler func(req Request) Response
+
+// Server accepts connections on a unix socket and dispatches their requests to the handler
+type Server struct {
+	path     string
+	listener net.Listener
+	handler  Handler
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	subs  map[net.Conn]*subscriber
+}
+
+// eventQueueSize is the number of events waiting to be written to a subscriber. A subscriber that falls
+// that far behind is disconnected, so that it never blocks the WM
+const eventQueueSize = 256
+
+// subscriber is a connection receiving the events of the given types
+type subscriber struct {
+	types  map[string]bool
+	events chan []byte
+}
+
+// Listen creates the socket at the given path, removing a stale one if needed
+func Listen(path string, handler Handler) (*Server, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return &Server{path: path, listener: l, handler: handler, conns: make(map[net.Conn]struct{}), subs: make(map[net.Conn]*subscriber)}, nil
+}
+
+// Path returns the path of the socket
+func (s *Server) Path() string { return s.path }
+
+// Serve accepts incoming connections until the server is closed
+func (s *Server) Serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections, closes the open ones and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	_ = os.Remove(s.path)
+	return err
+}
+
+// Broadcast sends the event to the connections subscribed to its type without waiting for them
+func (s *Server) Broadcast(ev Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		logger.Errorf("Failed to encode IPC event: %v", err)
+		return
+	}
+	data = append(data, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn, sub := range s.subs {
+		if !sub.types[ev.Type] {
+			continue
+		}
+		select {
+		case sub.events <- data:
+		default:
+			logger.Warnf("IPC subscriber is not reading the events, disconnecting it")
+			conn.Close()
+		}
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	// the responses and events are written from different goroutines
+	var wmu sync.Mutex
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		if sub, ok := s.subs[conn]; ok {
+			close(sub.events)
+			delete(s.subs, conn)
+		}
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		var resp Response
+		var sub *subscriber
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp = ErrorResponse(fmt.Errorf("invalid request: %v", err))
+		} else if req.Type == TypeSubscribe {
+			sub, resp = s.subscribe(conn, req.Payload)
+		} else {
+			resp = s.handler(req)
+		}
+		wmu.Lock()
+		err := enc.Encode(resp)
+		wmu.Unlock()
+		if err != nil {
+			logger.Errorf("Failed to send IPC response: %v", err)
+			return
+		}
+		if sub != nil {
+			// started only now, so that the events queued in the meantime follow the response
+			go writeEvents(conn, &wmu, sub.events)
+		}
+	}
+}
+
+// subscribe adds the event types listed in the payload to the subscriptions of the connection.
+// The subscriber is returned if it was newly created and its events have yet to be written
+func (s *Server) subscribe(conn net.Conn, payload string) (*subscriber, Response) {
+	types := strings.Fields(payload)
+	if len(types) == 0 {
+		return nil, ErrorResponse(fmt.Errorf("no event types to subscribe to"))
+	}
+	for _, t := range types {
+		if !eventTypes[t] {
+			return nil, ErrorResponse(fmt.Errorf("unknown event type %q", t))
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, subscribed := s.subs[conn]
+	if !subscribed {
+		sub = &subscriber{types: make(map[string]bool), events: make(chan []byte, eventQueueSize)}
+		s.subs[conn] = sub
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	if subscribed {
+		return nil, Response{Success: true}
+	}
+	return sub, Response{Success: true}
+}
+
+func writeEvents(conn net.Conn, wmu *sync.Mutex, events <-chan []byte) {
+	for data := range events {
+		wmu.Lock()
+		_, err := conn.Write(data)
+		wmu.Unlock()
+		if err != nil {
+			conn.Close()
+			return
+		}
+	}
+}
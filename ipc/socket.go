@@ -0,0 +1,26 @@
+package ipc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/patrislav/marwind/xdg"
+)
+
+// SocketEnv is the environment variable holding the path to the socket of the running WM
+const SocketEnv = "MARWIND_SOCKET"
+
+// SocketPath returns the path of the socket on which the WM listens, based on the environment
+func SocketPath() string {
+	if path := os.Getenv(SocketEnv); path != "" {
+		return path
+	}
+	display := strings.NewReplacer(":", "", "/", "_").Replace(os.Getenv("DISPLAY"))
+	if dir := xdg.RuntimeDir(); dir != os.TempDir() {
+		return filepath.Join(dir, fmt.Sprintf("marwind.%s.sock", display))
+	}
+	// the temporary directory is shared by all the users
+	return filepath.Join(os.TempDir(), fmt.Sprintf("marwind-%d.%s.sock", os.Getuid(), display))
+}
@@ -0,0 +1,100 @@
+package keysym
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// modifiers maps the names of the modifiers usable in keybindings to their masks
+var modifiers = map[string]uint16{
+	"shift":   xproto.ModMaskShift,
+	"lock":    xproto.ModMaskLock,
+	"ctrl":    xproto.ModMaskControl,
+	"control": xproto.ModMaskControl,
+	"alt":     xproto.ModMask1,
+	"mod1":    xproto.ModMask1,
+	"mod2":    xproto.ModMask2,
+	"mod3":    xproto.ModMask3,
+	"super":   xproto.ModMask4,
+	"win":     xproto.ModMask4,
+	"mod4":    xproto.ModMask4,
+	"mod5":    xproto.ModMask5,
+}
+
+// ParseModifier returns the mask of the modifier with the given name (e.g. "shift" or "mod4")
+func ParseModifier(name string) (uint16, error) {
+	mask, ok := modifiers[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown modifier %q", name)
+	}
+	return mask, nil
+}
+
+// buttons maps the names of the pointer buttons usable in mouse bindings to their numbers,
+// in addition to "button1" to "button9"
+var buttons = map[string]xproto.Button{
+	"left":       xproto.ButtonIndex1,
+	"middle":     xproto.ButtonIndex2,
+	"right":      xproto.ButtonIndex3,
+	"wheelup":    xproto.ButtonIndex4,
+	"wheeldown":  xproto.ButtonIndex5,
+	"wheelleft":  6,
+	"wheelright": 7,
+}
+
+// ParseBinding parses a key combination in the form of "mod+shift+q" returning the keysym and the modifier
+// mask. The "mod" modifier is replaced by the given mask
+func ParseBinding(s string, mod uint16) (xproto.Keysym, uint16, error) {
+	key, mask, err := parseModifiers(s, mod)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid keybinding %q: %v", s, err)
+	}
+	sym, ok := ByName(key)
+	if !ok {
+		sym, ok = ByName(strings.ToLower(key))
+	}
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid keybinding %q: unknown key %q", s, key)
+	}
+	return sym, mask, nil
+}
+
+// ParseButtonBinding parses a pointer button combination in the form of "mod+button2" or "mod+wheelup"
+// returning the button and the modifier mask. The "mod" modifier is replaced by the given mask
+func ParseButtonBinding(s string, mod uint16) (xproto.Button, uint16, error) {
+	name, mask, err := parseModifiers(s, mod)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid mouse binding %q: %v", s, err)
+	}
+	name = strings.ToLower(name)
+	if button, ok := buttons[name]; ok {
+		return button, mask, nil
+	}
+	if strings.HasPrefix(name, "button") {
+		if n, err := strconv.Atoi(name[len("button"):]); err == nil && n >= 1 && n <= 9 {
+			return xproto.Button(n), mask, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid mouse binding %q: unknown button %q", s, name)
+}
+
+// parseModifiers splits the combination into the modifier mask and the name of the key or button that ends it
+func parseModifiers(s string, mod uint16) (string, uint16, error) {
+	parts := strings.Split(s, "+")
+	var mask uint16
+	for _, name := range parts[:len(parts)-1] {
+		if strings.ToLower(name) == "mod" {
+			mask |= mod
+			continue
+		}
+		m, err := ParseModifier(name)
+		if err != nil {
+			return "", 0, err
+		}
+		mask |= m
+	}
+	return parts[len(parts)-1], mask, nil
+}
@@ -0,0 +1,71 @@
+package keysym
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestParseBinding(t *testing.T) {
+	tests := []struct {
+		s    string
+		sym  xproto.Keysym
+		mask uint16
+	}{
+		{"q", XKq, 0},
+		{"mod+shift+q", XKq, xproto.ModMask4 | xproto.ModMaskShift},
+		{"Mod+Return", XKReturn, xproto.ModMask4},
+		{"ctrl+alt+Delete", XKDelete, xproto.ModMaskControl | xproto.ModMask1},
+		{"mod+Q", XKQ, xproto.ModMask4},
+		{"mod+shift+space", XKSpace, xproto.ModMask4 | xproto.ModMaskShift},
+		{"XF86AudioMute", XF86AudioMute, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			sym, mask, err := ParseBinding(tt.s, xproto.ModMask4)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sym != tt.sym || mask != tt.mask {
+				t.Errorf("got = (%x, %x), want = (%x, %x)", sym, mask, tt.sym, tt.mask)
+			}
+		})
+	}
+
+	for _, s := range []string{"mod+hyper+q", "mod+foo", "mod+"} {
+		if _, _, err := ParseBinding(s, xproto.ModMask4); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestParseButtonBinding(t *testing.T) {
+	tests := []struct {
+		s      string
+		button xproto.Button
+		mask   uint16
+	}{
+		{"button1", xproto.ButtonIndex1, 0},
+		{"mod+button2", xproto.ButtonIndex2, xproto.ModMask4},
+		{"mod+shift+Button9", 9, xproto.ModMask4 | xproto.ModMaskShift},
+		{"mod+middle", xproto.ButtonIndex2, xproto.ModMask4},
+		{"mod+WheelDown", xproto.ButtonIndex5, xproto.ModMask4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			button, mask, err := ParseButtonBinding(tt.s, xproto.ModMask4)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if button != tt.button || mask != tt.mask {
+				t.Errorf("got = (%d, %x), want = (%d, %x)", button, mask, tt.button, tt.mask)
+			}
+		})
+	}
+
+	for _, s := range []string{"mod+hyper+button1", "mod+button0", "mod+button10", "mod+q", "mod+"} {
+		if _, _, err := ParseButtonBinding(s, xproto.ModMask4); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
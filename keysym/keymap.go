@@ -30,3 +30,53 @@ func LoadKeyMapping(xc *xgb.Conn) (*Keymap, error) {
 	}
 	return &keymap, nil
 }
+
+// GroupMask covers the bits of the key event state holding the active keyboard group (layout) when
+// the XKB extension is in use
+const GroupMask = 0x6000
+
+// Group returns the index of the keyboard group (0-3) reported in the state of a key event
+func Group(state uint16) int {
+	return int(state&GroupMask) >> 13
+}
+
+// Keysym returns the unshifted keysym of the key in the given keyboard group. The core mapping only lays out
+// the first two groups predictably (as G1L1 G1L2 G2L1 G2L2 ...), the keys of further groups and those with
+// no symbol in the group fall back to the first group
+func (k *Keymap) Keysym(code xproto.Keycode, group int) xproto.Keysym {
+	syms := k[code]
+	if len(syms) == 0 {
+		return 0
+	}
+	if group == 1 && len(syms) > 2 && syms[2] != 0 {
+		return syms[2]
+	}
+	return syms[0]
+}
+
+// ShiftedKeysym returns the keysym of the key in the given keyboard group with Shift held (the second level),
+// falling back to the unshifted one if the key has no symbol there
+func (k *Keymap) ShiftedKeysym(code xproto.Keycode, group int) xproto.Keysym {
+	syms := k[code]
+	if group == 1 && len(syms) > 3 && syms[3] != 0 {
+		return syms[3]
+	}
+	if group != 1 || len(syms) <= 2 || syms[2] == 0 {
+		if len(syms) > 1 && syms[1] != 0 {
+			return syms[1]
+		}
+	}
+	return k.Keysym(code, group)
+}
+
+// Rune returns the character typed with the keysym: those of the Latin-1 keysyms and the Unicode ones
+// (0x01000000 + the code point), 0 for the other keysyms, including the function keys
+func Rune(sym xproto.Keysym) rune {
+	switch {
+	case sym >= 0x20 && sym <= 0x7e, sym >= 0xa0 && sym <= 0xff:
+		return rune(sym)
+	case sym >= 0x01000100 && sym <= 0x0110ffff:
+		return rune(sym - 0x01000000)
+	}
+	return 0
+}
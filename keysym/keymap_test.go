@@ -0,0 +1,91 @@
+package keysym
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestKeymapKeysym(t *testing.T) {
+	var km Keymap
+	km[24] = []xproto.Keysym{XKq, XKQ, 0x6ca, 0x6ea} // q with the Cyrillic short i in the second group
+	km[25] = []xproto.Keysym{XKw, XKW}
+	tests := []struct {
+		name  string
+		code  xproto.Keycode
+		group int
+		want  xproto.Keysym
+	}{
+		{"first group", 24, 0, XKq},
+		{"second group", 24, 1, 0x6ca},
+		{"missing second group", 25, 1, XKw},
+		{"third group", 24, 2, XKq},
+		{"unmapped key", 26, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := km.Keysym(tt.code, tt.group); got != tt.want {
+				t.Errorf("Keysym() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	tests := []struct {
+		state uint16
+		want  int
+	}{
+		{0, 0},
+		{xproto.ModMask4, 0},
+		{0x2000 | xproto.ModMaskShift, 1},
+		{0x6000, 3},
+	}
+	for _, tt := range tests {
+		if got := Group(tt.state); got != tt.want {
+			t.Errorf("Group(%#x) got = %v, want = %v", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestKeymapShiftedKeysym(t *testing.T) {
+	var km Keymap
+	km[24] = []xproto.Keysym{XKq, XKQ, 0x6ca, 0x6ea}
+	km[25] = []xproto.Keysym{XKw, XKW}
+	km[26] = []xproto.Keysym{XKReturn}
+	tests := []struct {
+		name  string
+		code  xproto.Keycode
+		group int
+		want  xproto.Keysym
+	}{
+		{"first group", 24, 0, XKQ},
+		{"second group", 24, 1, 0x6ea},
+		{"missing second group", 25, 1, XKW},
+		{"no second level", 26, 0, XKReturn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := km.ShiftedKeysym(tt.code, tt.group); got != tt.want {
+				t.Errorf("ShiftedKeysym() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRune(t *testing.T) {
+	tests := map[xproto.Keysym]rune{
+		XKq:        'q',
+		XKQ:        'Q',
+		XKSpace:    ' ',
+		0xe9:       'é',
+		0x01000142: 'ł',
+		XKReturn:   0,
+		0x6ca:      0,
+	}
+	for sym, want := range tests {
+		if got := Rune(sym); got != want {
+			t.Errorf("Rune(%#x) got = %q, want = %q", sym, got, want)
+		}
+	}
+}
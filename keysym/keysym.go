@@ -16,6 +16,7 @@ const (
 	XKSysReq     = 0xff15
 	XKEscape     = 0xff1b
 	XKDelete     = 0xffff // Delete, rubout
+	XKNumLock    = 0xff7f
 	// Latin 1
 	// (ISO/IEC 8859-1 = Unicode U+0020..U+00FF)
 	// Byte 3 = 0
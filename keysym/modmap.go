@@ -0,0 +1,66 @@
+package keysym
+
+import (
+	"errors"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// ModMap holds the masks of the lock modifiers whose bits depend on the modifier mapping, e.g. as changed
+// with xmodmap. A mask is 0 if no key of the modifier is mapped
+type ModMap struct {
+	NumLock    uint16
+	ScrollLock uint16
+}
+
+// LoadModMap reads the modifier mapping, finding the keys of the modifiers with the given keymap
+func LoadModMap(xc *xgb.Conn, km *Keymap) (*ModMap, error) {
+	reply, err := xproto.GetModifierMapping(xc).Reply()
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, errors.New("could not load modifier map")
+	}
+	mm := newModMap(reply.Keycodes, int(reply.KeycodesPerModifier), km)
+	return &mm, nil
+}
+
+// newModMap finds the lock modifiers in the keycodes of the 8 modifiers, listed one modifier after another
+func newModMap(codes []xproto.Keycode, perModifier int, km *Keymap) ModMap {
+	var mm ModMap
+	for i, code := range codes {
+		mask := uint16(1) << uint(i/perModifier)
+		for _, sym := range km[code] {
+			switch sym {
+			case XKNumLock:
+				mm.NumLock |= mask
+			case XKScrollLock:
+				mm.ScrollLock |= mask
+			}
+		}
+	}
+	return mm
+}
+
+// Locks returns the mask of all the lock modifiers, including CapsLock which is always the Lock modifier
+func (m ModMap) Locks() uint16 {
+	return xproto.ModMaskLock | m.NumLock | m.ScrollLock
+}
+
+// LockCombinations returns every combination of the lock modifiers, starting with none of them, so that
+// a key can be grabbed with all the combinations to work regardless of the locks that are on
+func (m ModMap) LockCombinations() []uint16 {
+	locks := m.Locks()
+	combos := []uint16{0}
+	for bit := uint16(1); bit != 0 && bit <= locks; bit <<= 1 {
+		if locks&bit == 0 {
+			continue
+		}
+		for _, c := range combos {
+			combos = append(combos, c|bit)
+		}
+	}
+	return combos
+}
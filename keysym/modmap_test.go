@@ -0,0 +1,61 @@
+package keysym
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestNewModMap(t *testing.T) {
+	var km Keymap
+	km[50] = []xproto.Keysym{0xffe1} // Shift_L
+	km[77] = []xproto.Keysym{XKNumLock}
+	km[78] = []xproto.Keysym{XKScrollLock}
+	tests := []struct {
+		name  string
+		codes []xproto.Keycode
+		want  ModMap
+	}{
+		{"default", []xproto.Keycode{50, 0, 0, 0, 0, 0, 0, 0, 77, 0, 0, 0, 0, 0, 0, 0}, ModMap{NumLock: xproto.ModMask2}},
+		{"moved", []xproto.Keycode{50, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 78, 0, 77, 0}, ModMap{NumLock: xproto.ModMask5, ScrollLock: xproto.ModMask4}},
+		{"unmapped", make([]xproto.Keycode, 16), ModMap{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newModMap(tt.codes, 2, &km); got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModMapLockCombinations(t *testing.T) {
+	tests := []struct {
+		name string
+		mm   ModMap
+		want []uint16
+	}{
+		{"caps lock only", ModMap{}, []uint16{0, xproto.ModMaskLock}},
+		{"num lock", ModMap{NumLock: xproto.ModMask2}, []uint16{
+			0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2,
+		}},
+		{"all locks", ModMap{NumLock: xproto.ModMask2, ScrollLock: xproto.ModMask5}, []uint16{
+			0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2,
+			xproto.ModMask5, xproto.ModMaskLock | xproto.ModMask5, xproto.ModMask2 | xproto.ModMask5,
+			xproto.ModMaskLock | xproto.ModMask2 | xproto.ModMask5,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.mm.LockCombinations()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got = %v, want = %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got = %v, want = %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
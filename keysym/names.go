@@ -0,0 +1,139 @@
+package keysym
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// names maps the keysym names, as used in X11 (without the XK_ prefix), to their values
+var names = map[string]xproto.Keysym{
+	"BackSpace":             XKBackSpace,
+	"Tab":                   XKTab,
+	"Linefeed":              XKLinefeed,
+	"Clear":                 XKClear,
+	"Return":                XKReturn,
+	"Pause":                 XKPause,
+	"Scroll_Lock":           XKScrollLock,
+	"Num_Lock":              XKNumLock,
+	"Sys_Req":               XKSysReq,
+	"Escape":                XKEscape,
+	"Delete":                XKDelete,
+	"space":                 XKSpace,
+	"exclam":                XKExclam,
+	"quotedbl":              XKQuotedbl,
+	"numbersign":            XKNumberSign,
+	"dollar":                XKDollar,
+	"percent":               XKPercent,
+	"ampersand":             XKAmpersand,
+	"apostrophe":            XKApostrophe,
+	"quoteright":            XKQuoteRight,
+	"parenleft":             XKParenLeft,
+	"parenright":            XKParenRight,
+	"asterisk":              XKAsterisk,
+	"plus":                  XKPlus,
+	"comma":                 XKComma,
+	"minus":                 XKMinus,
+	"period":                XKPeriod,
+	"slash":                 XKSlash,
+	"0":                     XK0,
+	"1":                     XK1,
+	"2":                     XK2,
+	"3":                     XK3,
+	"4":                     XK4,
+	"5":                     XK5,
+	"6":                     XK6,
+	"7":                     XK7,
+	"8":                     XK8,
+	"9":                     XK9,
+	"colon":                 XKColon,
+	"semicolon":             XKSemicolon,
+	"less":                  XKLess,
+	"equal":                 XKEqual,
+	"greater":               XKGreater,
+	"question":              XKQuestion,
+	"at":                    XKAt,
+	"A":                     XKA,
+	"B":                     XKB,
+	"C":                     XKC,
+	"D":                     XKD,
+	"E":                     XKE,
+	"F":                     XKF,
+	"G":                     XKG,
+	"H":                     XKH,
+	"I":                     XKI,
+	"J":                     XKJ,
+	"K":                     XKK,
+	"L":                     XKL,
+	"M":                     XKM,
+	"N":                     XKN,
+	"O":                     XKO,
+	"P":                     XKP,
+	"Q":                     XKQ,
+	"R":                     XKR,
+	"S":                     XKS,
+	"T":                     XKT,
+	"U":                     XKU,
+	"V":                     XKV,
+	"W":                     XKW,
+	"X":                     XKX,
+	"Y":                     XKY,
+	"Z":                     XKZ,
+	"bracketleft":           XKBracketLeft,
+	"backslash":             XKBackslash,
+	"bracketright":          XKBracketRight,
+	"asciicircum":           XKAsciiCircum,
+	"underscore":            XKUnderscore,
+	"grave":                 XKGrave,
+	"quoteleft":             XKQuoteLeft,
+	"a":                     XKa,
+	"b":                     XKb,
+	"c":                     XKc,
+	"d":                     XKd,
+	"e":                     XKe,
+	"f":                     XKf,
+	"g":                     XKg,
+	"h":                     XKh,
+	"i":                     XKi,
+	"j":                     XKj,
+	"k":                     XKk,
+	"l":                     XKl,
+	"m":                     XKm,
+	"n":                     XKn,
+	"o":                     XKo,
+	"p":                     XKp,
+	"q":                     XKq,
+	"r":                     XKr,
+	"s":                     XKs,
+	"t":                     XKt,
+	"u":                     XKu,
+	"v":                     XKv,
+	"w":                     XKw,
+	"x":                     XKx,
+	"y":                     XKy,
+	"z":                     XKz,
+	"braceleft":             XKBraceLeft,
+	"bar":                   XKBar,
+	"braceright":            XKBraceRight,
+	"asciitilde":            XKAsciiTilde,
+	"Home":                  XKHome,
+	"Left":                  XKLeft,
+	"Up":                    XKUp,
+	"Right":                 XKRight,
+	"Down":                  XKDown,
+	"Prior":                 XKPrior,
+	"Page_Up":               XKPageUp,
+	"Next":                  XKNext,
+	"Page_Down":             XKPageDown,
+	"End":                   XKEnd,
+	"Begin":                 XKBegin,
+	"XF86MonBrightnessUp":   XF86MonBrightnessUp,
+	"XF86MonBrightnessDown": XF86MonBrightnessDown,
+	"XF86AudioLowerVolume":  XF86AudioLowerVolume,
+	"XF86AudioMute":         XF86AudioMute,
+	"XF86AudioRaiseVolume":  XF86AudioRaiseVolume,
+}
+
+// ByName looks up a keysym by its name, e.g. "Return", "q" or "XF86AudioMute"
+func ByName(name string) (xproto.Keysym, bool) {
+	sym, ok := names[name]
+	return sym, ok
+}
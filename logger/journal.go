@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const journalSocket = "/run/systemd/journal/socket"
+
+// identifier is the SYSLOG_IDENTIFIER of the journal entries, used e.g. by journalctl -t
+const identifier = "marwind"
+
+// syslog priorities of the levels
+var priorities = []int{7, 6, 4, 3}
+
+// journal sends the messages to the systemd journal using its native protocol
+type journal struct {
+	conn *net.UnixConn
+}
+
+func openJournal() (*journal, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journal{conn}, nil
+}
+
+func (j *journal) write(l Level, msg string) error {
+	_, err := j.conn.Write(journalEntry(l, msg))
+	return err
+}
+
+func (j *journal) Close() error {
+	return j.conn.Close()
+}
+
+// journalEntry encodes the fields of an entry. Values with newlines have to use the binary form:
+// the name, a newline, the 64-bit little endian length of the value and the value itself
+func journalEntry(l Level, msg string) []byte {
+	var b bytes.Buffer
+	field := func(name, value string) {
+		if !strings.Contains(value, "\n") {
+			b.WriteString(name + "=" + value + "\n")
+			return
+		}
+		b.WriteString(name + "\n")
+		_ = binary.Write(&b, binary.LittleEndian, uint64(len(value)))
+		b.WriteString(value + "\n")
+	}
+	field("MESSAGE", msg)
+	field("PRIORITY", strconv.Itoa(priorities[l]))
+	field("SYSLOG_IDENTIFIER", identifier)
+	return b.Bytes()
+}
@@ -0,0 +1,189 @@
+// Package logger writes the WM's log messages at different levels of importance, either to stderr,
+// a file or the systemd journal
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Level is the importance of a log message. Messages below the current level are discarded
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel converts the name of a level, e.g. "warn", to its value
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(s)
+	if name == "warning" {
+		name = "warn"
+	}
+	for i, n := range levelNames {
+		if n == name {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", s)
+}
+
+// Journal is the output name that sends the messages to the systemd journal
+const Journal = "journal"
+
+// sink receives the formatted messages together with their level
+type sink interface {
+	write(l Level, msg string) error
+	io.Closer
+}
+
+var (
+	mu     sync.Mutex
+	level  = LevelInfo
+	events bool
+	target string
+	out    sink = &stream{log.New(os.Stderr, "", log.LstdFlags), nil}
+)
+
+// SetLevel changes the lowest level of the messages that are written
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// GetLevel returns the lowest level of the messages that are written
+func GetLevel() Level {
+	mu.Lock()
+	defer mu.Unlock()
+	return level
+}
+
+// SetEvents enables or disables the logging of every X event received by the WM, at the debug level
+func SetEvents(enable bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	events = enable
+}
+
+// Events checks whether the X events should be logged
+func Events() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return events && level <= LevelDebug
+}
+
+// SetOutput changes where the messages are written: "" or "stderr" for the standard error, "journal" for
+// the systemd journal, anything else is the path of a file to which the messages are appended.
+// The previous output is kept if the new one cannot be opened
+func SetOutput(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "stderr" {
+		name = ""
+	}
+	if name == target {
+		return nil
+	}
+	var s sink
+	switch name {
+	case "":
+		s = &stream{log.New(os.Stderr, "", log.LstdFlags), nil}
+	case Journal:
+		j, err := openJournal()
+		if err != nil {
+			return fmt.Errorf("failed to connect to the journal: %v", err)
+		}
+		s = j
+	default:
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open the log file: %v", err)
+		}
+		s = &stream{log.New(f, "", log.LstdFlags), f}
+	}
+	out.Close()
+	out, target = s, name
+	return nil
+}
+
+// Output returns the name of the current output, "stderr" if it's the standard error
+func Output() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if target == "" {
+		return "stderr"
+	}
+	return target
+}
+
+// Debugf writes a message useful when tracking down problems in the WM
+func Debugf(format string, args ...interface{}) {
+	write(LevelDebug, format, args...)
+}
+
+// Infof writes a message about a notable, but expected, event
+func Infof(format string, args ...interface{}) {
+	write(LevelInfo, format, args...)
+}
+
+// Warnf writes a message about an unexpected situation that the WM recovered from
+func Warnf(format string, args ...interface{}) {
+	write(LevelWarn, format, args...)
+}
+
+// Errorf writes a message about a failed operation
+func Errorf(format string, args ...interface{}) {
+	write(LevelError, format, args...)
+}
+
+// Fatalf writes an error message and exits the program
+func Fatalf(format string, args ...interface{}) {
+	write(LevelError, format, args...)
+	os.Exit(1)
+}
+
+func write(l Level, format string, args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l < level {
+		return
+	}
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	if err := out.write(l, msg); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the log message %q: %v\n", msg, err)
+	}
+}
+
+// stream writes the messages, prefixed with the time and level, to a file or the standard error
+type stream struct {
+	logger *log.Logger
+	file   io.Closer // nil for the standard error, which is never closed
+}
+
+func (s *stream) write(l Level, msg string) error {
+	return s.logger.Output(4, "["+l.String()+"] "+msg)
+}
+
+func (s *stream) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
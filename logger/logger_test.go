@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", LevelDebug, false},
+		{"info", LevelInfo, false},
+		{"warn", LevelWarn, false},
+		{"WARNING", LevelWarn, false},
+		{"error", LevelError, false},
+		{"verbose", LevelInfo, true},
+		{"", LevelInfo, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseLevel(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	dir, err := ioutil.TempDir("", "marwind-logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "marwind.log")
+	if err := SetOutput(path); err != nil {
+		t.Fatal(err)
+	}
+	defer SetOutput("")
+	defer SetLevel(GetLevel())
+
+	SetLevel(LevelWarn)
+	Debugf("debug message")
+	Infof("info message")
+	Warnf("warn message")
+	Errorf("error message: %d", 42)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{"[warn] warn message", "[error] error message: 42"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log got = %q, want = %q", got, want)
+		}
+	}
+	for _, skipped := range []string{"debug message", "info message"} {
+		if strings.Contains(got, skipped) {
+			t.Errorf("log got = %q, should not contain %q", got, skipped)
+		}
+	}
+}
+
+func TestJournalEntry(t *testing.T) {
+	tests := []struct {
+		name  string
+		level Level
+		msg   string
+		want  string
+	}{
+		{"single line", LevelWarn, "hello", "MESSAGE=hello\nPRIORITY=4\nSYSLOG_IDENTIFIER=marwind\n"},
+		{"multiple lines", LevelError, "a\nb", "MESSAGE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\nPRIORITY=3\nSYSLOG_IDENTIFIER=marwind\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(journalEntry(tt.level, tt.msg)); got != tt.want {
+				t.Errorf("journalEntry() got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,12 @@
 package wm
 
 import (
-	"log"
-	"os"
-	"os/exec"
+	"sort"
 
 	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
 	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
 )
 
 type action struct {
@@ -16,106 +16,33 @@ type action struct {
 	act       func() error
 }
 
+// initActions creates an action for every keybinding of the current binding mode
 func initActions(wm *WM) []*action {
-	mod := xproto.ModMask4
-	shift := xproto.ModMaskShift
-	actions := []*action{
-		{
-			sym:       keysym.XKq,
-			modifiers: mod | shift,
-			act: func() error {
-				return handleRemoveWindow(wm)
-			},
-		},
-		{
-			sym:       keysym.XKt,
-			modifiers: mod | shift | xproto.ModMask1,
-			act: func() error {
-				os.Exit(1)
-				return nil
-			},
-		},
-		{
-			sym:       keysym.XKd,
-			modifiers: mod,
-			act: func() error {
-				cmd := exec.Command(wm.config.Shell, "-c", wm.config.LauncherCommand)
-				go func() {
-					if err := cmd.Run(); err != nil {
-						log.Println("Failed to open launcher:", err)
-					}
-				}()
-				return nil
-			},
-		},
-		{
-			sym:       keysym.XKReturn,
-			modifiers: mod | shift,
-			act: func() error {
-				cmd := exec.Command(wm.config.Shell, "-c", wm.config.TerminalCommand)
-				go func() {
-					if err := cmd.Run(); err != nil {
-						log.Println("Failed to open terminal:", err)
-					}
-				}()
-				return nil
-			},
-		},
-		{
-			sym:       keysym.XKh,
-			modifiers: mod | shift,
-			act:       func() error { return handleMoveWindow(wm, MoveLeft) },
-		},
-		{
-			sym:       keysym.XKj,
-			modifiers: mod | shift,
-			act:       func() error { return handleMoveWindow(wm, MoveDown) },
-		},
-		{
-			sym:       keysym.XKk,
-			modifiers: mod | shift,
-			act:       func() error { return handleMoveWindow(wm, MoveUp) },
-		},
-		{
-			sym:       keysym.XKl,
-			modifiers: mod | shift,
-			act:       func() error { return handleMoveWindow(wm, MoveRight) },
-		},
-		{
-			sym:       keysym.XKy,
-			modifiers: mod | shift,
-			act:       func() error { return handleResizeWindow(wm, ResizeHoriz, -5) },
-		},
-		{
-			sym:       keysym.XKu,
-			modifiers: mod | shift,
-			act:       func() error { return handleResizeWindow(wm, ResizeVert, 5) },
-		},
-		{
-			sym:       keysym.XKi,
-			modifiers: mod | shift,
-			act:       func() error { return handleResizeWindow(wm, ResizeVert, -5) },
-		},
-		{
-			sym:       keysym.XKo,
-			modifiers: mod | shift,
-			act:       func() error { return handleResizeWindow(wm, ResizeHoriz, 5) },
-		},
-	}
-	actions = appendWorkspaceActions(wm, actions, mod, mod|shift)
-
-	for sym, command := range wm.config.Keybindings {
-		cmd := command
+	mod := wm.modMask()
+	bindings := wm.modeBindings()
+	combos := make([]string, 0, len(bindings))
+	for combo := range bindings {
+		combos = append(combos, combo)
+	}
+	sort.Strings(combos)
+
+	actions := make([]*action, 0, len(combos))
+	for _, combo := range combos {
+		cmd := bindings[combo]
+		if cmd == "" {
+			// an empty command removes the default binding
+			continue
+		}
+		sym, modifiers, err := keysym.ParseBinding(combo, mod)
+		if err != nil {
+			logger.Warnf("Skipping keybinding: %v", err)
+			continue
+		}
 		actions = append(actions, &action{
-			sym: sym,
+			sym:       sym,
+			modifiers: int(modifiers),
 			act: func() error {
-				cmd := exec.Command(wm.config.Shell, "-c", cmd)
-				go func() {
-					if err := cmd.Run(); err != nil {
-						log.Printf("Failed to run command (%s): %v\n", cmd, err)
-					}
-				}()
-				return nil
+				return wm.runCommands(cmd)
 			},
 		})
 	}
@@ -132,47 +59,38 @@ func initActions(wm *WM) []*action {
 	return actions
 }
 
-func appendWorkspaceActions(wm *WM, actions []*action, switchMod int, moveMod int) []*action {
-	for i := 0; i < maxWorkspaces; i++ {
-		var sym xproto.Keysym
-		if i == 9 {
-			sym = keysym.XK0
-		} else {
-			sym = xproto.Keysym(keysym.XK1 + i)
+// modMask returns the modifier mask used in place of "mod" in the keybindings
+func (wm *WM) modMask() uint16 {
+	if wm.config.Mod != "" {
+		if mask, err := keysym.ParseModifier(wm.config.Mod); err == nil {
+			return mask
 		}
-		wsID := i
-		actions = append(actions, &action{
-			sym:       sym,
-			modifiers: switchMod,
-			act: func() error {
-				return handleSwitchWorkspace(wm, uint8(wsID))
-			},
-		}, &action{
-			sym:       sym,
-			modifiers: moveMod,
-			act: func() error {
-				return handleMoveWindowToWorkspace(wm, uint8(wsID))
-			},
-		})
+		logger.Warnf("Invalid mod key %q, using mod4 instead", wm.config.Mod)
 	}
-	return actions
+	return xproto.ModMask4
 }
 
 func handleRemoveWindow(wm *WM) error {
-	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+	frm := wm.targetFrame()
 	if frm == nil {
-		log.Printf("WARNING: handleRemoveWindow: could not find frame with window %d\n", wm.activeWin)
+		logger.Warnf("handleRemoveWindow: could not find frame with window %d", wm.activeWin)
 		return nil
 	}
-	return wm.xc.GracefullyDestroyWindow(frm.cli.Window())
+	return wm.closeWindow(frm)
 }
 
-func handleMoveWindow(wm *WM, dir MoveDirection) error {
-	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+func handleMoveWindow(wm *WM, dir MoveDirection, px int) error {
+	frm := wm.targetFrame()
 	if frm == nil {
-		log.Printf("WARNING: handleMoveWindow: could not find frame with window %d\n", wm.activeWin)
+		logger.Warnf("handleMoveWindow: could not find frame with window %d", wm.activeWin)
 		return nil
 	}
+	if frm.floating {
+		if frm.fullscreen || frm.workspace() == nil {
+			return nil
+		}
+		return wm.moveFloating(frm, dir, px)
+	}
 	if err := frm.workspace().moveFrame(frm, dir); err != nil {
 		return err
 	}
@@ -182,13 +100,53 @@ func handleMoveWindow(wm *WM, dir MoveDirection) error {
 	return wm.warpPointerToFrame(frm)
 }
 
-func handleResizeWindow(wm *WM, dir ResizeDirection, pct int) error {
-	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+func handleSwapWindow(wm *WM, dir MoveDirection) error {
+	frm := wm.targetFrame()
 	if frm == nil {
-		log.Printf("WARNING: handleResizeWindow: could not find frame with window %d\n", wm.activeWin)
+		logger.Warnf("handleSwapWindow: could not find frame with window %d", wm.activeWin)
+		return nil
+	}
+	if frm.col == nil {
+		return nil
+	}
+	other := wm.adjacentFrame(frm, dir, false)
+	if other == nil {
 		return nil
 	}
-	if err := frm.workspace().resizeFrame(frm, dir, pct); err != nil {
+	return wm.swapWindows(frm, other)
+}
+
+// resizeAmount is how much a resize command grows or shrinks a window by, negative for shrinking
+type resizeAmount struct {
+	pct    int  // of the workspace, for the tiled windows unless given in pixels
+	px     int  // for the floating windows
+	pixels bool // whether the amount was given in pixels, which applies to the tiled windows too
+}
+
+func handleResizeWindow(wm *WM, dir ResizeDirection, amount resizeAmount) error {
+	frm := wm.targetFrame()
+	if frm == nil {
+		logger.Warnf("handleResizeWindow: could not find frame with window %d", wm.activeWin)
+		return nil
+	}
+	ws := frm.workspace()
+	if frm.floating {
+		if frm.fullscreen || ws == nil {
+			return nil
+		}
+		return wm.resizeFloating(frm, dir, amount.px)
+	}
+	pct := amount.pct
+	if amount.pixels && ws != nil {
+		total := ws.area().H
+		if dir == ResizeHoriz {
+			total = ws.area().W
+		}
+		if total > 0 {
+			pct = amount.px * 100 / int(total)
+		}
+	}
+	if err := ws.resizeFrame(frm, dir, pct); err != nil {
 		return err
 	}
 	if err := wm.renderWorkspace(frm.workspace()); err != nil {
@@ -197,17 +155,64 @@ func handleResizeWindow(wm *WM, dir ResizeDirection, pct int) error {
 	return wm.warpPointerToFrame(frm)
 }
 
-func handleSwitchWorkspace(wm *WM, wsID uint8) error {
-	return wm.switchWorkspace(wsID)
+func handleToggleFloating(wm *WM) error {
+	frm := wm.targetFrame()
+	if frm == nil {
+		logger.Warnf("handleToggleFloating: could not find frame with window %d", wm.activeWin)
+		return nil
+	}
+	if frm.cli.Type() != client.TypeNormal {
+		return nil
+	}
+	if err := wm.toggleFloating(frm); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(frm)
+}
+
+func handleToggleFullscreen(wm *WM) error {
+	frm := wm.targetFrame()
+	if frm == nil {
+		logger.Warnf("handleToggleFullscreen: could not find frame with window %d", wm.activeWin)
+		return nil
+	}
+	return wm.setFullscreen(frm, !frm.fullscreen)
+}
+
+func handleToggleMaximize(wm *WM) error {
+	frm := wm.targetFrame()
+	if frm == nil {
+		logger.Warnf("handleToggleMaximize: could not find frame with window %d", wm.activeWin)
+		return nil
+	}
+	ws := frm.workspace()
+	if ws == nil || frm.col == nil {
+		return nil
+	}
+	return wm.setMaximized(frm, ws.maximizedFrame() != frm)
+}
+
+func handleSwitchWorkspace(wm *WM, name string) error {
+	return wm.switchWorkspace(workspaceTarget(wm.currentOutput(), name, wm.config.WorkspaceAutoBackAndForth))
+}
+
+// workspaceTarget returns the name of the workspace to switch to when asked for the named one on the output:
+// with autoBackAndForth, asking for the workspace that's already shown goes back to the one shown before it
+// on the same output, like switchBackAndForth
+func workspaceTarget(o *output, name string, autoBackAndForth bool) string {
+	if !autoBackAndForth || o == nil || o.activeWs == nil || o.activeWs.name != name || o.prevWs == "" {
+		return name
+	}
+	return o.prevWs
 }
 
-func handleMoveWindowToWorkspace(wm *WM, wsID uint8) error {
-	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
+func handleMoveWindowToWorkspace(wm *WM, name string) error {
+	frm := wm.targetFrame()
 	if frm == nil {
-		log.Printf("WARNING: handleMoveWindowToWorkspace: could not find frame with window %d\n", wm.activeWin)
+		logger.Warnf("handleMoveWindowToWorkspace: could not find frame with window %d", wm.activeWin)
 		return nil
 	}
-	if err := wm.moveFrameToWorkspace(frm, wsID); err != nil {
+	if err := wm.moveFrameToWorkspace(frm, name); err != nil {
 		return err
 	}
 	return nil
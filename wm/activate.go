@@ -0,0 +1,61 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// Policies of handling the requests of the clients and pagers to activate a window, see Config.FocusOnActivation
+const (
+	ActivationSmart  = "smart"  // focus the window if it's on a visible workspace, mark it as urgent otherwise
+	ActivationFocus  = "focus"  // always focus the window, switching to its workspace if needed
+	ActivationUrgent = "urgent" // always mark the window as urgent
+	ActivationNone   = "none"   // ignore the requests
+)
+
+// Sources of the _NET_ACTIVE_WINDOW requests
+const (
+	activationSourceApplication = 1
+	activationSourcePager       = 2
+)
+
+// handleActivateMessage processes the _NET_ACTIVE_WINDOW client message of the frame according to the policy
+// from the config. Pagers act on behalf of the user, so in the smart policy their requests are always honored
+func (wm *WM) handleActivateMessage(f *frame, source uint32) error {
+	if f.cli.Window() == wm.activeWin || f.workspace() == nil {
+		return nil
+	}
+	switch wm.config.FocusOnActivation {
+	case ActivationNone:
+		return nil
+	case ActivationUrgent:
+		return wm.setUrgent(f, true)
+	case ActivationFocus:
+		return wm.activateFrame(f)
+	}
+	if ws := f.workspace(); source == activationSourcePager || ws.output != nil && ws.output.activeWs == ws {
+		return wm.activateFrame(f)
+	}
+	return wm.setUrgent(f, true)
+}
+
+// activateFrame brings the frame into view, restoring it if it's minimized, and focuses it
+func (wm *WM) activateFrame(f *frame) error {
+	if f.state.hidden {
+		return wm.restoreFrame(f)
+	}
+	ws := f.workspace()
+	if ws.output == nil || ws.output.activeWs != ws {
+		if err := wm.switchWorkspace(ws.name); err != nil {
+			return err
+		}
+	}
+	if f.floating {
+		if err := wm.raiseFrame(f); err != nil {
+			return err
+		}
+	}
+	if err := wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(f)
+}
@@ -0,0 +1,130 @@
+package wm
+
+import (
+	"time"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// Auto-hidden docks don't reserve any space, the workspaces cover the whole output. The docks of an area
+// are shown on top of the windows once the pointer touches the edge of the output they're placed at, or
+// while any workspace demands attention, and hidden again after the pointer left them for the hide delay
+
+// setDocksAutoHide enables or disables hiding the docks of all the outputs
+func (wm *WM) setDocksAutoHide(enabled bool) error {
+	var err error
+	for _, o := range wm.outputs {
+		o.autoHideDocks = enabled
+		for area := range o.dockAreas {
+			if e := wm.showDocks(o, dockArea(area), !enabled); e != nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// showDocks maps the docks of the area above the windows of the output, or unmaps them
+func (wm *WM) showDocks(o *output, area dockArea, show bool) error {
+	o.docksShown[area] = show
+	var err error
+	for _, f := range o.dockAreas[area] {
+		if f.cli.Mapped() == show {
+			continue
+		}
+		if !show {
+			if e := f.cli.Unmap(); e != nil {
+				err = e
+			}
+			continue
+		}
+		if e := f.cli.Map(); e != nil {
+			err = e
+			continue
+		}
+		if e := wm.raiseFrame(f); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// updateDocks shows or hides the auto-hidden docks according to the position of the pointer. Touching an
+// edge doesn't count while busy, e.g. when a window is dragged
+func (wm *WM) updateDocks(x, y int16, busy bool) error {
+	urgent := false
+	for _, ws := range wm.workspaces {
+		if ws.urgent() {
+			urgent = true
+			break
+		}
+	}
+	var err error
+	for _, o := range wm.outputs {
+		if !o.autoHideDocks {
+			continue
+		}
+		for a := range o.dockAreas {
+			area := dockArea(a)
+			if len(o.dockAreas[area]) == 0 {
+				continue
+			}
+			g := o.dockAreaGeom(area)
+			switch {
+			case urgent || (o.docksShown[area] && geomContains(g, x, y)):
+				o.dockLeft[area] = time.Time{}
+			case o.docksShown[area] && o.dockLeft[area].IsZero():
+				o.dockLeft[area] = time.Now()
+			}
+			show := urgent || (!busy && o.atEdge(area, x, y))
+			if o.docksShown[area] {
+				delay := time.Duration(wm.config.DockHideDelay) * time.Millisecond
+				show = show || o.dockLeft[area].IsZero() || time.Since(o.dockLeft[area]) < delay
+			}
+			if show == o.docksShown[area] {
+				continue
+			}
+			o.dockLeft[area] = time.Time{}
+			if e := wm.showDocks(o, area, show); e != nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// dockAreaGeom returns the space taken by all the docks of the area
+func (o *output) dockAreaGeom(area dockArea) client.Geom {
+	side := o.sideDockGeom()
+	size := o.dockSize(area)
+	switch area {
+	case dockAreaBottom:
+		return client.Geom{X: o.geom.X, Y: o.geom.Y + int16(o.geom.H-size), W: o.geom.W, H: size}
+	case dockAreaLeft:
+		return client.Geom{X: side.X, Y: side.Y, W: size, H: side.H}
+	case dockAreaRight:
+		return client.Geom{X: side.X + int16(side.W-size), Y: side.Y, W: size, H: side.H}
+	}
+	return client.Geom{X: o.geom.X, Y: o.geom.Y, W: o.geom.W, H: size}
+}
+
+// atEdge returns true if the position is on the outermost row or column of the output at the side of the dock area
+func (o *output) atEdge(area dockArea, x, y int16) bool {
+	g := o.geom
+	if !geomContains(g, x, y) {
+		return false
+	}
+	switch area {
+	case dockAreaBottom:
+		return int(y) == int(g.Y)+int(g.H)-1
+	case dockAreaLeft:
+		return x == g.X
+	case dockAreaRight:
+		return int(x) == int(g.X)+int(g.W)-1
+	}
+	return y == g.Y
+}
+
+func geomContains(g client.Geom, x, y int16) bool {
+	return x >= g.X && y >= g.Y && int(x) < int(g.X)+int(g.W) && int(y) < int(g.Y)+int(g.H)
+}
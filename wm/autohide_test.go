@@ -0,0 +1,56 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestDockAreaGeom(t *testing.T) {
+	o := &output{geom: client.Geom{X: 1920, Y: 0, W: 1280, H: 1024}}
+	o.dockAreas[dockAreaTop] = []*frame{{strut: 20}, {strut: 10}}
+	o.dockAreas[dockAreaBottom] = []*frame{{strut: 24}}
+	o.dockAreas[dockAreaRight] = []*frame{{strut: 48}}
+	tests := []struct {
+		name string
+		area dockArea
+		want client.Geom
+	}{
+		{"top", dockAreaTop, client.Geom{X: 1920, Y: 0, W: 1280, H: 30}},
+		{"bottom", dockAreaBottom, client.Geom{X: 1920, Y: 1000, W: 1280, H: 24}},
+		{"left", dockAreaLeft, client.Geom{X: 1920, Y: 30, W: 0, H: 970}},
+		{"right", dockAreaRight, client.Geom{X: 3152, Y: 30, W: 48, H: 970}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.dockAreaGeom(tt.area); got != tt.want {
+				t.Errorf("dockAreaGeom() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAtEdge(t *testing.T) {
+	o := &output{geom: client.Geom{X: 1920, Y: 0, W: 1280, H: 1024}}
+	tests := []struct {
+		name string
+		area dockArea
+		x, y int16
+		want bool
+	}{
+		{"top", dockAreaTop, 2000, 0, true},
+		{"below top", dockAreaTop, 2000, 1, false},
+		{"bottom", dockAreaBottom, 2000, 1023, true},
+		{"left", dockAreaLeft, 1920, 500, true},
+		{"left of output", dockAreaLeft, 1919, 500, false},
+		{"right", dockAreaRight, 3199, 500, true},
+		{"other side", dockAreaRight, 1920, 500, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.atEdge(tt.area, tt.x, tt.y); got != tt.want {
+				t.Errorf("atEdge() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package wm
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/xdg"
+)
+
+// desktopName is the name of the WM used in the OnlyShowIn and NotShowIn keys of the desktop entries
+const desktopName = "Marwind"
+
+// desktopEntry holds the keys of an XDG desktop entry relevant for starting the program, either on autostart
+// or from the launcher
+type desktopEntry struct {
+	Type          string
+	Name          string
+	Exec          string
+	TryExec       string
+	Icon          string
+	Hidden        bool
+	NoDisplay     bool
+	Terminal      bool
+	StartupNotify bool
+	OnlyShowIn    []string
+	NotShowIn     []string
+}
+
+// runAutostart starts the XDG autostart programs, as described by the desktop entries in the autostart
+// directories, the entries of the user overriding the system ones with the same file name
+func (wm *WM) runAutostart() {
+	for _, entry := range desktopEntries(autostartDirs()) {
+		if !entry.shouldStart() {
+			continue
+		}
+		if err := wm.spawn(execCommand(entry.Exec)); err != nil {
+			logger.Errorf("Failed to run autostart program: %v", err)
+		}
+	}
+}
+
+// autostartDirs returns the XDG autostart directories, the most important one first
+func autostartDirs() []string {
+	var dirs []string
+	if home := xdg.ConfigHome(); home != "" {
+		dirs = append(dirs, filepath.Join(home, "autostart"))
+	}
+	for _, dir := range xdg.ConfigDirs() {
+		dirs = append(dirs, filepath.Join(dir, "autostart"))
+	}
+	return dirs
+}
+
+// desktopEntries reads the desktop entries of the given directories. An entry shadows those
+// with the same file name in the following directories, even if it's hidden
+func desktopEntries(dirs []string) []desktopEntry {
+	seen := make(map[string]bool)
+	var entries []desktopEntry
+	for _, dir := range dirs {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range files {
+			if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".desktop") || seen[fi.Name()] {
+				continue
+			}
+			seen[fi.Name()] = true
+			f, err := os.Open(filepath.Join(dir, fi.Name()))
+			if err != nil {
+				logger.Errorf("Failed to read desktop entry: %v", err)
+				continue
+			}
+			entry, err := parseDesktopEntry(f)
+			f.Close()
+			if err != nil {
+				logger.Errorf("Failed to read desktop entry %s: %v", fi.Name(), err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// parseDesktopEntry reads the keys of the [Desktop Entry] group of a desktop file
+func parseDesktopEntry(r io.Reader) (desktopEntry, error) {
+	var entry desktopEntry
+	group := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = line[1 : len(line)-1]
+			continue
+		}
+		i := strings.Index(line, "=")
+		if group != "Desktop Entry" || i < 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		switch key {
+		case "Type":
+			entry.Type = value
+		case "Name":
+			entry.Name = value
+		case "Exec":
+			entry.Exec = value
+		case "TryExec":
+			entry.TryExec = value
+		case "Icon":
+			entry.Icon = value
+		case "Hidden":
+			entry.Hidden = value == "true"
+		case "NoDisplay":
+			entry.NoDisplay = value == "true"
+		case "Terminal":
+			entry.Terminal = value == "true"
+		case "StartupNotify":
+			entry.StartupNotify = value == "true"
+		case "OnlyShowIn":
+			entry.OnlyShowIn = splitList(value)
+		case "NotShowIn":
+			entry.NotShowIn = splitList(value)
+		}
+	}
+	return entry, scanner.Err()
+}
+
+// shouldStart checks whether the program of the entry should be started in this WM
+func (e desktopEntry) shouldStart() bool {
+	if e.Hidden || e.Exec == "" || contains(e.NotShowIn, desktopName) {
+		return false
+	}
+	if len(e.OnlyShowIn) > 0 && !contains(e.OnlyShowIn, desktopName) {
+		return false
+	}
+	if e.TryExec != "" {
+		if _, err := exec.LookPath(e.TryExec); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// execCommand turns the Exec key of a desktop entry into a shell command by removing the field codes
+// (e.g. %U), which are meaningless when no files or URLs are opened
+func execCommand(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if s[i] == '%' {
+			b.WriteByte('%')
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// splitList splits a semicolon-separated list of a desktop entry
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ";") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
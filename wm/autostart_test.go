@@ -0,0 +1,71 @@
+package wm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDesktopEntry(t *testing.T) {
+	data := `# comment
+[Desktop Entry]
+Type=Application
+Name=Network
+Exec=nm-applet --indicator
+OnlyShowIn=GNOME;Marwind;
+Hidden=false
+
+[Desktop Action New]
+Exec=nm-applet --new
+`
+	got, err := parseDesktopEntry(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := desktopEntry{Type: "Application", Name: "Network", Exec: "nm-applet --indicator", OnlyShowIn: []string{"GNOME", "Marwind"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDesktopEntry() got = %v, want = %v", got, want)
+	}
+}
+
+func TestDesktopEntryShouldStart(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry desktopEntry
+		want  bool
+	}{
+		{"plain", desktopEntry{Exec: "dunst"}, true},
+		{"hidden", desktopEntry{Exec: "dunst", Hidden: true}, false},
+		{"no exec", desktopEntry{}, false},
+		{"only other desktops", desktopEntry{Exec: "dunst", OnlyShowIn: []string{"KDE"}}, false},
+		{"only this desktop", desktopEntry{Exec: "dunst", OnlyShowIn: []string{"KDE", "Marwind"}}, true},
+		{"not this desktop", desktopEntry{Exec: "dunst", NotShowIn: []string{"Marwind"}}, false},
+		{"missing try exec", desktopEntry{Exec: "dunst", TryExec: "surely-not-installed-anywhere"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.shouldStart(); got != tt.want {
+				t.Errorf("shouldStart() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecCommand(t *testing.T) {
+	tests := []struct {
+		exec string
+		want string
+	}{
+		{"firefox %u", "firefox"},
+		{"app --file %F --name %c", "app --file  --name"},
+		{"printf 100%%", "printf 100%"},
+		{"dunst", "dunst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exec, func(t *testing.T) {
+			if got := execCommand(tt.exec); got != tt.want {
+				t.Errorf("execCommand() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/x11"
+)
+
+// The borders of the floating frames and the boundaries between the tiled ones can be dragged with the left
+// button, without the mod key, to resize them. The cursor changes its shape while the pointer is over them
+const (
+	resizeHandle = 3  // distance in pixels past the borders or the gaps at which the pointer can still resize
+	resizeCorner = 16 // length of the part of an edge of a floating frame that resizes it diagonally
+)
+
+// tileBoundary is the boundary between two neighbouring items of a tiled container: the columns of
+// a workspace, or the frames of a column or a nested container
+type tileBoundary struct {
+	ws         *workspace
+	horizontal bool        // the items are placed side by side, the boundary is a vertical line
+	pos        int         // position of the boundary along the container, relative to the root window
+	from, to   int         // extent of the boundary across the container
+	weights    [2]*float64 // of the items before and after the boundary
+	scale      float64     // weight per pixel of the container
+}
+
+// resizeTarget is what a drag started at a given position of the pointer resizes
+type resizeTarget struct {
+	f      *frame // floating frame whose edges are resized, nil for the boundaries
+	edges  resizeEdges
+	bounds []tileBoundary // at most one horizontal and one vertical
+}
+
+// pointerCursor is the cursor set by the WM on a window while the pointer is over a resize target
+type pointerCursor struct {
+	win   xproto.Window
+	shape uint16
+}
+
+// tileBoundaries returns the boundaries between the tiled items of the workspace, laid out like
+// in renderTiling. There's none with a maximized frame or in the monocle layout
+func (wm *WM) tileBoundaries(ws *workspace) []tileBoundary {
+	if ws.monocle || ws.maximizedFrame() != nil {
+		return nil
+	}
+	cols := ws.visibleColumns()
+	weights := make([]*float64, len(cols))
+	for i, col := range cols {
+		weights[i] = &col.weight
+	}
+	bounds, slots := splitBoundaries(ws, weights, ws.area(), true)
+	for i, col := range cols {
+		bounds = append(bounds, columnBoundaries(col, slots[i])...)
+	}
+	return bounds
+}
+
+// columnBoundaries returns the boundaries between the frames of the column laid out in the given slot, and
+// within the nested containers. The frames of a stacked column overlap, so there's none between them
+func columnBoundaries(col *column, geom client.Geom) []tileBoundary {
+	if col.layout == layoutStacked {
+		return nil
+	}
+	frames := col.visibleFrames()
+	weights := make([]*float64, len(frames))
+	for i, f := range frames {
+		weights[i] = &f.weight
+	}
+	bounds, slots := splitBoundaries(col.ws, weights, geom, col.orientation == orientHorizontal)
+	for i, f := range frames {
+		if f.split != nil {
+			bounds = append(bounds, columnBoundaries(f.split, slots[i])...)
+		}
+	}
+	return bounds
+}
+
+// splitBoundaries divides the geometry between items of the given weights like splitLength, returning
+// the boundaries between them along with their slots
+func splitBoundaries(ws *workspace, weights []*float64, geom client.Geom, horizontal bool) ([]tileBoundary, []client.Geom) {
+	values := make([]float64, len(weights))
+	var total float64
+	for i, w := range weights {
+		values[i] = *w
+		total += *w
+	}
+	length, pos := geom.H, int(geom.Y)
+	from, to := int(geom.X), int(geom.X)+int(geom.W)
+	if horizontal {
+		length, pos = geom.W, int(geom.X)
+		from, to = int(geom.Y), int(geom.Y)+int(geom.H)
+	}
+	var bounds []tileBoundary
+	slots := make([]client.Geom, len(weights))
+	for i, l := range splitLength(values, length) {
+		slots[i] = client.Geom{X: geom.X, Y: int16(pos), W: geom.W, H: l}
+		if horizontal {
+			slots[i] = client.Geom{X: int16(pos), Y: geom.Y, W: l, H: geom.H}
+		}
+		pos += int(l)
+		if i == len(weights)-1 || total <= 0 || length == 0 {
+			continue
+		}
+		bounds = append(bounds, tileBoundary{
+			ws:         ws,
+			horizontal: horizontal,
+			pos:        pos,
+			from:       from,
+			to:         to,
+			weights:    [2]*float64{weights[i], weights[i+1]},
+			scale:      total / float64(length),
+		})
+	}
+	return bounds, slots
+}
+
+// boundariesAt returns the boundaries within the margin of the point, the closest one in each direction
+func boundariesAt(bounds []tileBoundary, x, y, margin int) []tileBoundary {
+	var found []tileBoundary
+	for _, horizontal := range []bool{true, false} {
+		best, bestDist := -1, 0
+		for i, b := range bounds {
+			if b.horizontal != horizontal {
+				continue
+			}
+			// the point is compared across the line of the boundary and along it
+			along, across := x, y
+			if horizontal {
+				along, across = y, x
+			}
+			dist := absInt(across - b.pos)
+			if along < b.from || along >= b.to || dist > margin {
+				continue
+			}
+			if best < 0 || dist < bestDist {
+				best, bestDist = i, dist
+			}
+		}
+		if best >= 0 {
+			found = append(found, bounds[best])
+		}
+	}
+	return found
+}
+
+// frameEdges returns the edges of the geometry the point is within the margin of. Close to an edge, the point
+// is also at a corner when it's within the given length of the perpendicular edge
+func frameEdges(geom client.Geom, x, y, margin, corner int) resizeEdges {
+	left, top := int(geom.X), int(geom.Y)
+	right, bottom := left+int(geom.W), top+int(geom.H)
+	if x < left || x >= right || y < top || y >= bottom {
+		return 0
+	}
+	var edges resizeEdges
+	if x < left+margin {
+		edges |= resizeLeft
+	} else if x >= right-margin {
+		edges |= resizeRight
+	}
+	if y < top+margin {
+		edges |= resizeTop
+	} else if y >= bottom-margin {
+		edges |= resizeBottom
+	}
+	if edges&(resizeLeft|resizeRight) != 0 && edges&(resizeTop|resizeBottom) == 0 {
+		if y < top+corner {
+			edges |= resizeTop
+		} else if y >= bottom-corner {
+			edges |= resizeBottom
+		}
+	}
+	if edges&(resizeTop|resizeBottom) != 0 && edges&(resizeLeft|resizeRight) == 0 {
+		if x < left+corner {
+			edges |= resizeLeft
+		} else if x >= right-corner {
+			edges |= resizeRight
+		}
+	}
+	return edges
+}
+
+// resizeTargetAt returns what a drag started at the position of the pointer would resize, given the window
+// reporting the position and its child containing the pointer, if any. Only the decorations of the frames
+// and the bare root window are considered, the clients get the events within their windows
+func (wm *WM) resizeTargetAt(win, child xproto.Window, x, y int16) *resizeTarget {
+	var ws *workspace
+	if win == wm.xc.GetRootWindow() {
+		o := wm.outputAt(x, y)
+		if o == nil || child != 0 && !wm.isDesktopWindow(o, child) {
+			return nil
+		}
+		ws = o.activeWs
+	} else {
+		f := wm.findFrame(func(frm *frame) bool { return frm.cli.Parent() == win })
+		if f == nil || f.fullscreen || wm.clientContains(f, x, y) {
+			return nil
+		}
+		if f.floating {
+			margin := int(scaledUint8(wm.config.BorderWidth, f.pixelScale())) + resizeHandle
+			if edges := frameEdges(f.cli.Geom(), int(x), int(y), margin, resizeCorner); edges != 0 {
+				return &resizeTarget{f: f, edges: edges}
+			}
+			return nil
+		}
+		ws = f.workspace()
+	}
+	if ws == nil {
+		return nil
+	}
+	margin := int(ws.innerGap()) + int(scaledUint8(wm.config.BorderWidth, ws.pixelScale())) + resizeHandle
+	if bounds := boundariesAt(wm.tileBoundaries(ws), int(x), int(y), margin); len(bounds) > 0 {
+		return &resizeTarget{bounds: bounds}
+	}
+	return nil
+}
+
+// isDesktopWindow returns true if the window is one of the desktop windows of the output, which stand in for
+// the root window
+func (wm *WM) isDesktopWindow(o *output, win xproto.Window) bool {
+	for _, f := range o.desktops {
+		if wm.frameWindow(f) == win {
+			return true
+		}
+	}
+	return false
+}
+
+// clientContains returns true if the point is within the client window of the frame rather than
+// its decorations
+func (wm *WM) clientContains(f *frame, x, y int16) bool {
+	g, d := f.cli.Geom(), wm.getFrameDecorations(f)
+	return int(x) >= int(g.X)+int(d.Left) && int(x) < int(g.X)+int(g.W)-int(d.Right) &&
+		int(y) >= int(g.Y)+int(d.Top) && int(y) < int(g.Y)+int(g.H)-int(d.Bottom)
+}
+
+// cursor returns the shape of the cursor shown over the resize target
+func (t *resizeTarget) cursor() uint16 {
+	if t.f != nil {
+		return edgesCursor(t.edges)
+	}
+	if len(t.bounds) > 1 {
+		return x11.CursorFleur
+	}
+	if t.bounds[0].horizontal {
+		return x11.CursorHDoubleArrow
+	}
+	return x11.CursorVDoubleArrow
+}
+
+// edgesCursor returns the shape of the cursor for resizing the given edges of a frame
+func edgesCursor(edges resizeEdges) uint16 {
+	switch edges {
+	case resizeTop | resizeLeft:
+		return x11.CursorTopLeftCorner
+	case resizeTop | resizeRight:
+		return x11.CursorTopRightCorner
+	case resizeBottom | resizeLeft:
+		return x11.CursorBottomLeftCorner
+	case resizeBottom | resizeRight:
+		return x11.CursorBottomRightCorner
+	case resizeTop:
+		return x11.CursorTopSide
+	case resizeBottom:
+		return x11.CursorBottomSide
+	case resizeLeft:
+		return x11.CursorLeftSide
+	case resizeRight:
+		return x11.CursorRightSide
+	}
+	return x11.CursorFleur
+}
+
+// updateResizeCursor shows the cursor of the resize target under the pointer on the window reporting
+// its position, and brings back the default one once the pointer leaves the target
+func (wm *WM) updateResizeCursor(win, child xproto.Window, x, y int16) error {
+	if t := wm.resizeTargetAt(win, child, x, y); t != nil {
+		return wm.setPointerCursor(win, t.cursor())
+	}
+	return wm.resetPointerCursor()
+}
+
+// setPointerCursor sets the cursor of the given shape on the window, resetting the window it was set on before
+func (wm *WM) setPointerCursor(win xproto.Window, shape uint16) error {
+	if c := wm.pointerCursor; c != nil && c.win == win && c.shape == shape {
+		return nil
+	}
+	if err := wm.resetPointerCursor(); err != nil {
+		return err
+	}
+	cursor, err := wm.xc.Cursor(shape)
+	if err != nil {
+		return err
+	}
+	if err := wm.xc.SetCursor(win, cursor); err != nil {
+		return err
+	}
+	wm.pointerCursor = &pointerCursor{win: win, shape: shape}
+	return nil
+}
+
+// resetPointerCursor brings back the default cursor on the window the WM set a resize cursor on, if any
+func (wm *WM) resetPointerCursor() error {
+	c := wm.pointerCursor
+	if c == nil {
+		return nil
+	}
+	wm.pointerCursor = nil
+	var cursor xproto.Cursor
+	if c.win == wm.xc.GetRootWindow() {
+		var err error
+		if cursor, err = wm.xc.Cursor(wm.rootCursor()); err != nil {
+			return err
+		}
+	}
+	// the window could have been destroyed in the meantime
+	if err := wm.xc.SetCursor(c.win, cursor); err != nil {
+		logger.Debugf("Failed to reset the cursor of window %d: %v", c.win, err)
+	}
+	return nil
+}
+
+// startResizeAt starts resizing what's under the pointer when the left button is pressed without modifiers
+// over the decorations of a frame or the bare root window. It returns false if there's nothing to resize there
+func (wm *WM) startResizeAt(e xproto.ButtonPressEvent) (bool, error) {
+	state := e.State &^ (keysym.GroupMask | wm.modmap.Locks() | buttonStateMask)
+	if e.Detail != xproto.ButtonIndex1 || state != 0 {
+		return false, nil
+	}
+	t := wm.resizeTargetAt(e.Event, e.Child, e.RootX, e.RootY)
+	if t == nil {
+		return false, nil
+	}
+	return true, wm.startResizeDrag(t, e.RootX, e.RootY, e.Time)
+}
+
+// startResizeDrag starts resizing the target with the pointer at the given position
+func (wm *WM) startResizeDrag(t *resizeTarget, x, y int16, tm xproto.Timestamp) error {
+	if err := wm.resetPointerCursor(); err != nil {
+		return err
+	}
+	if t.f != nil {
+		return wm.startDrag(t.f, dragResize, t.edges, x, y, tm)
+	}
+	if ok, err := wm.grabDragPointer(t.cursor(), tm); !ok || err != nil {
+		return err
+	}
+	d := &drag{kind: dragBoundary, startX: x, startY: y, bounds: t.bounds}
+	for _, b := range t.bounds {
+		d.weights = append(d.weights, [2]float64{*b.weights[0], *b.weights[1]})
+	}
+	wm.drag = d
+	return nil
+}
+
+// dragBoundaries moves the dragged boundaries by the given distance from where they were, resizing the items
+// on both sides of them, neither of which gets smaller than minFloatingSize
+func (wm *WM) dragBoundaries(d *drag, dx, dy int) error {
+	for i, b := range d.bounds {
+		delta := dy
+		if b.horizontal {
+			delta = dx
+		}
+		*b.weights[0], *b.weights[1] = moveBoundary(d.weights[i], float64(delta)*b.scale, minFloatingSize*b.scale)
+	}
+	return wm.renderWorkspace(d.bounds[0].ws)
+}
+
+// moveBoundary returns the weights of the items around a boundary moved by the given weight, keeping
+// both of them at least min, unless they were already smaller
+func moveBoundary(weights [2]float64, delta, min float64) (float64, float64) {
+	if delta > 0 && weights[1]-delta < min {
+		delta = weights[1] - min
+		if delta < 0 {
+			delta = 0
+		}
+	}
+	if delta < 0 && weights[0]+delta < min {
+		delta = min - weights[0]
+		if delta > 0 {
+			delta = 0
+		}
+	}
+	return weights[0] + delta, weights[1] - delta
+}
@@ -0,0 +1,92 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestFrameEdges(t *testing.T) {
+	g := client.Geom{X: 100, Y: 100, W: 200, H: 100}
+	tests := []struct {
+		name string
+		x, y int
+		want resizeEdges
+	}{
+		{"inside", 200, 150, 0},
+		{"outside", 99, 150, 0},
+		{"left", 101, 150, resizeLeft},
+		{"right", 299, 150, resizeRight},
+		{"top", 200, 100, resizeTop},
+		{"bottom", 200, 198, resizeBottom},
+		{"top left", 100, 100, resizeTop | resizeLeft},
+		{"left near the bottom", 102, 190, resizeLeft | resizeBottom},
+		{"top near the right", 290, 101, resizeTop | resizeRight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frameEdges(g, tt.x, tt.y, 4, 16); got != tt.want {
+				t.Errorf("frameEdges() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoundariesAt(t *testing.T) {
+	cols := []float64{1, 3}
+	frames := []float64{1, 1}
+	bounds, slots := splitBoundaries(nil, []*float64{&cols[0], &cols[1]}, client.Geom{X: 0, Y: 0, W: 400, H: 200}, true)
+	if want := (client.Geom{X: 100, Y: 0, W: 300, H: 200}); slots[1] != want {
+		t.Fatalf("slot got = %v, want = %v", slots[1], want)
+	}
+	inner, _ := splitBoundaries(nil, []*float64{&frames[0], &frames[1]}, slots[1], false)
+	bounds = append(bounds, inner...)
+	tests := []struct {
+		name string
+		x, y int
+		want []int // positions of the boundaries found
+	}{
+		{"none", 50, 50, nil},
+		{"columns", 104, 50, []int{100}},
+		{"frames", 250, 98, []int{100}},
+		{"frames out of the column", 50, 100, nil},
+		{"both", 102, 101, []int{100, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, b := range boundariesAt(bounds, tt.x, tt.y, 5) {
+				got = append(got, b.pos)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("boundaries got = %v, want = %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("boundaries got = %v, want = %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveBoundary(t *testing.T) {
+	tests := []struct {
+		name         string
+		delta        float64
+		want1, want2 float64
+	}{
+		{"grow", 20, 120, 80},
+		{"shrink", -30, 70, 130},
+		{"min after", 80, 150, 50},
+		{"min before", -80, 50, 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got1, got2 := moveBoundary([2]float64{100, 100}, tt.delta, 50)
+			if got1 != tt.want1 || got2 != tt.want2 {
+				t.Errorf("moveBoundary() got = %v %v, want = %v %v", got1, got2, tt.want1, tt.want2)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package wm
+
+import (
+	"sort"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
+)
+
+// buttonAction is a mouse binding, running its command when the button is pressed with the modifiers held
+type buttonAction struct {
+	button    xproto.Button
+	modifiers uint16
+	command   string
+}
+
+// buttonStateMask covers the bits of the button event state telling which buttons were already held
+const buttonStateMask = xproto.KeyButMaskButton1 | xproto.KeyButMaskButton2 | xproto.KeyButMaskButton3 |
+	xproto.KeyButMaskButton4 | xproto.KeyButMaskButton5
+
+// initButtonActions creates an action for every mouse binding
+func initButtonActions(wm *WM) []*buttonAction {
+	mod := wm.modMask()
+	combos := make([]string, 0, len(wm.config.Mousebindings))
+	for combo := range wm.config.Mousebindings {
+		combos = append(combos, combo)
+	}
+	sort.Strings(combos)
+
+	actions := make([]*buttonAction, 0, len(combos))
+	for _, combo := range combos {
+		cmd := wm.config.Mousebindings[combo]
+		if cmd == "" {
+			// an empty command removes the default binding
+			continue
+		}
+		button, modifiers, err := keysym.ParseButtonBinding(combo, mod)
+		if err != nil {
+			logger.Warnf("Skipping mouse binding: %v", err)
+			continue
+		}
+		actions = append(actions, &buttonAction{button: button, modifiers: modifiers, command: cmd})
+	}
+	return actions
+}
+
+// grabButtonActions grabs the buttons of the mouse bindings with modifiers over all the windows. The bindings
+// without modifiers only apply to the clicks on the desktop and on the titlebars, which the WM receives anyway
+func (wm *WM) grabButtonActions() error {
+	for _, action := range wm.buttons {
+		if action.modifiers == 0 {
+			continue
+		}
+		for _, locks := range wm.modmap.LockCombinations() {
+			cookie := xproto.GrabButtonChecked(
+				wm.xc.X(),
+				false,
+				wm.xc.GetRootWindow(),
+				xproto.EventMaskButtonPress|xproto.EventMaskButtonRelease,
+				xproto.GrabModeAsync,
+				xproto.GrabModeAsync,
+				xproto.WindowNone,
+				xproto.CursorNone,
+				byte(action.button),
+				action.modifiers|locks,
+			)
+			if err := cookie.Check(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runButtonAction runs the command bound to the pressed button for the clicked frame, or without a window
+// if nothing but the desktop was clicked. It returns false if the button is not bound
+func (wm *WM) runButtonAction(e xproto.ButtonPressEvent, f *frame) (bool, error) {
+	locks := wm.modmap.Locks()
+	state := e.State &^ (keysym.GroupMask | locks | buttonStateMask)
+	for _, action := range wm.buttons {
+		if action.button != xproto.Button(e.Detail) || action.modifiers&^locks != state {
+			continue
+		}
+		if f == nil {
+			return true, wm.runCommands(action.command)
+		}
+		return true, wm.runWithCriteria(criteria{window: f.cli.Window()}, action.command)
+	}
+	return false, nil
+}
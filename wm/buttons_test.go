@@ -0,0 +1,29 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestInitButtonActions(t *testing.T) {
+	wm := &WM{config: Config{Mod: "alt", Mousebindings: map[string]string{
+		"mod+wheelup": "workspace prev",
+		"button2":     "kill",
+		"mod+button3": "", // disabled
+		"mod+foo":     "kill",
+	}}}
+	got := initButtonActions(wm)
+	want := []buttonAction{
+		{button: xproto.ButtonIndex2, command: "kill"},
+		{button: xproto.ButtonIndex4, modifiers: xproto.ModMask1, command: "workspace prev"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got = %v, want = %v", got, want)
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("action %d got = %v, want = %v", i, *got[i], want[i])
+		}
+	}
+}
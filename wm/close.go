@@ -0,0 +1,73 @@
+package wm
+
+import (
+	"time"
+)
+
+// closeWindow asks the client of the frame to close its window using WM_DELETE_WINDOW. Clients that
+// don't support the protocol are killed right away, the others when the window is closed a second time
+// or, if configured, when they don't react within the kill timeout. Closing a placeholder removes it
+func (wm *WM) closeWindow(f *frame) error {
+	if f.placeholder != nil {
+		// the window belongs to the WM, killing its client would kill the WM
+		wm.destroyPlaceholder(f)
+		return nil
+	}
+	win := f.cli.Window()
+	if f.closing || !wm.xc.SupportsProtocol(win, "WM_DELETE_WINDOW") {
+		return wm.xc.KillClient(win)
+	}
+	if err := wm.xc.SendDeleteWindow(win); err != nil {
+		return err
+	}
+	f.closing = true
+	if wm.config.KillTimeout > 0 {
+		time.AfterFunc(time.Duration(wm.config.KillTimeout)*time.Second, func() {
+			wm.queueTask(func() error { return wm.killUnresponsive(f) })
+		})
+	}
+	return nil
+}
+
+// killUnresponsive kills the client of the frame if its window is still managed after it was asked to close
+func (wm *WM) killUnresponsive(f *frame) error {
+	if wm.findFrame(func(frm *frame) bool { return frm == f }) == nil {
+		return nil
+	}
+	return wm.xc.KillClient(f.cli.Window())
+}
+
+// queueTask runs the function in the event loop, it's meant for calls made from other goroutines, e.g. timers.
+// The function is dropped once the event loop has stopped
+func (wm *WM) queueTask(fn func() error) {
+	select {
+	case wm.tasks <- fn:
+	case <-wm.done:
+	}
+}
+
+// pollEvery runs the function in the event loop at the given interval, until the returned function is called
+// or the event loop stops. A call queued before stopping can still run after it
+func (wm *WM) pollEvery(interval time.Duration, fn func() error) (stop func()) {
+	ticker := time.NewTicker(interval)
+	stopped := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case <-stopped:
+					return
+				default:
+				}
+				wm.queueTask(fn)
+			case <-stopped:
+				return
+			case <-wm.done:
+				return
+			}
+		}
+	}()
+	return func() { close(stopped) }
+}
@@ -0,0 +1,42 @@
+package wm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueTask(t *testing.T) {
+	wm := &WM{tasks: make(chan func() error), done: make(chan struct{})}
+	close(wm.done)
+	queued := make(chan struct{})
+	go func() {
+		wm.queueTask(func() error { return nil })
+		close(queued)
+	}()
+	select {
+	case <-queued:
+	case <-time.After(time.Second):
+		t.Fatalf("queueTask blocked after the event loop stopped")
+	}
+}
+
+func TestPollEvery(t *testing.T) {
+	wm := &WM{tasks: make(chan func() error), done: make(chan struct{})}
+	stop := wm.pollEvery(time.Millisecond, func() error { return nil })
+	select {
+	case <-wm.tasks:
+	case <-time.After(time.Second):
+		t.Fatalf("no task queued")
+	}
+	stop()
+	// a call queued before stopping can still come
+	select {
+	case <-wm.tasks:
+	case <-time.After(10 * time.Millisecond):
+	}
+	select {
+	case <-wm.tasks:
+		t.Errorf("task queued after stopping")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
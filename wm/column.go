@@ -1,29 +1,50 @@
 package wm
 
+import (
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// columnLayout describes how the frames of a column are arranged
+type columnLayout uint8
+
+const (
+	// layoutSplit divides the height of the column between all of its frames
+	layoutSplit columnLayout = iota
+	// layoutStacked shows only the active frame, with the titlebars of the others stacked above it as tabs
+	layoutStacked
+)
+
+// A column is also used for the split containers nested in the place of a frame (see splitFrame), whose
+// frames are placed either top to bottom, like in the columns of the workspace, or side by side
 type column struct {
 	ws     *workspace
 	frames []*frame
-	width  uint16
+	weight float64 // share of the workspace width, relative to the other columns
+
+	layout columnLayout
+	active *frame // frame shown in the stacked layout
+
+	parent      *frame      // frame holding a nested split container, nil for the columns of the workspace
+	orientation orientation // direction in which the frames of a nested container are placed
+
+	rendered *layoutKey // key of the last rendering of a column of the workspace, nil if it must be rendered
 }
 
+// addFrame inserts the frame after the given one or, if it's nil, at the end of the column
 func (c *column) addFrame(frm *frame, after *frame) {
-	frm.col = c
-	wsHeight := c.ws.area().H
-	if len(c.frames) > 0 {
-		frm.height = wsHeight / uint16(len(c.frames)+1)
-		remHeight := float32(wsHeight - frm.height)
-		leftHeight := uint16(remHeight)
-		for _, f := range c.frames {
-			f.height = uint16((float32(f.height) / float32(wsHeight)) * remHeight)
-			leftHeight -= f.height
-		}
-		if leftHeight != 0 {
-			frm.height += leftHeight
-		}
-	} else {
-		frm.height = wsHeight
+	i := c.findFrameIndex(func(f *frame) bool { return f == after })
+	if after == nil || i < 0 {
+		i = len(c.frames) - 1
 	}
-	c.frames = append(c.frames, frm)
+	c.insertFrame(frm, i+1)
+}
+
+// insertFrame puts the frame at the given index of the column, giving it an equal share of the space
+func (c *column) insertFrame(frm *frame, i int) {
+	frm.weight = newWeight(c.frameWeights())
+	frm.col = c
+	c.frames = append(c.frames[:i], append([]*frame{frm}, c.frames[i:]...)...)
+	c.active = frm
 }
 
 func (c *column) deleteFrame(frm *frame) {
@@ -32,15 +53,61 @@ func (c *column) deleteFrame(frm *frame) {
 		return
 	}
 	c.frames = append(c.frames[:idx], c.frames[idx+1:]...)
-	c.updateTiling()
+	if c.active == frm {
+		c.active = nil
+		if len(c.frames) > 0 {
+			if idx >= len(c.frames) {
+				idx = len(c.frames) - 1
+			}
+			c.active = c.frames[idx]
+		}
+	}
+}
+
+// activeFrame returns the frame shown when the column is stacked: the last one selected or the first one
+func (c *column) activeFrame() *frame {
+	frames := c.visibleFrames()
+	for _, f := range frames {
+		if f == c.active {
+			return f
+		}
+	}
+	if len(frames) > 0 {
+		return frames[0]
+	}
+	return nil
+}
+
+// cycleFrame returns the frame that follows (or precedes, if offset is negative) the given one in the column
+func (c *column) cycleFrame(f *frame, offset int) *frame {
+	frames := c.visibleFrames()
+	for i, frm := range frames {
+		if frm == f {
+			n := len(frames)
+			return frames[((i+offset)%n+n)%n]
+		}
+	}
+	return nil
+}
+
+// frameWeights returns the weights of all the frames of the column
+func (c *column) frameWeights() []float64 {
+	weights := make([]float64, len(c.frames))
+	for i, f := range c.frames {
+		weights[i] = f.weight
+	}
+	return weights
 }
 
-func (c *column) updateTiling() {
-	wsHeight := c.ws.area().H
-	// TODO: assign the heights proportional to the original height/totalHeight ratio
+// visibleFrames returns the frames of the column that are not hidden
+func (c *column) visibleFrames() []*frame {
+	var frames []*frame
 	for _, f := range c.frames {
-		f.height = wsHeight / uint16(len(c.frames))
+		if f.visible() {
+			frames = append(frames, f)
+		}
 	}
+	return frames
 }
 
 func (c *column) findFrameIndex(predicate func(*frame) bool) int {
@@ -51,3 +118,42 @@ func (c *column) findFrameIndex(predicate func(*frame) bool) int {
 	}
 	return -1
 }
+
+// setColumnLayout switches the layout of the column, the focused frame becoming the active one when stacked
+func (wm *WM) setColumnLayout(col *column, layout columnLayout) error {
+	if col.layout == layout {
+		return nil
+	}
+	col.layout = layout
+	if f := wm.targetFrame(); f != nil {
+		if item := f.itemIn(col); item != nil {
+			col.active = item
+		}
+	}
+	return wm.renderWorkspace(col.ws)
+}
+
+// cycleColumnFocus moves the focus to the frame of the same column that's offset from the given one.
+// Inside a split container nested in a stacked column, it's the tabs of the stacked column that are cycled
+func (wm *WM) cycleColumnFocus(f *frame, offset int) error {
+	col := f.col
+	for c := f.col; c != nil; c = c.parentColumn() {
+		if c.layout == layoutStacked {
+			col = c
+			break
+		}
+	}
+	item := f.itemIn(col)
+	next := col.cycleFrame(item, offset)
+	if next == nil || next == item {
+		return nil
+	}
+	target := next.focusTarget()
+	if target == nil {
+		return nil
+	}
+	if err := wm.setFocus(target.cli.Window(), xproto.TimeCurrentTime); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(target)
+}
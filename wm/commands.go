@@ -0,0 +1,445 @@
+package wm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// command is a function executed for a textual command, e.g. "workspace 2", receiving the arguments
+// that follow the command name
+type command func(wm *WM, args []string) error
+
+var commands map[string]command
+
+// the commands are registered in init as some of them (e.g. reload) refer back to the command table
+func init() {
+	commands = map[string]command{
+		"workspace":     cmdWorkspace,
+		"move":          cmdMove,
+		"swap":          cmdSwap,
+		"kill":          cmdKill,
+		"floating":      cmdFloating,
+		"fullscreen":    cmdFullscreen,
+		"maximize":      cmdMaximize,
+		"monocle":       cmdMonocle,
+		"sticky":        cmdSticky,
+		"minimize":      cmdMinimize,
+		"restore":       cmdRestore,
+		"reload":        cmdReload,
+		"restart":       cmdRestart,
+		"resize":        cmdResize,
+		"scratchpad":    cmdScratchpad,
+		"layout":        cmdLayout,
+		"tab":           cmdTab,
+		"focus":         cmdFocus,
+		"gaps":          cmdGaps,
+		"opacity":       cmdOpacity,
+		"place":         cmdPlace,
+		"split":         cmdSplit,
+		"mark":          cmdMark,
+		"unmark":        cmdUnmark,
+		"save_layout":   cmdSaveLayout,
+		"append_layout": cmdAppendLayout,
+		"mode":          cmdMode,
+		"overview":      cmdOverview,
+		"osd":           cmdOSD,
+		"goto":          cmdGoto,
+		"launcher":      cmdLauncher,
+		"log":           cmdLog,
+		"exit":          cmdQuit,
+		"quit":          cmdQuit,
+	}
+}
+
+// runCommands executes a list of commands separated by semicolons, stopping at the first error.
+// The exec command consumes the rest of the line, semicolons included
+func (wm *WM) runCommands(line string) error {
+	for _, cmd := range splitCommands(line) {
+		if err := wm.runCommand(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitCommands splits the line into its commands separated by semicolons, empty ones left out. An exec
+// command takes the rest of the line, which is passed on to the shell as is
+func splitCommands(line string) []string {
+	var cmds []string
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		cmd := line
+		if isExecCommand(line) {
+			line = ""
+		} else if i := strings.Index(line, ";"); i >= 0 {
+			cmd, line = line[:i], line[i+1:]
+		} else {
+			line = ""
+		}
+		if strings.TrimSpace(cmd) != "" {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// runCommand parses and executes a single command, applied to the windows matching its criteria if any
+func (wm *WM) runCommand(line string) error {
+	if line = strings.TrimSpace(line); strings.HasPrefix(line, "[") && wm.selected == nil {
+		c, rest, err := parseCriteria(line)
+		if err != nil {
+			return err
+		}
+		return wm.runWithCriteria(c, rest)
+	}
+	if isExecCommand(line) {
+		return wm.exec(strings.TrimSpace(strings.TrimSpace(line)[len("exec"):]))
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty command")
+	}
+	cmd, ok := commands[fields[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", fields[0])
+	}
+	return cmd(wm, fields[1:])
+}
+
+// cmdWorkspace switches to the workspace with the given number or name, creating it if it doesn't exist:
+// workspace <number|name|next|prev|back_and_forth>
+func cmdWorkspace(wm *WM, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: workspace <number|name|next|prev|back_and_forth>")
+	}
+	switch strings.Join(args, " ") {
+	case "next":
+		return wm.switchAdjacentWorkspace(1)
+	case "prev":
+		return wm.switchAdjacentWorkspace(-1)
+	case "back_and_forth":
+		return wm.switchBackAndForth()
+	}
+	return handleSwitchWorkspace(wm, strings.Join(args, " "))
+}
+
+// cmdMove moves the focused window: move <left|right|up|down>, move to workspace <number|name> or move scratchpad
+func cmdMove(wm *WM, args []string) error {
+	if len(args) == 1 && args[0] == "scratchpad" || len(args) == 2 && args[0] == "to" && args[1] == "scratchpad" {
+		f := wm.targetFrame()
+		if f == nil {
+			return nil
+		}
+		return wm.moveToScratchpad(f)
+	}
+	if len(args) >= 3 && args[0] == "to" && args[1] == "workspace" {
+		return handleMoveWindowToWorkspace(wm, strings.Join(args[2:], " "))
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("usage: move <left|right|up|down> [<pixels>px] | move to workspace <number|name> | move scratchpad")
+	}
+	dir, err := parseMoveDirection(args[0])
+	if err != nil {
+		return err
+	}
+	px := int(wm.config.FloatingStep)
+	if len(args) == 2 {
+		if px, err = parsePixels(args[1]); err != nil {
+			return err
+		}
+	}
+	return handleMoveWindow(wm, dir, px)
+}
+
+// cmdScratchpad shows or hides the scratchpad windows: scratchpad show
+func cmdScratchpad(wm *WM, args []string) error {
+	if len(args) != 1 || args[0] != "show" {
+		return fmt.Errorf("usage: scratchpad show")
+	}
+	return wm.showScratchpad()
+}
+
+// cmdLayout changes the layout of the column of the focused window: layout <split|stacked|toggle>
+func cmdLayout(wm *WM, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: layout <split|stacked|toggle>")
+	}
+	f := wm.targetFrame()
+	if f == nil || f.col == nil {
+		return nil
+	}
+	switch args[0] {
+	case "split":
+		return wm.setColumnLayout(f.col, layoutSplit)
+	case "stacked":
+		return wm.setColumnLayout(f.col, layoutStacked)
+	case "toggle":
+		if f.col.layout == layoutStacked {
+			return wm.setColumnLayout(f.col, layoutSplit)
+		}
+		return wm.setColumnLayout(f.col, layoutStacked)
+	}
+	return fmt.Errorf("invalid layout %q, expected split, stacked or toggle", args[0])
+}
+
+// cmdTab focuses another frame of the column of the focused window, selecting its tab in a stacked column,
+// or the next tiled window of a workspace in the monocle layout: tab <next|prev>
+func cmdTab(wm *WM, args []string) error {
+	if len(args) != 1 || (args[0] != "next" && args[0] != "prev") {
+		return fmt.Errorf("usage: tab <next|prev>")
+	}
+	f := wm.targetFrame()
+	if f == nil || f.col == nil {
+		return nil
+	}
+	offset := 1
+	if args[0] == "prev" {
+		offset = -1
+	}
+	if f.workspace().monocle {
+		return wm.cycleMonocle(f, offset)
+	}
+	return wm.cycleColumnFocus(f, offset)
+}
+
+// cmdFocus moves the focus in a direction, to an urgent window, another output, a marked window or the next
+// window of the focus history, or activates the window selected by the criteria: focus <left|right|up|down|urgent>,
+// focus output <direction|name>, focus mark <name>, focus cycle [back] or [criteria] focus
+func cmdFocus(wm *WM, args []string) error {
+	if len(args) >= 1 && args[0] == "cycle" {
+		if len(args) > 2 || len(args) == 2 && args[1] != "back" {
+			return fmt.Errorf("usage: focus cycle [back]")
+		}
+		return wm.cycleFocus(len(args) == 2)
+	}
+	if len(args) == 0 && wm.selected != nil {
+		return wm.activateFrame(wm.selected)
+	}
+	if len(args) == 2 && args[0] == "mark" {
+		return wm.focusMark(args[1])
+	}
+	if len(args) == 2 && args[0] == "output" {
+		o, err := wm.findOutput(args[1])
+		if err != nil {
+			return err
+		}
+		return wm.focusOutput(o)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: focus <left|right|up|down|urgent> | focus output <left|right|up|down|next|prev|primary|name> | focus mark <name> | focus cycle [back] | [criteria] focus")
+	}
+	if args[0] == "urgent" {
+		return wm.focusUrgent()
+	}
+	dir, err := parseMoveDirection(args[0])
+	if err != nil {
+		return err
+	}
+	return wm.focusDirection(dir)
+}
+
+// cmdKill closes the focused window
+func cmdKill(wm *WM, args []string) error {
+	return handleRemoveWindow(wm)
+}
+
+// cmdFloating changes the floating state of the focused window: floating <enable|disable|toggle>
+func cmdFloating(wm *WM, args []string) error {
+	f := wm.targetFrame()
+	if f == nil {
+		return nil
+	}
+	enable, err := parseToggle(args, f.floating)
+	if err != nil {
+		return fmt.Errorf("floating: %v", err)
+	}
+	if enable == f.floating {
+		return nil
+	}
+	return handleToggleFloating(wm)
+}
+
+// cmdFullscreen changes the fullscreen state of the focused window: fullscreen <enable|disable|toggle>
+func cmdFullscreen(wm *WM, args []string) error {
+	f := wm.targetFrame()
+	if f == nil {
+		return nil
+	}
+	enable, err := parseToggle(args, f.fullscreen)
+	if err != nil {
+		return fmt.Errorf("fullscreen: %v", err)
+	}
+	return wm.setFullscreen(f, enable)
+}
+
+// cmdSticky changes whether the focused window is shown on every workspace of its output, floating it
+// if it's tiled: sticky <enable|disable|toggle>
+func cmdSticky(wm *WM, args []string) error {
+	f := wm.targetFrame()
+	if f == nil {
+		return nil
+	}
+	enable, err := parseToggle(args, f.state.sticky)
+	if err != nil {
+		return fmt.Errorf("sticky: %v", err)
+	}
+	return wm.setSticky(f, enable)
+}
+
+// isExecCommand checks whether the line starts with the exec command, which takes the rest of the line
+// verbatim as a shell command
+func isExecCommand(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && fields[0] == "exec"
+}
+
+// cmdResize changes the size of the focused window: resize <grow|shrink> <width|height> [percent]
+func cmdResize(wm *WM, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return fmt.Errorf("usage: resize <grow|shrink> <width|height> [percent|<pixels>px]")
+	}
+	amount := resizeAmount{pct: 5, px: int(wm.config.FloatingStep)}
+	if len(args) == 3 && strings.HasSuffix(args[2], "px") {
+		px, err := parsePixels(args[2])
+		if err != nil {
+			return err
+		}
+		amount = resizeAmount{px: px, pixels: true}
+	} else if len(args) == 3 {
+		n, err := strconv.Atoi(strings.TrimSuffix(args[2], "%"))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid resize amount %q", args[2])
+		}
+		amount.pct = n
+	}
+	switch args[0] {
+	case "grow":
+	case "shrink":
+		amount.pct, amount.px = -amount.pct, -amount.px
+	default:
+		return fmt.Errorf("invalid resize mode %q, expected grow or shrink", args[0])
+	}
+	switch args[1] {
+	case "width":
+		return handleResizeWindow(wm, ResizeHoriz, amount)
+	case "height":
+		return handleResizeWindow(wm, ResizeVert, amount)
+	}
+	return fmt.Errorf("invalid resize dimension %q, expected width or height", args[1])
+}
+
+// cmdGaps changes the gaps at runtime, either of the current workspace or all of them:
+// gaps <inner|outer> <current|all> <set|plus|minus> <pixels> | gaps <inner|outer> <current|all> reset
+func cmdGaps(wm *WM, args []string) error {
+	usage := fmt.Errorf("usage: gaps <inner|outer> <current|all> <set|plus|minus> <pixels> | gaps <inner|outer> <current|all> reset")
+	if len(args) != 3 && len(args) != 4 || len(args) == 3 && args[2] != "reset" {
+		return usage
+	}
+	var inner bool
+	switch args[0] {
+	case "inner":
+		inner = true
+	case "outer":
+	default:
+		return usage
+	}
+	var workspaces []*workspace
+	switch args[1] {
+	case "current":
+		workspaces = []*workspace{wm.currentOutput().activeWs}
+	case "all":
+		workspaces = wm.workspaces
+	default:
+		return usage
+	}
+	var px int
+	if len(args) == 4 {
+		n, err := strconv.Atoi(args[3])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid gap size %q", args[3])
+		}
+		px = n
+	}
+	var change func(gap uint16) int
+	switch args[2] {
+	case "set":
+		change = func(uint16) int { return px }
+	case "plus":
+		change = func(gap uint16) int { return int(gap) + px }
+	case "minus":
+		change = func(gap uint16) int { return int(gap) - px }
+	case "reset":
+		change = func(uint16) int {
+			if inner {
+				return int(wm.config.InnerGap)
+			}
+			return int(wm.config.OuterGap)
+		}
+	default:
+		return usage
+	}
+	return wm.changeGaps(workspaces, inner, change)
+}
+
+// cmdMode switches to another set of keybindings: mode <name|default>
+func cmdMode(wm *WM, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: mode <name|default>")
+	}
+	return wm.setMode(args[0])
+}
+
+// cmdQuit gives all the windows back to the X server and stops the WM
+func cmdQuit(wm *WM, args []string) error {
+	return wm.quit()
+}
+
+// cmdReload re-reads the config file and applies the new settings
+func cmdReload(wm *WM, args []string) error {
+	return wm.reload()
+}
+
+// cmdRestart restarts the WM in place, keeping the windows and their layout
+func cmdRestart(wm *WM, args []string) error {
+	return wm.restart()
+}
+
+// parsePixels reads a positive distance in pixels, e.g. "20px"
+func parsePixels(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSuffix(s, "px"))
+	if err != nil || n <= 0 || !strings.HasSuffix(s, "px") {
+		return 0, fmt.Errorf("invalid distance %q, expected a number of pixels, e.g. 20px", s)
+	}
+	return n, nil
+}
+
+func parseMoveDirection(s string) (MoveDirection, error) {
+	switch s {
+	case "left":
+		return MoveLeft, nil
+	case "right":
+		return MoveRight, nil
+	case "up":
+		return MoveUp, nil
+	case "down":
+		return MoveDown, nil
+	}
+	return 0, fmt.Errorf("invalid direction %q", s)
+}
+
+// parseToggle returns the next value of a flag given an "enable", "disable" or "toggle" argument
+// (toggle being the default)
+func parseToggle(args []string, current bool) (bool, error) {
+	if len(args) == 0 {
+		return !current, nil
+	}
+	switch args[0] {
+	case "enable":
+		return true, nil
+	case "disable":
+		return false, nil
+	case "toggle":
+		return !current, nil
+	}
+	return current, fmt.Errorf("invalid argument %q, expected enable, disable or toggle", args[0])
+}
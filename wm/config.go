@@ -1,28 +1,270 @@
 package wm
 
 import (
-	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/fonts"
+	"github.com/patrislav/marwind/logger"
 )
 
 type Config struct {
 	InnerGap uint16 // Gap around each window, in pixels
 	OuterGap uint16 // Additional gap around the entire workspace, in pixels
 
+	// Whether to leave out all the gaps when a workspace has a single tiled window
+	SmartGaps bool
+
+	// Whether to hide the border and titlebar of the only tiled window of a workspace
+	SmartBorders bool
+
 	Shell string // Name of the program to use for executing commands ("/bin/sh" by default)
 
-	// Shell command to execute after using the "Launcher" binding (Win + D by default)
-	LauncherCommand string
-	// Shell command to execute after using the "Terminal" binding (Win + Shift + Enter by default)
-	TerminalCommand string
+	// Modifier used in place of "mod" in the keybindings, e.g. "mod4" (the default) or "alt"
+	Mod string
 
-	BorderWidth uint8
-	BorderColor uint32
+	BorderWidth         uint8
+	BorderColor         uint32 // Border of the focused window
+	BorderColorInactive uint32
+	BorderColorUrgent   uint32 // Border of the windows demanding attention
+
+	// Opacity of the focused and the other windows, from 0 (transparent) to 1 (opaque), applied by a compositor
+	Opacity         float64
+	OpacityInactive float64
 
 	TitleBarHeight            uint8
-	TitleBarBgColor           uint32
+	TitleBarBgColor           uint32 // Background of the titlebar of the focused window
+	TitleBarBgColorInactive   uint32
+	TitleBarBgColorUrgent     uint32 // Background of the titlebar of the windows demanding attention
 	TitleBarFontColorActive   uint32
 	TitleBarFontColorInactive uint32
 	TitleBarFontSize          float64
+	TitleBarShowMarks         bool // Whether the marks of the windows are shown before their titles
+
+	// Template of the titles, in which "{title}", "{class}" and "{instance}" are replaced with the title
+	// and the parts of WM_CLASS of the window, e.g. "{class} — {title}"
+	TitleBarFormat    string
+	TitleBarAlign     string // client.AlignLeft, client.AlignCenter or client.AlignRight
+	TitleBarPadding   uint16 // Space kept free at both ends of the titles, in pixels
+	TitleBarMaxLength int    // Maximum number of characters of the titles, 0 for no limit
+
+	// TrueType fonts of all the text drawn by the WM, as file paths or fontconfig patterns, in the order of
+	// preference: each character is drawn with the first font that has it, the embedded Go font being the last resort
+	Fonts []string
+
+	Tray         bool   // Whether to show the system tray at the top of the first output
+	TrayIconSize uint16 // Width and height of the tray icons, in pixels
+	TrayBgColor  uint32
+
+	// Whether the name of the workspace switched to and of the binding mode entered are briefly shown
+	// in the middle of the output, on the on-screen display (OSD). The "osd" command shows any text regardless
+	OSD          bool
+	OSDFontSize  float64
+	OSDFontColor uint32
+	OSDBgColor   uint32
+	OSDTimeout   uint16 // Milliseconds the text stays on the screen
+
+	// TrueType font of the OSD text, as a file path or a fontconfig pattern, put in front of the fonts of
+	// all the text; empty for those alone
+	OSDFont string
+
+	// XCursor theme of the cursors shown by the WM (e.g. "Adwaita") and their size in pixels. The theme and
+	// size of XCURSOR_THEME and XCURSOR_SIZE or of the Xcursor.theme and Xcursor.size resources are used
+	// if empty or 0
+	CursorTheme string
+	CursorSize  uint16
+
+	// Whether "workspace next" and "workspace prev" also go through the empty workspaces that are not shown
+	WorkspaceIncludeEmpty bool
+
+	// Whether switching to the workspace that's already shown goes back to the workspace shown before it
+	// on the same output, like "workspace back_and_forth"
+	WorkspaceAutoBackAndForth bool
+
+	// Whether the window under the pointer gets focused (focus follows mouse), otherwise windows
+	// are focused by clicking them
+	FocusFollowsMouse bool
+
+	// When the pointer is moved to the window focused with the keyboard: WarpFocus (whenever the focus
+	// changes), WarpOutput (only when the window is on another output than the pointer) or WarpNone
+	MouseWarping string
+
+	// Where the pointer is moved within the window: WarpCenter or WarpLast, where the pointer last was
+	// in the window
+	MouseWarpingPosition string
+
+	// Commands executed when the pointer is pushed against an edge of the screen, e.g. "workspace prev"
+	// for the left one, empty for none
+	EdgeLeft   string
+	EdgeRight  string
+	EdgeTop    string
+	EdgeBottom string
+
+	// Milliseconds the pointer has to stay at an edge of the screen before its command is executed
+	EdgeDelay uint16
+
+	// Whether the docks are hidden until the pointer touches the edge of the output they're placed at
+	// or a workspace demands attention, instead of reserving space next to the workspaces
+	DockAutoHide bool
+
+	// Milliseconds after the pointer left a shown auto-hidden dock before it's hidden again
+	DockHideDelay uint16
+
+	// Classes (or instances) of the docks in the order they're placed in each area, from the edge of
+	// the output inwards. The other docks follow them in the order they were mapped
+	DockOrder []string
+
+	// How the requests of the windows to be activated (e.g. from a pager or a program opening a link)
+	// are handled: ActivationSmart (the default), ActivationFocus, ActivationUrgent or ActivationNone
+	FocusOnActivation string
+
+	// Whether the new windows take the focus only if the user interacted with them (according to
+	// _NET_WM_USER_TIME or the startup ID) after the focused window, being marked as urgent otherwise
+	FocusStealingPrevention bool
+
+	// Where new tiled windows are placed: PlacementAuto (the default), PlacementNewColumn,
+	// PlacementFocusedColumn or PlacementAfterFocused
+	Placement string
+
+	// Pixels a floating window is moved or resized by with each step of the keyboard: a "move left" command,
+	// a "resize grow width" command without an amount in pixels or a press of an arrow key while it's dragged
+	FloatingStep uint16
+
+	// Distance in pixels within which the edges of a floating window moved or resized with the mouse snap to
+	// the edges of the output, of the workspace area and of the other floating windows, and to the boundaries
+	// between the tiled windows. Moves with the keyboard stop at them. 0 to disable snapping
+	SnapDistance uint16
+
+	// Whether the geometry of a floating window is remembered when it's closed, by its WM_CLASS, across restarts,
+	// and given to the next floating window of the same class and instance, e.g. a file dialog
+	FloatingRemember bool
+
+	// Whether a focused fullscreen window keeps the focus: the pointer doesn't move the focus away from it
+	// and new windows, other than its own dialogs, are marked as urgent and stay below it instead of taking the focus
+	FullscreenFocusLock bool
+
+	// Where the notification windows appear while a fullscreen window is shown: NotificationsAbove
+	// (the default) or NotificationsOtherOutput
+	FullscreenNotifications string
+
+	// Corner of the workspace area in which the notification windows are stacked, below the titlebars
+	// at the top of the workspace: NotificationCornerTopRight (the default), NotificationCornerTopLeft,
+	// NotificationCornerBottomRight, NotificationCornerBottomLeft or NotificationCornerNone to leave them
+	// where their clients put them
+	NotificationCorner string
+
+	// Maximum number of notification windows shown at once on a workspace, the newer ones wait until
+	// the older ones are closed. 0 for no limit
+	NotificationMax uint16
+
+	// Commands executed once the user has been idle for some time, e.g. to start a screen locker
+	IdleHooks []IdleHook
+
+	// Whether a focused fullscreen window, e.g. a video, keeps the idle hooks, the screen saver
+	// and the power saving of the monitors from activating
+	IdleInhibitFullscreen bool
+
+	// Whether to enable the power saving of the monitors with the standby, suspend and off timeouts below,
+	// in seconds (0 disables the level), otherwise the DPMS settings of the X server are left alone
+	DPMS        bool
+	DPMSStandby uint16
+	DPMSSuspend uint16
+	DPMSOff     uint16
+
+	// Whether the focus moved in a direction wraps around to the other side of the workspace
+	FocusWrap bool
+
+	// Whether a terminal (a window of one of the SwallowClasses) is hidden while a window started
+	// from it, e.g. an image viewer, is shown in its place
+	Swallow        bool
+	SwallowClasses []string
+
+	// Lowest level of the logged messages: "debug", "info" (the default), "warn" or "error"
+	LogLevel string
+
+	// Where the log messages are written: the path of a file, "journal" for the systemd journal
+	// or empty for the standard error
+	LogFile string
+
+	// Whether to log every X event received by the WM, only effective at the debug level
+	LogEvents bool
+
+	// Seconds after which the client of a window that was asked to close but didn't is killed,
+	// 0 to only kill it when the window is closed again
+	KillTimeout uint16
+
+	// Commands executed on key combinations, e.g. "mod+shift+q" = "kill" or "mod+d" = "exec rofi -show drun".
+	// Binding a combination to an empty command disables it
+	Keybindings map[string]string
+
+	// Commands executed on pointer button combinations, e.g. "mod+button2" = "kill" or "mod+wheelup" = "workspace prev".
+	// The commands act on the clicked window. Binding a combination to an empty command disables it
+	Mousebindings map[string]string
+
+	// Binding modes, e.g. "resize", each with its own keybindings that replace the ones above while the mode
+	// is active (after the "mode <name>" command). Escape leads back to the default mode unless it's bound
+	Modes map[string]map[string]string
+
+	// Shell commands executed once, when the WM starts
+	StartupCommands []string
+
+	// Shell commands executed when the WM starts and every time it's reloaded or restarted
+	StartupAlwaysCommands []string
+
+	// Whether to start the programs of the XDG autostart desktop entries (e.g. ~/.config/autostart/*.desktop)
+	// together with the WM
+	XDGAutostart bool
+
+	// Rules applied to the new windows, in order
+	Rules []Rule
+
+	// Wallpapers of the outputs, keyed by the RandR output name (e.g. "HDMI-1") or DefaultWallpaper for
+	// the outputs without their own. The background of the root window is left alone if there are none
+	Wallpapers map[string]Wallpaper
+
+	// Whether the pixel values of the config (gaps, borders, titlebars and their fonts) are scaled on each
+	// output by its DPI, as computed from the physical size reported by RandR, see BaseDPI
+	DetectDPI bool
+
+	// DPI of the outputs overriding the detected one, keyed by the RandR output name (e.g. "eDP-1") or
+	// DefaultDPI for the outputs without their own
+	OutputDPI map[string]float64
+
+	// Names of the RandR outputs (e.g. "HDMI-1") to which the workspaces should be assigned,
+	// keyed by the workspace name, e.g. "1" or "web"
+	WorkspaceOutputs map[string]string
+}
+
+// ConfigLoader returns a freshly loaded configuration, it's used when reloading the WM's settings
+type ConfigLoader func() (Config, error)
+
+func newWindowConfig(config Config) client.Config {
+	return client.Config{
+		BgColor:             config.TitleBarBgColor,
+		BgColorInactive:     config.TitleBarBgColorInactive,
+		BgColorUrgent:       config.TitleBarBgColorUrgent,
+		TitlebarHeight:      config.TitleBarHeight,
+		FontColor:           config.TitleBarFontColorActive,
+		FontColorInactive:   config.TitleBarFontColorInactive,
+		FontSize:            config.TitleBarFontSize,
+		Fonts:               loadFonts(config.Fonts),
+		TitleFormat:         config.TitleBarFormat,
+		TitleAlign:          config.TitleBarAlign,
+		TitlePadding:        config.TitleBarPadding,
+		TitleMaxLength:      config.TitleBarMaxLength,
+		BorderWidth:         config.BorderWidth,
+		BorderColor:         config.BorderColor,
+		BorderColorInactive: config.BorderColorInactive,
+		BorderColorUrgent:   config.BorderColorUrgent,
+		Opacity:             config.Opacity,
+		OpacityInactive:     config.OpacityInactive,
+	}
+}
 
-	Keybindings map[xproto.Keysym]string
+// loadFonts reads the fonts, falling back to the embedded Go font if any of them can't be used
+func loadFonts(names []string) *fonts.Set {
+	set, err := fonts.Load(names)
+	if err != nil {
+		logger.Errorf("Failed to load the fonts: %v", err)
+		return fonts.Default()
+	}
+	return set
 }
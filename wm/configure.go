@@ -0,0 +1,153 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/x11"
+)
+
+// handleConfigureRequest grants the changes requested by the unmanaged and floating windows. The tiled,
+// fullscreen and dock windows keep the geometry given to them by the WM, which is reported back instead
+func (wm *WM) handleConfigureRequest(e xproto.ConfigureRequestEvent) error {
+	f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+	if f == nil {
+		return wm.configureUnmanaged(e)
+	}
+	if f.floating && !f.fullscreen && f.cli.Type() == client.TypeNormal {
+		return wm.configureFloating(f, e)
+	}
+	if f.cli.Type() == client.TypeDock {
+		// the docks are sized by their struts, which a dock resizing itself usually changes as well
+		if err := wm.updateDockStrut(f); err != nil {
+			return err
+		}
+	}
+	wm.configureNotify(f)
+	return nil
+}
+
+// configureUnmanaged passes the request of a window that is not managed (e.g. not mapped yet) on to the X server
+func (wm *WM) configureUnmanaged(e xproto.ConfigureRequestEvent) error {
+	var vals []uint32
+	fields := []struct {
+		flag  uint16
+		value uint32
+	}{
+		{xproto.ConfigWindowX, uint32(e.X)},
+		{xproto.ConfigWindowY, uint32(e.Y)},
+		{xproto.ConfigWindowWidth, uint32(e.Width)},
+		{xproto.ConfigWindowHeight, uint32(e.Height)},
+		{xproto.ConfigWindowBorderWidth, uint32(e.BorderWidth)},
+		{xproto.ConfigWindowSibling, uint32(e.Sibling)},
+		{xproto.ConfigWindowStackMode, uint32(e.StackMode)},
+	}
+	for _, field := range fields {
+		if e.ValueMask&field.flag != 0 {
+			vals = append(vals, field.value)
+		}
+	}
+	return xproto.ConfigureWindowChecked(wm.xc.X(), e.Window, e.ValueMask, vals).Check()
+}
+
+// configureFloating moves and resizes the floating frame so that its client window gets the requested
+// position (relative to the root window) and size. Of the stacking changes, only raising the window is honored
+func (wm *WM) configureFloating(f *frame, e xproto.ConfigureRequestEvent) error {
+	d := wm.getFrameDecorations(f)
+	geom := f.floatGeom
+	if e.ValueMask&xproto.ConfigWindowX != 0 {
+		geom.X = e.X - int16(d.Left)
+	}
+	if e.ValueMask&xproto.ConfigWindowY != 0 {
+		geom.Y = e.Y - int16(d.Top)
+	}
+	if e.ValueMask&xproto.ConfigWindowWidth != 0 {
+		geom.W = e.Width + uint16(d.Left+d.Right)
+	}
+	if e.ValueMask&xproto.ConfigWindowHeight != 0 {
+		geom.H = e.Height + uint16(d.Top+d.Bottom)
+	}
+	f.floatGeom = geom
+	ws := f.workspace()
+	if e.ValueMask&xproto.ConfigWindowStackMode != 0 && e.StackMode == xproto.StackModeAbove && ws != nil {
+		ws.raiseFloating(f)
+	}
+	if ws == nil || ws.output == nil || ws.output.activeWs != ws || !f.cli.Mapped() {
+		// the new geometry is applied once the frame is shown, the client still expects a reply
+		wm.configureNotify(f)
+		return nil
+	}
+	placed := f.placed
+	err := wm.renderWorkspace(ws)
+	if f.placed == placed {
+		// the geometry didn't change, the client is told so
+		wm.configureNotify(f)
+	}
+	return err
+}
+
+// handleMoveResizeWindowMessage applies a _NET_MOVERESIZE_WINDOW message, sent by pagers and tools such as
+// wmctrl, like a ConfigureRequest of the fields it sets. The position is the one of the reference point of
+// the gravity given in the message, or of the win_gravity of the window if it's 0
+func (wm *WM) handleMoveResizeWindowMessage(win xproto.Window, data []uint32) error {
+	gravity := byte(data[0])
+	var d x11.Dimensions
+	if f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == win }); f != nil {
+		if gravity == 0 {
+			gravity = xproto.GravityNorthWest
+			if f.hints != nil {
+				gravity = f.hints.Gravity
+			}
+		}
+		d = wm.getFrameDecorations(f)
+	}
+	e := moveResizeRequest(win, data, gravity, d)
+	if e.ValueMask == 0 {
+		return nil
+	}
+	return wm.handleConfigureRequest(e)
+}
+
+// moveResizeRequest translates the data of a _NET_MOVERESIZE_WINDOW message into the ConfigureRequest of
+// the fields it sets, the position moved from the reference point of the gravity to the client window
+// in a frame with the given decorations
+func moveResizeRequest(win xproto.Window, data []uint32, gravity byte, d x11.Dimensions) xproto.ConfigureRequestEvent {
+	flags := data[0] >> 8
+	dx, dy := gravityOffset(gravity, d)
+	e := xproto.ConfigureRequestEvent{
+		Window: win,
+		X:      int16(data[1]) + dx,
+		Y:      int16(data[2]) + dy,
+		Width:  uint16(data[3]),
+		Height: uint16(data[4]),
+	}
+	for i, flag := range []uint16{xproto.ConfigWindowX, xproto.ConfigWindowY, xproto.ConfigWindowWidth, xproto.ConfigWindowHeight} {
+		if flags&(1<<uint(i)) != 0 {
+			e.ValueMask |= flag
+		}
+	}
+	return e
+}
+
+// gravityOffset returns how far the client window is placed from the position requested for it with the given
+// gravity (ICCCM 4.1.2.3), e.g. the size of the top left decorations with the default north west gravity
+func gravityOffset(gravity byte, d x11.Dimensions) (int16, int16) {
+	left, right, top, bottom := int16(d.Left), int16(d.Right), int16(d.Top), int16(d.Bottom)
+	dx, dy := left, top
+	switch gravity {
+	case xproto.GravityNorth, xproto.GravityCenter, xproto.GravitySouth:
+		dx = left - (left+right)/2
+	case xproto.GravityNorthEast, xproto.GravityEast, xproto.GravitySouthEast:
+		dx = -right
+	case xproto.GravityStatic:
+		dx = 0
+	}
+	switch gravity {
+	case xproto.GravityWest, xproto.GravityCenter, xproto.GravityEast:
+		dy = top - (top+bottom)/2
+	case xproto.GravitySouthWest, xproto.GravitySouth, xproto.GravitySouthEast:
+		dy = -bottom
+	case xproto.GravityStatic:
+		dy = 0
+	}
+	return dx, dy
+}
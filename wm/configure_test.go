@@ -0,0 +1,44 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/x11"
+)
+
+func TestMoveResizeRequest(t *testing.T) {
+	d := x11.Dimensions{Top: 22, Right: 2, Bottom: 2, Left: 2}
+	tests := []struct {
+		name     string
+		flags    uint32
+		gravity  byte
+		d        x11.Dimensions
+		wantMask uint16
+		wantX    int16
+		wantY    int16
+	}{
+		{"position", 1<<8 | 1<<9, xproto.GravityStatic, d, xproto.ConfigWindowX | xproto.ConfigWindowY, 100, 200},
+		{"size", 1<<10 | 1<<11, xproto.GravityStatic, d, xproto.ConfigWindowWidth | xproto.ConfigWindowHeight, 100, 200},
+		{"all", 0xf << 8, xproto.GravityStatic, d, xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight, 100, 200},
+		{"none", 0, xproto.GravityStatic, d, 0, 100, 200},
+		{"source bits ignored", 2 << 12, xproto.GravityStatic, d, 0, 100, 200},
+		{"north west", 1 << 8, xproto.GravityNorthWest, d, xproto.ConfigWindowX, 102, 222},
+		{"center", 1 << 8, xproto.GravityCenter, d, xproto.ConfigWindowX, 100, 210},
+		{"south east", 1 << 8, xproto.GravitySouthEast, d, xproto.ConfigWindowX, 98, 198},
+		{"undecorated", 1 << 8, xproto.GravitySouthEast, x11.Dimensions{}, xproto.ConfigWindowX, 100, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []uint32{tt.flags | uint32(tt.gravity), 100, 200, 640, 480}
+			e := moveResizeRequest(1, data, tt.gravity, tt.d)
+			if e.ValueMask != tt.wantMask {
+				t.Errorf("ValueMask got = %#x, want = %#x", e.ValueMask, tt.wantMask)
+			}
+			if e.X != tt.wantX || e.Y != tt.wantY || e.Width != 640 || e.Height != 480 {
+				t.Errorf("geometry got = %v,%v %vx%v, want = %v,%v 640x480", e.X, e.Y, e.Width, e.Height, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
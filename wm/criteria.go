@@ -0,0 +1,158 @@
+package wm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// criteria select the windows a command acts on in place of the focused one, written before the command,
+// e.g. [class="Firefox" workspace=1] move to workspace 2. A window has to match all of them
+type criteria struct {
+	rule      Rule          // class, instance, title and process of the window
+	mark      string        // one of the marks of the window
+	workspace string        // name of the workspace the window is on
+	window    xproto.Window // the window with the given ID
+}
+
+// parseCriteria parses the criteria in brackets at the start of the line, returning the rest of the line.
+// The values can be quoted, a backslash escaping a quote or another backslash
+func parseCriteria(line string) (criteria, string, error) {
+	var c criteria
+	s := strings.TrimPrefix(line, "[")
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return c, "", fmt.Errorf("missing ] after the criteria")
+		}
+		if s[0] == ']' {
+			return c, s[1:], nil
+		}
+		i := strings.Index(s, "=")
+		if i <= 0 {
+			return c, "", fmt.Errorf("invalid criterion %q, expected key=value", strings.Fields(s)[0])
+		}
+		key := s[:i]
+		value, rest, err := parseCriterionValue(s[i+1:])
+		if err != nil {
+			return c, "", fmt.Errorf("%s: %v", key, err)
+		}
+		s = rest
+		switch key {
+		case "class":
+			c.rule.Class = value
+		case "instance":
+			c.rule.Instance = value
+		case "title":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return c, "", fmt.Errorf("title: %v", err)
+			}
+			c.rule.Title = re
+		case "process":
+			c.rule.Process = value
+		case "mark":
+			c.mark = value
+		case "workspace":
+			c.workspace = value
+		case "id":
+			id, err := strconv.ParseUint(value, 0, 32)
+			if err != nil || id == 0 {
+				return c, "", fmt.Errorf("id: invalid window ID %q", value)
+			}
+			c.window = xproto.Window(id)
+		default:
+			return c, "", fmt.Errorf("unknown criterion %q, expected class, instance, title, process, mark, workspace or id", key)
+		}
+	}
+}
+
+// parseCriterionValue reads a value, either quoted or ending with a space or the closing bracket
+func parseCriterionValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		i := strings.IndexAny(s, " \t]")
+		if i < 0 {
+			i = len(s)
+		}
+		return s[:i], s[i:], nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+				i++
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return "", "", fmt.Errorf("missing closing quote")
+}
+
+// matchingFrames returns the frames of the windows on the workspaces that match the criteria
+func (wm *WM) matchingFrames(c criteria) []*frame {
+	var frames []*frame
+	matchRule := c.rule.Class != "" || c.rule.Instance != "" || c.rule.Title != nil || c.rule.Process != ""
+	for _, f := range wm.allFrames() {
+		win := f.cli.Window()
+		if c.window != 0 && win != c.window {
+			continue
+		}
+		if c.mark != "" && !f.hasMark(c.mark) {
+			continue
+		}
+		if c.workspace != "" && f.workspace().name != c.workspace {
+			continue
+		}
+		if matchRule {
+			var info windowInfo
+			info.instance, info.class, _ = wm.xc.GetWMClass(win)
+			info.title = f.cli.Title()
+			if c.rule.Process != "" {
+				info.process = wm.windowProcess(win)
+			}
+			if !wm.ruleMatches(c.rule, info) {
+				continue
+			}
+		}
+		frames = append(frames, f)
+	}
+	return frames
+}
+
+// runWithCriteria executes the command for each of the windows matching the criteria in turn
+func (wm *WM) runWithCriteria(c criteria, line string) error {
+	frames := wm.matchingFrames(c)
+	if len(frames) == 0 {
+		logger.Debugf("No window matches the criteria of %q", strings.TrimSpace(line))
+	}
+	for _, f := range frames {
+		// a previous run of the command could have closed the window
+		if wm.findFrame(func(frm *frame) bool { return frm == f }) == nil {
+			continue
+		}
+		wm.selected = f
+		err := wm.runCommand(line)
+		wm.selected = nil
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// targetFrame returns the frame a command acts on: the window selected by the criteria of the command
+// or, without criteria, the focused one
+func (wm *WM) targetFrame() *frame {
+	if wm.selected != nil {
+		return wm.selected
+	}
+	return wm.focusedFrame()
+}
@@ -0,0 +1,56 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestParseCriteria(t *testing.T) {
+	tests := []struct {
+		line      string
+		class     string
+		title     string
+		mark      string
+		workspace string
+		window    xproto.Window
+		rest      string
+		wantErr   bool
+	}{
+		{`[class="Firefox"] move to workspace 2`, "Firefox", "", "", "", 0, " move to workspace 2", false},
+		{`[class=XTerm workspace=web]kill`, "XTerm", "", "", "web", 0, "kill", false},
+		{`[title="^vim \"main\"" mark=a] focus`, "", `^vim "main"`, "a", "", 0, " focus", false},
+		{`[id=0x1e00004] floating enable`, "", "", "", "", 0x1e00004, " floating enable", false},
+		{`[ id=42 ] kill`, "", "", "", "", 42, " kill", false},
+		{`[class="Firefox" kill`, "", "", "", "", 0, "", true},
+		{`[class="Firefox] kill`, "", "", "", "", 0, "", true},
+		{`[process=slack workspace=9]kill`, "", "", "", "9", 0, "kill", false},
+		{`[role=browser] kill`, "", "", "", "", 0, "", true},
+		{`[title="("] kill`, "", "", "", "", 0, "", true},
+		{`[id=firefox] kill`, "", "", "", "", 0, "", true},
+		{`[class] kill`, "", "", "", "", 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			c, rest, err := parseCriteria(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCriteria() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var title string
+			if c.rule.Title != nil {
+				title = c.rule.Title.String()
+			}
+			got := []interface{}{c.rule.Class, title, c.mark, c.workspace, c.window, rest}
+			want := []interface{}{tt.class, tt.title, tt.mark, tt.workspace, tt.window, tt.rest}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Errorf("parseCriteria() got = %v, want = %v", got, want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package wm
+
+import (
+	"strconv"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// applyCursorTheme loads the configured cursor theme and size, those the WM was started with standing in
+// for the ones that aren't set
+func (wm *WM) applyCursorTheme() {
+	if err := wm.xc.SetCursorTheme(wm.config.CursorTheme, int(wm.config.CursorSize)); err != nil {
+		logger.Errorf("Failed to set the cursor theme: %v", err)
+	}
+}
+
+// cursorEnv returns the XCURSOR_THEME and XCURSOR_SIZE variables of the configured cursor theme and size,
+// which are passed to the programs started by the WM so that they show the same cursors over their windows.
+// The environment of the WM itself is left alone, so that the programs get the variables it was started with
+// again once the config no longer sets them
+func (wm *WM) cursorEnv() []string {
+	var env []string
+	if wm.config.CursorTheme != "" {
+		env = append(env, "XCURSOR_THEME="+wm.config.CursorTheme)
+	}
+	if wm.config.CursorSize > 0 {
+		env = append(env, "XCURSOR_SIZE="+strconv.Itoa(int(wm.config.CursorSize)))
+	}
+	return env
+}
@@ -0,0 +1,47 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCursorEnv(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"unset", Config{}, nil},
+		{"theme", Config{CursorTheme: "Adwaita"}, []string{"XCURSOR_THEME=Adwaita"}},
+		{"both", Config{CursorTheme: "Adwaita", CursorSize: 32}, []string{"XCURSOR_THEME=Adwaita", "XCURSOR_SIZE=32"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &WM{config: tt.config}
+			if got := wm.cursorEnv(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cursorEnv() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverridden(t *testing.T) {
+	overrides := []string{"DISPLAY=:1", "XCURSOR_SIZE=32"}
+	tests := []struct {
+		v    string
+		want bool
+	}{
+		{"DISPLAY=:0", true},
+		{"XCURSOR_SIZE=24", true},
+		{"XCURSOR_SIZES=24", false},
+		{"XCURSOR_THEME=Adwaita", false},
+		{"MALFORMED", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v, func(t *testing.T) {
+			if got := overridden(tt.v, overrides); got != tt.want {
+				t.Errorf("overridden() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
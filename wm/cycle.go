@@ -0,0 +1,155 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// focusCycle is the state of the alt-tab like focus cycling, which lasts while the modifiers
+// of the keybinding that started it are held
+type focusCycle struct {
+	frames      []*frame // frames of the workspace, most recently focused first
+	index       int      // index of the highlighted frame
+	releaseKeys map[xproto.Keycode]bool
+}
+
+// cycleFocus highlights the next (or previous) frame of the current workspace in the most recently used order.
+// When started from a keybinding with modifiers, the keyboard is grabbed and the focus is given to the highlighted
+// frame once one of the modifiers is released, otherwise the focus moves right away
+func (wm *WM) cycleFocus(backwards bool) error {
+	if wm.cycle == nil {
+		frames := wm.currentOutput().activeWs.recentFrames()
+		if len(frames) < 2 {
+			return nil
+		}
+		keys, err := wm.modifierKeys(cycleModifiers(wm.keyState, wm.modmap.Locks()))
+		if err != nil || len(keys) == 0 || !wm.grabKeyboard() {
+			next := frames[1]
+			if backwards {
+				next = frames[len(frames)-1]
+			}
+			return wm.commitFocus(next)
+		}
+		wm.cycle = &focusCycle{frames: frames, releaseKeys: keys}
+	}
+	c := wm.cycle
+	prev := c.frames[c.index]
+	step := 1
+	if backwards {
+		step = len(c.frames) - 1
+	}
+	c.index = (c.index + step) % len(c.frames)
+	if err := wm.highlightFrame(prev, c.frames[c.index]); err != nil {
+		return err
+	}
+	// the modifiers might have been released before the keyboard got grabbed
+	if reply, err := xproto.QueryPointer(wm.xc.X(), wm.xc.GetRootWindow()).Reply(); err == nil {
+		if reply.Mask&cycleModifiers(wm.keyState, wm.modmap.Locks()) == 0 {
+			return wm.endCycle()
+		}
+	}
+	return nil
+}
+
+// cycleModifiers returns the modifiers of the key state that have to be held during the cycling. Shift is
+// left out as it's used for cycling backwards, as are the given lock modifiers
+func cycleModifiers(state, locks uint16) uint16 {
+	return state &^ (xproto.ModMaskShift | locks)
+}
+
+// handleKeyReleaseEvent ends the focus cycling after one of its modifiers was released
+func (wm *WM) handleKeyReleaseEvent(e xproto.KeyReleaseEvent) error {
+	if wm.cycle == nil || !wm.cycle.releaseKeys[e.Detail] {
+		return nil
+	}
+	return wm.endCycle()
+}
+
+// endCycle releases the keyboard and focuses the highlighted frame
+func (wm *WM) endCycle() error {
+	c := wm.cycle
+	wm.cycle = nil
+	if err := xproto.UngrabKeyboardChecked(wm.xc.X(), xproto.TimeCurrentTime).Check(); err != nil {
+		return fmt.Errorf("failed to ungrab the keyboard: %v", err)
+	}
+	f := c.frames[c.index]
+	if wm.findFrame(func(frm *frame) bool { return frm == f }) == nil {
+		return wm.focusWorkspace(wm.currentOutput().activeWs)
+	}
+	return wm.commitFocus(f)
+}
+
+// commitFocus gives the focus to the frame chosen by the cycling
+func (wm *WM) commitFocus(f *frame) error {
+	if f.floating {
+		if err := wm.raiseFrame(f); err != nil {
+			return err
+		}
+	}
+	return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+}
+
+// highlightFrame draws the frame as if it were focused, bringing it into view, without giving it the input focus
+func (wm *WM) highlightFrame(prev, f *frame) error {
+	if err := prev.cli.SetFocused(false); err != nil {
+		return err
+	}
+	if f.floating {
+		if err := wm.raiseFrame(f); err != nil {
+			return err
+		}
+	} else if f.col != nil && f.revealInStacks() {
+		if err := wm.renderWorkspace(f.workspace()); err != nil {
+			return err
+		}
+	}
+	return f.cli.SetFocused(true)
+}
+
+// grabKeyboard makes the WM receive all the key events, including the release of the modifiers
+func (wm *WM) grabKeyboard() bool {
+	reply, err := xproto.GrabKeyboard(wm.xc.X(), false, wm.xc.GetRootWindow(), xproto.TimeCurrentTime,
+		xproto.GrabModeAsync, xproto.GrabModeAsync).Reply()
+	return err == nil && reply.Status == xproto.GrabStatusSuccess
+}
+
+// modifierKeys returns the keycodes of the keys that generate any of the modifiers in the mask
+func (wm *WM) modifierKeys(mask uint16) (map[xproto.Keycode]bool, error) {
+	reply, err := xproto.GetModifierMapping(wm.xc.X()).Reply()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[xproto.Keycode]bool)
+	perMod := int(reply.KeycodesPerModifier)
+	for mod := 0; mod < 8; mod++ {
+		if mask&(1<<uint(mod)) == 0 {
+			continue
+		}
+		for _, code := range reply.Keycodes[mod*perMod : (mod+1)*perMod] {
+			if code != 0 {
+				keys[code] = true
+			}
+		}
+	}
+	return keys, nil
+}
+
+// recentFrames returns the visible frames of the workspace, the most recently focused first
+func (ws *workspace) recentFrames() []*frame {
+	var frames []*frame
+	seen := make(map[*frame]bool)
+	for i := len(ws.focusStack) - 1; i >= 0; i-- {
+		f := ws.focusStack[i]
+		if f.workspace() == ws && !f.state.hidden && !seen[f] {
+			frames = append(frames, f)
+			seen[f] = true
+		}
+	}
+	for _, f := range ws.frames() {
+		if !f.state.hidden && !seen[f] {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
@@ -0,0 +1,97 @@
+package wm
+
+import (
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/x11"
+)
+
+// layoutKey lists everything the rendering of a column of a workspace depends on. A column whose key didn't
+// change since it was last rendered is left alone, so that an operation on a large workspace only reconfigures
+// and redraws the windows of the columns it affected, see renderColumns
+type layoutKey struct {
+	geom     client.Geom
+	innerGap uint16
+	items    []itemKey // the frames and nested containers of the column, depth first
+}
+
+// itemKey describes a frame of a column or a nested container, along with the container holding it
+type itemKey struct {
+	f     *frame
+	depth int // 0 for the frames of the column, 1 for those of a container nested in it, and so on
+
+	layout      columnLayout // of the column or container holding the frame
+	orientation orientation
+	active      *frame
+
+	weight      float64
+	hidden      bool
+	fullscreen  bool
+	mapped      bool
+	decorations x11.Dimensions
+	hints       *x11.SizeHints
+	placed      *placement // changes whenever the frame is rendered with another geometry, e.g. by another layout
+}
+
+// columnKey returns the key of the column rendered in the given geometry
+func (wm *WM) columnKey(col *column, geom client.Geom) *layoutKey {
+	key := &layoutKey{geom: geom, innerGap: col.ws.innerGap()}
+	var add func(c *column, depth int)
+	add = func(c *column, depth int) {
+		for _, f := range c.frames {
+			item := itemKey{
+				f:           f,
+				depth:       depth,
+				layout:      c.layout,
+				orientation: c.orientation,
+				active:      c.active,
+				weight:      f.weight,
+				hidden:      f.state.hidden,
+				fullscreen:  f.fullscreen,
+				hints:       f.hints,
+				placed:      f.placed,
+			}
+			if f.split == nil {
+				item.mapped = f.cli.Mapped()
+				item.decorations = wm.getFrameDecorations(f)
+			}
+			key.items = append(key.items, item)
+			if f.split != nil {
+				add(f.split, depth+1)
+			}
+		}
+	}
+	add(col, 0)
+	return key
+}
+
+func (k *layoutKey) equal(other *layoutKey) bool {
+	if k == nil || other == nil || k.geom != other.geom || k.innerGap != other.innerGap || len(k.items) != len(other.items) {
+		return false
+	}
+	for i := range k.items {
+		if k.items[i] != other.items[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// markStackedRaised marks the active frames of the stacked column and of its stacked containers as raised,
+// as rendering them would, for the columns whose rendering is skipped
+func (wm *WM) markStackedRaised(col *column) {
+	for _, f := range col.visibleFrames() {
+		if f.split != nil {
+			wm.markStackedRaised(f.split)
+		}
+	}
+	if col.layout != layoutStacked {
+		return
+	}
+	if active := col.activeFrame(); active != nil {
+		for _, f := range active.leaves() {
+			if !f.fullscreen {
+				wm.markRaised(f)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestColumnKey(t *testing.T) {
+	geom := client.Geom{W: 500, H: 600}
+	tests := []struct {
+		name   string
+		change func(ws *workspace, frames []*frame) client.Geom
+		want   bool // whether the column is rendered the same way
+	}{
+		{"unchanged", func(ws *workspace, frames []*frame) client.Geom { return geom }, true},
+		{"resized column", func(ws *workspace, frames []*frame) client.Geom {
+			return client.Geom{W: 400, H: 600}
+		}, false},
+		{"frame resized", func(ws *workspace, frames []*frame) client.Geom {
+			frames[0].weight *= 2
+			return geom
+		}, false},
+		{"frames swapped", func(ws *workspace, frames []*frame) client.Geom {
+			col := frames[0].col
+			col.frames[0], col.frames[1] = col.frames[1], col.frames[0]
+			return geom
+		}, false},
+		{"frame minimized", func(ws *workspace, frames []*frame) client.Geom {
+			frames[1].state.hidden = true
+			return geom
+		}, false},
+		{"stacked", func(ws *workspace, frames []*frame) client.Geom {
+			frames[0].col.layout = layoutStacked
+			return geom
+		}, false},
+		{"frame rendered elsewhere", func(ws *workspace, frames []*frame) client.Geom {
+			frames[1].placed = &placement{}
+			return geom
+		}, false},
+		{"nested container changed", func(ws *workspace, frames []*frame) client.Geom {
+			ws.splitFrame(frames[1], orientHorizontal)
+			if err := ws.placeFrame(frames[2], PlacementAfterFocused, frames[1]); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			key := (&WM{}).columnKey(frames[0].col, geom)
+			frames[0].col.rendered = key
+			frames[2].weight *= 2
+			return geom
+		}, false},
+		{"other column changed", func(ws *workspace, frames []*frame) client.Geom {
+			if err := ws.placeFrame(frames[2], PlacementNewColumn, frames[1]); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			frames[0].col.rendered = (&WM{}).columnKey(frames[0].col, geom)
+			frames[2].weight *= 2
+			return geom
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpy := newFakeDisplay()
+			ws := newWorkspace("1", workspaceConfig{})
+			ws.output = &output{}
+			var frames []*frame
+			for i := 0; i < 3; i++ {
+				f, err := dpy.newFrame(xproto.Window(10+i), client.TypeNormal)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				frames = append(frames, f)
+			}
+			for _, f := range frames[:2] {
+				if err := ws.placeFrame(f, PlacementFocusedColumn, frames[0]); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			col := frames[0].col
+			col.rendered = (&WM{}).columnKey(col, geom)
+			g := tt.change(ws, frames)
+			if got := col.rendered.equal((&WM{}).columnKey(col, g)); got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// focusDirection moves the focus to the frame next to the focused one in the given direction. Tiled frames
+// move the focus across columns and within them, floating frames to the nearest other floating frame.
+// At the edge of the workspace the focus wraps around to the other side if the config says so
+func (wm *WM) focusDirection(dir MoveDirection) error {
+	f := wm.focusedFrame()
+	if f == nil || f.workspace() == nil {
+		return nil
+	}
+	if f.col != nil && f.workspace().monocle {
+		// the tiled frames are all in the same place, they're gone through in order instead
+		if dir == MoveLeft || dir == MoveUp {
+			return wm.cycleMonocle(f, -1)
+		}
+		return wm.cycleMonocle(f, 1)
+	}
+	next := wm.adjacentFrame(f, dir, wm.config.FocusWrap)
+	if next == nil {
+		return nil
+	}
+	if next.floating {
+		if err := wm.raiseFrame(next); err != nil {
+			return err
+		}
+	}
+	if err := wm.setFocus(next.cli.Window(), xproto.TimeCurrentTime); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(next)
+}
+
+// adjacentFrame returns the visible frame next to the given one in the direction, among the tiled frames
+// for a tiled one or the floating frames for a floating one, nil if there's none
+func (wm *WM) adjacentFrame(f *frame, dir MoveDirection, wrap bool) *frame {
+	ws := f.workspace()
+	var candidates []*frame
+	if f.floating {
+		for _, frm := range ws.floating {
+			if !frm.state.hidden {
+				candidates = append(candidates, frm)
+			}
+		}
+	} else {
+		for _, col := range ws.visibleColumns() {
+			candidates = append(candidates, col.focusCandidates()...)
+		}
+	}
+	geoms := make([]client.Geom, len(candidates))
+	for i, frm := range candidates {
+		geoms[i] = frm.cli.Geom()
+	}
+	i := adjacentGeom(f.cli.Geom(), geoms, dir, wrap)
+	if i < 0 || candidates[i] == f {
+		return nil
+	}
+	return candidates[i]
+}
+
+// adjacentGeom returns the index of the geometry closest to from in the given direction among the ones
+// that overlap it on the other axis, or -1 if there's none. With wrap set, the search continues from
+// the opposite side, returning the farthest geometry instead
+func adjacentGeom(from client.Geom, geoms []client.Geom, dir MoveDirection, wrap bool) int {
+	best := -1
+	var bestScore [2]int
+	for _, wrapped := range []bool{false, true} {
+		if wrapped && (!wrap || best >= 0) {
+			break
+		}
+		for i, g := range geoms {
+			dist, overlap, offset := directionDistance(from, g, dir)
+			if !overlap || dist == 0 || dist < 0 != wrapped {
+				continue
+			}
+			// the distance is negative on the other side, where the farthest geometry comes first
+			score := [2]int{dist, offset}
+			if best < 0 || lessScore(score, bestScore) {
+				best, bestScore = i, score
+			}
+		}
+	}
+	return best
+}
+
+// directionDistance returns how far the center of g lies from the center of from in the direction (negative
+// if it's in the opposite one), whether the two overlap on the other axis and the distance between their
+// centers along that axis
+func directionDistance(from, g client.Geom, dir MoveDirection) (dist int, overlap bool, offset int) {
+	fx, fy := int(from.X)+int(from.W)/2, int(from.Y)+int(from.H)/2
+	gx, gy := int(g.X)+int(g.W)/2, int(g.Y)+int(g.H)/2
+	switch dir {
+	case MoveLeft, MoveRight:
+		dist, offset = gx-fx, absInt(gy-fy)
+		overlap = int(g.Y) < int(from.Y)+int(from.H) && int(from.Y) < int(g.Y)+int(g.H)
+	case MoveUp, MoveDown:
+		dist, offset = gy-fy, absInt(gx-fx)
+		overlap = int(g.X) < int(from.X)+int(from.W) && int(from.X) < int(g.X)+int(g.W)
+	}
+	if dir == MoveLeft || dir == MoveUp {
+		dist = -dist
+	}
+	return dist, overlap, offset
+}
+
+func lessScore(a, b [2]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
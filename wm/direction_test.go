@@ -0,0 +1,40 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestAdjacentGeom(t *testing.T) {
+	// two columns: a single frame on the left, two stacked frames on the right
+	geoms := []client.Geom{
+		{X: 0, Y: 0, W: 400, H: 600},
+		{X: 400, Y: 0, W: 400, H: 300},
+		{X: 400, Y: 300, W: 400, H: 300},
+	}
+	tests := []struct {
+		name string
+		from int
+		dir  MoveDirection
+		wrap bool
+		want int
+	}{
+		{"right to the top frame", 0, MoveRight, false, 1},
+		{"left from the bottom frame", 2, MoveLeft, false, 0},
+		{"down within the column", 1, MoveDown, false, 2},
+		{"up within the column", 2, MoveUp, false, 1},
+		{"stop at the edge", 1, MoveRight, false, -1},
+		{"stop at the top", 1, MoveUp, false, -1},
+		{"wrap to the other side", 1, MoveRight, true, 0},
+		{"wrap within the column", 2, MoveDown, true, 1},
+		{"no other frame in the direction", 0, MoveUp, true, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adjacentGeom(geoms[tt.from], geoms, tt.dir, tt.wrap); got != tt.want {
+				t.Errorf("adjacentGeom() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/x11"
+)
+
+// display is the part of the X connection through which the layout is applied to the windows: rendering
+// the workspaces only configures and stacks the windows with it, so that the tiling, moving and resizing
+// can be tested without an X server. The windows are configured without waiting for the X server, which
+// makes retiling many windows at once fast; the render passes wait for all of them at the end with Sync
+type display interface {
+	MoveResizeWindow(window xproto.Window, x, y int16, width, height uint16)
+	StackAbove(window, sibling xproto.Window) error
+	SendConfigureNotify(window xproto.Window, x, y int16, width, height uint16)
+	Sync()
+	SetFrameExtents(window xproto.Window, d x11.Dimensions) error
+	SetClientStacking(windows []xproto.Window) error
+}
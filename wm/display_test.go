@@ -0,0 +1,109 @@
+package wm
+
+import (
+	"image"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/x11"
+)
+
+// fakeDisplay stands in for the X server in the tests of the layout: it records the geometry the windows
+// were given and the order they were stacked in, and creates the frame windows of the clients
+type fakeDisplay struct {
+	geoms      map[xproto.Window]client.Geom
+	configured int // number of times the windows were moved or resized
+	stack      []xproto.Window
+	parents    xproto.Window // last frame window created
+}
+
+func newFakeDisplay() *fakeDisplay {
+	return &fakeDisplay{geoms: make(map[xproto.Window]client.Geom), parents: 1000}
+}
+
+// newFrame returns the frame of a new mapped client window, reparented into a frame window if it's a normal one
+func (d *fakeDisplay) newFrame(win xproto.Window, typ client.Type) (*frame, error) {
+	c, err := client.New(d, &client.Config{}, win, typ)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Map(); err != nil {
+		return nil, err
+	}
+	return &frame{cli: c}, nil
+}
+
+func (d *fakeDisplay) MoveResizeWindow(window xproto.Window, x, y int16, width, height uint16) {
+	d.geoms[window] = client.Geom{X: x, Y: y, W: width, H: height}
+	d.configured++
+}
+
+func (d *fakeDisplay) StackAbove(window, sibling xproto.Window) error {
+	for i, w := range d.stack {
+		if w == window {
+			d.stack = append(d.stack[:i], d.stack[i+1:]...)
+			break
+		}
+	}
+	i := 0
+	for j, w := range d.stack {
+		if w == sibling {
+			i = j + 1
+		}
+	}
+	d.stack = append(d.stack[:i], append([]xproto.Window{window}, d.stack[i:]...)...)
+	return nil
+}
+
+func (d *fakeDisplay) SendConfigureNotify(window xproto.Window, x, y int16, width, height uint16) {}
+func (d *fakeDisplay) Sync()                                                                      {}
+
+func (d *fakeDisplay) SetFrameExtents(window xproto.Window, dims x11.Dimensions) error { return nil }
+func (d *fakeDisplay) SetClientStacking(windows []xproto.Window) error                 { return nil }
+
+// the rest of the methods make fakeDisplay usable as the X connection of the clients
+
+func (d *fakeDisplay) GetRootWindow() xproto.Window { return 1 }
+
+func (d *fakeDisplay) CreateWindow(
+	parent xproto.Window,
+	x int16, y int16, width uint16, height uint16,
+	borderWidth uint16,
+	class uint16, valueMask uint32, valueList []uint32,
+) (xproto.Window, error) {
+	d.parents++
+	return d.parents, nil
+}
+
+func (d *fakeDisplay) CreateVisualWindow(
+	parent xproto.Window,
+	x int16, y int16, width uint16, height uint16,
+	borderWidth uint16,
+	class uint16, visual xproto.Visualid, depth byte, valueMask uint32, valueList []uint32,
+) (xproto.Window, error) {
+	d.parents++
+	return d.parents, nil
+}
+
+func (d *fakeDisplay) GetWindowVisual(window xproto.Window) (xproto.Visualid, byte, xproto.Colormap, error) {
+	return 0, 24, 0, nil
+}
+
+func (d *fakeDisplay) MapWindow(window xproto.Window) error                          { return nil }
+func (d *fakeDisplay) UnmapWindow(window xproto.Window) error                        { return nil }
+func (d *fakeDisplay) DestroyWindow(window xproto.Window) error                      { return nil }
+func (d *fakeDisplay) ReparentWindow(window, parent xproto.Window, x, y int16) error { return nil }
+func (d *fakeDisplay) AddToSaveSet(window xproto.Window) error                       { return nil }
+func (d *fakeDisplay) SetWindowBackground(window xproto.Window, pixel uint32) error  { return nil }
+func (d *fakeDisplay) SetWindowOpacity(window xproto.Window, opacity float64) error  { return nil }
+func (d *fakeDisplay) SetWMState(window xproto.Window, state uint32) error           { return nil }
+func (d *fakeDisplay) IsMapped(window xproto.Window) bool                            { return true }
+func (d *fakeDisplay) GetWindowTitle(window xproto.Window) (string, error)           { return "", nil }
+func (d *fakeDisplay) GetWMClass(window xproto.Window) (string, string, error)       { return "", "", nil }
+func (d *fakeDisplay) Atom(name string) xproto.Atom                                  { return 0 }
+func (d *fakeDisplay) NewImage(rect image.Rectangle) *xgraphics.Image                { return nil }
+func (d *fakeDisplay) PaintARGBImage(win xproto.Window, img *xgraphics.Image, x, y int) error {
+	return nil
+}
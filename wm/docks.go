@@ -0,0 +1,80 @@
+package wm
+
+import (
+	"github.com/patrislav/marwind/logger"
+)
+
+// addDock places the frame as a dock at the edge it reserves space at, on the output assigned to it by
+// the rules or otherwise the one its window is on
+func (wm *WM) addDock(f *frame, rule Rule) error {
+	o := wm.outputForWindow(f.cli.Window())
+	area, reserved, err := wm.dockStrut(f, o)
+	if err != nil {
+		return err
+	}
+	if rule.Output != "" {
+		if target := findOutputByName(wm.outputs, nil, rule.Output); target != nil {
+			o = target
+		} else {
+			logger.Debugf("Output %q of dock %d not found", rule.Output, f.cli.Window())
+		}
+	}
+	wm.docks++
+	f.docked = wm.docks
+	if err := o.addDock(f, area, reserved, wm.config.DockOrder); err != nil {
+		return err
+	}
+	return wm.renderOutput(o)
+}
+
+// dockStrut returns the area at whose edge the dock reserves the most space, along with the space it reserves
+// at every edge, on the given output or otherwise the first one it reserves space on, e.g. when the dock is
+// assigned to another output than the one its client placed it on
+func (wm *WM) dockStrut(f *frame, o *output) (dockArea, [4]uint16, error) {
+	area, reserved, err := o.dockStrut(f)
+	if err == nil {
+		return area, reserved, nil
+	}
+	for _, other := range wm.outputs {
+		if other == o {
+			continue
+		}
+		if area, reserved, e := other.dockStrut(f); e == nil {
+			return area, reserved, nil
+		}
+	}
+	return 0, [4]uint16{}, err
+}
+
+// updateDockStrut places the dock again after its client changed its struts or the size of its window, e.g.
+// when a status bar reloads, and renders its output anew so that the workspaces fill the space left to them
+func (wm *WM) updateDockStrut(f *frame) error {
+	for _, o := range wm.outputs {
+		if _, ok := o.dockArea(f); !ok {
+			continue
+		}
+		area, reserved, err := wm.dockStrut(f, o)
+		if err != nil {
+			// e.g. the struts are removed right before the dock is unmapped, it keeps its place until then
+			logger.Debugf("Keeping the place of dock %d: %v", f.cli.Window(), err)
+			return nil
+		}
+		if !o.setDockStrut(f, area, reserved, wm.config.DockOrder) {
+			return nil
+		}
+		if err := wm.showDocks(o, area, o.docksShown[area]); err != nil {
+			return err
+		}
+		return wm.renderOutput(o)
+	}
+	return nil
+}
+
+// sortAllDocks orders the docks of every area of the outputs again, after the order of the config changed
+func (wm *WM) sortAllDocks() {
+	for _, o := range wm.outputs {
+		for area := range o.dockAreas {
+			sortDocks(o.dockAreas[area], wm.config.DockOrder)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package wm
+
+import (
+	"math"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
+)
+
+// BaseDPI is the resolution the pixel values of the config are meant for, they're scaled up on outputs
+// with a higher one
+const BaseDPI = 96
+
+// DefaultDPI is the key of the DPI of the outputs without their own, see Config.OutputDPI
+const DefaultDPI = "*"
+
+// outputScale returns the factor of the pixel values of the config on an output of the given width: its DPI
+// as configured or, with DetectDPI, as computed from its physical width, relative to BaseDPI. The detected
+// factors are rounded to halves and never below 1, as the sizes reported by some monitors are rough or bogus
+func outputScale(cfg Config, name string, width uint16, mmWidth uint32) float64 {
+	if dpi, ok := cfg.OutputDPI[name]; ok {
+		return dpi / BaseDPI
+	}
+	if dpi, ok := cfg.OutputDPI[DefaultDPI]; ok {
+		return dpi / BaseDPI
+	}
+	if !cfg.DetectDPI || mmWidth == 0 {
+		return 1
+	}
+	dpi := float64(width) * 25.4 / float64(mmWidth)
+	if scale := math.Round(dpi/BaseDPI*2) / 2; scale > 1 {
+		return scale
+	}
+	return 1
+}
+
+// scaled returns the pixel value multiplied by the factor, rounded
+func scaled(px int, scale float64) int {
+	return int(math.Round(float64(px) * scale))
+}
+
+// scaledUint8 is scaled for the values stored in a byte, such as the border width, which are capped
+func scaledUint8(px uint8, scale float64) uint8 {
+	if v := scaled(int(px), scale); v < math.MaxUint8 {
+		return uint8(v)
+	}
+	return math.MaxUint8
+}
+
+// scaleWindowConfig returns the window config with its sizes multiplied by the factor
+func scaleWindowConfig(cfg client.Config, scale float64) client.Config {
+	cfg.TitlebarHeight = scaledUint8(cfg.TitlebarHeight, scale)
+	cfg.BorderWidth = scaledUint8(cfg.BorderWidth, scale)
+	cfg.TitlePadding = uint16(scaled(int(cfg.TitlePadding), scale))
+	cfg.FontSize *= scale
+	return cfg
+}
+
+// scaleOutputs sets the factors of the outputs from the config and their sizes, after either changed
+func (wm *WM) scaleOutputs() {
+	for _, o := range wm.outputs {
+		scale := outputScale(wm.config, o.name, o.geom.W, o.mmWidth)
+		if scale != o.pixelScale() {
+			logger.Debugf("Scaling output %q by %v", o.name, scale)
+		}
+		o.scale = scale
+	}
+}
+
+// clientConfig returns the window config of the clients on the outputs with the given factor. The scaled
+// copies are kept, so that the clients sharing them see the changes made on reload, see updateClientConfigs
+func (wm *WM) clientConfig(scale float64) *client.Config {
+	if scale == 1 {
+		return wm.windowConfig
+	}
+	if cfg, ok := wm.scaledConfigs[scale]; ok {
+		return cfg
+	}
+	cfg := scaleWindowConfig(*wm.windowConfig, scale)
+	if wm.scaledConfigs == nil {
+		wm.scaledConfigs = make(map[float64]*client.Config)
+	}
+	wm.scaledConfigs[scale] = &cfg
+	return &cfg
+}
+
+// updateClientConfigs scales the copies of the window config again after it changed
+func (wm *WM) updateClientConfigs() {
+	for scale, cfg := range wm.scaledConfigs {
+		*cfg = scaleWindowConfig(*wm.windowConfig, scale)
+	}
+}
+
+// pixelScale returns the factor of the pixel values of the config on the output, 1 if it isn't scaled
+func (o *output) pixelScale() float64 {
+	if o == nil || o.scale == 0 {
+		return 1
+	}
+	return o.scale
+}
+
+// pixelScale returns the factor of the pixel values of the config on the output of the workspace
+func (ws *workspace) pixelScale() float64 {
+	return ws.output.pixelScale()
+}
+
+// pixelScale returns the factor of the pixel values of the config on the output the frame is shown on,
+// 1 for the frames that aren't on a workspace, such as the docks and the hidden scratchpad windows
+func (f *frame) pixelScale() float64 {
+	if ws := f.workspace(); ws != nil {
+		return ws.pixelScale()
+	}
+	return 1
+}
+
+// outerGap returns the gap around the workspace area, scaled for its output
+func (ws *workspace) outerGap() uint16 {
+	return uint16(scaled(int(ws.config.gap), ws.pixelScale()))
+}
+
+// innerGap returns the gap around each tiled frame of the workspace, scaled for its output
+func (ws *workspace) innerGap() uint16 {
+	return uint16(scaled(int(ws.config.innerGap), ws.pixelScale()))
+}
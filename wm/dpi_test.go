@@ -0,0 +1,62 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestOutputScale(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		output  string
+		width   uint16
+		mmWidth uint32
+		want    float64
+	}{
+		{"not detected", Config{}, "DP-1", 3840, 597, 1},
+		{"detected", Config{DetectDPI: true}, "DP-1", 3840, 597, 1.5},
+		{"detected low", Config{DetectDPI: true}, "HDMI-1", 1920, 531, 1},
+		{"detected laptop", Config{DetectDPI: true}, "eDP-1", 3840, 344, 3},
+		{"unknown size", Config{DetectDPI: true}, "DP-1", 3840, 0, 1},
+		{"configured", Config{OutputDPI: map[string]float64{"eDP-1": 192, DefaultDPI: 144}}, "eDP-1", 3840, 344, 2},
+		{"default", Config{OutputDPI: map[string]float64{"eDP-1": 192, DefaultDPI: 144}}, "DP-1", 1920, 531, 1.5},
+		{"configured over detected", Config{DetectDPI: true, OutputDPI: map[string]float64{"DP-1": 96}}, "DP-1", 3840, 597, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outputScale(tt.config, tt.output, tt.width, tt.mmWidth); got != tt.want {
+				t.Errorf("outputScale() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleWindowConfig(t *testing.T) {
+	cfg := client.Config{TitlebarHeight: 18, BorderWidth: 3, TitlePadding: 4, FontSize: 12, BgColor: 0xff000000}
+	want := client.Config{TitlebarHeight: 27, BorderWidth: 5, TitlePadding: 6, FontSize: 18, BgColor: 0xff000000}
+	if got := scaleWindowConfig(cfg, 1.5); got != want {
+		t.Errorf("scaleWindowConfig() got = %+v, want = %+v", got, want)
+	}
+	if got := scaledUint8(200, 2); got != 255 {
+		t.Errorf("scaledUint8() got = %v, want = 255", got)
+	}
+}
+
+func TestWorkspaceGapsScaled(t *testing.T) {
+	ws := newWorkspace("1", workspaceConfig{gap: 4, innerGap: 3})
+	if got := ws.innerGap(); got != 3 {
+		t.Errorf("innerGap() without an output got = %v, want = 3", got)
+	}
+	o := &output{geom: client.Geom{W: 1000, H: 800}, scale: 2}
+	if err := o.addWorkspace(ws); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ws.innerGap(); got != 6 {
+		t.Errorf("innerGap() got = %v, want = 6", got)
+	}
+	if got, want := ws.area(), (client.Geom{X: 8, Y: 8, W: 984, H: 784}); got != want {
+		t.Errorf("area() got = %v, want = %v", got, want)
+	}
+}
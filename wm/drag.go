@@ -0,0 +1,286 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/x11"
+)
+
+type dragKind uint8
+
+const (
+	dragMove dragKind = iota
+	dragResize
+	dragTile     // moving a tiled frame by its titlebar to another place in the tree
+	dragBoundary // resizing the tiled items around the boundaries between them, see startResizeDrag
+)
+
+// resizeEdges are the edges of a floating frame that follow the pointer while it's resized
+type resizeEdges uint8
+
+const (
+	resizeLeft resizeEdges = 1 << iota
+	resizeRight
+	resizeTop
+	resizeBottom
+
+	allEdges = resizeLeft | resizeRight | resizeTop | resizeBottom // moved together when the frame is moved
+)
+
+// drag describes an ongoing pointer drag of a frame
+type drag struct {
+	f              *frame
+	kind           dragKind
+	startX, startY int16
+	orig           client.Geom
+	edges          resizeEdges // edges moved by a resize
+
+	// moves and resizes done with the keyboard, see startKeyboardDrag
+	keyboard bool
+	dx, dy   int // distance the frame was moved or resized by so far
+
+	// tiled frames only
+	moved     bool          // set once the pointer went past the threshold
+	target    *frame        // frame the dragged one would be dropped on, nil for none
+	pos       dropPosition  // where the dragged frame goes relative to the target
+	indicator xproto.Window // bar showing the drop position, 0 until shown
+
+	// floating frames dragged with the pointer only, the lines their edges snap to, see snapTargets
+	snapXs, snapYs []snapLine
+
+	// boundaries between tiled items only
+	bounds  []tileBoundary
+	weights [][2]float64 // of the items around each of the boundaries when the drag started
+}
+
+const dragEventMask = xproto.EventMaskButtonPress | xproto.EventMaskButtonRelease | xproto.EventMaskPointerMotion
+
+// grabButtons sets up the modifier + pointer button combinations used for moving (left button)
+// and resizing (right button) floating frames, with any of the lock modifiers on, followed by the ones
+// of the mouse bindings, which take precedence
+func (wm *WM) grabButtons() error {
+	for _, button := range []byte{xproto.ButtonIndex1, xproto.ButtonIndex3} {
+		for _, locks := range wm.modmap.LockCombinations() {
+			cookie := xproto.GrabButtonChecked(
+				wm.xc.X(),
+				false,
+				wm.xc.GetRootWindow(),
+				dragEventMask,
+				xproto.GrabModeAsync,
+				xproto.GrabModeAsync,
+				xproto.WindowNone,
+				xproto.CursorNone,
+				button,
+				wm.modMask()|locks,
+			)
+			if err := cookie.Check(); err != nil {
+				return err
+			}
+		}
+	}
+	return wm.grabButtonActions()
+}
+
+// regrabButtons creates the actions of the mouse bindings anew and grabs the pointer buttons of the root
+// window, e.g. after the mouse bindings, the mod key or the lock modifiers changed
+func (wm *WM) regrabButtons() error {
+	root := wm.xc.GetRootWindow()
+	if err := xproto.UngrabButtonChecked(wm.xc.X(), xproto.ButtonIndexAny, root, xproto.ModMaskAny).Check(); err != nil {
+		return fmt.Errorf("failed to ungrab buttons: %v", err)
+	}
+	wm.buttons = initButtonActions(wm)
+	if err := wm.grabButtons(); err != nil {
+		return fmt.Errorf("failed to grab buttons: %v", err)
+	}
+	return nil
+}
+
+func (wm *WM) handleButtonPressEvent(e xproto.ButtonPressEvent) error {
+	if wm.menu != nil {
+		return wm.handleMenuButtonPress(e)
+	}
+	if wm.overview != nil {
+		return wm.handleOverviewButtonPress(e)
+	}
+	if wm.prompt != nil {
+		return wm.handlePromptButtonPress(e)
+	}
+	if f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Event }); f != nil {
+		return wm.focusClicked(f, e)
+	}
+	if wm.drag != nil {
+		return nil
+	}
+	if e.Event != wm.xc.GetRootWindow() {
+		return wm.handleFrameButtonPress(e)
+	}
+	f := wm.findFrame(func(frm *frame) bool { return wm.frameWindow(frm) == e.Child })
+	if ok, err := wm.runButtonAction(e, f); ok {
+		return err
+	}
+	if ok, err := wm.startResizeAt(e); ok {
+		return err
+	}
+	if f == nil || !f.floating {
+		return nil
+	}
+	kind, edges := dragMove, resizeEdges(0)
+	if e.Detail == xproto.ButtonIndex3 {
+		kind, edges = dragResize, resizeRight|resizeBottom
+	}
+	return wm.startDrag(f, kind, edges, e.RootX, e.RootY, e.Time)
+}
+
+// startDrag grabs the pointer so that the floating frame is moved or resized along with it, starting
+// at the given position of the pointer
+func (wm *WM) startDrag(f *frame, kind dragKind, edges resizeEdges, x, y int16, t xproto.Timestamp) error {
+	if ok, err := wm.grabDragPointer(dragCursor(kind, edges), t); !ok || err != nil {
+		return err
+	}
+	if err := wm.unmaximizeFloating(f); err != nil {
+		return err
+	}
+	wm.drag = &drag{f: f, kind: kind, edges: edges, startX: x, startY: y, orig: f.floatGeom}
+	if wm.config.SnapDistance > 0 {
+		wm.drag.snapXs, wm.drag.snapYs = wm.snapTargets(f)
+	}
+	if err := wm.raiseFrame(f); err != nil {
+		return err
+	}
+	return wm.setFocus(f.cli.Window(), t)
+}
+
+// grabDragPointer grabs the pointer for a drag, showing the cursor of the given shape. It returns false if
+// the pointer is grabbed by another client
+func (wm *WM) grabDragPointer(shape uint16, t xproto.Timestamp) (bool, error) {
+	cursor, err := wm.xc.Cursor(shape)
+	if err != nil {
+		return false, err
+	}
+	reply, err := xproto.GrabPointer(
+		wm.xc.X(), false, wm.xc.GetRootWindow(), dragEventMask,
+		xproto.GrabModeAsync, xproto.GrabModeAsync, xproto.WindowNone, cursor, t,
+	).Reply()
+	if err != nil {
+		return false, err
+	}
+	return reply.Status == xproto.GrabStatusSuccess, nil
+}
+
+// dragCursor returns the shape of the cursor shown during a drag of the given kind: the arrows of the resized
+// edges or the cross of a move
+func dragCursor(kind dragKind, edges resizeEdges) uint16 {
+	if kind == dragResize {
+		return edgesCursor(edges)
+	}
+	return x11.CursorFleur
+}
+
+// unmaximizeFloating turns a maximized floating frame into a regular one of the same size before it's dragged
+func (wm *WM) unmaximizeFloating(f *frame) error {
+	if !f.state.maximizedVert && !f.state.maximizedHorz {
+		return nil
+	}
+	f.floatGeom = maximizedGeom(f, f.workspace())
+	f.state.maximizedVert, f.state.maximizedHorz = false, false
+	return wm.updateWindowState(f)
+}
+
+func (wm *WM) handleMotionNotifyEvent(e xproto.MotionNotifyEvent) error {
+	if wm.menu != nil {
+		return wm.handleMenuMotion(e)
+	}
+	if wm.overview != nil {
+		return wm.handleOverviewMotion(e)
+	}
+	if wm.prompt != nil {
+		return nil
+	}
+	d := wm.drag
+	if d == nil {
+		return wm.updateResizeCursor(e.Event, e.Child, e.RootX, e.RootY)
+	}
+	switch d.kind {
+	case dragTile:
+		return wm.updateTileDrag(d, e.RootX, e.RootY)
+	case dragBoundary:
+		return wm.dragBoundaries(d, int(e.RootX-d.startX), int(e.RootY-d.startY))
+	}
+	if d.keyboard {
+		return nil
+	}
+	return wm.dragFrame(d, int(e.RootX-d.startX), int(e.RootY-d.startY))
+}
+
+// dragFrame moves or resizes the dragged floating frame by the given distance from its original geometry,
+// snapping its edges to the lines close to them when it's dragged with the pointer
+func (wm *WM) dragFrame(d *drag, dx, dy int) error {
+	geom := d.orig
+	distance := int(wm.config.SnapDistance)
+	switch d.kind {
+	case dragMove:
+		geom.X += int16(dx)
+		geom.Y += int16(dy)
+		if !d.keyboard && distance > 0 {
+			geom = snapMove(geom, d.snapXs, d.snapYs, distance)
+		}
+	case dragResize:
+		geom = resizeGeom(geom, d.edges, dx, dy)
+		if !d.keyboard && distance > 0 {
+			geom = snapResize(geom, d.edges, d.snapXs, d.snapYs, distance)
+		}
+	}
+	d.f.floatGeom = geom
+	return wm.renderFrame(d.f, geom)
+}
+
+// resizeGeom moves the given edges of the geometry by the distance, keeping the opposite edges in place
+// and the size at least minFloatingSize
+func resizeGeom(geom client.Geom, edges resizeEdges, dx, dy int) client.Geom {
+	right, bottom := int(geom.X)+int(geom.W), int(geom.Y)+int(geom.H)
+	switch {
+	case edges&resizeLeft != 0:
+		geom.W = clampSize(int(geom.W)-dx, minFloatingSize)
+		geom.X = int16(right - int(geom.W))
+	case edges&resizeRight != 0:
+		geom.W = clampSize(int(geom.W)+dx, minFloatingSize)
+	}
+	switch {
+	case edges&resizeTop != 0:
+		geom.H = clampSize(int(geom.H)-dy, minFloatingSize)
+		geom.Y = int16(bottom - int(geom.H))
+	case edges&resizeBottom != 0:
+		geom.H = clampSize(int(geom.H)+dy, minFloatingSize)
+	}
+	return geom
+}
+
+func (wm *WM) handleButtonReleaseEvent(e xproto.ButtonReleaseEvent) error {
+	if wm.menu != nil {
+		return wm.handleMenuButtonRelease(e)
+	}
+	if wm.overview != nil || wm.prompt != nil {
+		return nil
+	}
+	d := wm.drag
+	if d == nil || d.keyboard {
+		return nil
+	}
+	wm.drag = nil
+	if err := xproto.UngrabPointerChecked(wm.xc.X(), e.Time).Check(); err != nil {
+		return err
+	}
+	if d.kind == dragTile {
+		return wm.finishTileDrag(d)
+	}
+	return nil
+}
+
+func clampSize(size, min int) uint16 {
+	if size < min {
+		return uint16(min)
+	}
+	return uint16(size)
+}
@@ -0,0 +1,176 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
+)
+
+// dropPosition is where a tiled frame dragged by its titlebar goes relative to the frame it's dropped on
+type dropPosition uint8
+
+const (
+	dropBefore       dropPosition = iota // above the target or, in a horizontal container, left of it
+	dropAfter                            // below the target or, in a horizontal container, right of it
+	dropColumnBefore                     // in a new column left of the target's column
+	dropColumnAfter                      // in a new column right of the target's column
+)
+
+const (
+	dragThreshold      = 5 // distance in pixels the pointer has to move before a click becomes a drag
+	dropIndicatorWidth = 4
+)
+
+// startTileDrag grabs the pointer so that the tiled frame can be dragged by its titlebar to another place
+func (wm *WM) startTileDrag(f *frame, x, y int16, t xproto.Timestamp) error {
+	if ok, err := wm.grabDragPointer(dragCursor(dragTile, 0), t); !ok || err != nil {
+		return err
+	}
+	wm.drag = &drag{f: f, kind: dragTile, startX: x, startY: y}
+	return nil
+}
+
+// updateTileDrag finds the place where the dragged frame would be dropped and shows the indicator there
+func (wm *WM) updateTileDrag(d *drag, x, y int16) error {
+	if !d.moved {
+		dx, dy := int(x-d.startX), int(y-d.startY)
+		if dx*dx+dy*dy < dragThreshold*dragThreshold {
+			return nil
+		}
+		d.moved = true
+	}
+	d.target, d.pos = wm.dropTarget(x, y)
+	if d.target == nil || d.target == d.f {
+		d.target = nil
+		if d.indicator != 0 {
+			return wm.xc.UnmapWindow(d.indicator)
+		}
+		return nil
+	}
+	g := dropIndicatorGeom(d.target.cli.Geom(), d.target.col.orientation, d.pos)
+	if d.indicator == 0 {
+		win, err := wm.xc.CreateWindow(wm.xc.GetRootWindow(), g.X, g.Y, g.W, g.H, 0,
+			xproto.WindowClassInputOutput, xproto.CwBackPixel|xproto.CwOverrideRedirect,
+			[]uint32{wm.config.BorderColor, 1})
+		if err != nil {
+			return err
+		}
+		d.indicator = win
+	}
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+	values := []uint32{uint32(g.X), uint32(g.Y), uint32(g.W), uint32(g.H)}
+	if err := xproto.ConfigureWindowChecked(wm.xc.X(), d.indicator, mask, values).Check(); err != nil {
+		return err
+	}
+	if err := wm.xc.MapWindow(d.indicator); err != nil {
+		return err
+	}
+	return wm.xc.RaiseWindow(d.indicator)
+}
+
+// finishTileDrag moves the dragged frame to the place it was dropped on
+func (wm *WM) finishTileDrag(d *drag) error {
+	if d.indicator != 0 {
+		if err := wm.xc.DestroyWindow(d.indicator); err != nil {
+			logger.Errorf("Failed to destroy the drop indicator: %v", err)
+		}
+	}
+	t := d.target
+	// the windows could have been closed or moved away during the drag
+	if t == nil || t.col == nil || d.f.col == nil || d.f.fullscreen {
+		return nil
+	}
+	from, to := d.f.workspace(), t.workspace()
+	to.dropFrame(d.f, t, d.pos)
+	if from != to {
+		wm.emitWindowEvent("move", d.f)
+		if err := wm.renderWorkspace(from); err != nil {
+			return err
+		}
+	}
+	return wm.renderWorkspace(to)
+}
+
+// dropTarget returns the tiled frame shown under the pointer and where a frame dropped on it goes.
+// Close to the left or right edge of a frame the dropped frame gets a column of its own, otherwise it's put
+// in the frame's container, before or after the frame depending on the half of the frame under the pointer
+func (wm *WM) dropTarget(x, y int16) (*frame, dropPosition) {
+	o := wm.outputAt(x, y)
+	if o == nil || o.activeWs == nil {
+		return nil, dropBefore
+	}
+	for _, f := range o.activeWs.frames() {
+		if f.col == nil || !f.cli.Mapped() {
+			continue
+		}
+		g := f.cli.Geom()
+		if x < g.X || y < g.Y || int(x) >= int(g.X)+int(g.W) || int(y) >= int(g.Y)+int(g.H) {
+			continue
+		}
+		dx, dy := int(x-g.X), int(y-g.Y)
+		if f.col.orientation == orientHorizontal {
+			if dx < int(g.W)/2 {
+				return f, dropBefore
+			}
+			return f, dropAfter
+		}
+		switch {
+		case dx < int(g.W)/5:
+			return f, dropColumnBefore
+		case dx >= int(g.W)-int(g.W)/5:
+			return f, dropColumnAfter
+		case dy < int(g.H)/2:
+			return f, dropBefore
+		}
+		return f, dropAfter
+	}
+	return nil, dropBefore
+}
+
+// dropIndicatorGeom returns the geometry of the bar showing where a frame dropped on the target goes
+func dropIndicatorGeom(g client.Geom, o orientation, pos dropPosition) client.Geom {
+	w := uint16(dropIndicatorWidth)
+	vertical := pos == dropColumnBefore || pos == dropColumnAfter || o == orientHorizontal
+	switch {
+	case vertical && (pos == dropBefore || pos == dropColumnBefore):
+		return client.Geom{X: g.X, Y: g.Y, W: w, H: g.H}
+	case vertical:
+		return client.Geom{X: g.X + int16(g.W) - int16(w), Y: g.Y, W: w, H: g.H}
+	case pos == dropBefore:
+		return client.Geom{X: g.X, Y: g.Y, W: g.W, H: w}
+	}
+	return client.Geom{X: g.X, Y: g.Y + int16(g.H) - int16(w), W: g.W, H: w}
+}
+
+// dropFrame takes the tiled frame out of its place, possibly on another workspace, and puts it at the given
+// position relative to the target tiled frame of this workspace
+func (ws *workspace) dropFrame(f, t *frame, pos dropPosition) {
+	if f == t {
+		return
+	}
+	f.workspace().detachFrame(f)
+	// the target's container could have been dissolved along with the one of the frame
+	col := t.col
+	switch pos {
+	case dropColumnBefore, dropColumnAfter:
+		top := col.topColumn()
+		after := top
+		if pos == dropColumnBefore {
+			after = nil
+			if i := ws.findColumnIndex(func(c *column) bool { return c == top }); i > 0 {
+				after = ws.columns[i-1]
+			}
+		}
+		col = ws.createColumnAfter(after)
+		col.insertFrame(f, 0)
+	default:
+		i := col.findFrameIndex(func(frm *frame) bool { return frm == t })
+		if pos == dropAfter {
+			i++
+		}
+		col.insertFrame(f, i)
+	}
+	f.ws = ws
+	f.floating = false
+}
@@ -0,0 +1,75 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestDropFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		split   bool // whether the frames 1 and 3 are in a horizontal container
+		dragged int
+		target  int
+		pos     dropPosition
+		want    string // frame indexes in each column, h(...) for horizontal containers
+	}{
+		{"before", false, 2, 1, dropBefore, "[0] [2 1]"},
+		{"after", false, 0, 2, dropAfter, "[1 2 0]"},
+		{"on itself", false, 1, 1, dropAfter, "[0] [1 2]"},
+		{"column before", false, 2, 0, dropColumnBefore, "[2] [0] [1]"},
+		{"column after", false, 1, 0, dropColumnAfter, "[0] [1] [2]"},
+		{"own column removed", false, 0, 1, dropColumnAfter, "[1 2] [0]"},
+		{"first column removed", false, 0, 2, dropColumnBefore, "[0] [1 2]"},
+		{"into container", true, 0, 3, dropAfter, "[h(1 3 0) 2]"},
+		{"out of container", true, 3, 2, dropBefore, "[0] [1 3 2]"},
+		{"column after container", true, 2, 1, dropColumnAfter, "[0] [h(1 3)] [2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newWorkspace("1", workspaceConfig{})
+			ws.output = &output{}
+			frames := []*frame{{}, {}, {}, {}}
+			for _, f := range frames[:3] {
+				if err := ws.addFrame(f); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if tt.split {
+				ws.splitFrame(frames[1], orientHorizontal)
+				if err := ws.placeFrame(frames[3], PlacementAfterFocused, frames[1]); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			ws.dropFrame(frames[tt.dragged], frames[tt.target], tt.pos)
+			if got := describeLayout(ws, frames); got != tt.want {
+				t.Errorf("layout got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropIndicatorGeom(t *testing.T) {
+	g := client.Geom{X: 10, Y: 20, W: 100, H: 50}
+	tests := []struct {
+		name   string
+		orient orientation
+		pos    dropPosition
+		want   client.Geom
+	}{
+		{"before", orientVertical, dropBefore, client.Geom{X: 10, Y: 20, W: 100, H: 4}},
+		{"after", orientVertical, dropAfter, client.Geom{X: 10, Y: 66, W: 100, H: 4}},
+		{"horizontal before", orientHorizontal, dropBefore, client.Geom{X: 10, Y: 20, W: 4, H: 50}},
+		{"horizontal after", orientHorizontal, dropAfter, client.Geom{X: 106, Y: 20, W: 4, H: 50}},
+		{"column before", orientVertical, dropColumnBefore, client.Geom{X: 10, Y: 20, W: 4, H: 50}},
+		{"column after", orientVertical, dropColumnAfter, client.Geom{X: 106, Y: 20, W: 4, H: 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dropIndicatorGeom(g, tt.orient, tt.pos); got != tt.want {
+				t.Errorf("dropIndicatorGeom() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package wm
+
+import (
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// screenEdge is one of the sides of the screen, the area covered by all the outputs
+type screenEdge uint8
+
+const (
+	edgeNone screenEdge = iota
+	edgeLeft
+	edgeRight
+	edgeTop
+	edgeBottom
+)
+
+// edgePollInterval is how often the position of the pointer is checked, see watchPointer
+const edgePollInterval = 50 * time.Millisecond
+
+// edgeState tracks the edge of the screen the pointer is pushed against
+type edgeState struct {
+	edge  screenEdge
+	since time.Time // when the pointer reached the edge
+	fired bool      // whether the command of the edge was executed, it's executed once until the pointer leaves
+	stop  func()    // stops the polling
+}
+
+// watchPointer starts polling the position of the pointer once any edge action or the auto-hiding of the
+// docks is configured. X offers no events for a pointer that keeps pushing against the border of the screen,
+// only XInput 2 reports the hits of the XFixes pointer barriers, so the pointer is looked up periodically instead.
+// The polling stops when a reload disables both
+func (wm *WM) watchPointer() {
+	if !wm.hasEdgeActions() && !wm.config.DockAutoHide {
+		if wm.edge != nil {
+			wm.edge.stop()
+			wm.edge = nil
+		}
+		return
+	}
+	if wm.edge != nil {
+		return
+	}
+	wm.edge = &edgeState{stop: wm.pollEvery(edgePollInterval, wm.pollPointer)}
+}
+
+// pollPointer updates the auto-hidden docks and runs the edge actions for the current position of the pointer
+func (wm *WM) pollPointer() error {
+	if wm.edge == nil {
+		// queued before the polling stopped
+		return nil
+	}
+	reply, err := xproto.QueryPointer(wm.xc.X(), wm.xc.GetRootWindow()).Reply()
+	if err != nil {
+		return err
+	}
+	// dragging a window, picking an entry of the menu or a thumbnail of the overview or selecting text
+	// shouldn't trigger anything
+	busy := wm.drag != nil || wm.menu != nil || wm.overview != nil || wm.prompt != nil || reply.Mask&(xproto.KeyButMaskButton1|xproto.KeyButMaskButton3) != 0
+	err = wm.updateDocks(reply.RootX, reply.RootY, busy)
+	if e := wm.checkEdges(reply.RootX, reply.RootY, busy); e != nil {
+		err = e
+	}
+	return err
+}
+
+// checkEdges executes the command of the edge the pointer stayed at for the configured delay
+func (wm *WM) checkEdges(x, y int16, busy bool) error {
+	edge := edgeNone
+	if !busy {
+		edge = wm.edgeAt(x, y)
+	}
+	s := wm.edge
+	if edge != s.edge {
+		s.edge, s.since, s.fired = edge, time.Now(), false
+		return nil
+	}
+	cmd := wm.edgeCommand(edge)
+	if s.fired || cmd == "" || time.Since(s.since) < time.Duration(wm.config.EdgeDelay)*time.Millisecond {
+		return nil
+	}
+	s.fired = true
+	logger.Debugf("Running the command of the screen edge %d: %q", edge, cmd)
+	return wm.runCommands(cmd)
+}
+
+// edgeAt returns the edge of the screen at the position, the edges shared by two outputs don't count
+func (wm *WM) edgeAt(x, y int16) screenEdge {
+	o := wm.outputAt(x, y)
+	if o == nil {
+		return edgeNone
+	}
+	g := o.geom
+	switch {
+	case x == g.X && wm.outputAt(x-1, y) == nil:
+		return edgeLeft
+	case int(x) == int(g.X)+int(g.W)-1 && wm.outputAt(x+1, y) == nil:
+		return edgeRight
+	case y == g.Y && wm.outputAt(x, y-1) == nil:
+		return edgeTop
+	case int(y) == int(g.Y)+int(g.H)-1 && wm.outputAt(x, y+1) == nil:
+		return edgeBottom
+	}
+	return edgeNone
+}
+
+func (wm *WM) hasEdgeActions() bool {
+	for _, edge := range []screenEdge{edgeLeft, edgeRight, edgeTop, edgeBottom} {
+		if wm.edgeCommand(edge) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (wm *WM) edgeCommand(edge screenEdge) string {
+	switch edge {
+	case edgeLeft:
+		return wm.config.EdgeLeft
+	case edgeRight:
+		return wm.config.EdgeRight
+	case edgeTop:
+		return wm.config.EdgeTop
+	case edgeBottom:
+		return wm.config.EdgeBottom
+	}
+	return ""
+}
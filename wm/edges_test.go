@@ -0,0 +1,37 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestEdgeAt(t *testing.T) {
+	// laid out as: left | right, the right one being shorter
+	left := &output{geom: client.Geom{X: 0, Y: 0, W: 1920, H: 1080}}
+	right := &output{geom: client.Geom{X: 1920, Y: 0, W: 1280, H: 1024}}
+	wm := &WM{outputs: []*output{left, right}}
+	tests := []struct {
+		name string
+		x, y int16
+		want screenEdge
+	}{
+		{"left", 0, 500, edgeLeft},
+		{"top left corner", 0, 0, edgeLeft},
+		{"right", 3199, 500, edgeRight},
+		{"top", 1000, 0, edgeTop},
+		{"bottom", 1000, 1079, edgeBottom},
+		{"bottom of shorter output", 2500, 1023, edgeBottom},
+		{"between outputs", 1919, 500, edgeNone},
+		{"between outputs on the right", 1920, 500, edgeNone},
+		{"below shorter output", 1919, 1050, edgeRight},
+		{"inside", 1000, 500, edgeNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wm.edgeAt(tt.x, tt.y); got != tt.want {
+				t.Errorf("edgeAt() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,147 +1,404 @@
 package wm
 
 import (
-	"log"
+	"fmt"
 
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/randr"
 	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/ipc"
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/x11"
 )
 
 type eventHandler struct {
 	wm *WM
 }
 
+// eventLoop handles the X events, the IPC requests and the queued tasks one at a time. Each of them is
+// handled safely, so that a panic only fails the event or request that caused it
 func (h eventHandler) eventLoop() {
+	defer close(h.wm.done)
+	events := make(chan xgb.Event)
+	xerrs := make(chan xgb.Error)
+	go h.readEvents(events, xerrs)
+	for !h.wm.quitting {
+		select {
+		case xev, ok := <-events:
+			if !ok {
+				return
+			}
+			if logger.Events() {
+				logger.Debugf("X event: %v", xev)
+			}
+			h.wm.safely(eventWindow(xev), "an X event", func() { h.handleEvent(xev) })
+		case err := <-xerrs:
+			h.wm.safely(0, "an X error", func() { h.wm.handleXError(err) })
+		case r := <-h.wm.ipcRequests:
+			resp := ipc.ErrorResponse(fmt.Errorf("internal error"))
+			h.wm.safely(0, "an IPC request", func() { resp = h.wm.handleIPCRequest(r.req) })
+			r.reply <- resp
+		case fn := <-h.wm.tasks:
+			h.wm.safely(0, "a task", func() {
+				if err := fn(); err != nil {
+					logger.Errorf("%v", err)
+				}
+			})
+		}
+	}
+}
+
+// readEvents passes the X events and the asynchronous X errors to the channels until the connection is closed
+func (h eventHandler) readEvents(events chan<- xgb.Event, xerrs chan<- xgb.Error) {
+	defer close(events)
 	for {
 		xev, err := h.wm.xc.X().WaitForEvent()
 		if err != nil {
-			log.Println(err)
+			xerrs <- err
 			continue
 		}
-		switch e := xev.(type) {
-		case xproto.KeyPressEvent:
-			h.keyPress(e)
-		case xproto.EnterNotifyEvent:
-			h.enterNotify(e)
-		case xproto.ConfigureRequestEvent:
-			h.configureRequest(e)
-		case xproto.MapNotifyEvent:
-			h.mapNotify(e)
-		case xproto.MapRequestEvent:
-			h.mapRequest(e)
-		case xproto.UnmapNotifyEvent:
-			h.unmapNotify(e)
-		case xproto.DestroyNotifyEvent:
-			h.destroyNotify(e)
-		case xproto.PropertyNotifyEvent:
-			h.propertyNotify(e)
-		case xproto.ClientMessageEvent:
-			h.clientMessage(e)
-		case xproto.ExposeEvent:
-			h.expose(e)
+		if xev == nil {
+			return
 		}
+		events <- xev
+	}
+}
+
+func (h eventHandler) handleEvent(xev xgb.Event) {
+	switch e := xev.(type) {
+	case xproto.KeyPressEvent:
+		h.keyPress(e)
+	case xproto.KeyReleaseEvent:
+		h.keyRelease(e)
+	case xproto.ButtonPressEvent:
+		h.buttonPress(e)
+	case xproto.ButtonReleaseEvent:
+		h.buttonRelease(e)
+	case xproto.MotionNotifyEvent:
+		h.motionNotify(e)
+	case xproto.EnterNotifyEvent:
+		h.enterNotify(e)
+	case xproto.FocusInEvent:
+		h.focusChange(e.Event, e.Mode, e.Detail, true)
+	case xproto.FocusOutEvent:
+		h.focusChange(e.Event, e.Mode, e.Detail, false)
+	case xproto.ConfigureRequestEvent:
+		h.configureRequest(e)
+	case xproto.MapNotifyEvent:
+		h.mapNotify(e)
+	case xproto.MapRequestEvent:
+		h.mapRequest(e)
+	case xproto.UnmapNotifyEvent:
+		h.unmapNotify(e)
+	case xproto.DestroyNotifyEvent:
+		h.destroyNotify(e)
+	case xproto.ReparentNotifyEvent:
+		h.reparentNotify(e)
+	case xproto.PropertyNotifyEvent:
+		h.propertyNotify(e)
+	case xproto.ClientMessageEvent:
+		h.clientMessage(e)
+	case xproto.SelectionClearEvent:
+		h.selectionClear(e)
+	case xproto.ExposeEvent:
+		h.expose(e)
+	case xproto.MappingNotifyEvent:
+		h.mappingNotify(e)
+	case x11.XkbEvent:
+		h.xkbNotify(e)
+	case randr.ScreenChangeNotifyEvent:
+		h.screenChangeNotify(e)
+	case randr.NotifyEvent:
+		h.randrNotify(e)
 	}
 }
 
 func (h eventHandler) keyPress(e xproto.KeyPressEvent) {
 	if err := h.wm.handleKeyPressEvent(e); err != nil {
-		log.Println(err)
+		logger.Errorf("%v", err)
+	}
+}
+
+func (h eventHandler) keyRelease(e xproto.KeyReleaseEvent) {
+	if err := h.wm.handleKeyReleaseEvent(e); err != nil {
+		logger.Errorf("%v", err)
+	}
+}
+
+func (h eventHandler) buttonPress(e xproto.ButtonPressEvent) {
+	if err := h.wm.handleButtonPressEvent(e); err != nil {
+		logger.Errorf("Failed to handle button press: %v", err)
+	}
+}
+
+func (h eventHandler) buttonRelease(e xproto.ButtonReleaseEvent) {
+	if err := h.wm.handleButtonReleaseEvent(e); err != nil {
+		logger.Errorf("Failed to handle button release: %v", err)
+	}
+}
+
+func (h eventHandler) motionNotify(e xproto.MotionNotifyEvent) {
+	if err := h.wm.handleMotionNotifyEvent(e); err != nil {
+		logger.Errorf("Failed to handle pointer motion: %v", err)
 	}
 }
 
 func (h eventHandler) enterNotify(e xproto.EnterNotifyEvent) {
+	// the client windows without their own cursor would show the resize cursor set on their parent
+	if err := h.wm.resetPointerCursor(); err != nil {
+		logger.Errorf("Failed to reset the cursor: %v", err)
+	}
+	if !h.wm.config.FocusFollowsMouse || h.wm.fullscreenLocked() {
+		return
+	}
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Event })
 	if f != nil {
 		if err := h.wm.setFocus(e.Event, e.Time); err != nil {
-			log.Println("Failed to set focus:", err)
+			logger.Errorf("Failed to set focus: %v", err)
+		}
+	}
+}
+
+// focusChange redraws the border and titlebar of a frame when the input focus enters or leaves it, which
+// also covers the clients moving the focus themselves. The focus changes caused by keyboard grabs
+// (e.g. of the keybindings) and those within the frame are ignored
+func (h eventHandler) focusChange(win xproto.Window, mode, detail byte, focused bool) {
+	if mode == xproto.NotifyModeGrab || mode == xproto.NotifyModeUngrab ||
+		detail == xproto.NotifyDetailInferior || detail == xproto.NotifyDetailPointer {
+		return
+	}
+	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Parent() == win })
+	if f != nil {
+		if err := f.cli.SetFocused(focused); err != nil {
+			logger.Errorf("Failed to draw the frame of window %d: %v", f.cli.Window(), err)
 		}
 	}
 }
 
 func (h eventHandler) configureRequest(e xproto.ConfigureRequestEvent) {
 	if err := h.wm.handleConfigureRequest(e); err != nil {
-		log.Println("Failed to configure window:", err)
+		logger.Errorf("Failed to configure window: %v", err)
 	}
 }
 
 func (h eventHandler) mapNotify(e xproto.MapNotifyEvent) {
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
 	if f != nil {
-		if err := h.wm.configureNotify(f); err != nil {
-			log.Printf("Failed to send ConfigureNotify event to %d: %v\n", e.Window, err)
-		}
+		h.wm.configureNotify(f)
 	}
 }
 
 func (h eventHandler) mapRequest(e xproto.MapRequestEvent) {
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+	if f != nil && f.state.hidden && f.workspace() != nil {
+		// mapping an iconified window brings it back to the normal state
+		if err := h.wm.restoreFrame(f); err != nil {
+			logger.Errorf("Failed to restore window %d: %v", e.Window, err)
+		}
+		return
+	}
 	if f != nil {
-		log.Printf("Skipping MapRequest of an already mapped window %d\n", e.Window)
+		logger.Debugf("Skipping MapRequest of an already mapped window %d", e.Window)
 		return
 	}
 	if attr, err := xproto.GetWindowAttributes(h.wm.xc.X(), e.Window).Reply(); err != nil || !attr.OverrideRedirect {
 		if err := h.wm.manageWindow(e.Window); err != nil {
-			log.Println("Failed to manage a window:", err)
+			logger.Errorf("Failed to manage a window: %v", err)
 		}
 	}
 	if err := h.wm.updateDesktopHints(); err != nil {
-		log.Printf("Failed to update desktop hints: %v", err)
+		logger.Errorf("Failed to update desktop hints: %v", err)
+	}
+}
+
+func (h eventHandler) mappingNotify(e xproto.MappingNotifyEvent) {
+	if e.Request != xproto.MappingKeyboard && e.Request != xproto.MappingModifier {
+		return
+	}
+	if err := h.wm.reloadKeymap(); err != nil {
+		logger.Errorf("Failed to reload the keyboard mapping: %v", err)
 	}
 }
 
+func (h eventHandler) xkbNotify(e x11.XkbEvent) {
+	if err := h.wm.reloadKeymap(); err != nil {
+		logger.Errorf("Failed to reload the keyboard mapping: %v", err)
+	}
+}
+
+// unmapNotify tells the windows hidden by the WM apart from those withdrawn by their clients, which are
+// no longer managed. A client withdraws a window that is already unmapped (e.g. on another workspace)
+// by sending a synthetic UnmapNotify event to the root window
 func (h eventHandler) unmapNotify(e xproto.UnmapNotifyEvent) {
+	if ok, err := h.wm.releaseTrayIcon(e.Window); ok {
+		if err != nil {
+			logger.Errorf("Failed to remove the tray icon: %v", err)
+		}
+		return
+	}
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
-	if f != nil {
-		if err := f.cli.OnUnmap(); err != nil {
-			log.Println("Failed to unmap frame's parent:", err)
+	if f == nil {
+		return
+	}
+	withdrawn := e.Event == h.wm.xc.GetRootWindow()
+	if !withdrawn {
+		var err error
+		if withdrawn, err = f.cli.OnUnmap(); err != nil {
+			logger.Errorf("Failed to unmap frame's parent: %v", err)
 			return
 		}
 	}
+	if !withdrawn {
+		return
+	}
+	if err := h.wm.withdrawFrame(f); err != nil {
+		logger.Errorf("Failed to release the withdrawn window: %v", err)
+	}
+	if err := h.wm.updateDesktopHints(); err != nil {
+		logger.Errorf("Failed to update desktop hints: %v", err)
+	}
+}
+
+// reparentNotify undocks the tray icons that their clients took out of the tray
+func (h eventHandler) reparentNotify(e xproto.ReparentNotifyEvent) {
+	if h.wm.tray == nil || e.Parent == h.wm.tray.win {
+		return
+	}
+	if _, err := h.wm.undockTrayIcon(e.Window); err != nil {
+		logger.Errorf("Failed to remove the tray icon: %v", err)
+	}
 }
 
 func (h eventHandler) destroyNotify(e xproto.DestroyNotifyEvent) {
+	if ok, err := h.wm.undockTrayIcon(e.Window); ok {
+		if err != nil {
+			logger.Errorf("Failed to remove the tray icon: %v", err)
+		}
+		return
+	}
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
 	if f != nil {
 		if err := f.cli.OnDestroy(); err != nil {
-			log.Println("Failed to destroy frame's parent:", err)
+			logger.Errorf("Failed to destroy frame's parent: %v", err)
 			return
 		}
 		if err := h.wm.deleteFrame(f); err != nil {
-			log.Println("Failed to delete the frame:", err)
+			logger.Errorf("Failed to delete the frame: %v", err)
 		}
 		if err := h.wm.updateDesktopHints(); err != nil {
-			log.Printf("Failed to update desktop hints: %v", err)
+			logger.Errorf("Failed to update desktop hints: %v", err)
 		}
 	}
 }
 
 func (h eventHandler) propertyNotify(e xproto.PropertyNotifyEvent) {
+	if e.Atom == h.wm.xc.Atom("_XEMBED_INFO") && h.wm.tray != nil && h.wm.tray.index(e.Window) >= 0 {
+		if err := h.wm.updateTrayIcon(e.Window); err != nil {
+			logger.Errorf("Failed to update the tray icon: %v", err)
+		}
+		return
+	}
+	if e.Atom == h.wm.xc.Atom("_NET_WM_USER_TIME") {
+		f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window || frm.userTimeWin == e.Window })
+		if f != nil {
+			h.wm.updateUserTime(f)
+		}
+		return
+	}
 	f := h.wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
 	if f != nil {
 		f.cli.OnProperty(e.Atom)
+		switch e.Atom {
+		case h.wm.xc.Atom("_NET_WM_NAME"), xproto.AtomWmName:
+			h.wm.emitWindowEvent("title", f)
+		case xproto.AtomWmNormalHints:
+			if err := h.wm.updateSizeHints(f); err != nil {
+				logger.Errorf("Failed to apply size hints: %v", err)
+			}
+		case xproto.AtomWmHints:
+			if err := h.wm.updateUrgency(f); err != nil {
+				logger.Errorf("Failed to update the urgency: %v", err)
+			}
+		case h.wm.xc.Atom("_NET_WM_STRUT_PARTIAL"), h.wm.xc.Atom("_NET_WM_STRUT"):
+			if f.cli.Type() != client.TypeDock {
+				break
+			}
+			if err := h.wm.updateDockStrut(f); err != nil {
+				logger.Errorf("Failed to update the dock: %v", err)
+			}
+		}
 	}
 }
 
 func (h eventHandler) clientMessage(e xproto.ClientMessageEvent) {
-	switch e.Type {
-	case h.wm.xc.Atom("_NET_CURRENT_DESKTOP"):
-		out := h.wm.outputs[0]
-		index := int(e.Data.Data32[0])
-		if index < len(out.workspaces) {
-			ws := out.workspaces[index]
-			if err := h.wm.switchWorkspace(ws.id); err != nil {
-				log.Printf("Failed to switch workspace: %v", err)
-			}
-		}
+	if err := h.wm.handleClientMessage(e); err != nil {
+		logger.Errorf("Failed to handle %v", err)
+	}
+}
+
+func (h eventHandler) selectionClear(e xproto.SelectionClearEvent) {
+	if e.Owner == h.wm.xc.CheckWindow() && e.Selection == h.wm.xc.ManagerSelection() {
+		h.wm.handOver()
 	}
 }
 
 func (h eventHandler) expose(e xproto.ExposeEvent) {
+	if m := h.wm.menu; m != nil && m.win == e.Window {
+		if err := h.wm.drawMenu(); err != nil {
+			logger.Errorf("Failed to draw the menu: %v", err)
+		}
+		return
+	}
+	if ov := h.wm.overview; ov != nil && ov.win == e.Window {
+		if err := h.wm.drawOverview(); err != nil {
+			logger.Errorf("Failed to draw the overview: %v", err)
+		}
+		return
+	}
+	if p := h.wm.prompt; p != nil && p.win == e.Window {
+		if err := h.wm.drawPrompt(); err != nil {
+			logger.Errorf("Failed to draw the prompt: %v", err)
+		}
+		return
+	}
+	if d := h.wm.osd; d != nil && d.win == e.Window {
+		if err := h.wm.drawOSD(); err != nil {
+			logger.Errorf("Failed to draw the OSD: %v", err)
+		}
+		return
+	}
 	f := h.wm.findFrame(func(frm *frame) bool {
 		return frm.cli.Parent() == e.Window || frm.cli.Window() == e.Window
 	})
 	if f != nil {
 		if err := f.cli.Draw(); err != nil {
-			log.Println("Failed to draw client:", err)
+			logger.Errorf("Failed to draw client: %v", err)
 		}
 	}
 }
+
+func (h eventHandler) screenChangeNotify(e randr.ScreenChangeNotifyEvent) {
+	h.wm.xc.UpdateScreenSize(e.Width, e.Height)
+	h.randrChange()
+}
+
+func (h eventHandler) randrNotify(e randr.NotifyEvent) {
+	h.randrChange()
+}
+
+func (h eventHandler) randrChange() {
+	if err := h.wm.updateOutputs(); err != nil {
+		logger.Errorf("Failed to update outputs: %v", err)
+	}
+	if err := h.wm.placeTray(); err != nil {
+		logger.Errorf("Failed to place the tray: %v", err)
+	}
+	h.wm.emitEvent(ipc.Event{Type: ipc.EventOutput, Change: "change"})
+	if err := h.wm.renderOutputs(); err != nil {
+		logger.Errorf("Failed to render outputs: %v", err)
+	}
+	if err := h.wm.updateDesktopHints(); err != nil {
+		logger.Errorf("Failed to update desktop hints: %v", err)
+	}
+}
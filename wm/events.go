@@ -0,0 +1,58 @@
+package wm
+
+import (
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/ipc"
+	"github.com/patrislav/marwind/logger"
+)
+
+// emitEvent sends the event to the IPC clients subscribed to its type
+func (wm *WM) emitEvent(ev ipc.Event) {
+	if wm.ipc != nil {
+		wm.ipc.Broadcast(ev)
+	}
+}
+
+// emitWindowEvent notifies the subscribers about a change of a window, e.g. "new" or "title"
+func (wm *WM) emitWindowEvent(change string, f *frame) {
+	if f.cli.Type() != client.TypeNormal {
+		return
+	}
+	node := wm.frameNode(f)
+	wm.emitEvent(ipc.Event{Type: ipc.EventWindow, Change: change, Node: &node})
+}
+
+// emitWorkspaceEvent notifies the subscribers about a change of a workspace, e.g. "init" or "empty"
+func (wm *WM) emitWorkspaceEvent(change string, ws *workspace) {
+	wm.emitEvent(ipc.Event{Type: ipc.EventWorkspace, Change: change, Node: wm.workspaceEventNode(ws)})
+}
+
+// emitWorkspaceFocus sends a workspace focus event if the focused workspace changed since the last one,
+// also showing its name on the OSD if enabled
+func (wm *WM) emitWorkspaceFocus(ws *workspace) {
+	if ws.name == wm.focusedWs {
+		return
+	}
+	if wm.config.OSD && wm.focusedWs != "" {
+		if err := wm.showOSD(ws.output, ws.name); err != nil {
+			logger.Errorf("Failed to show the OSD: %v", err)
+		}
+	}
+	ev := ipc.Event{Type: ipc.EventWorkspace, Change: "focus", Node: wm.workspaceEventNode(ws)}
+	if old := wm.findWorkspace(wm.focusedWs); old != nil {
+		ev.Old = wm.workspaceEventNode(old)
+	}
+	wm.focusedWs = ws.name
+	wm.emitEvent(ev)
+}
+
+// workspaceEventNode returns the node of the workspace without its windows, which can be read
+// with get_tree if needed
+func (wm *WM) workspaceEventNode(ws *workspace) *ipc.Node {
+	if ws.output == nil {
+		return &ipc.Node{Type: "workspace", Name: ws.name}
+	}
+	node := wm.workspaceNode(ws)
+	node.Nodes = nil
+	return &node
+}
@@ -0,0 +1,95 @@
+package wm
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// exec runs the shell command in the background, as used by the exec command
+func (wm *WM) exec(command string) error {
+	if command == "" {
+		return fmt.Errorf("usage: exec <command>")
+	}
+	return wm.spawn(command)
+}
+
+// spawn starts the shell command detached from the WM: in its own session (so that it's not affected by
+// signals sent to the WM's process group and can outlive it), with the standard streams closed and
+// with DISPLAY pointing at the display managed by the WM. The extra variables are added to its environment
+func (wm *WM) spawn(command string, env ...string) error {
+	cmd := exec.Command(wm.config.Shell, "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.Env = append(wm.childEnv(), env...)
+	if home, err := os.UserHomeDir(); err == nil {
+		cmd.Dir = home
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run command (%s): %v", command, err)
+	}
+	go func() {
+		// reap the process so that it doesn't remain a zombie
+		if err := cmd.Wait(); err != nil {
+			logger.Infof("Command (%s) exited: %v", command, err)
+		}
+	}()
+	return nil
+}
+
+// childEnv returns the environment of the programs started by the WM
+func (wm *WM) childEnv() []string {
+	overrides := append([]string{"DISPLAY=" + wm.xc.Display()}, wm.cursorEnv()...)
+	env := make([]string, 0, len(os.Environ())+len(overrides))
+	for _, v := range os.Environ() {
+		// the startup notification ID of the WM itself isn't meant for the programs it starts
+		if strings.HasPrefix(v, startupIDEnv+"=") || overridden(v, overrides) {
+			continue
+		}
+		env = append(env, v)
+	}
+	return append(env, overrides...)
+}
+
+// overridden returns true if the variable, e.g. "DISPLAY=:0", is set by one of the overrides
+func overridden(v string, overrides []string) bool {
+	i := strings.IndexByte(v, '=')
+	if i < 0 {
+		return false
+	}
+	name := v[:i+1]
+	for _, o := range overrides {
+		if strings.HasPrefix(o, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// runStartupCommands executes the commands that should be started together with the WM. After a restart
+// only the commands meant to run on every reload are executed, the others should still be running
+func (wm *WM) runStartupCommands() {
+	if !wm.restarted {
+		for _, command := range wm.config.StartupCommands {
+			if err := wm.spawn(command); err != nil {
+				logger.Errorf("Failed to run startup command: %v", err)
+			}
+		}
+		if wm.config.XDGAutostart {
+			wm.runAutostart()
+		}
+	}
+	wm.runReloadCommands()
+}
+
+// runReloadCommands executes the commands that should run whenever the configuration is (re)loaded
+func (wm *WM) runReloadCommands() {
+	for _, command := range wm.config.StartupAlwaysCommands {
+		if err := wm.spawn(command); err != nil {
+			logger.Errorf("Failed to run startup command: %v", err)
+		}
+	}
+}
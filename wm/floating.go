@@ -0,0 +1,106 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+)
+
+const minFloatingSize = 50
+
+// toggleFloating moves the frame between the tiling columns and the floating layer of its workspace
+func (wm *WM) toggleFloating(f *frame) error {
+	ws := f.workspace()
+	if ws == nil {
+		return fmt.Errorf("frame does not belong to any workspace")
+	}
+	if f.floating {
+		f.scratchpad = false
+		ws.deleteFrame(f)
+		if err := ws.addFrame(f); err != nil {
+			return fmt.Errorf("failed to tile the frame: %v", err)
+		}
+		wm.emitWindowEvent("floating", f)
+		return wm.renderWorkspace(ws)
+	}
+	geom := f.cli.Geom()
+	if geom.W == 0 || geom.H == 0 {
+		geom = ws.centeredGeom(ws.area().W/2, ws.area().H/2)
+	}
+	ws.deleteFrame(f)
+	if err := ws.addFloatingFrame(f, geom); err != nil {
+		return fmt.Errorf("failed to float the frame: %v", err)
+	}
+	wm.emitWindowEvent("floating", f)
+	return wm.renderWorkspace(ws)
+}
+
+// initialFloatingGeom calculates the geometry of a newly floated frame based on the size
+// requested by the client window, centered within the workspace
+func (wm *WM) initialFloatingGeom(f *frame, ws *workspace) client.Geom {
+	w, h := ws.area().W/2, ws.area().H/2
+	if g, err := xproto.GetGeometry(wm.xc.X(), xproto.Drawable(f.cli.Window())).Reply(); err == nil {
+		d := wm.getFrameDecorations(f)
+		w = g.Width + uint16(d.Left+d.Right)
+		h = g.Height + uint16(d.Top+d.Bottom)
+		if f.cli.Role().OwnPosition() {
+			return client.Geom{X: g.X, Y: g.Y, W: w, H: h}
+		}
+	}
+	if w < minFloatingSize {
+		w = minFloatingSize
+	}
+	if h < minFloatingSize {
+		h = minFloatingSize
+	}
+	return ws.centeredGeom(w, h)
+}
+
+// centerOver returns the geometry of the given size placed in the middle of another geometry
+func centerOver(geom client.Geom, w, h uint16) client.Geom {
+	return client.Geom{
+		X: geom.X + int16((int(geom.W)-int(w))/2),
+		Y: geom.Y + int16((int(geom.H)-int(h))/2),
+		W: w,
+		H: h,
+	}
+}
+
+// moveFloating moves the floating frame in the direction by the given number of pixels, stopping at the first
+// line its edges snap to, see snapStep
+func (wm *WM) moveFloating(f *frame, dir MoveDirection, px int) error {
+	if err := wm.unmaximizeFloating(f); err != nil {
+		return err
+	}
+	var dx, dy int
+	switch dir {
+	case MoveLeft:
+		dx = -px
+	case MoveRight:
+		dx = px
+	case MoveUp:
+		dy = -px
+	case MoveDown:
+		dy = px
+	}
+	dx, dy = wm.snapStep(f, f.floatGeom, allEdges, dx, dy)
+	f.floatGeom.X += int16(dx)
+	f.floatGeom.Y += int16(dy)
+	return wm.renderFrame(f, f.floatGeom)
+}
+
+// resizeFloating grows the floating frame by the given number of pixels, or shrinks it with a negative one,
+// moving its right or bottom edge and stopping at the first line it snaps to
+func (wm *WM) resizeFloating(f *frame, dir ResizeDirection, px int) error {
+	if err := wm.unmaximizeFloating(f); err != nil {
+		return err
+	}
+	edges, dx, dy := resizeRight, px, 0
+	if dir == ResizeVert {
+		edges, dx, dy = resizeBottom, 0, px
+	}
+	dx, dy = wm.snapStep(f, f.floatGeom, edges, dx, dy)
+	f.floatGeom = resizeGeom(f.floatGeom, edges, dx, dy)
+	return wm.renderFrame(f, f.floatGeom)
+}
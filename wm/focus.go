@@ -1,16 +1,63 @@
 package wm
 
 import (
+	"image"
+
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
 )
 
 func (wm *WM) setFocus(win xproto.Window, time xproto.Timestamp) error {
-	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == win && f.cli.Type() == client.TypeNormal })
+	frm := wm.findFrame(func(f *frame) bool { return f.cli.Window() == win && f.focusable() })
 	if frm == nil && win != wm.xc.GetRootWindow() {
 		return nil
 	}
+	prev := wm.focusedFrame()
+	if prev != nil && prev != frm {
+		if err := prev.cli.SetFocused(false); err != nil {
+			logger.Errorf("Failed to draw the titlebar of window %d: %v", prev.cli.Window(), err)
+		}
+	}
 	wm.activeWin = win
+	if frm != nil && time != xproto.TimeCurrentTime {
+		// focusing with a click or a key is the user's activity in the window
+		frm.noteUserTime(uint32(time))
+	}
+	if frm != nil {
+		if ws := frm.workspace(); ws != nil {
+			ws.pushFocus(frm)
+			if ws.output != nil {
+				wm.focusedOutput = ws.output
+			}
+			// focusing another tiled frame, e.g. with "focus left", brings back the layout under the maximized one
+			unmaximized := false
+			if m := ws.maximizedFrame(); m != nil && m != frm && frm.col != nil {
+				ws.maximized = nil
+				unmaximized = true
+			}
+			if frm.col != nil && (frm.revealInStacks() || unmaximized || ws.monocle) {
+				if err := wm.renderWorkspace(ws); err != nil {
+					return err
+				}
+			}
+		}
+		if err := frm.cli.SetFocused(true); err != nil {
+			logger.Errorf("Failed to draw the titlebar of window %d: %v", win, err)
+		}
+		if frm != prev {
+			wm.emitWindowEvent("focus", frm)
+		}
+	}
+	if frm != nil {
+		if err := wm.setUrgent(frm, false); err != nil {
+			return err
+		}
+	}
+	// the focus decides whether a fullscreen window is stacked above the docks
+	if err := wm.restack(); err != nil {
+		return err
+	}
 	cookie := xproto.GetProperty(wm.xc.X(), false, win, wm.xc.Atom("WM_PROTOCOLS"), xproto.GetPropertyTypeAny, 0, 64)
 	prop, err := cookie.Reply()
 	if err == nil && wm.takeFocusProp(prop, win, time) {
@@ -27,6 +74,15 @@ func (wm *WM) removeFocus() error {
 	return wm.setFocus(wm.xc.GetRootWindow(), xproto.TimeCurrentTime)
 }
 
+// focusWorkspace gives the focus to the most recently focused frame of the workspace or,
+// if there are no frames left, removes the focus altogether
+func (wm *WM) focusWorkspace(ws *workspace) error {
+	if f := ws.lastFocused(); f != nil {
+		return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+	}
+	return wm.removeFocus()
+}
+
 func (wm *WM) takeFocusProp(prop *xproto.GetPropertyReply, win xproto.Window, time xproto.Timestamp) bool {
 	for v := prop.Value; len(v) >= 4; v = v[4:] {
 		switch xproto.Atom(uint32(v[0]) | uint32(v[1])<<8 | uint32(v[2])<<16 | uint32(v[3])<<24) {
@@ -55,7 +111,139 @@ func (wm *WM) takeFocusProp(prop *xproto.GetPropertyReply, win xproto.Window, ti
 	return false
 }
 
+// Policies of moving the pointer to the window focused with the keyboard, see Config.MouseWarping
+const (
+	WarpFocus  = "focus"  // whenever the focus changes
+	WarpOutput = "output" // only when the window is on another output than the pointer
+	WarpNone   = "none"   // never
+)
+
+// Positions within the window the pointer is moved to, see Config.MouseWarpingPosition
+const (
+	WarpCenter = "center" // the middle of the window
+	WarpLast   = "last"   // where the pointer was when it last left the window, the middle the first time
+)
+
+// warpPointerToFrame moves the pointer to the frame focused with the keyboard, according to the pointer
+// warping policy. The pointer stays in place for the frames that are not focused, e.g. moved by a command
+// with criteria. The position of the pointer within the frame it's moved away from is remembered
 func (wm *WM) warpPointerToFrame(f *frame) error {
+	if f.cli.Window() != wm.activeWin || !wm.warping() {
+		return nil
+	}
+	reply, err := xproto.QueryPointer(wm.xc.X(), wm.xc.GetRootWindow()).Reply()
+	if err != nil {
+		return err
+	}
+	if wm.config.MouseWarping == WarpOutput {
+		if ws := f.workspace(); ws == nil || wm.outputAt(reply.RootX, reply.RootY) == ws.output {
+			return nil
+		}
+	}
 	geom := f.cli.Geom()
-	return wm.xc.WarpPointer(geom.X+int16(geom.W/2), geom.Y+int16(geom.H/2))
+	if prev := wm.frameAt(reply.RootX, reply.RootY); prev != nil {
+		if prev == f && wm.config.MouseWarpingPosition == WarpLast {
+			return nil
+		}
+		g := prev.cli.Geom()
+		prev.pointer = &image.Point{X: int(reply.RootX - g.X), Y: int(reply.RootY - g.Y)}
+	}
+	x, y := warpPosition(geom, f.pointer, wm.config.MouseWarpingPosition)
+	return wm.xc.WarpPointer(x, y)
+}
+
+// warpPointer moves the pointer to the position, e.g. the middle of another output, unless the pointer
+// warping is disabled
+func (wm *WM) warpPointer(x, y int16) error {
+	if !wm.warping() {
+		return nil
+	}
+	return wm.xc.WarpPointer(x, y)
+}
+
+// warping returns true unless the pointer warping is disabled
+func (wm *WM) warping() bool {
+	return wm.config.MouseWarping == WarpFocus || wm.config.MouseWarping == WarpOutput
+}
+
+// warpPosition returns where the pointer goes within the frame of the given geometry: the remembered
+// position relative to the frame with WarpLast, if it's still within the frame, or its middle
+func warpPosition(geom client.Geom, last *image.Point, position string) (int16, int16) {
+	if position == WarpLast && last != nil && last.X >= 0 && last.Y >= 0 && last.X < int(geom.W) && last.Y < int(geom.H) {
+		return geom.X + int16(last.X), geom.Y + int16(last.Y)
+	}
+	return geom.X + int16(geom.W/2), geom.Y + int16(geom.H/2)
+}
+
+// frameAt returns the topmost shown frame at the position relative to the root window, nil if there's none
+func (wm *WM) frameAt(x, y int16) *frame {
+	frames := wm.stackedFrames()
+	stackFrames(frames, wm.focusedFrame())
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		if f.cli.Mapped() && f.cli.Role().Focusable() && geomContains(f.cli.Geom(), x, y) {
+			return f
+		}
+	}
+	return nil
+}
+
+// grabFocusClick sets up a synchronous grab of the pointer buttons over the client window when the click
+// to focus model is used, so that the WM can focus the window before the click is replayed to it
+func (wm *WM) grabFocusClick(f *frame) error {
+	if wm.config.FocusFollowsMouse || !f.focusable() {
+		return nil
+	}
+	return xproto.GrabButtonChecked(
+		wm.xc.X(),
+		true,
+		f.cli.Window(),
+		xproto.EventMaskButtonPress,
+		xproto.GrabModeSync,
+		xproto.GrabModeAsync,
+		xproto.WindowNone,
+		xproto.CursorNone,
+		xproto.ButtonIndexAny,
+		xproto.ModMaskAny,
+	).Check()
+}
+
+// updateFocusModel grabs or releases the pointer buttons over all the client windows after
+// the focus model changed
+func (wm *WM) updateFocusModel() error {
+	var err error
+	for _, f := range append(wm.allFrames(), wm.scratchpad...) {
+		e := xproto.UngrabButtonChecked(wm.xc.X(), xproto.ButtonIndexAny, f.cli.Window(), xproto.ModMaskAny).Check()
+		if e == nil {
+			e = wm.grabFocusClick(f)
+		}
+		if e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// focusClicked gives the focus to the clicked frame in the click to focus model and lets the click,
+// held back by the synchronous grab, through to the client window
+func (wm *WM) focusClicked(f *frame, e xproto.ButtonPressEvent) error {
+	if f.cli.Window() != wm.activeWin {
+		if f.floating {
+			if err := wm.raiseFrame(f); err != nil {
+				logger.Errorf("Failed to raise window %d: %v", f.cli.Window(), err)
+			}
+		}
+		if err := wm.setFocus(f.cli.Window(), e.Time); err != nil {
+			logger.Errorf("Failed to focus window %d: %v", f.cli.Window(), err)
+		}
+	}
+	return xproto.AllowEventsChecked(wm.xc.X(), xproto.AllowReplayPointer, e.Time).Check()
+}
+
+// focusedFrame returns the frame of the active window or nil if no window is focused
+func (wm *WM) focusedFrame() *frame {
+	if wm.activeWin == 0 || wm.activeWin == wm.xc.GetRootWindow() {
+		return nil
+	}
+	return wm.findFrame(func(f *frame) bool { return f.cli.Window() == wm.activeWin })
 }
@@ -0,0 +1,32 @@
+package wm
+
+import (
+	"image"
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestWarpPosition(t *testing.T) {
+	geom := client.Geom{X: 100, Y: 50, W: 400, H: 300}
+	tests := []struct {
+		name     string
+		last     *image.Point
+		position string
+		wantX    int16
+		wantY    int16
+	}{
+		{"center", &image.Point{X: 10, Y: 20}, WarpCenter, 300, 200},
+		{"last", &image.Point{X: 10, Y: 20}, WarpLast, 110, 70},
+		{"never left", nil, WarpLast, 300, 200},
+		{"outside after a resize", &image.Point{X: 450, Y: 20}, WarpLast, 300, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := warpPosition(geom, tt.last, tt.position)
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("warpPosition() got = (%v, %v), want = (%v, %v)", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
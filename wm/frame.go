@@ -1,6 +1,8 @@
 package wm
 
 import (
+	"image"
+
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/patrislav/marwind/client"
 	"github.com/patrislav/marwind/x11"
@@ -8,35 +10,130 @@ import (
 
 type frame struct {
 	col    *column
+	ws     *workspace
 	cli    *client.Client
-	height uint16
+	weight float64 // share of the column height taken by a tiled frame, relative to the other frames
+	strut  uint16  // size of the output edge reserved by a dock in its area
+	docked uint64  // order in which the dock was mapped, see sortDocks
+
+	reserved [4]uint16 // space reserved by a dock at each edge of its output, indexed by the dock area
+
+	floating  bool
+	floatGeom client.Geom // geometry of a floating frame, including decorations
+
+	fullscreen bool
+	state      frameState
+
+	hints *x11.SizeHints // size constraints from WM_NORMAL_HINTS, nil if not set
+
+	transientFor *frame // frame of the main window of a transient (dialog) window
+	scratchpad   bool   // whether the frame belongs to the scratchpad, either hidden or currently shown
+
+	closing bool // the client was asked to close the window, closing it again kills the client
+
+	userTime    uint32        // time of the last user activity in the window, valid if hasUserTime is set
+	hasUserTime bool          // whether the client reported the time of its user activity
+	userTimeWin xproto.Window // window holding _NET_WM_USER_TIME on behalf of the client, 0 if it's the client itself
+
+	swallowed *frame // terminal hidden while the window started from it is shown in its place
+
+	split *column // container taking the place of a frame in a column, nil for the frames of windows
+
+	marks []string // names given to the window to come back to it, see markFrame
+
+	placeholder []layoutCriteria // windows that can take the place of a placeholder frame, nil for other frames
+
+	raised uint64 // when the frame was last raised, the frames raised later are stacked above it within its layer
+
+	extents *x11.Dimensions // decorations last published in _NET_FRAME_EXTENTS, nil until they're published
+	placed  *placement      // geometry last given to the windows of the frame, nil until it's rendered
+
+	pointer *image.Point // position of the pointer relative to the frame when it was last warped away from it
+
+	notified uint64 // order in which a notification window appeared, see renderNotifications
+	queued   bool   // whether a notification window waits, unmapped, for the older ones to be closed
 }
 
-func (wm *WM) createFrame(win xproto.Window, typ client.Type) (*frame, error) {
+func (wm *WM) createFrame(win xproto.Window, typ client.Type, role client.Role) (*frame, error) {
 	c, err := client.New(wm.xc, wm.windowConfig, win, typ)
 	if err != nil {
 		return nil, err
 	}
+	c.SetRole(role)
 	f := &frame{cli: c}
+	if typ == client.TypeNormal {
+		f.hints, _ = wm.xc.GetNormalHints(win)
+	}
 
 	return f, nil
 }
 
+// updateSizeHints reloads the size constraints of the frame after its WM_NORMAL_HINTS property changed
+func (wm *WM) updateSizeHints(f *frame) error {
+	f.hints, _ = wm.xc.GetNormalHints(f.cli.Window())
+	ws := f.workspace()
+	if ws == nil || ws.output == nil || ws.output.activeWs != ws {
+		return nil
+	}
+	return wm.renderWorkspace(ws)
+}
+
+// constrainGeom shrinks the given frame geometry so that the client window satisfies its size hints.
+// Tiled frames are centered within the space given to them and never exceed it, while floating frames
+// keep their position and grow if required by the minimum size
+func (wm *WM) constrainGeom(f *frame, geom client.Geom) client.Geom {
+	if f.hints == nil || f.fullscreen || f.cli.Type() != client.TypeNormal {
+		return geom
+	}
+	d := wm.getFrameDecorations(f)
+	dw, dh := uint16(d.Left+d.Right), uint16(d.Top+d.Bottom)
+	if geom.W <= dw || geom.H <= dh {
+		return geom
+	}
+	w, h := f.hints.Constrain(geom.W-dw, geom.H-dh)
+	w, h = w+dw, h+dh
+	if f.floating {
+		geom.W, geom.H = w, h
+		return geom
+	}
+	if w < geom.W {
+		geom.X += int16((geom.W - w) / 2)
+		geom.W = w
+	}
+	if h < geom.H {
+		geom.Y += int16((geom.H - h) / 2)
+		geom.H = h
+	}
+	return geom
+}
+
+// focusable returns true if the window of the frame can be given the focus, which is never the case for
+// docks, desktops, or windows only shown for a moment such as menus and notifications
+func (f *frame) focusable() bool {
+	return f.cli.Type() == client.TypeNormal && f.cli.Role().Focusable()
+}
+
 func (f *frame) workspace() *workspace {
 	if f.col != nil {
 		return f.col.ws
 	}
-	return nil
+	return f.ws
 }
 
 func (wm *WM) getFrameDecorations(f *frame) x11.Dimensions {
-	if f.cli.Parent() == 0 {
+	if f.cli.Parent() == 0 || f.fullscreen || !f.cli.Role().Decorated() || wm.config.SmartBorders && wm.loneFrame(f) {
 		return x11.Dimensions{Top: 0, Left: 0, Right: 0, Bottom: 0}
 	}
+	return wm.decorations(!f.cli.TitlebarHidden(), f.pixelScale())
+}
+
+// decorations returns the size of the border and, if shown, the titlebar around a decorated window on
+// an output with the given factor of the pixel values
+func (wm *WM) decorations(titlebar bool, scale float64) x11.Dimensions {
 	var bar uint32
-	border := uint32(wm.config.BorderWidth)
-	if wm.config.TitleBarHeight > 0 {
-		bar = uint32(wm.config.TitleBarHeight) + 1
+	border := uint32(scaledUint8(wm.config.BorderWidth, scale))
+	if wm.config.TitleBarHeight > 0 && titlebar {
+		bar = uint32(scaledUint8(wm.config.TitleBarHeight, scale)) + 1
 	}
 	return x11.Dimensions{
 		Top:    border + bar,
@@ -45,3 +142,61 @@ func (wm *WM) getFrameDecorations(f *frame) x11.Dimensions {
 		Left:   border,
 	}
 }
+
+// updateFrameExtents publishes the size of the decorations of the frame in _NET_FRAME_EXTENTS whenever it changes
+func (wm *WM) updateFrameExtents(f *frame) error {
+	d := wm.getFrameDecorations(f)
+	if f.extents != nil && *f.extents == d {
+		return nil
+	}
+	if err := wm.dpy.SetFrameExtents(f.cli.Window(), d); err != nil {
+		return err
+	}
+	f.extents = &d
+	return nil
+}
+
+// requestFrameExtents answers a _NET_REQUEST_FRAME_EXTENTS message of a window that isn't mapped yet
+// with the size of the decorations it will most likely get, so that the client can account for them
+func (wm *WM) requestFrameExtents(win xproto.Window) error {
+	typeAtoms, err := wm.getWindowTypeAtoms(win)
+	if err != nil {
+		return err
+	}
+	var d x11.Dimensions
+	if typ, role := wm.getWindowType(typeAtoms); typ == client.TypeNormal && role.Decorated() {
+		rule := wm.matchRules(win, typeAtoms)
+		// most new windows are placed on the current workspace
+		d = wm.decorations(!rule.NoTitlebar, wm.currentOutput().pixelScale())
+	}
+	return wm.xc.SetFrameExtents(win, d)
+}
+
+// loneFrame returns true if the frame is the only visible tiled frame of its workspace
+func (wm *WM) loneFrame(f *frame) bool {
+	ws := f.workspace()
+	return !f.floating && ws != nil && (ws.singleFrame() == f || ws.maximizedFrame() == f)
+}
+
+// frameWindow returns the top-level window of the frame, i.e. the parent if the client is reparented
+func (wm *WM) frameWindow(f *frame) xproto.Window {
+	if f.cli.Parent() != 0 {
+		return f.cli.Parent()
+	}
+	return f.cli.Window()
+}
+
+// transients returns the frames of the windows that are transient for the given frame
+func (wm *WM) transients(f *frame) []*frame {
+	ws := f.workspace()
+	if ws == nil {
+		return nil
+	}
+	var frames []*frame
+	for _, frm := range ws.floating {
+		if frm.transientFor == f && frm != f {
+			frames = append(frames, frm)
+		}
+	}
+	return frames
+}
@@ -0,0 +1,29 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/x11"
+)
+
+func TestDecorations(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		titlebar bool
+		want     x11.Dimensions
+	}{
+		{"border and titlebar", Config{BorderWidth: 2, TitleBarHeight: 18}, true, x11.Dimensions{Top: 21, Left: 2, Right: 2, Bottom: 2}},
+		{"hidden titlebar", Config{BorderWidth: 2, TitleBarHeight: 18}, false, x11.Dimensions{Top: 2, Left: 2, Right: 2, Bottom: 2}},
+		{"titlebars disabled", Config{BorderWidth: 1}, true, x11.Dimensions{Top: 1, Left: 1, Right: 1, Bottom: 1}},
+		{"no decorations", Config{}, false, x11.Dimensions{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &WM{config: tt.config}
+			if got := wm.decorations(tt.titlebar, 1); got != tt.want {
+				t.Errorf("decorations() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// setFullscreen makes the frame cover the entire output of its workspace or restores it to its previous
+// position within the tiling or floating layer. The position is remembered as the frame is never removed
+// from its column while fullscreen
+func (wm *WM) setFullscreen(f *frame, enable bool) error {
+	ws := f.workspace()
+	if ws == nil || f.fullscreen == enable {
+		return nil
+	}
+	if enable && ws.fullscreen != nil {
+		prev := ws.fullscreen
+		prev.fullscreen = false
+		if err := wm.updateWindowState(prev); err != nil {
+			return fmt.Errorf("failed to update window state: %v", err)
+		}
+	}
+	f.fullscreen = enable
+	if enable {
+		ws.fullscreen = f
+	} else {
+		ws.fullscreen = nil
+	}
+	if err := wm.updateWindowState(f); err != nil {
+		return fmt.Errorf("failed to update window state: %v", err)
+	}
+	wm.emitWindowEvent("fullscreen_mode", f)
+	if ws.output == nil || ws.output.activeWs != ws {
+		return nil
+	}
+	return wm.renderOutput(ws.output)
+}
+
+// renderFullscreen configures the fullscreen frame of the workspace to cover the entire output, docks included
+func (wm *WM) renderFullscreen(ws *workspace) error {
+	f := ws.fullscreen
+	if f == nil {
+		return nil
+	}
+	if err := wm.renderFrame(f, ws.output.geom); err != nil {
+		return err
+	}
+	wm.markRaised(f)
+	return nil
+}
+
+// Placements of the notifications appearing over a fullscreen window, see Config.FullscreenNotifications
+const (
+	NotificationsAbove       = "above"        // show the notifications above the fullscreen window
+	NotificationsOtherOutput = "other_output" // show them on another output without a fullscreen window, if any
+)
+
+// fullscreenLocked returns true if the focus is held by a fullscreen window, see Config.FullscreenFocusLock
+func (wm *WM) fullscreenLocked() bool {
+	return wm.config.FullscreenFocusLock && wm.fullscreenFocused()
+}
+
+// fullscreenFocused returns true if the focused window is fullscreen
+func (wm *WM) fullscreenFocused() bool {
+	f := wm.focusedFrame()
+	return f != nil && f.fullscreen
+}
+
+// notificationWorkspace returns the workspace and geometry of a new notification window placed by its
+// client at the given geometry. Notifications that would cover a fullscreen window are moved to the same
+// position on the first output that shows no fullscreen window, if so configured
+func (wm *WM) notificationWorkspace(ws *workspace, geom client.Geom) (*workspace, client.Geom) {
+	if wm.config.FullscreenNotifications != NotificationsOtherOutput {
+		return ws, geom
+	}
+	from := wm.outputAt(geom.X, geom.Y)
+	if from == nil {
+		from = ws.output
+	}
+	if from == nil || from.activeWs == nil || from.activeWs.fullscreen == nil {
+		return ws, geom
+	}
+	for _, o := range wm.outputs {
+		if o == from || o.activeWs == nil || o.activeWs.fullscreen != nil {
+			continue
+		}
+		geom.X = clampPosition(geom.X-from.geom.X, geom.W, o.geom.W) + o.geom.X
+		geom.Y = clampPosition(geom.Y-from.geom.Y, geom.H, o.geom.H) + o.geom.Y
+		return o.activeWs, geom
+	}
+	return ws, geom
+}
+
+// clampPosition keeps a window of the given size placed at the offset within an output of the given size
+func clampPosition(offset int16, size, outputSize uint16) int16 {
+	if max := int16(outputSize) - int16(size); offset > max {
+		offset = max
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
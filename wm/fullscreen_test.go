@@ -0,0 +1,44 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestNotificationWorkspace(t *testing.T) {
+	left := &output{name: "DP-1", geom: client.Geom{X: 0, Y: 0, W: 1920, H: 1080}}
+	right := &output{name: "HDMI-1", geom: client.Geom{X: 1920, Y: 0, W: 1280, H: 1024}}
+	for _, o := range []*output{left, right} {
+		o.activeWs = newWorkspace(o.name, workspaceConfig{})
+		o.activeWs.output = o
+	}
+	fullscreen := &frame{fullscreen: true}
+	// a notification placed by its client at the top right corner of the left output
+	geom := client.Geom{X: 1620, Y: 40, W: 280, H: 80}
+	tests := []struct {
+		name       string
+		placement  string
+		fullscreen *output
+		wantWs     *workspace
+		wantGeom   client.Geom
+	}{
+		{"above", NotificationsAbove, left, left.activeWs, geom},
+		{"no fullscreen", NotificationsOtherOutput, nil, left.activeWs, geom},
+		{"fullscreen on other output", NotificationsOtherOutput, right, left.activeWs, geom},
+		{"moved to other output", NotificationsOtherOutput, left, right.activeWs, client.Geom{X: 2920, Y: 40, W: 280, H: 80}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left.activeWs.fullscreen, right.activeWs.fullscreen = nil, nil
+			if tt.fullscreen != nil {
+				tt.fullscreen.activeWs.fullscreen = fullscreen
+			}
+			wm := &WM{outputs: []*output{left, right}, config: Config{FullscreenNotifications: tt.placement}}
+			ws, g := wm.notificationWorkspace(left.activeWs, geom)
+			if ws != tt.wantWs || g != tt.wantGeom {
+				t.Errorf("notificationWorkspace() got = %v, %v, want = %v, %v", ws.name, g, tt.wantWs.name, tt.wantGeom)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package wm
+
+// maxGap limits the gaps so that they can't take up the entire workspace area
+const maxGap = 200
+
+// changeGaps sets the inner or outer gaps of the workspaces to the values returned by the change function
+// for the current ones, and re-renders the visible workspaces
+func (wm *WM) changeGaps(workspaces []*workspace, inner bool, change func(gap uint16) int) error {
+	for _, ws := range workspaces {
+		gap := &ws.config.gap
+		if inner {
+			gap = &ws.config.innerGap
+		}
+		*gap = clampGap(change(*gap))
+	}
+	var err error
+	for _, ws := range workspaces {
+		if ws.output == nil || ws.output.activeWs != ws {
+			continue
+		}
+		if e := wm.renderWorkspace(ws); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func clampGap(gap int) uint16 {
+	if gap < 0 {
+		return 0
+	}
+	if gap > maxGap {
+		return maxGap
+	}
+	return uint16(gap)
+}
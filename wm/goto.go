@@ -0,0 +1,46 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// cmdGoto opens a prompt searching the windows of all the workspaces by their titles and classes, switching to
+// the workspace of the picked window and focusing it: goto
+func cmdGoto(wm *WM, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: goto")
+	}
+	var windows []xproto.Window
+	var items []promptItem
+	for _, ws := range wm.workspaces {
+		for _, f := range ws.frames() {
+			if !f.focusable() || f.placeholder != nil {
+				continue
+			}
+			windows = append(windows, f.cli.Window())
+			items = append(items, gotoItem(ws.name, f.cli.Class(), f.cli.Instance(), f.cli.Title()))
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return wm.openPrompt("window", items, func(i int, _ xproto.Timestamp) error {
+		// the window might have been closed while the prompt was open
+		f := wm.findFrame(func(f *frame) bool { return f.cli.Window() == windows[i] })
+		if f == nil || f.workspace() == nil {
+			return nil
+		}
+		return wm.activateFrame(f)
+	})
+}
+
+// gotoItem returns the prompt entry of a window, matched by its title, class and instance
+func gotoItem(ws, class, instance, title string) promptItem {
+	label := fmt.Sprintf("[%s] %s", ws, title)
+	if class != "" {
+		label = fmt.Sprintf("[%s] %s - %s", ws, class, title)
+	}
+	return promptItem{label: label, text: title + " " + class + " " + instance}
+}
@@ -0,0 +1,131 @@
+package wm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/x11"
+)
+
+// IdleHook is a command executed once the user has been idle for the given time
+type IdleHook struct {
+	After   time.Duration // time without any input of the user
+	Command string        // command executed once per idle period, e.g. "exec i3lock -n"
+}
+
+// idlePollInterval is how often the idle time is checked, see watchIdle
+const idlePollInterval = time.Second
+
+// idleState tracks the hooks executed during the current idle period
+type idleState struct {
+	fired     []bool    // whether each of the hooks was executed, indexed like the configured hooks
+	inhibited bool      // whether the idle hooks and the screen saver are inhibited by a fullscreen window
+	resumed   time.Time // when the last inhibition ended, the idle time is counted from then at most
+	stop      func()    // stops the polling
+}
+
+// watchIdle starts checking the idle time of the user once any idle hook or the inhibition by fullscreen
+// windows is configured. The MIT-SCREEN-SAVER extension only notifies about its own screen saver, whose
+// timeout is shared with other programs, so the time since the last input is polled instead. The polling stops
+// when a reload disables both
+func (wm *WM) watchIdle() {
+	if len(wm.config.IdleHooks) == 0 && !wm.config.IdleInhibitFullscreen {
+		wm.stopIdle()
+		return
+	}
+	if wm.idle != nil {
+		return
+	}
+	wm.idle = &idleState{stop: wm.pollEvery(idlePollInterval, wm.pollIdle)}
+}
+
+// stopIdle stops checking the idle time, resuming the screen saver if it was inhibited
+func (wm *WM) stopIdle() {
+	s := wm.idle
+	if s == nil {
+		return
+	}
+	s.stop()
+	wm.idle = nil
+	if s.inhibited {
+		if err := wm.xc.SuspendScreenSaver(false); err != nil {
+			logger.Errorf("Failed to resume the screen saver: %v", err)
+		}
+	}
+}
+
+// pollIdle updates the inhibition and runs the idle hooks that became due. Without the MIT-SCREEN-SAVER
+// extension the idle hooks can't run, the polling is then stopped until the next reload
+func (wm *WM) pollIdle() error {
+	s := wm.idle
+	if s == nil {
+		// queued before the polling stopped
+		return nil
+	}
+	inhibited := wm.config.IdleInhibitFullscreen && wm.fullscreenFocused()
+	if inhibited != s.inhibited {
+		s.inhibited = inhibited
+		if !inhibited {
+			s.resumed = time.Now()
+		}
+		if err := wm.xc.SuspendScreenSaver(inhibited); err != nil {
+			logger.Errorf("Failed to suspend the screen saver: %v", err)
+		}
+	}
+	if len(wm.config.IdleHooks) == 0 {
+		return nil
+	}
+	idle, err := wm.xc.IdleTime()
+	if err == x11.ErrNoScreenSaver {
+		wm.stopIdle()
+		return fmt.Errorf("the idle hooks are disabled: %v", err)
+	}
+	if err != nil {
+		return err
+	}
+	if inhibited {
+		idle = 0
+	} else if since := time.Since(s.resumed); since < idle {
+		idle = since
+	}
+	var due []string
+	s.fired, due = dueIdleHooks(wm.config.IdleHooks, s.fired, idle)
+	for _, cmd := range due {
+		logger.Debugf("Running the idle command %q after %v", cmd, idle.Truncate(time.Second))
+		if err := wm.runCommands(cmd); err != nil {
+			logger.Errorf("Failed to run the idle command %q: %v", cmd, err)
+		}
+	}
+	return nil
+}
+
+// dueIdleHooks returns which of the hooks have been executed and the commands of the ones to execute now
+// for the idle time. Every hook runs once per idle period, all of them are rearmed by the next input
+func dueIdleHooks(hooks []IdleHook, fired []bool, idle time.Duration) ([]bool, []string) {
+	if len(fired) != len(hooks) {
+		// the configuration was reloaded
+		fired = make([]bool, len(hooks))
+	}
+	var due []string
+	for i, h := range hooks {
+		switch {
+		case idle < h.After:
+			fired[i] = false
+		case !fired[i]:
+			fired[i] = true
+			due = append(due, h.Command)
+		}
+	}
+	return fired, due
+}
+
+// applyDPMS sets the configured power saving timeouts of the monitors, if any
+func (wm *WM) applyDPMS() {
+	if !wm.config.DPMS {
+		return
+	}
+	if err := wm.xc.SetDPMS(wm.config.DPMSStandby, wm.config.DPMSSuspend, wm.config.DPMSOff); err != nil {
+		logger.Errorf("Failed to set up DPMS: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDueIdleHooks(t *testing.T) {
+	hooks := []IdleHook{
+		{After: 5 * time.Minute, Command: "exec notify-send idle"},
+		{After: 10 * time.Minute, Command: "exec i3lock -n"},
+	}
+	steps := []struct {
+		idle  time.Duration
+		want  []string
+		fired []bool
+	}{
+		{time.Minute, nil, []bool{false, false}},
+		{5 * time.Minute, []string{"exec notify-send idle"}, []bool{true, false}},
+		{6 * time.Minute, nil, []bool{true, false}},
+		{11 * time.Minute, []string{"exec i3lock -n"}, []bool{true, true}},
+		{12 * time.Minute, nil, []bool{true, true}},
+		{time.Second, nil, []bool{false, false}},
+		{20 * time.Minute, []string{"exec notify-send idle", "exec i3lock -n"}, []bool{true, true}},
+	}
+	var fired []bool
+	for _, step := range steps {
+		var due []string
+		fired, due = dueIdleHooks(hooks, fired, step.idle)
+		if !reflect.DeepEqual(due, step.want) || !reflect.DeepEqual(fired, step.fired) {
+			t.Errorf("dueIdleHooks() at %v got = %v, %v, want = %v, %v", step.idle, due, fired, step.want, step.fired)
+		}
+	}
+}
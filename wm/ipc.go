@@ -0,0 +1,70 @@
+package wm
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/patrislav/marwind/ipc"
+	"github.com/patrislav/marwind/logger"
+)
+
+// ipcRequest is an IPC request waiting to be handled by the event loop
+type ipcRequest struct {
+	req   ipc.Request
+	reply chan ipc.Response
+}
+
+// startIPC creates the IPC socket and exports its path to the environment of programs started by the WM
+func (wm *WM) startIPC() error {
+	path := ipc.SocketPath()
+	srv, err := ipc.Listen(path, wm.forwardIPCRequest)
+	if err != nil {
+		return err
+	}
+	if err := os.Setenv(ipc.SocketEnv, path); err != nil {
+		logger.Errorf("Failed to set %s: %v", ipc.SocketEnv, err)
+	}
+	wm.ipc = srv
+	go srv.Serve()
+	return nil
+}
+
+// forwardIPCRequest passes the request to the event loop, so that it's never handled concurrently
+// with X events, and waits for the response. Once the event loop has stopped, e.g. while the WM is
+// exiting, the request fails instead
+func (wm *WM) forwardIPCRequest(req ipc.Request) ipc.Response {
+	stopped := ipc.ErrorResponse(fmt.Errorf("the window manager is not running"))
+	r := ipcRequest{req: req, reply: make(chan ipc.Response, 1)}
+	select {
+	case wm.ipcRequests <- r:
+	case <-wm.done:
+		return stopped
+	}
+	select {
+	case resp := <-r.reply:
+		return resp
+	case <-wm.done:
+		return stopped
+	}
+}
+
+func (wm *WM) handleIPCRequest(req ipc.Request) ipc.Response {
+	switch req.Type {
+	case ipc.TypeCommand:
+		if err := wm.runCommands(req.Payload); err != nil {
+			return ipc.ErrorResponse(err)
+		}
+		return ipc.Response{Success: true}
+	case ipc.TypeGetTree:
+		return ipc.DataResponse(wm.tree())
+	case ipc.TypeGetWorkspaces:
+		return ipc.DataResponse(wm.workspaceList())
+	case ipc.TypeGetOutputs:
+		return ipc.DataResponse(wm.outputList())
+	case ipc.TypeGetMinimized:
+		return ipc.DataResponse(wm.minimizedNodes())
+	case ipc.TypeGetMarks:
+		return ipc.DataResponse(wm.markList())
+	}
+	return ipc.ErrorResponse(fmt.Errorf("unknown request type %q", req.Type))
+}
@@ -0,0 +1,50 @@
+package wm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrislav/marwind/ipc"
+)
+
+func TestForwardIPCRequest(t *testing.T) {
+	t.Run("handled", func(t *testing.T) {
+		wm := &WM{ipcRequests: make(chan ipcRequest), done: make(chan struct{})}
+		go func() {
+			r := <-wm.ipcRequests
+			r.reply <- ipc.Response{Success: true}
+		}()
+		if resp := wm.forwardIPCRequest(ipc.Request{Type: ipc.TypeCommand}); !resp.Success {
+			t.Errorf("got = %v, want a success", resp)
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		wm := &WM{ipcRequests: make(chan ipcRequest), done: make(chan struct{})}
+		close(wm.done)
+		testStoppedIPC(t, wm)
+	})
+
+	t.Run("stopped while handled", func(t *testing.T) {
+		wm := &WM{ipcRequests: make(chan ipcRequest), done: make(chan struct{})}
+		go func() {
+			<-wm.ipcRequests
+			close(wm.done)
+		}()
+		testStoppedIPC(t, wm)
+	})
+}
+
+func testStoppedIPC(t *testing.T, wm *WM) {
+	t.Helper()
+	got := make(chan ipc.Response)
+	go func() { got <- wm.forwardIPCRequest(ipc.Request{Type: ipc.TypeCommand}) }()
+	select {
+	case resp := <-got:
+		if resp.Success || resp.Error == "" {
+			t.Errorf("got = %v, want an error", resp)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("forwardIPCRequest blocked after the event loop stopped")
+	}
+}
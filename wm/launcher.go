@@ -0,0 +1,293 @@
+package wm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/x11"
+	"github.com/patrislav/marwind/xdg"
+)
+
+// startupIDEnv passes the startup notification ID to the launched programs, which set it as the
+// _NET_STARTUP_ID of their windows
+const startupIDEnv = "DESKTOP_STARTUP_ID"
+
+// launchTimeout is how long the busy cursor is shown for a launched program that doesn't map a window
+const launchTimeout = 15 * time.Second
+
+// launcherCacheFile is the file in the cache directory of the WM ($XDG_CACHE_HOME/marwind) in which the
+// desktop entries and executables of the launcher are kept between the times it's opened, see launcherLists
+const launcherCacheFile = "launcher.json"
+
+// launcherItem is a program that can be started from the launcher
+type launcherItem struct {
+	command string // shell command starting the program
+	notify  bool   // whether the program supports startup notification
+	name    string // name of the application, empty for the executables
+	icon    string // icon of the application, if any
+}
+
+// launcherCache is the content of the launcher cache file: the lists read from the directories, valid as
+// long as the modification times of the directories are the same, see dirStamps
+type launcherCache struct {
+	Stamps      map[string]int64
+	Entries     []desktopEntry
+	Executables []string
+}
+
+// cmdLauncher opens a prompt completing over the applications of the desktop entries and the executables
+// in $PATH, starting the picked one: launcher
+func cmdLauncher(wm *WM, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: launcher")
+	}
+	programs, items := launcherItems(launcherLists())
+	if len(items) == 0 {
+		return nil
+	}
+	return wm.openPrompt("run", items, func(i int, t xproto.Timestamp) error {
+		return wm.launch(programs[i], t)
+	})
+}
+
+// launch starts the program picked in the launcher at the given time. The start of the programs supporting
+// startup notification is announced with a "new:" message, and the busy cursor is shown over the root window
+// until their window is mapped, or for launchTimeout at most
+func (wm *WM) launch(p launcherItem, t xproto.Timestamp) error {
+	if !p.notify {
+		return wm.exec(p.command)
+	}
+	wm.launches++
+	id := startupID(os.Getpid(), wm.launches, uint32(t))
+	var bin string
+	if fields := strings.Fields(p.command); len(fields) > 0 {
+		bin = filepath.Base(fields[0])
+	}
+	msg := startupMessage("new", "ID", id, "NAME", p.name, "ICON", p.icon, "BIN", bin,
+		"SCREEN", strconv.Itoa(wm.xc.ScreenNumber()), "TIMESTAMP", strconv.FormatUint(uint64(t), 10))
+	if err := wm.xc.SendStartupInfo(msg); err != nil {
+		logger.Warnf("Failed to announce the launch of %s: %v", p.name, err)
+	}
+	if wm.launching == nil {
+		wm.launching = make(map[string]bool)
+	}
+	wm.launching[id] = true
+	if err := wm.spawn(p.command, startupIDEnv+"="+id); err != nil {
+		if e := wm.endLaunch(id); e != nil {
+			logger.Warnf("Failed to end the launch of %s: %v", p.name, e)
+		}
+		return err
+	}
+	time.AfterFunc(launchTimeout, func() {
+		wm.queueTask(func() error { return wm.endLaunch(id) })
+	})
+	return wm.setRootCursor()
+}
+
+// endLaunch stops waiting for the window of the launch with the startup ID, if it's still awaited, telling
+// the clients monitoring it with a "remove:" message, and brings back the default cursor after the last one
+func (wm *WM) endLaunch(id string) error {
+	if !wm.launching[id] {
+		return nil
+	}
+	delete(wm.launching, id)
+	if err := wm.xc.SendStartupInfo(startupMessage("remove", "ID", id)); err != nil {
+		logger.Warnf("Failed to announce the end of launch %s: %v", id, err)
+	}
+	return wm.setRootCursor()
+}
+
+// rootCursor returns the shape of the cursor over the root window: the busy one while a launch is awaited
+func (wm *WM) rootCursor() uint16 {
+	if len(wm.launching) > 0 {
+		return x11.CursorWatch
+	}
+	return x11.CursorLeftPtr
+}
+
+// setRootCursor shows the cursor of rootCursor over the root window, unless a resize cursor is set on it
+// for now, which is replaced by it once reset
+func (wm *WM) setRootCursor() error {
+	root := wm.xc.GetRootWindow()
+	if c := wm.pointerCursor; c != nil && c.win == root {
+		return nil
+	}
+	cursor, err := wm.xc.Cursor(wm.rootCursor())
+	if err != nil {
+		return err
+	}
+	return wm.xc.SetCursor(root, cursor)
+}
+
+// startupMessage formats a message of the startup notification protocol of the given type with the pairs
+// of keys and values, the empty values left out
+func startupMessage(typ string, pairs ...string) string {
+	var b strings.Builder
+	b.WriteString(typ + ":")
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			continue
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(pairs[i+1])
+		fmt.Fprintf(&b, ` %s="%s"`, pairs[i], value)
+	}
+	return b.String()
+}
+
+// launcherLists returns the desktop entries of the applications and the executables in $PATH, from the cache
+// file unless one of their directories changed since it was written, in which case they're read again and
+// the cache is updated
+func launcherLists() ([]desktopEntry, []string) {
+	appDirs, pathDirs := applicationDirs(), filepath.SplitList(os.Getenv("PATH"))
+	stamps := dirStamps(appDirs, pathDirs)
+	path, err := xdg.CacheFile(launcherCacheFile)
+	if err != nil {
+		logger.Warnf("Failed to locate the launcher cache: %v", err)
+		return desktopEntries(appDirs), pathExecutables(os.Getenv("PATH"))
+	}
+	cache, err := loadLauncherCache(path)
+	if err != nil {
+		logger.Warnf("Failed to load the launcher cache: %v", err)
+	}
+	if cache != nil && reflect.DeepEqual(cache.Stamps, stamps) {
+		return cache.Entries, cache.Executables
+	}
+	cache = &launcherCache{
+		Stamps:      stamps,
+		Entries:     desktopEntries(appDirs),
+		Executables: pathExecutables(os.Getenv("PATH")),
+	}
+	if err := saveLauncherCache(path, cache); err != nil {
+		logger.Warnf("Failed to save the launcher cache: %v", err)
+	}
+	return cache.Entries, cache.Executables
+}
+
+// dirStamps returns the modification times of the existing directories of the desktop entries and of the
+// executables, which change as files are added or removed, along with the times of the desktop entries
+// themselves, which are edited in place
+func dirStamps(appDirs, pathDirs []string) map[string]int64 {
+	stamps := make(map[string]int64)
+	for _, dir := range append(appDirs, pathDirs...) {
+		if fi, err := os.Stat(dir); err == nil {
+			stamps[dir] = fi.ModTime().UnixNano()
+		}
+	}
+	for _, dir := range appDirs {
+		files, _ := ioutil.ReadDir(dir)
+		for _, fi := range files {
+			if strings.HasSuffix(fi.Name(), ".desktop") {
+				stamps[filepath.Join(dir, fi.Name())] = fi.ModTime().UnixNano()
+			}
+		}
+	}
+	return stamps
+}
+
+// saveLauncherCache writes the lists of the launcher to the cache file
+func saveLauncherCache(path string, cache *launcherCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// loadLauncherCache reads the lists of the launcher from the cache file, none if it doesn't exist yet
+func loadLauncherCache(path string) (*launcherCache, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cache launcherCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &cache, nil
+}
+
+// launcherItems returns the programs and their prompt entries: first the applications of the desktop entries
+// shown in menus, by their names, then the executables
+func launcherItems(entries []desktopEntry, executables []string) ([]launcherItem, []promptItem) {
+	var programs []launcherItem
+	var items []promptItem
+	for _, e := range entries {
+		// there's no terminal to run the terminal applications in
+		if e.Type != "Application" || e.Name == "" || e.NoDisplay || e.Terminal || !e.shouldStart() {
+			continue
+		}
+		command := execCommand(e.Exec)
+		programs = append(programs, launcherItem{command: command, notify: e.StartupNotify, name: e.Name, icon: e.Icon})
+		items = append(items, promptItem{label: e.Name, text: e.Name + " " + command})
+	}
+	for _, name := range executables {
+		programs = append(programs, launcherItem{command: name})
+		items = append(items, promptItem{label: name, text: name})
+	}
+	return programs, items
+}
+
+// applicationDirs returns the XDG directories of the desktop entries of the applications, the most
+// important one first
+func applicationDirs() []string {
+	var dirs []string
+	if home := xdg.DataHome(); home != "" {
+		dirs = append(dirs, filepath.Join(home, "applications"))
+	}
+	for _, dir := range xdg.DataDirs() {
+		dirs = append(dirs, filepath.Join(dir, "applications"))
+	}
+	return dirs
+}
+
+// pathExecutables returns the sorted names of the executable files in the directories of the list,
+// e.g. $PATH
+func pathExecutables(path string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(path) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range files {
+			name := fi.Name()
+			if seen[name] {
+				continue
+			}
+			if fi.Mode()&os.ModeSymlink != 0 {
+				// the mode of the file the link points at
+				if fi, err = os.Stat(filepath.Join(dir, name)); err != nil {
+					continue
+				}
+			}
+			if fi.IsDir() || fi.Mode()&0111 == 0 {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// startupID returns a startup notification ID unique to the launch, ending with the time of the user action
+// that launched the program so that its window gets the focus, see startupTime
+func startupID(pid int, n uint64, t uint32) string {
+	return fmt.Sprintf("marwind-%d-%d_TIME%d", pid, n, t)
+}
@@ -0,0 +1,142 @@
+package wm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLauncherItems(t *testing.T) {
+	entries := []desktopEntry{
+		{Type: "Application", Name: "Firefox", Exec: "firefox %u", Icon: "firefox", StartupNotify: true},
+		{Type: "Application", Name: "Hidden", Exec: "hidden", NoDisplay: true},
+		{Type: "Application", Name: "htop", Exec: "htop", Terminal: true},
+		{Type: "Link", Name: "Website", Exec: "xdg-open"},
+		{Type: "Application", Name: "Elsewhere", Exec: "elsewhere", OnlyShowIn: []string{"KDE"}},
+	}
+	programs, items := launcherItems(entries, []string{"alacritty", "firefox"})
+	wantPrograms := []launcherItem{{"firefox", true, "Firefox", "firefox"}, {"alacritty", false, "", ""}, {"firefox", false, "", ""}}
+	wantItems := []promptItem{{"Firefox", "Firefox firefox"}, {"alacritty", "alacritty"}, {"firefox", "firefox"}}
+	if !reflect.DeepEqual(programs, wantPrograms) {
+		t.Errorf("launcherItems() programs got = %v, want = %v", programs, wantPrograms)
+	}
+	if !reflect.DeepEqual(items, wantItems) {
+		t.Errorf("launcherItems() items got = %v, want = %v", items, wantItems)
+	}
+}
+
+func TestPathExecutables(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	files := map[string]os.FileMode{
+		filepath.Join(first, "vim"):        0755,
+		filepath.Join(first, "README"):     0644,
+		filepath.Join(second, "vim"):       0755,
+		filepath.Join(second, "alacritty"): 0755,
+	}
+	for path, mode := range files {
+		if err := ioutil.WriteFile(path, nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(first, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(second, "alacritty"), filepath.Join(first, "terminal")); err != nil {
+		t.Fatal(err)
+	}
+	path := first + string(filepath.ListSeparator) + "/nonexistent" + string(filepath.ListSeparator) + second
+	want := []string{"alacritty", "terminal", "vim"}
+	if got := pathExecutables(path); !reflect.DeepEqual(got, want) {
+		t.Errorf("pathExecutables() got = %v, want = %v", got, want)
+	}
+}
+
+func TestStartupID(t *testing.T) {
+	id := startupID(1234, 5, 987654)
+	if id != "marwind-1234-5_TIME987654" {
+		t.Errorf("startupID() got = %v", id)
+	}
+	if got, ok := startupTime(id); !ok || got != 987654 {
+		t.Errorf("startupTime() got = (%v, %v), want = (987654, true)", got, ok)
+	}
+}
+
+func TestStartupMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   string
+		pairs []string
+		want  string
+	}{
+		{"remove", "remove", []string{"ID", "marwind-1-2_TIME3"}, `remove: ID="marwind-1-2_TIME3"`},
+		{"empty values", "new", []string{"ID", "a", "ICON", "", "BIN", "vim"}, `new: ID="a" BIN="vim"`},
+		{"escaped", "new", []string{"NAME", `say "hi" \o/`}, `new: NAME="say \"hi\" \\o/"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startupMessage(tt.typ, tt.pairs...); got != tt.want {
+				t.Errorf("startupMessage() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirStamps(t *testing.T) {
+	apps, bin := t.TempDir(), t.TempDir()
+	entry := filepath.Join(apps, "vim.desktop")
+	if err := ioutil.WriteFile(entry, []byte("[Desktop Entry]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stamps := dirStamps([]string{apps, "/nonexistent"}, []string{bin})
+	if len(stamps) != 3 {
+		t.Fatalf("dirStamps() got = %v, want the stamps of 2 directories and 1 entry", stamps)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(entry, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if got := dirStamps([]string{apps, "/nonexistent"}, []string{bin}); reflect.DeepEqual(got, stamps) {
+		t.Errorf("dirStamps() of an edited entry got = %v, want a change", got)
+	}
+}
+
+func TestLauncherCache(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		cache, err := loadLauncherCache(filepath.Join(t.TempDir(), "launcher.json"))
+		if err != nil || cache != nil {
+			t.Errorf("got = %v, %v, want = nil, nil", cache, err)
+		}
+	})
+
+	t.Run("saved", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "launcher.json")
+		want := &launcherCache{
+			Stamps:      map[string]int64{"/usr/share/applications": 1, "/usr/bin": 2},
+			Entries:     []desktopEntry{{Type: "Application", Name: "Firefox", Exec: "firefox %u", StartupNotify: true}},
+			Executables: []string{"alacritty", "vim"},
+		}
+		if err := saveLauncherCache(path, want); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := loadLauncherCache(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "launcher.json")
+		if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadLauncherCache(path); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package wm
+
+// The tiled columns and frames don't store their sizes in pixels but weights, i.e. their shares of the space
+// relative to their siblings. The lengths are only computed when rendering, so that changes of the output size,
+// docks or gaps all rescale the layout consistently
+
+// splitLength divides the available length into parts proportional to the weights. The rounding error
+// goes to the last part so that the parts always add up to the available length
+func splitLength(weights []float64, available uint16) []uint16 {
+	lengths := make([]uint16, len(weights))
+	if len(weights) == 0 {
+		return lengths
+	}
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	left := available
+	for i, w := range weights[:len(weights)-1] {
+		l := available / uint16(len(weights))
+		if total > 0 {
+			l = uint16(float64(available) * w / total)
+		}
+		if l > left {
+			l = left
+		}
+		lengths[i] = l
+		left -= l
+	}
+	lengths[len(lengths)-1] = left
+	return lengths
+}
+
+// newWeight returns the weight for an element added to the ones with the given weights, giving it
+// an equal share of the space while the others keep their proportions
+func newWeight(weights []float64) float64 {
+	if len(weights) == 0 {
+		return 1
+	}
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return 1
+	}
+	return total / float64(len(weights))
+}
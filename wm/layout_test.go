@@ -0,0 +1,48 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		weights   []float64
+		available uint16
+		want      []uint16
+	}{
+		{"equal weights", []float64{1, 1, 1}, 900, []uint16{300, 300, 300}},
+		{"proportional", []float64{1, 3}, 1000, []uint16{250, 750}},
+		{"pixel weights rescaled", []float64{300, 600}, 600, []uint16{200, 400}},
+		{"remainder to the last", []float64{1, 1, 1}, 100, []uint16{33, 33, 34}},
+		{"zero weights", []float64{0, 0}, 100, []uint16{50, 50}},
+		{"no weights", nil, 100, []uint16{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitLength(tt.weights, tt.available); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitLength() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []float64
+		want    float64
+	}{
+		{"first", nil, 1},
+		{"average of others", []float64{200, 400}, 300},
+		{"zero total", []float64{0}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newWeight(tt.weights); got != tt.want {
+				t.Errorf("newWeight() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
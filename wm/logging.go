@@ -0,0 +1,81 @@
+package wm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/xdg"
+)
+
+// applyLogging sets up the level and output of the log messages as configured. The runtime changes
+// made with the log command are overridden
+func applyLogging(cfg Config) error {
+	level := logger.LevelInfo
+	if cfg.LogLevel != "" {
+		l, err := logger.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			return err
+		}
+		level = l
+	}
+	logger.SetLevel(level)
+	logger.SetEvents(cfg.LogEvents)
+	return setLogOutput(cfg.LogFile)
+}
+
+// setLogOutput sends the log messages to the standard error, the journal or a file. A relative path of
+// the file is relative to the directory of the WM in $XDG_STATE_HOME (~/.local/state/marwind)
+func setLogOutput(name string) error {
+	switch name {
+	case "", "stderr", logger.Journal:
+		return logger.SetOutput(name)
+	}
+	path := xdg.ExpandHome(name)
+	if !filepath.IsAbs(path) {
+		var err error
+		if path, err = xdg.StateFile(path); err != nil {
+			return fmt.Errorf("failed to create the log directory: %v", err)
+		}
+	}
+	return logger.SetOutput(path)
+}
+
+// cmdLog changes the logging at runtime:
+// log level <debug|info|warn|error>, log events [enable|disable|toggle] or log output <stderr|journal|path>
+func cmdLog(wm *WM, args []string) error {
+	usage := fmt.Errorf("usage: log level <debug|info|warn|error>, log events [enable|disable|toggle] or log output <stderr|journal|path>")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "level":
+		if len(args) != 2 {
+			return usage
+		}
+		level, err := logger.ParseLevel(args[1])
+		if err != nil {
+			return err
+		}
+		logger.SetLevel(level)
+	case "events":
+		enable, err := parseToggle(args[1:], logger.Events())
+		if err != nil {
+			return fmt.Errorf("log events: %v", err)
+		}
+		if enable && logger.GetLevel() > logger.LevelDebug {
+			logger.SetLevel(logger.LevelDebug)
+		}
+		logger.SetEvents(enable)
+	case "output":
+		if len(args) < 2 {
+			return usage
+		}
+		// the path of the file may contain spaces
+		return setLogOutput(strings.Join(args[1:], " "))
+	default:
+		return usage
+	}
+	return nil
+}
@@ -9,56 +9,189 @@ import (
 )
 
 func (wm *WM) manageWindow(win xproto.Window) error {
-	typ, err := wm.getWindowType(win)
+	typeAtoms, err := wm.getWindowTypeAtoms(win)
 	if err != nil {
 		return fmt.Errorf("failed to get window type: %v", err)
 	}
+	typ, role := wm.getWindowType(typeAtoms)
+	rule := wm.matchRules(win, typeAtoms)
+	if rule.Ignore {
+		return wm.xc.MapWindow(win)
+	}
 	mask := uint32(xproto.EventMaskStructureNotify | xproto.EventMaskEnterWindow | xproto.EventMaskPropertyChange)
 	cookie := xproto.ChangeWindowAttributesChecked(wm.xc.X(), win, xproto.CwEventMask, []uint32{mask})
 	if err := cookie.Check(); err != nil {
 		return fmt.Errorf("failed to change window attributes: %v", err)
 	}
-	f, err := wm.createFrame(win, typ)
+	f, err := wm.createFrame(win, typ, role)
 	if err != nil {
 		return fmt.Errorf("failed to frame the window: %v", err)
 	}
+	f.cli.SetTitlebarHidden(rule.NoTitlebar)
+	if rule.Opacity > 0 {
+		if err := f.cli.SetOpacity(rule.Opacity); err != nil {
+			return fmt.Errorf("failed to set the opacity: %v", err)
+		}
+	}
+	if err := wm.grabFocusClick(f); err != nil {
+		return fmt.Errorf("failed to grab the pointer buttons: %v", err)
+	}
+	if err := wm.xc.AddClient(win); err != nil {
+		return fmt.Errorf("failed to update the client list: %v", err)
+	}
 	switch f.cli.Type() {
 	case client.TypeNormal:
-		ws := wm.outputs[0].activeWs
-		if err := ws.addFrame(f); err != nil {
+		ws := wm.currentOutput().activeWs
+		if rule.Workspace != "" {
+			if ws, err = wm.ensureWorkspace(rule.Workspace); err != nil {
+				return fmt.Errorf("failed to assign the window to workspace %q: %v", rule.Workspace, err)
+			}
+		}
+		parent, transient := wm.transientParent(win)
+		if parent != nil {
+			ws = parent.workspace()
+			f.transientFor = parent
+		}
+		if transient {
+			geom := wm.initialFloatingGeom(f, ws)
+			if g, ok := wm.rememberedFloatingGeom(f, ws); ok {
+				geom = g
+			} else if parent != nil {
+				geom = centerOver(parent.cli.Geom(), geom.W, geom.H)
+			}
+			if err := ws.addFloatingFrame(f, geom); err != nil {
+				return fmt.Errorf("failed to add floating frame: %v", err)
+			}
+		} else if rule.Floating || role.Floating() {
+			geom := wm.initialFloatingGeom(f, ws)
+			if role == client.RoleNotification {
+				ws, geom = wm.notificationWorkspace(ws, geom)
+				wm.queueNotification(f, ws)
+			} else if g, ok := wm.rememberedFloatingGeom(f, ws); ok {
+				geom = g
+			}
+			if err := ws.addFloatingFrame(f, geom); err != nil {
+				return fmt.Errorf("failed to add floating frame: %v", err)
+			}
+		} else if ph := wm.matchingPlaceholder(win); ph != nil {
+			ws = ph.workspace()
+			if err := wm.fillPlaceholder(ph, f); err != nil {
+				return fmt.Errorf("failed to fill the placeholder: %v", err)
+			}
+		} else if term := wm.swallowingTerminal(win, ws); term != nil {
+			if err := wm.swallow(term, f); err != nil {
+				return fmt.Errorf("failed to swallow the terminal: %v", err)
+			}
+		} else if err := wm.placeNewFrame(f, ws); err != nil {
 			return fmt.Errorf("failed to add frame: %v", err)
 		}
+		if err := wm.applyInitialState(f); err != nil {
+			return fmt.Errorf("failed to apply initial window state: %v", err)
+		}
 		if err := wm.renderWorkspace(ws); err != nil {
 			return fmt.Errorf("failed to render workspace: %v", err)
 		}
+		wm.initUserTime(f)
+		if err := wm.endLaunch(wm.xc.GetStartupID(win)); err != nil {
+			return fmt.Errorf("failed to end the launch: %v", err)
+		}
+		if err := wm.focusNewFrame(f); err != nil {
+			return fmt.Errorf("failed to focus the window: %v", err)
+		}
 	case client.TypeDock:
-		if err := wm.outputs[0].addDock(f); err != nil {
+		if err := wm.addDock(f, rule); err != nil {
 			return fmt.Errorf("failed to add dock: %v", err)
 		}
-		if err := wm.renderOutput(wm.outputs[0]); err != nil {
+	case client.TypeDesktop:
+		o := wm.outputForWindow(win)
+		if err := o.addDesktop(f); err != nil {
+			return fmt.Errorf("failed to add desktop: %v", err)
+		}
+		if err := wm.renderOutput(o); err != nil {
 			return fmt.Errorf("failed to render output: %v", err)
 		}
 	}
+	wm.emitWindowEvent("new", f)
 	return nil
 }
 
-func (wm *WM) getWindowType(win xproto.Window) (client.Type, error) {
+// getWindowTypeAtoms returns the list of atoms set in the window's _NET_WM_WINDOW_TYPE property
+func (wm *WM) getWindowTypeAtoms(win xproto.Window) ([]xproto.Atom, error) {
 	typeAtom := wm.xc.Atom("_NET_WM_WINDOW_TYPE")
-	dockTypeAtom := wm.xc.Atom("_NET_WM_WINDOW_TYPE_DOCK")
-	normalTypeAtom := wm.xc.Atom("_NET_WM_WINDOW_TYPE_NORMAL")
 	prop, err := xproto.GetProperty(wm.xc.X(), false, win, typeAtom, xproto.GetPropertyTypeAny, 0, 64).Reply()
 	if err != nil {
-		return client.TypeUnknown, err
+		return nil, err
 	}
+	var atoms []xproto.Atom
 	if prop != nil {
 		for v := prop.Value; len(v) >= 4; v = v[4:] {
-			switch xproto.Atom(uint32(v[0]) | uint32(v[1])<<8 | uint32(v[2])<<16 | uint32(v[3])<<24) {
-			case dockTypeAtom:
-				return client.TypeDock, nil
-			case normalTypeAtom:
-				return client.TypeNormal, nil
+			atoms = append(atoms, xproto.Atom(uint32(v[0])|uint32(v[1])<<8|uint32(v[2])<<16|uint32(v[3])<<24))
+		}
+	}
+	return atoms, nil
+}
+
+// windowRoles maps the suffixes of the _NET_WM_WINDOW_TYPE atoms of normal windows to their roles
+var windowRoles = map[string]client.Role{
+	"NORMAL":        client.RoleNormal,
+	"DIALOG":        client.RoleDialog,
+	"UTILITY":       client.RoleUtility,
+	"TOOLBAR":       client.RoleUtility,
+	"SPLASH":        client.RoleSplash,
+	"MENU":          client.RoleMenu,
+	"DROPDOWN_MENU": client.RoleMenu,
+	"POPUP_MENU":    client.RoleMenu,
+	"TOOLTIP":       client.RoleTooltip,
+	"NOTIFICATION":  client.RoleNotification,
+}
+
+// getWindowType returns how the window with the given _NET_WM_WINDOW_TYPE is managed. The atoms are listed
+// in the order of preference, the first known one wins and windows without any are normal
+func (wm *WM) getWindowType(typeAtoms []xproto.Atom) (client.Type, client.Role) {
+	for _, atom := range typeAtoms {
+		switch atom {
+		case wm.xc.Atom("_NET_WM_WINDOW_TYPE_DOCK"):
+			return client.TypeDock, client.RoleNormal
+		case wm.xc.Atom("_NET_WM_WINDOW_TYPE_DESKTOP"):
+			return client.TypeDesktop, client.RoleNormal
+		}
+		for name, role := range windowRoles {
+			if atom == wm.xc.Atom("_NET_WM_WINDOW_TYPE_"+name) {
+				return client.TypeNormal, role
 			}
 		}
 	}
-	return client.TypeNormal, nil
+	return client.TypeNormal, client.RoleNormal
+}
+
+// transientParent checks whether the window is transient (WM_TRANSIENT_FOR) and returns the frame of the window
+// it's transient for. The frame is nil if that window is not managed, e.g. for transients of the root window
+func (wm *WM) transientParent(win xproto.Window) (*frame, bool) {
+	parent, err := wm.xc.GetTransientFor(win)
+	if err != nil || parent == 0 || parent == win {
+		return nil, false
+	}
+	f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == parent && frm.cli.Type() == client.TypeNormal })
+	return f, true
+}
+
+// applyInitialState honors the _NET_WM_STATE set by the client before the window was mapped
+func (wm *WM) applyInitialState(f *frame) error {
+	// the property is usually not set
+	states, _ := wm.xc.GetWindowState(f.cli.Window())
+	for _, state := range states {
+		if state == wm.xc.Atom("_NET_WM_STATE_FULLSCREEN") {
+			if err := wm.setFullscreen(f, true); err != nil {
+				return err
+			}
+		} else if flag := wm.stateFlag(f, state); flag != nil {
+			*flag = true
+		}
+	}
+	f.state.restoreUrgencyHint(wm.xc.GetUrgencyHint(f.cli.Window()))
+	if f.state.hidden {
+		// e.g. a window minimized before the WM was restarted
+		wm.minimized = append(wm.minimized, f)
+	}
+	return wm.applyFrameState(f)
 }
@@ -0,0 +1,122 @@
+package wm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A mark is a name given to a window, e.g. by a mark mode bound to the letters, so that the focus can be
+// brought back to it later on with "focus mark <name>", whichever workspace the window is on. Each name
+// marks a single window, while a window can have any number of marks
+
+// markFrame gives the mark to the frame, taking it away from the window that had it until now
+func (wm *WM) markFrame(f *frame, name string) error {
+	if f.hasMark(name) {
+		return nil
+	}
+	if prev := wm.markedFrame(name); prev != nil {
+		if err := wm.unmarkFrame(prev, name); err != nil {
+			return err
+		}
+	}
+	f.marks = append(f.marks, name)
+	wm.emitWindowEvent("mark", f)
+	return wm.drawMarks(f)
+}
+
+// unmarkFrame removes the mark from the frame, or all of its marks if the name is empty
+func (wm *WM) unmarkFrame(f *frame, name string) error {
+	var marks []string
+	for _, m := range f.marks {
+		if name != "" && m != name {
+			marks = append(marks, m)
+		}
+	}
+	if len(marks) == len(f.marks) {
+		return nil
+	}
+	f.marks = marks
+	wm.emitWindowEvent("mark", f)
+	return wm.drawMarks(f)
+}
+
+// drawMarks shows the marks of the frame in its titlebar, if enabled
+func (wm *WM) drawMarks(f *frame) error {
+	if !wm.config.TitleBarShowMarks {
+		return f.cli.SetMarks(nil)
+	}
+	return f.cli.SetMarks(f.marks)
+}
+
+// markedFrame returns the frame with the given mark, including the hidden scratchpad frames, or nil if there's none
+func (wm *WM) markedFrame(name string) *frame {
+	frames := append(wm.allFrames(), wm.scratchpad...)
+	for _, f := range frames {
+		if f.hasMark(name) {
+			return f
+		}
+	}
+	return nil
+}
+
+func (f *frame) hasMark(name string) bool {
+	for _, m := range f.marks {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// markList returns the marks of all the windows in alphabetical order
+func (wm *WM) markList() []string {
+	marks := []string{}
+	for _, f := range append(wm.allFrames(), wm.scratchpad...) {
+		marks = append(marks, f.marks...)
+	}
+	sort.Strings(marks)
+	return marks
+}
+
+// focusMark focuses the window with the given mark, switching to its workspace or showing it
+// from the scratchpad if needed
+func (wm *WM) focusMark(name string) error {
+	f := wm.markedFrame(name)
+	if f == nil {
+		return fmt.Errorf("no window is marked %q", name)
+	}
+	if f.scratchpad && f.workspace() == nil {
+		return wm.showScratchpadFrame(wm.currentOutput().activeWs, f)
+	}
+	return wm.activateFrame(f)
+}
+
+// cmdMark gives a mark to the focused window: mark <name>
+func cmdMark(wm *WM, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: mark <name>")
+	}
+	f := wm.targetFrame()
+	if f == nil {
+		return nil
+	}
+	return wm.markFrame(f, args[0])
+}
+
+// cmdUnmark removes a mark from the window that has it or, without a name, all the marks of the focused window:
+// unmark [<name>]
+func cmdUnmark(wm *WM, args []string) error {
+	switch len(args) {
+	case 0:
+		if f := wm.targetFrame(); f != nil {
+			return wm.unmarkFrame(f, "")
+		}
+		return nil
+	case 1:
+		if f := wm.markedFrame(args[0]); f != nil {
+			return wm.unmarkFrame(f, args[0])
+		}
+		return nil
+	}
+	return fmt.Errorf("usage: unmark [<name>]")
+}
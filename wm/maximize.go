@@ -0,0 +1,114 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// setMaximized gives the tiled frame the whole tiling area of its workspace, covering the other tiled frames,
+// or brings back the layout. The frame keeps its place in the tree, and the layout comes back as well when
+// another tiled frame of the workspace is focused
+func (wm *WM) setMaximized(f *frame, enable bool) error {
+	ws := f.workspace()
+	if ws == nil || f.col == nil {
+		return fmt.Errorf("only tiled windows can be maximized")
+	}
+	if (ws.maximized == f) == enable {
+		return nil
+	}
+	if enable {
+		ws.maximized = f
+	} else {
+		ws.maximized = nil
+	}
+	if ws.output == nil || ws.output.activeWs != ws {
+		return nil
+	}
+	// the decorations depend on whether the frame is alone with smart borders
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(f)
+}
+
+// maximizedFrame returns the maximized frame of the workspace if it's still a visible tiled frame of it
+func (ws *workspace) maximizedFrame() *frame {
+	f := ws.maximized
+	if f == nil || f.col == nil || f.workspace() != ws || f.state.hidden || f.fullscreen {
+		return nil
+	}
+	return f
+}
+
+// covered returns true if the frame is a tiled frame of the workspace hidden behind its maximized frame
+func (ws *workspace) covered(f *frame) bool {
+	m := ws.maximizedFrame()
+	return m != nil && m != f && !ws.monocle && f.col != nil && f.workspace() == ws && !f.fullscreen
+}
+
+// showUncovered unmaps the tiled frames of the shown workspace covered by its maximized frame, so that they
+// don't show through its gaps, and maps them again once they aren't covered anymore
+func (ws *workspace) showUncovered() error {
+	if ws.output == nil || ws.output.activeWs != ws {
+		return nil
+	}
+	var err error
+	for _, col := range ws.columns {
+		for _, f := range col.leaves() {
+			if f.state.hidden || f.queued {
+				continue
+			}
+			var e error
+			if covered := ws.covered(f); covered && f.cli.Mapped() {
+				e = f.cli.Unmap()
+			} else if !covered && !f.cli.Mapped() {
+				e = f.cli.Map()
+			}
+			if e != nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// renderMaximized lays out the tiled frames as usual, then stretches the maximized one over the tiling area
+// above them, the way a single frame of the workspace is rendered
+func (wm *WM) renderMaximized(ws *workspace, f *frame) error {
+	err := wm.renderColumns(ws)
+	if e := wm.renderFrame(f, wm.wholeArea(ws)); e != nil {
+		return e
+	}
+	wm.markRaised(f)
+	return err
+}
+
+// wholeArea returns the geometry of a tiled frame taking the whole workspace, without the gaps with smart gaps enabled
+func (wm *WM) wholeArea(ws *workspace) client.Geom {
+	if wm.config.SmartGaps {
+		return ws.fullArea()
+	}
+	a := ws.area()
+	gap := ws.innerGap()
+	return client.Geom{X: a.X + int16(gap), Y: a.Y + int16(gap), W: a.W - gap*2, H: a.H - gap*2}
+}
+
+// cmdMaximize gives the focused tiled window the whole workspace until it's toggled off or another tiled
+// window is focused: maximize <enable|disable|toggle>
+func cmdMaximize(wm *WM, args []string) error {
+	f := wm.targetFrame()
+	if f == nil {
+		return nil
+	}
+	ws := f.workspace()
+	maximized := ws != nil && ws.maximizedFrame() == f
+	enable, err := parseToggle(args, maximized)
+	if err != nil {
+		return fmt.Errorf("maximize: %v", err)
+	}
+	if enable == maximized {
+		return nil
+	}
+	return handleToggleMaximize(wm)
+}
@@ -0,0 +1,84 @@
+package wm
+
+import (
+	"testing"
+)
+
+func TestMaximizedFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(ws *workspace, f *frame)
+		want   bool
+	}{
+		{"maximized", func(ws *workspace, f *frame) {}, true},
+		{"minimized", func(ws *workspace, f *frame) { f.state.hidden = true }, false},
+		{"fullscreen", func(ws *workspace, f *frame) { f.fullscreen = true }, false},
+		{"removed", func(ws *workspace, f *frame) { ws.deleteFrame(f) }, false},
+		{"swapped away", func(ws *workspace, f *frame) {
+			other := newWorkspace("2", workspaceConfig{})
+			other.output = &output{}
+			frm := &frame{}
+			if err := other.addFrame(frm); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			swapFrames(f, frm)
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newWorkspace("1", workspaceConfig{})
+			ws.output = &output{}
+			frames := []*frame{{}, {}}
+			for _, f := range frames {
+				if err := ws.addFrame(f); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			ws.maximized = frames[1]
+			tt.change(ws, frames[1])
+			if got := ws.maximizedFrame() == frames[1]; got != tt.want {
+				t.Errorf("maximized got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCovered(t *testing.T) {
+	ws := newWorkspace("1", workspaceConfig{})
+	ws.output = &output{}
+	frames := []*frame{{}, {}, {}}
+	for _, f := range frames {
+		if err := ws.addFrame(f); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	floating := &frame{floating: true, ws: ws}
+	ws.floating = append(ws.floating, floating)
+	frames[2].fullscreen = true
+	ws.maximized = frames[0]
+	tests := []struct {
+		name string
+		f    *frame
+		want bool
+	}{
+		{"maximized", frames[0], false},
+		{"sibling", frames[1], true},
+		{"fullscreen", frames[2], false},
+		{"floating", floating, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ws.covered(tt.f); got != tt.want {
+				t.Errorf("covered() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+	ws.monocle = true
+	if ws.covered(frames[1]) {
+		t.Errorf("covered() in the monocle layout got = true, want = false")
+	}
+	ws.monocle, ws.maximized = false, nil
+	if ws.covered(frames[1]) {
+		t.Errorf("covered() without a maximized frame got = true, want = false")
+	}
+}
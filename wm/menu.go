@@ -0,0 +1,243 @@
+package wm
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
+)
+
+// menu is a popup window drawn by the WM listing actions for a frame, one per row. While it's open the
+// pointer and keyboard are grabbed: releasing a button over an entry runs its command, while pressing
+// a button outside of the menu or any key closes it
+type menu struct {
+	win      xproto.Window
+	geom     client.Geom
+	items    []menuItem
+	target   *frame
+	selected int // index of the entry under the pointer, -1 for none
+
+	// armed is set once the pointer moved or a button was pressed within the menu, so that releasing
+	// the button that opened the menu doesn't pick the entry that happens to be under the pointer
+	armed bool
+}
+
+// menuItem is an entry of the menu running a command for the frame the menu was opened for
+type menuItem struct {
+	label   string
+	command string
+}
+
+const (
+	menuMinWidth   = 150
+	menuPadding    = 8 // horizontal space around the labels
+	menuItemHeight = 20
+)
+
+// frameMenuItems returns the entries of the titlebar menu of the frame
+func (wm *WM) frameMenuItems(f *frame) []menuItem {
+	items := []menuItem{{"Close", "kill"}}
+	if f.floating {
+		items = append(items, menuItem{"Tile", "floating disable"})
+	} else {
+		items = append(items, menuItem{"Float", "floating enable"})
+	}
+	if f.fullscreen {
+		items = append(items, menuItem{"Exit fullscreen", "fullscreen disable"})
+	} else {
+		items = append(items, menuItem{"Fullscreen", "fullscreen enable"})
+	}
+	var names []string
+	for _, ws := range wm.workspaces {
+		if ws != f.workspace() {
+			names = append(names, ws.name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return workspaceLess(names[i], names[j]) })
+	for _, name := range names {
+		items = append(items, menuItem{"Move to workspace " + name, "move to workspace " + name})
+	}
+	return items
+}
+
+// openMenu shows the menu of the frame with its top left corner at the given position, moved if needed
+// so that it fits on the output
+func (wm *WM) openMenu(f *frame, x, y int16, t xproto.Timestamp) error {
+	if wm.menu != nil || wm.drag != nil {
+		return nil
+	}
+	items := wm.frameMenuItems(f)
+	w := menuMinWidth
+	for _, item := range items {
+		if ew, _ := wm.windowConfig.Fonts.Extents(wm.config.TitleBarFontSize, item.label); ew+2*menuPadding > w {
+			w = ew + 2*menuPadding
+		}
+	}
+	geom := client.Geom{X: x, Y: y, W: uint16(w), H: uint16(len(items) * wm.menuItemHeight())}
+	if o := wm.outputAt(x, y); o != nil {
+		geom = fitGeom(geom, o.geom)
+	}
+	win, err := wm.xc.CreateWindow(wm.xc.GetRootWindow(), geom.X, geom.Y, geom.W, geom.H, 0,
+		xproto.WindowClassInputOutput, xproto.CwBackPixel|xproto.CwOverrideRedirect|xproto.CwEventMask,
+		[]uint32{wm.windowConfig.BgColorInactive, 1, xproto.EventMaskExposure})
+	if err != nil {
+		return err
+	}
+	m := &menu{win: win, geom: geom, items: items, target: f, selected: -1}
+	if err := wm.xc.MapWindow(win); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return err
+	}
+	if err := wm.grabMenuInput(t); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return err
+	}
+	wm.menu = m
+	return wm.drawMenu()
+}
+
+// grabMenuInput redirects all the pointer and keyboard events to the WM while the menu is open
+func (wm *WM) grabMenuInput(t xproto.Timestamp) error {
+	reply, err := xproto.GrabPointer(
+		wm.xc.X(), false, wm.xc.GetRootWindow(), dragEventMask,
+		xproto.GrabModeAsync, xproto.GrabModeAsync, xproto.WindowNone, xproto.CursorNone, t,
+	).Reply()
+	if err != nil {
+		return err
+	}
+	if reply.Status != xproto.GrabStatusSuccess {
+		return fmt.Errorf("failed to grab the pointer")
+	}
+	kb, err := xproto.GrabKeyboard(wm.xc.X(), false, wm.xc.GetRootWindow(), t,
+		xproto.GrabModeAsync, xproto.GrabModeAsync).Reply()
+	if err != nil || kb.Status != xproto.GrabStatusSuccess {
+		// the menu can still be closed with the pointer
+		logger.Warnf("Failed to grab the keyboard for the menu: %v", err)
+	}
+	return nil
+}
+
+// closeMenu destroys the menu window and releases the grabs
+func (wm *WM) closeMenu(t xproto.Timestamp) error {
+	m := wm.menu
+	if m == nil {
+		return nil
+	}
+	wm.menu = nil
+	if err := xproto.UngrabKeyboardChecked(wm.xc.X(), t).Check(); err != nil {
+		logger.Errorf("Failed to ungrab the keyboard: %v", err)
+	}
+	if err := xproto.UngrabPointerChecked(wm.xc.X(), t).Check(); err != nil {
+		logger.Errorf("Failed to ungrab the pointer: %v", err)
+	}
+	return wm.xc.DestroyWindow(m.win)
+}
+
+func (wm *WM) menuItemHeight() int {
+	if wm.config.TitleBarHeight > 0 {
+		return int(wm.config.TitleBarHeight)
+	}
+	return menuItemHeight
+}
+
+// menuItemAt returns the index of the entry at the position of the pointer relative to the root window,
+// -1 if the pointer is outside of the menu
+func (wm *WM) menuItemAt(x, y int16) int {
+	m := wm.menu
+	if x < m.geom.X || y < m.geom.Y || int(x) >= int(m.geom.X)+int(m.geom.W) || int(y) >= int(m.geom.Y)+int(m.geom.H) {
+		return -1
+	}
+	return int(y-m.geom.Y) / wm.menuItemHeight()
+}
+
+// handleMenuButtonPress closes the menu when clicking outside of it
+func (wm *WM) handleMenuButtonPress(e xproto.ButtonPressEvent) error {
+	if wm.menuItemAt(e.RootX, e.RootY) < 0 {
+		return wm.closeMenu(e.Time)
+	}
+	wm.menu.armed = true
+	return nil
+}
+
+// handleMenuMotion highlights the entry under the pointer
+func (wm *WM) handleMenuMotion(e xproto.MotionNotifyEvent) error {
+	m := wm.menu
+	i := wm.menuItemAt(e.RootX, e.RootY)
+	if i == m.selected {
+		return nil
+	}
+	m.selected = i
+	m.armed = true
+	return wm.drawMenu()
+}
+
+// handleMenuButtonRelease runs the command of the entry under the pointer
+func (wm *WM) handleMenuButtonRelease(e xproto.ButtonReleaseEvent) error {
+	m := wm.menu
+	i := wm.menuItemAt(e.RootX, e.RootY)
+	if i < 0 || !m.armed {
+		return nil
+	}
+	if err := wm.closeMenu(e.Time); err != nil {
+		return err
+	}
+	return wm.runWithCriteria(criteria{window: m.target.cli.Window()}, m.items[i].command)
+}
+
+// drawMenu paints the entries of the menu, the selected one in the colors of the focused titlebars
+func (wm *WM) drawMenu() error {
+	m := wm.menu
+	cfg := wm.windowConfig
+	h := wm.menuItemHeight()
+	img := wm.xc.NewImage(image.Rect(0, 0, int(m.geom.W), int(m.geom.H)))
+	defer img.Destroy()
+	for i, item := range m.items {
+		bg, fg := rgba(cfg.BgColorInactive), rgba(cfg.FontColorInactive)
+		if i == m.selected {
+			bg, fg = rgba(cfg.BgColor), rgba(cfg.FontColor)
+		}
+		row := image.Rect(0, i*h, int(m.geom.W), (i+1)*h)
+		for y := row.Min.Y; y < row.Max.Y; y++ {
+			for x := row.Min.X; x < row.Max.X; x++ {
+				img.SetBGRA(x, y, xgraphics.BGRA{B: bg.B, G: bg.G, R: bg.R, A: bg.A})
+			}
+		}
+		_, eh := cfg.Fonts.Extents(cfg.FontSize, item.label)
+		if _, err := cfg.Fonts.Draw(img, menuPadding, row.Min.Y+(h-eh)/2, fg, cfg.FontSize, item.label); err != nil {
+			return err
+		}
+	}
+	if err := img.CreatePixmap(); err != nil {
+		return err
+	}
+	img.XDraw()
+	img.XExpPaint(m.win, 0, 0)
+	return nil
+}
+
+// fitGeom moves the geometry so that it lies within the bounds, as far as its size allows
+func fitGeom(g, bounds client.Geom) client.Geom {
+	if right := int(bounds.X) + int(bounds.W); int(g.X)+int(g.W) > right {
+		g.X = int16(right - int(g.W))
+	}
+	if bottom := int(bounds.Y) + int(bounds.H); int(g.Y)+int(g.H) > bottom {
+		g.Y = int16(bottom - int(g.H))
+	}
+	if g.X < bounds.X {
+		g.X = bounds.X
+	}
+	if g.Y < bounds.Y {
+		g.Y = bounds.Y
+	}
+	return g
+}
+
+func rgba(c uint32) color.RGBA {
+	return color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: uint8(c >> 24)}
+}
@@ -0,0 +1,46 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestFitGeom(t *testing.T) {
+	bounds := client.Geom{X: 1920, Y: 0, W: 1280, H: 1024}
+	tests := []struct {
+		name string
+		g    client.Geom
+		want client.Geom
+	}{
+		{"inside", client.Geom{X: 2000, Y: 100, W: 150, H: 100}, client.Geom{X: 2000, Y: 100, W: 150, H: 100}},
+		{"past the right edge", client.Geom{X: 3150, Y: 100, W: 150, H: 100}, client.Geom{X: 3050, Y: 100, W: 150, H: 100}},
+		{"past the bottom edge", client.Geom{X: 2000, Y: 1000, W: 150, H: 100}, client.Geom{X: 2000, Y: 924, W: 150, H: 100}},
+		{"larger than the bounds", client.Geom{X: 2000, Y: 100, W: 150, H: 2000}, client.Geom{X: 2000, Y: 0, W: 150, H: 2000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitGeom(tt.g, bounds); got != tt.want {
+				t.Errorf("fitGeom() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameMenuItems(t *testing.T) {
+	wm := &WM{}
+	for _, name := range []string{"web", "2", "10", "1"} {
+		wm.workspaces = append(wm.workspaces, newWorkspace(name, workspaceConfig{}))
+	}
+	f := &frame{ws: wm.workspaces[3], floating: true}
+	var got []string
+	for _, item := range wm.frameMenuItems(f) {
+		got = append(got, item.command)
+	}
+	want := []string{"kill", "floating disable", "fullscreen enable",
+		"move to workspace 2", "move to workspace 10", "move to workspace web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("frameMenuItems() got = %v, want = %v", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+package wm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/x11"
+)
+
+// messageHandler handles a ClientMessage event of the type it's registered for
+type messageHandler func(wm *WM, e xproto.ClientMessageEvent) error
+
+// message is a handler bound to the atom of its message type, see initMessages
+type message struct {
+	name   string
+	handle messageHandler
+}
+
+// messageHandlers are keyed by the name of the message type
+var messageHandlers map[string]messageHandler
+
+func init() {
+	messageHandlers = map[string]messageHandler{
+		"_NET_CURRENT_DESKTOP":       msgCurrentDesktop,
+		"_NET_ACTIVE_WINDOW":         frameMessage(msgActiveWindow),
+		"WM_CHANGE_STATE":            frameMessage(msgChangeState),
+		"_NET_WM_STATE":              frameMessage(msgWMState),
+		"_NET_WM_MOVERESIZE":         frameMessage(msgMoveResize),
+		"_NET_MOVERESIZE_WINDOW":     msgMoveResizeWindow,
+		"_NET_CLOSE_WINDOW":          frameMessage(msgCloseWindow),
+		"_NET_REQUEST_FRAME_EXTENTS": msgRequestFrameExtents,
+		"_NET_SYSTEM_TRAY_OPCODE":    msgTrayOpcode,
+		"_MARWIND_COMMAND":           msgCommand,
+	}
+}
+
+// initMessages interns the atoms of the message types handled by the WM
+func initMessages(wm *WM) map[xproto.Atom]message {
+	messages := make(map[xproto.Atom]message, len(messageHandlers))
+	for name, handle := range messageHandlers {
+		messages[wm.xc.Atom(name)] = message{name: name, handle: handle}
+	}
+	return messages
+}
+
+// handleClientMessage passes the message on to the handler registered for its type, the others are ignored
+func (wm *WM) handleClientMessage(e xproto.ClientMessageEvent) error {
+	m, ok := wm.messages[e.Type]
+	if !ok {
+		return nil
+	}
+	if err := m.handle(wm, e); err != nil {
+		return fmt.Errorf("%s of window %d: %v", m.name, e.Window, err)
+	}
+	return nil
+}
+
+// frameMessage makes a handler of a message about a managed window, ignored for the other windows
+func frameMessage(handle func(wm *WM, f *frame, e xproto.ClientMessageEvent) error) messageHandler {
+	return func(wm *WM, e xproto.ClientMessageEvent) error {
+		f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
+		if f == nil {
+			return nil
+		}
+		return handle(wm, f, e)
+	}
+}
+
+func msgCurrentDesktop(wm *WM, e xproto.ClientMessageEvent) error {
+	workspaces := wm.desktopWorkspaces()
+	index := int(e.Data.Data32[0])
+	if index >= len(workspaces) {
+		return nil
+	}
+	return wm.switchWorkspace(workspaces[index].name)
+}
+
+func msgActiveWindow(wm *WM, f *frame, e xproto.ClientMessageEvent) error {
+	if !f.focusable() {
+		return nil
+	}
+	return wm.handleActivateMessage(f, e.Data.Data32[0])
+}
+
+// msgChangeState iconifies the window the ICCCM way
+func msgChangeState(wm *WM, f *frame, e xproto.ClientMessageEvent) error {
+	if e.Data.Data32[0] != x11.IconicState {
+		return nil
+	}
+	return wm.setMinimized(f, true)
+}
+
+func msgWMState(wm *WM, f *frame, e xproto.ClientMessageEvent) error {
+	return wm.handleStateMessage(f, e.Data.Data32)
+}
+
+func msgMoveResize(wm *WM, f *frame, e xproto.ClientMessageEvent) error {
+	return wm.handleMoveResizeMessage(f, e.Data.Data32)
+}
+
+func msgMoveResizeWindow(wm *WM, e xproto.ClientMessageEvent) error {
+	return wm.handleMoveResizeWindowMessage(e.Window, e.Data.Data32)
+}
+
+func msgCloseWindow(wm *WM, f *frame, e xproto.ClientMessageEvent) error {
+	return wm.closeWindow(f)
+}
+
+// msgRequestFrameExtents publishes the frame extents of a managed window, or the ones a window that isn't
+// mapped yet will most likely get
+func msgRequestFrameExtents(wm *WM, e xproto.ClientMessageEvent) error {
+	if f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window }); f != nil {
+		return wm.updateFrameExtents(f)
+	}
+	return wm.requestFrameExtents(e.Window)
+}
+
+// msgTrayOpcode docks the icon of a request sent to the system tray
+func msgTrayOpcode(wm *WM, e xproto.ClientMessageEvent) error {
+	icon, ok := wm.xc.TrayDockRequest(e)
+	if !ok || wm.tray == nil || e.Window != wm.tray.win {
+		return nil
+	}
+	return wm.dockTrayIcon(icon)
+}
+
+// msgCommand runs the commands (up to 20 bytes, e.g. "workspace 3") of a _MARWIND_COMMAND message, with
+// the format of 8, sent to the root window or to a managed window the commands then act on. It lets
+// programs without access to the IPC socket, e.g. on another host, control the WM
+func msgCommand(wm *WM, e xproto.ClientMessageEvent) error {
+	if e.Format != 8 {
+		return fmt.Errorf("unexpected format %d", e.Format)
+	}
+	line := commandData(e.Data.Data8)
+	if err := checkMessageCommands(line); err != nil {
+		return err
+	}
+	if f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window }); f != nil {
+		wm.selected = f
+		defer func() { wm.selected = nil }()
+	}
+	return wm.runCommands(line)
+}
+
+// unsafeMessageCommands are refused in _MARWIND_COMMAND messages, as any X client, including the remote ones,
+// can send them: they start processes or write files
+var unsafeMessageCommands = map[string]bool{
+	"exec":        true,
+	"restart":     true,
+	"launcher":    true,
+	"save_layout": true,
+	"log":         true,
+}
+
+// checkMessageCommands returns an error if any of the commands of a _MARWIND_COMMAND message is unsafe,
+// or if its criteria can't be parsed. The commands are split and their criteria stripped the way they are
+// when they're run, see runCommands
+func checkMessageCommands(line string) error {
+	for _, cmd := range splitCommands(line) {
+		for cmd = strings.TrimSpace(cmd); strings.HasPrefix(cmd, "["); cmd = strings.TrimSpace(cmd) {
+			var err error
+			if _, cmd, err = parseCriteria(cmd); err != nil {
+				return err
+			}
+		}
+		if fields := strings.Fields(cmd); len(fields) > 0 && unsafeMessageCommands[fields[0]] {
+			return fmt.Errorf("command %q is not allowed in a _MARWIND_COMMAND message", fields[0])
+		}
+	}
+	return nil
+}
+
+// commandData returns the text of the data of a _MARWIND_COMMAND message, up to the first NUL byte
+func commandData(data []byte) string {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	return string(data)
+}
@@ -0,0 +1,92 @@
+package wm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestCommandData(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"terminated", []byte("workspace 3\x00\x00\x00\x00\x00\x00\x00\x00"), "workspace 3"},
+		{"full", []byte("focus left;focus up!"), "focus left;focus up!"},
+		{"empty", make([]byte, 20), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandData(tt.data); got != tt.want {
+				t.Errorf("got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMessageCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantErr bool
+	}{
+		{"safe", "workspace 3", false},
+		{"several safe", "focus left;kill", false},
+		{"exec", "exec rm -rf ~", true},
+		{"exec after a safe command", "workspace 3; exec xterm", true},
+		{"with criteria", "[class=a] exec xterm", true},
+		{"safe with criteria", "[class=a] kill", false},
+		{"quoted bracket in criteria", `[title="]|"]exec sh`, true},
+		{"escaped quote in criteria", `[title="\"]"]exec sh`, true},
+		{"quoted semicolon in criteria", `[title=";"]exec sh`, true},
+		{"invalid criteria", `[title="]exec sh`, true},
+		{"file", "save_layout ~/.bashrc", true},
+		{"restart", "restart", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkMessageCommands(tt.line); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleClientMessage(t *testing.T) {
+	var handled []xproto.Atom
+	record := func(err error) messageHandler {
+		return func(wm *WM, e xproto.ClientMessageEvent) error {
+			handled = append(handled, e.Type)
+			return err
+		}
+	}
+	wm := &WM{messages: map[xproto.Atom]message{
+		1: {name: "_TEST_OK", handle: record(nil)},
+		2: {name: "_TEST_FAIL", handle: record(errors.New("failed"))},
+	}}
+	tests := []struct {
+		name    string
+		typ     xproto.Atom
+		wantErr string
+	}{
+		{"handled", 1, ""},
+		{"failed", 2, "_TEST_FAIL of window 10: failed"},
+		{"unknown", 3, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotErr string
+			if err := wm.handleClientMessage(xproto.ClientMessageEvent{Window: 10, Type: tt.typ}); err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("got = %q, want = %q", gotErr, tt.wantErr)
+			}
+		})
+	}
+	if want := []xproto.Atom{1, 2}; len(handled) != len(want) || handled[0] != want[0] || handled[1] != want[1] {
+		t.Errorf("got = %v, want = %v", handled, want)
+	}
+}
@@ -0,0 +1,104 @@
+package wm
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/ipc"
+)
+
+// setMinimized hides the frame (or shows it again) without taking it off its workspace, the other windows
+// taking up its space in the meantime. The minimized frames are remembered in order, for restoreFrame
+func (wm *WM) setMinimized(f *frame, minimized bool) error {
+	if f.state.hidden == minimized || f.cli.Type() != client.TypeNormal {
+		return nil
+	}
+	f.state.hidden = minimized
+	wm.deleteMinimized(f)
+	if minimized {
+		wm.minimized = append(wm.minimized, f)
+		wm.emitWindowEvent("minimize", f)
+	} else {
+		wm.emitWindowEvent("restore", f)
+	}
+	return wm.applyFrameState(f)
+}
+
+// restoreFrame shows the minimized frame again, switching to its workspace, and focuses it
+func (wm *WM) restoreFrame(f *frame) error {
+	if err := wm.setMinimized(f, false); err != nil {
+		return err
+	}
+	ws := f.workspace()
+	if ws == nil {
+		return nil
+	}
+	if ws.output == nil || ws.output.activeWs != ws {
+		if err := wm.switchWorkspace(ws.name); err != nil {
+			return err
+		}
+	}
+	if f.floating {
+		if err := wm.raiseFrame(f); err != nil {
+			return err
+		}
+	}
+	return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+}
+
+// deleteMinimized removes the frame from the list of the minimized frames
+func (wm *WM) deleteMinimized(f *frame) {
+	for i, frm := range wm.minimized {
+		if frm == f {
+			wm.minimized = append(wm.minimized[:i], wm.minimized[i+1:]...)
+			return
+		}
+	}
+}
+
+// minimizedNodes returns the minimized windows, the most recently minimized first
+func (wm *WM) minimizedNodes() []ipc.Node {
+	nodes := make([]ipc.Node, 0, len(wm.minimized))
+	for i := len(wm.minimized) - 1; i >= 0; i-- {
+		nodes = append(nodes, wm.frameNode(wm.minimized[i]))
+	}
+	return nodes
+}
+
+// cmdMinimize hides the focused window until it's restored: minimize
+func cmdMinimize(wm *WM, args []string) error {
+	f := wm.targetFrame()
+	if f == nil {
+		return nil
+	}
+	return wm.setMinimized(f, true)
+}
+
+// cmdRestore shows a minimized window again: restore [window id], by default the most recently minimized one
+func cmdRestore(wm *WM, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: restore [window id]")
+	}
+	if len(wm.minimized) == 0 {
+		return nil
+	}
+	f := wm.minimized[len(wm.minimized)-1]
+	if len(args) == 1 {
+		id, err := strconv.ParseUint(args[0], 0, 32)
+		if err != nil {
+			return fmt.Errorf("invalid window id %q", args[0])
+		}
+		f = nil
+		for _, frm := range wm.minimized {
+			if frm.cli.Window() == xproto.Window(id) {
+				f = frm
+			}
+		}
+		if f == nil {
+			return fmt.Errorf("window %s is not minimized", args[0])
+		}
+	}
+	return wm.restoreFrame(f)
+}
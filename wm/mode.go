@@ -0,0 +1,68 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/patrislav/marwind/ipc"
+	"github.com/patrislav/marwind/logger"
+)
+
+// DefaultMode is the name of the binding mode using the regular keybindings
+const DefaultMode = "default"
+
+// modeBindings returns the keybindings of the current binding mode. Escape returns to the default mode
+// unless the mode binds it to something else
+func (wm *WM) modeBindings() map[string]string {
+	if wm.mode == "" {
+		return wm.config.Keybindings
+	}
+	bindings := map[string]string{"Escape": "mode " + DefaultMode}
+	for combo, cmd := range wm.config.Modes[wm.mode] {
+		bindings[combo] = cmd
+	}
+	return bindings
+}
+
+// setMode switches to another binding mode, grabbing the keys of its bindings in place of the current ones
+func (wm *WM) setMode(name string) error {
+	if name == DefaultMode {
+		name = ""
+	}
+	if name == wm.mode {
+		return nil
+	}
+	if _, ok := wm.config.Modes[name]; !ok && name != "" {
+		return fmt.Errorf("unknown mode %q", name)
+	}
+	wm.mode = name
+	wm.emitModeEvent()
+	if wm.config.OSD {
+		if err := wm.showOSD(wm.currentOutput(), "mode "+wm.modeName()); err != nil {
+			logger.Errorf("Failed to show the OSD: %v", err)
+		}
+	}
+	return wm.regrabKeys()
+}
+
+// resetMode goes back to the default mode without regrabbing the keys if the current mode no longer exists,
+// e.g. after it was removed from the reloaded config
+func (wm *WM) resetMode() {
+	if _, ok := wm.config.Modes[wm.mode]; !ok && wm.mode != "" {
+		logger.Warnf("Mode %q no longer exists, switching back to the default mode", wm.mode)
+		wm.mode = ""
+		wm.emitModeEvent()
+	}
+}
+
+// modeName returns the name of the active binding mode, DefaultMode for the default one
+func (wm *WM) modeName() string {
+	if wm.mode == "" {
+		return DefaultMode
+	}
+	return wm.mode
+}
+
+// emitModeEvent notifies the IPC subscribers about the new binding mode
+func (wm *WM) emitModeEvent() {
+	wm.emitEvent(ipc.Event{Type: ipc.EventMode, Change: wm.modeName()})
+}
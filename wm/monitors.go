@@ -0,0 +1,289 @@
+package wm
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
+)
+
+// updateOutputs synchronizes the outputs with the monitors reported by X, creating outputs for
+// new monitors, resizing the existing ones and moving the workspaces of removed monitors elsewhere
+func (wm *WM) updateOutputs() error {
+	monitors, err := wm.xc.Monitors()
+	if err != nil {
+		return fmt.Errorf("failed to get monitors: %v", err)
+	}
+	outputs := make([]*output, 0, len(monitors))
+	for _, m := range monitors {
+		geom := client.Geom{X: m.X, Y: m.Y, W: m.W, H: m.H}
+		o := findOutputByName(wm.outputs, outputs, m.Name)
+		if o == nil {
+			o = newOutput(wm.xc, m.Name, geom)
+			o.autoHideDocks = wm.config.DockAutoHide
+			if o.autoHideDocks {
+				o.docksShown = [4]bool{}
+			}
+		} else {
+			o.setGeom(geom)
+		}
+		o.primary = m.Primary
+		o.mmWidth = m.MmW
+		outputs = append(outputs, o)
+	}
+	var removed []*output
+	for _, o := range wm.outputs {
+		if !containsOutput(outputs, o) {
+			removed = append(removed, o)
+		}
+	}
+	wm.outputs = outputs
+	wm.scaleOutputs()
+	if !containsOutput(outputs, wm.focusedOutput) {
+		wm.focusedOutput = nil
+	}
+	for _, o := range removed {
+		if err := wm.evacuateOutput(o, wm.primaryOutput()); err != nil {
+			logger.Errorf("Failed to move the workspaces of removed output %q: %v", o.name, err)
+		}
+	}
+	for _, o := range outputs {
+		if o.activeWs != nil {
+			continue
+		}
+		ws := wm.freeWorkspace(o)
+		if ws == nil {
+			return fmt.Errorf("no free workspace for output %q", o.name)
+		}
+		if err := o.addWorkspace(ws); err != nil {
+			return fmt.Errorf("failed to add workspace to output %q: %v", o.name, err)
+		}
+	}
+	wm.applyWallpaper()
+	return nil
+}
+
+// evacuateOutput moves all the workspaces, docks and desktop windows of an output that no longer exists to another output
+func (wm *WM) evacuateOutput(o *output, target *output) error {
+	var err error
+	if o.activeWs != nil {
+		err = o.activeWs.hide()
+	}
+	for _, ws := range o.workspaces {
+		if e := target.addWorkspace(ws); e != nil {
+			err = e
+		}
+	}
+	for area := range o.dockAreas {
+		target.dockAreas[area] = append(target.dockAreas[area], o.dockAreas[area]...)
+		sortDocks(target.dockAreas[area], wm.config.DockOrder)
+	}
+	target.desktops = append(target.desktops, o.desktops...)
+	return err
+}
+
+// freeWorkspace returns the workspace that should be shown on a newly added output: one that's assigned
+// to it in the config or otherwise the first numbered workspace not belonging to any output
+func (wm *WM) freeWorkspace(o *output) *workspace {
+	var names []string
+	for name, out := range wm.config.WorkspaceOutputs {
+		if out == o.name && o.name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return workspaceLess(names[i], names[j]) })
+	for _, name := range names {
+		if ws := wm.getWorkspace(name); ws.output == nil {
+			return ws
+		}
+	}
+	for n := 1; ; n++ {
+		name := strconv.Itoa(n)
+		if ws := wm.findWorkspace(name); ws != nil && ws.output != nil || wm.configuredOutput(name) != nil {
+			continue
+		}
+		return wm.getWorkspace(name)
+	}
+}
+
+// configuredOutput returns the output to which the workspace is assigned in the config
+// or nil if there's no such assignment (or the output is not connected)
+func (wm *WM) configuredOutput(wsName string) *output {
+	name, ok := wm.config.WorkspaceOutputs[wsName]
+	if !ok {
+		return nil
+	}
+	for _, o := range wm.outputs {
+		if o.name == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// currentOutput returns the output containing the focused window or, if there's none, the output focused
+// last (e.g. by switching to an empty workspace) and finally the one containing the pointer
+func (wm *WM) currentOutput() *output {
+	if f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == wm.activeWin }); f != nil {
+		if ws := f.workspace(); ws != nil && ws.output != nil {
+			return ws.output
+		}
+	}
+	if wm.focusedOutput != nil {
+		return wm.focusedOutput
+	}
+	if reply, err := xproto.QueryPointer(wm.xc.X(), wm.xc.GetRootWindow()).Reply(); err == nil {
+		if o := wm.outputAt(reply.RootX, reply.RootY); o != nil {
+			return o
+		}
+	}
+	return wm.primaryOutput()
+}
+
+// primaryOutput returns the RandR primary output, falling back to the first one if none is primary
+func (wm *WM) primaryOutput() *output {
+	for _, o := range wm.outputs {
+		if o.primary {
+			return o
+		}
+	}
+	return wm.outputs[0]
+}
+
+// focusOutput moves the focus to the most recently focused window of the output's active workspace,
+// warping the pointer to it (or the middle of the output if the workspace is empty) if enabled
+func (wm *WM) focusOutput(o *output) error {
+	wm.focusedOutput = o
+	f := o.activeWs.lastFocused()
+	if f == nil {
+		if err := wm.removeFocus(); err != nil {
+			return err
+		}
+		if err := wm.updateDesktopHints(); err != nil {
+			return err
+		}
+		a := o.workspaceArea()
+		return wm.warpPointer(a.X+int16(a.W/2), a.Y+int16(a.H/2))
+	}
+	if f.floating {
+		if err := wm.raiseFrame(f); err != nil {
+			return err
+		}
+	}
+	if err := wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime); err != nil {
+		return err
+	}
+	if err := wm.updateDesktopHints(); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(f)
+}
+
+// findOutput returns the output in the given direction from the current one: left, right, up, down,
+// next or prev (in the order of their positions, wrapping around), primary or the name of an output
+func (wm *WM) findOutput(target string) (*output, error) {
+	cur := wm.currentOutput()
+	switch target {
+	case "primary":
+		return wm.primaryOutput(), nil
+	case "next", "prev":
+		outputs := wm.outputsByPosition()
+		i := 0
+		for i < len(outputs) && outputs[i] != cur {
+			i++
+		}
+		if target == "next" {
+			return outputs[(i+1)%len(outputs)], nil
+		}
+		return outputs[(i+len(outputs)-1)%len(outputs)], nil
+	}
+	if dir, err := parseMoveDirection(target); err == nil {
+		geoms := make([]client.Geom, len(wm.outputs))
+		for i, o := range wm.outputs {
+			geoms[i] = o.geom
+		}
+		if i := adjacentGeom(cur.geom, geoms, dir, false); i >= 0 {
+			return wm.outputs[i], nil
+		}
+		return cur, nil
+	}
+	if o := findOutputByName(wm.outputs, nil, target); o != nil {
+		return o, nil
+	}
+	return nil, fmt.Errorf("no output %q", target)
+}
+
+// outputsByPosition returns the outputs ordered left to right, then top to bottom
+func (wm *WM) outputsByPosition() []*output {
+	outputs := append([]*output(nil), wm.outputs...)
+	sort.SliceStable(outputs, func(i, j int) bool {
+		if outputs[i].geom.X != outputs[j].geom.X {
+			return outputs[i].geom.X < outputs[j].geom.X
+		}
+		return outputs[i].geom.Y < outputs[j].geom.Y
+	})
+	return outputs
+}
+
+// outputAt returns the output containing the given point or nil if there's none
+func (wm *WM) outputAt(x, y int16) *output {
+	for _, o := range wm.outputs {
+		if x >= o.geom.X && x < o.geom.X+int16(o.geom.W) && y >= o.geom.Y && y < o.geom.Y+int16(o.geom.H) {
+			return o
+		}
+	}
+	return nil
+}
+
+// outputForWindow returns the output on which the given (unmanaged) window is currently placed
+func (wm *WM) outputForWindow(win xproto.Window) *output {
+	if g, err := xproto.GetGeometry(wm.xc.X(), xproto.Drawable(win)).Reply(); err == nil {
+		if o := wm.outputAt(g.X, g.Y); o != nil {
+			return o
+		}
+	}
+	return wm.currentOutput()
+}
+
+// desktopWorkspaces returns the workspaces visible to EWMH pagers, i.e. all those assigned to an output
+func (wm *WM) desktopWorkspaces() []*workspace {
+	var workspaces []*workspace
+	for _, o := range wm.outputs {
+		workspaces = append(workspaces, o.workspaces...)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaceLess(workspaces[i].name, workspaces[j].name)
+	})
+	return workspaces
+}
+
+func (wm *WM) renderOutputs() error {
+	var err error
+	for _, o := range wm.outputs {
+		if e := wm.renderOutput(o); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func findOutputByName(outputs []*output, exclude []*output, name string) *output {
+	for _, o := range outputs {
+		if o.name == name && !containsOutput(exclude, o) {
+			return o
+		}
+	}
+	return nil
+}
+
+func containsOutput(outputs []*output, o *output) bool {
+	for _, other := range outputs {
+		if other == o {
+			return true
+		}
+	}
+	return false
+}
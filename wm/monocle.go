@@ -0,0 +1,136 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// In the monocle layout of a workspace all of its tiled frames take the whole workspace, stacked on top
+// of each other, and only the focused one is seen. Its titlebar tells its position among the others,
+// which can be cycled through with "tab next" and "tab prev". The columns are kept as they are, so that
+// they come back when the layout is turned off
+
+// setMonocle switches the workspace between the monocle layout and its columns
+func (wm *WM) setMonocle(ws *workspace, enable bool) error {
+	if ws.monocle == enable {
+		return nil
+	}
+	ws.monocle = enable
+	if !enable {
+		for _, f := range ws.tiledFrames() {
+			if err := f.cli.SetPosition(0, 0); err != nil {
+				return err
+			}
+		}
+	}
+	wm.emitWorkspaceEvent("layout", ws)
+	if ws.output == nil || ws.output.activeWs != ws {
+		return nil
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	if f := ws.monocleFrame(); f != nil {
+		return wm.warpPointerToFrame(f)
+	}
+	return nil
+}
+
+// layoutName describes the layout of the workspace in the IPC replies
+func (ws *workspace) layoutName() string {
+	if ws.monocle {
+		return "monocle"
+	}
+	return "columns"
+}
+
+// tiledFrames returns the visible tiled frames of the workspace in the order of the layout
+func (ws *workspace) tiledFrames() []*frame {
+	var frames []*frame
+	for _, col := range ws.columns {
+		for _, f := range col.leaves() {
+			if !f.state.hidden {
+				frames = append(frames, f)
+			}
+		}
+	}
+	return frames
+}
+
+// inMonocle returns true if the frame is a tiled frame of a workspace in the monocle layout, whose titlebar
+// tells its position among the others
+func (f *frame) inMonocle() bool {
+	ws := f.workspace()
+	return f.col != nil && ws != nil && ws.monocle
+}
+
+// monocleFrame returns the tiled frame shown in the monocle layout: the most recently focused one
+func (ws *workspace) monocleFrame() *frame {
+	for i := len(ws.focusStack) - 1; i >= 0; i-- {
+		f := ws.focusStack[i]
+		if f.col != nil && f.workspace() == ws && !f.state.hidden {
+			return f
+		}
+	}
+	if frames := ws.tiledFrames(); len(frames) > 0 {
+		return frames[0]
+	}
+	return nil
+}
+
+// renderMonocle gives the whole workspace to each of its tiled frames, raising the one that is shown
+func (wm *WM) renderMonocle(ws *workspace) error {
+	var err error
+	frames := ws.tiledFrames()
+	geom := wm.wholeArea(ws)
+	for i, f := range frames {
+		if e := f.cli.SetPosition(i+1, len(frames)); e != nil {
+			err = e
+		}
+		if f.fullscreen {
+			continue
+		}
+		if e := wm.renderFrame(f, geom); e != nil {
+			err = e
+		}
+	}
+	if f := ws.monocleFrame(); f != nil && !f.fullscreen {
+		wm.markRaised(f)
+	}
+	return err
+}
+
+// cycleMonocle focuses the tiled frame after (or before, for a negative offset) the given one in the
+// monocle layout, going around at the ends
+func (wm *WM) cycleMonocle(f *frame, offset int) error {
+	frames := f.workspace().tiledFrames()
+	i := -1
+	for j, frm := range frames {
+		if frm == f {
+			i = j
+		}
+	}
+	if i < 0 || len(frames) < 2 {
+		return nil
+	}
+	next := frames[((i+offset)%len(frames)+len(frames))%len(frames)]
+	if err := wm.setFocus(next.cli.Window(), xproto.TimeCurrentTime); err != nil {
+		return err
+	}
+	return wm.warpPointerToFrame(next)
+}
+
+// cmdMonocle switches the current workspace to the monocle layout or back to its columns:
+// monocle <enable|disable|toggle>
+func cmdMonocle(wm *WM, args []string) error {
+	ws := wm.currentOutput().activeWs
+	if f := wm.targetFrame(); f != nil && f.workspace() != nil {
+		ws = f.workspace()
+	}
+	enable, err := parseToggle(args, ws.monocle)
+	if err != nil {
+		return fmt.Errorf("monocle: %v", err)
+	}
+	return wm.setMonocle(ws, enable)
+}
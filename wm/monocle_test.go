@@ -0,0 +1,65 @@
+package wm
+
+import (
+	"testing"
+)
+
+func TestMonocleFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused []int // indexes of the frames in the order they're focused
+		hidden  int   // index of a minimized frame, -1 for none
+		want    int
+	}{
+		{"none focused", nil, -1, 0},
+		{"last focused", []int{2, 1}, -1, 1},
+		{"last focused minimized", []int{2, 1}, 1, 2},
+		{"floating focused", []int{1, 3}, -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newWorkspace("1", workspaceConfig{})
+			ws.output = &output{}
+			frames := []*frame{{}, {}, {}, {}}
+			for _, f := range frames[:3] {
+				if err := ws.addFrame(f); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := ws.addFloatingFrame(frames[3], frames[3].floatGeom); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, i := range tt.focused {
+				ws.pushFocus(frames[i])
+			}
+			if tt.hidden >= 0 {
+				frames[tt.hidden].state.hidden = true
+			}
+			if got := ws.monocleFrame(); got != frames[tt.want] {
+				t.Errorf("monocleFrame() got = %p, want = %p", got, frames[tt.want])
+			}
+		})
+	}
+}
+
+func TestInMonocle(t *testing.T) {
+	ws := newWorkspace("1", workspaceConfig{})
+	ws.output = &output{}
+	tiled, floating := &frame{}, &frame{}
+	if err := ws.addFrame(tiled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ws.addFloatingFrame(floating, floating.floatGeom); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tiled.inMonocle() || floating.inMonocle() {
+		t.Errorf("expected no frame in the monocle layout of a workspace with columns")
+	}
+	ws.monocle = true
+	if !tiled.inMonocle() {
+		t.Errorf("expected the tiled frame in the monocle layout")
+	}
+	if floating.inMonocle() {
+		t.Errorf("expected the floating frame out of the monocle layout")
+	}
+}
@@ -2,8 +2,8 @@ package wm
 
 import (
 	"fmt"
-
-	"github.com/BurntSushi/xgb/xproto"
+	"sort"
+	"strconv"
 )
 
 type MoveDirection uint8
@@ -22,48 +22,96 @@ const (
 	ResizeHoriz
 )
 
-func (wm *WM) switchWorkspace(id uint8) error {
-	ws, err := wm.ensureWorkspace(id)
+func (wm *WM) switchWorkspace(name string) error {
+	prevOutput := wm.currentOutput()
+	ws, err := wm.ensureWorkspace(name)
 	if err != nil {
 		return fmt.Errorf("failed to ensure workspace: %v", err)
 	}
 	if err := ws.output.switchWorkspace(ws); err != nil {
 		return fmt.Errorf("output unable to switch workpace: %v", err)
 	}
+	wm.focusedOutput = ws.output
 	if err := wm.renderWorkspace(ws); err != nil {
 		return fmt.Errorf("wm.renderWorkspace: %w", err)
 	}
 	if err := wm.updateDesktopHints(); err != nil {
 		return fmt.Errorf("failed to update desktop hints: %v", err)
 	}
-	if err := wm.removeFocus(); err != nil {
-		return fmt.Errorf("failed to remove focus: %v", err)
+	if ws.output != prevOutput {
+		a := ws.output.workspaceArea()
+		if err := wm.warpPointer(a.X+int16(a.W/2), a.Y+int16(a.H/2)); err != nil {
+			return fmt.Errorf("failed to warp pointer: %v", err)
+		}
+	}
+	if err := wm.focusWorkspace(ws); err != nil {
+		return fmt.Errorf("failed to set focus: %w", err)
 	}
+	return nil
+}
+
+// switchBackAndForth switches to the workspace that was shown on the current output before its active one,
+// recreating it if it has been removed in the meantime
+func (wm *WM) switchBackAndForth() error {
+	o := wm.currentOutput()
+	if o == nil || o.prevWs == "" {
+		return nil
+	}
+	return wm.switchWorkspace(o.prevWs)
+}
 
-	// TODO: temporary solution! Focuses always the first window of the first column
-	// Better approach: implement a window focus stack for each workspace, on switch focus the top-of-stack window
-	if len(ws.columns) > 0 && len(ws.columns[0].frames) > 0 {
-		win := ws.columns[0].frames[0].cli.Window()
-		if err := wm.setFocus(win, xproto.TimeCurrentTime); err != nil {
-			return fmt.Errorf("failed to set focus: %w", err)
+// switchAdjacentWorkspace switches to the next (offset 1) or previous (offset -1) workspace, wrapping around.
+// With WorkspaceIncludeEmpty set, the empty workspaces among the numbered ones up to 10 are visited as well
+func (wm *WM) switchAdjacentWorkspace(offset int) error {
+	current := wm.currentOutput().activeWs
+	names := adjacentCandidates(wm.workspaces, wm.config.WorkspaceIncludeEmpty)
+	for i, name := range names {
+		if name == current.name {
+			return wm.switchWorkspace(names[((i+offset)%len(names)+len(names))%len(names)])
 		}
 	}
 	return nil
 }
 
-func (wm *WM) moveFrameToWorkspace(f *frame, wsID uint8) error {
-	current := wm.outputs[0].activeWs
-	next, err := wm.ensureWorkspace(wsID)
+// adjacentCandidates returns the sorted names of the workspaces that "workspace next" and "workspace prev"
+// go through, optionally including the default numbered ones that don't exist at the moment
+func adjacentCandidates(workspaces []*workspace, includeEmpty bool) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ws := range workspaces {
+		seen[ws.name] = true
+		names = append(names, ws.name)
+	}
+	for n := 1; includeEmpty && n <= defaultWorkspaces; n++ {
+		if name := strconv.Itoa(n); !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return workspaceLess(names[i], names[j]) })
+	return names
+}
+
+func (wm *WM) moveFrameToWorkspace(f *frame, name string) error {
+	current := f.workspace()
+	next, err := wm.ensureWorkspace(name)
 	if err != nil {
 		return err
 	}
 	if next == current {
 		return nil
 	}
+	floating := f.floating
+	focused := f.cli.Window() == wm.activeWin
+	transients := wm.transients(f)
 	if !current.deleteFrame(f) {
-		return fmt.Errorf("frame not contained within workspace %d", wsID)
+		return fmt.Errorf("frame not contained within workspace %q", current.name)
 	}
-	if err := next.addFrame(f); err != nil {
+	current.forgetFocus(f)
+	if floating {
+		if err := next.addFloatingFrame(f, f.floatGeom); err != nil {
+			return fmt.Errorf("failed to add the frame to the next workspace: %v", err)
+		}
+	} else if err := next.addFrame(f); err != nil {
 		return fmt.Errorf("failed to add the frame to the next workspace: %v", err)
 	}
 	if err := f.cli.Unmap(); err != nil {
@@ -75,31 +123,78 @@ func (wm *WM) moveFrameToWorkspace(f *frame, wsID uint8) error {
 	if err := wm.renderWorkspace(current); err != nil {
 		return fmt.Errorf("failed to render previous workspace: %v", err)
 	}
+	if focused {
+		if err := wm.focusWorkspace(current); err != nil {
+			return fmt.Errorf("failed to set focus: %v", err)
+		}
+	}
+	for _, t := range transients {
+		if err := wm.moveFrameToWorkspace(t, name); err != nil {
+			return fmt.Errorf("failed to move transient window: %v", err)
+		}
+	}
 	if err := wm.updateDesktopHints(); err != nil {
 		return fmt.Errorf("failed to update desktop hints: %v", err)
 	}
+	wm.emitWindowEvent("move", f)
 	return nil
 }
 
-// ensureWorkspace looks up a workspace by ID, adding it to the current output if needed
-func (wm *WM) ensureWorkspace(id uint8) (*workspace, error) {
-	var nextWs *workspace
-	for _, ws := range wm.workspaces {
-		if ws.id == id {
-			nextWs = ws
-			break
+// ensureWorkspace looks up a workspace by name, creating it if it doesn't exist, and adds it
+// to the output it's assigned to in the config or to the current output if needed
+func (wm *WM) ensureWorkspace(name string) (*workspace, error) {
+	if err := ValidWorkspaceName(name); err != nil {
+		return nil, err
+	}
+	nextWs := wm.getWorkspace(name)
+	if nextWs.output == nil {
+		o := wm.configuredOutput(name)
+		if o == nil {
+			o = wm.currentOutput()
 		}
-	}
-	if nextWs == nil {
-		return nil, fmt.Errorf("no workspace with ID %d", id)
-	}
-	switch {
-	case nextWs.output == nil:
-		if err := wm.outputs[0].addWorkspace(nextWs); err != nil {
+		if err := o.addWorkspace(nextWs); err != nil {
 			return nil, err
 		}
-	case nextWs.output != wm.outputs[0]:
-		return nil, fmt.Errorf("multiple outputs not supported yet")
+		wm.emitWorkspaceEvent("init", nextWs)
 	}
 	return nextWs, nil
 }
+
+// findWorkspace returns the workspace with the given name or nil if it doesn't exist
+func (wm *WM) findWorkspace(name string) *workspace {
+	for _, ws := range wm.workspaces {
+		if ws.name == name {
+			return ws
+		}
+	}
+	return nil
+}
+
+// getWorkspace returns the workspace with the given name, creating it (without an output) if it doesn't exist
+func (wm *WM) getWorkspace(name string) *workspace {
+	if ws := wm.findWorkspace(name); ws != nil {
+		return ws
+	}
+	ws := newWorkspace(name, workspaceConfig{gap: wm.config.OuterGap, innerGap: wm.config.InnerGap})
+	wm.workspaces = append(wm.workspaces, ws)
+	sort.Slice(wm.workspaces, func(i, j int) bool {
+		return workspaceLess(wm.workspaces[i].name, wm.workspaces[j].name)
+	})
+	return ws
+}
+
+// pruneWorkspaces removes the workspaces that have no windows and are not shown on any output
+func (wm *WM) pruneWorkspaces() {
+	var workspaces []*workspace
+	for _, ws := range wm.workspaces {
+		if len(ws.frames()) > 0 || ws.output != nil && ws.output.activeWs == ws {
+			workspaces = append(workspaces, ws)
+			continue
+		}
+		wm.emitWorkspaceEvent("empty", ws)
+		if ws.output != nil {
+			ws.output.removeWorkspace(ws)
+		}
+	}
+	wm.workspaces = workspaces
+}
@@ -0,0 +1,191 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
+)
+
+// Directions of the _NET_WM_MOVERESIZE messages
+const (
+	moveResizeSizeTopLeft = iota
+	moveResizeSizeTop
+	moveResizeSizeTopRight
+	moveResizeSizeRight
+	moveResizeSizeBottomRight
+	moveResizeSizeBottom
+	moveResizeSizeBottomLeft
+	moveResizeSizeLeft
+	moveResizeMove
+	moveResizeSizeKeyboard
+	moveResizeMoveKeyboard
+	moveResizeCancel
+)
+
+// moveResizeEdges maps the resizing directions of _NET_WM_MOVERESIZE to the edges following the pointer
+var moveResizeEdges = [...]resizeEdges{
+	moveResizeSizeTopLeft:     resizeTop | resizeLeft,
+	moveResizeSizeTop:         resizeTop,
+	moveResizeSizeTopRight:    resizeTop | resizeRight,
+	moveResizeSizeRight:       resizeRight,
+	moveResizeSizeBottomRight: resizeBottom | resizeRight,
+	moveResizeSizeBottom:      resizeBottom,
+	moveResizeSizeBottomLeft:  resizeBottom | resizeLeft,
+	moveResizeSizeLeft:        resizeLeft,
+}
+
+// handleMoveResizeMessage starts or cancels the move or resize of the frame requested by its client with
+// _NET_WM_MOVERESIZE, e.g. when the headerbar of a GTK window is dragged. Tiled frames can only be moved,
+// by dragging them to another place in the layout
+func (wm *WM) handleMoveResizeMessage(f *frame, data []uint32) error {
+	x, y, direction := int16(data[0]), int16(data[1]), data[2]
+	if direction == moveResizeCancel {
+		if wm.drag == nil || wm.drag.f != f {
+			return nil
+		}
+		return wm.endDrag()
+	}
+	if wm.drag != nil || wm.menu != nil || wm.overview != nil || wm.prompt != nil || wm.cycle != nil || f.fullscreen || f.workspace() == nil {
+		return nil
+	}
+	if !f.floating {
+		if direction != moveResizeMove || f.col == nil {
+			return nil
+		}
+		if err := wm.startTileDrag(f, x, y, xproto.TimeCurrentTime); err != nil {
+			return err
+		}
+		return wm.endReleasedDrag()
+	}
+	switch {
+	case direction == moveResizeMove:
+		if err := wm.startDrag(f, dragMove, 0, x, y, xproto.TimeCurrentTime); err != nil {
+			return err
+		}
+	case direction < uint32(len(moveResizeEdges)):
+		if err := wm.startDrag(f, dragResize, moveResizeEdges[direction], x, y, xproto.TimeCurrentTime); err != nil {
+			return err
+		}
+	case direction == moveResizeMoveKeyboard:
+		return wm.startKeyboardDrag(f, dragMove)
+	case direction == moveResizeSizeKeyboard:
+		return wm.startKeyboardDrag(f, dragResize)
+	default:
+		return fmt.Errorf("unknown _NET_WM_MOVERESIZE direction %d", direction)
+	}
+	return wm.endReleasedDrag()
+}
+
+// endReleasedDrag ends the drag that was just started if the button was already released, as the client
+// had to release its own grab of the pointer before asking for the drag
+func (wm *WM) endReleasedDrag() error {
+	if wm.drag == nil {
+		return nil
+	}
+	reply, err := xproto.QueryPointer(wm.xc.X(), wm.xc.GetRootWindow()).Reply()
+	if err != nil {
+		return err
+	}
+	buttons := uint16(xproto.KeyButMaskButton1 | xproto.KeyButMaskButton2 | xproto.KeyButMaskButton3)
+	if reply.Mask&buttons != 0 {
+		return nil
+	}
+	return wm.endDrag()
+}
+
+// startKeyboardDrag grabs the keyboard so that the floating frame can be moved, or resized by its bottom
+// right corner, with the arrow keys. Return ends the drag and Escape brings the frame back where it was
+func (wm *WM) startKeyboardDrag(f *frame, kind dragKind) error {
+	if !wm.grabKeyboard() {
+		return nil
+	}
+	if err := wm.unmaximizeFloating(f); err != nil {
+		return err
+	}
+	wm.drag = &drag{f: f, kind: kind, edges: resizeRight | resizeBottom, orig: f.floatGeom, keyboard: true}
+	if err := wm.raiseFrame(f); err != nil {
+		return err
+	}
+	return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+}
+
+// handleDragKey moves or resizes the frame dragged with the keyboard according to the pressed key, by a step
+// stopping at the first line its edges snap to
+func (wm *WM) handleDragKey(e xproto.KeyPressEvent) error {
+	d := wm.drag
+	step := int(wm.config.FloatingStep)
+	var dx, dy int
+	switch wm.keymap.Keysym(e.Detail, keysym.Group(e.State)) {
+	case keysym.XKLeft:
+		dx = -step
+	case keysym.XKRight:
+		dx = step
+	case keysym.XKUp:
+		dy = -step
+	case keysym.XKDown:
+		dy = step
+	case keysym.XKReturn:
+		return wm.endDrag()
+	case keysym.XKEscape:
+		d.dx, d.dy = 0, 0
+		if err := wm.dragFrame(d, 0, 0); err != nil {
+			return err
+		}
+		return wm.endDrag()
+	default:
+		return nil
+	}
+	edges := d.edges
+	if d.kind == dragMove {
+		edges = allEdges
+	}
+	dx, dy = wm.snapStep(d.f, d.f.floatGeom, edges, dx, dy)
+	d.dx += dx
+	d.dy += dy
+	return wm.dragFrame(d, d.dx, d.dy)
+}
+
+// endDrag ends the drag in progress, leaving the frame where it currently is, and releases the grabbed
+// pointer or keyboard
+func (wm *WM) endDrag() error {
+	d := wm.drag
+	wm.drag = nil
+	if d.keyboard {
+		return xproto.UngrabKeyboardChecked(wm.xc.X(), xproto.TimeCurrentTime).Check()
+	}
+	if err := xproto.UngrabPointerChecked(wm.xc.X(), xproto.TimeCurrentTime).Check(); err != nil {
+		return err
+	}
+	if d.kind == dragTile {
+		return wm.finishTileDrag(d)
+	}
+	return nil
+}
+
+// cancelDrag stops the drag in progress without applying it, e.g. when the dragged window is closed, and
+// releases the grabbed pointer or keyboard
+func (wm *WM) cancelDrag() error {
+	d := wm.drag
+	wm.drag = nil
+	if d.indicator != 0 {
+		if err := wm.xc.DestroyWindow(d.indicator); err != nil {
+			logger.Errorf("Failed to destroy the drop indicator: %v", err)
+		}
+	}
+	if d.keyboard {
+		return xproto.UngrabKeyboardChecked(wm.xc.X(), xproto.TimeCurrentTime).Check()
+	}
+	return xproto.UngrabPointerChecked(wm.xc.X(), xproto.TimeCurrentTime).Check()
+}
+
+// involves returns true if the drag moves or resizes the frame, or resizes the tiled items of its workspace.
+// The target of a tiled frame isn't involved, finishTileDrag checks that it's still there
+func (d *drag) involves(f *frame) bool {
+	if d.kind == dragBoundary {
+		return len(d.bounds) > 0 && d.bounds[0].ws == f.workspace()
+	}
+	return d.f == f
+}
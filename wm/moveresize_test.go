@@ -0,0 +1,63 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestResizeGeom(t *testing.T) {
+	geom := client.Geom{X: 100, Y: 100, W: 400, H: 300}
+	tests := []struct {
+		name   string
+		edges  resizeEdges
+		dx, dy int
+		want   client.Geom
+	}{
+		{"bottom right", resizeBottom | resizeRight, 50, 20, client.Geom{X: 100, Y: 100, W: 450, H: 320}},
+		{"top left", resizeTop | resizeLeft, 50, 20, client.Geom{X: 150, Y: 120, W: 350, H: 280}},
+		{"left only", resizeLeft, -30, 40, client.Geom{X: 70, Y: 100, W: 430, H: 300}},
+		{"bottom only", resizeBottom, 30, -40, client.Geom{X: 100, Y: 100, W: 400, H: 260}},
+		{"left past the minimum", resizeLeft, 1000, 0, client.Geom{X: 500 - minFloatingSize, Y: 100, W: minFloatingSize, H: 300}},
+		{"top right past the minimum", resizeTop | resizeRight, -1000, 1000, client.Geom{X: 100, Y: 400 - minFloatingSize, W: minFloatingSize, H: minFloatingSize}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resizeGeom(geom, tt.edges, tt.dx, tt.dy); got != tt.want {
+				t.Errorf("resizeGeom() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDragInvolves(t *testing.T) {
+	ws, other := newWorkspace("1", workspaceConfig{}), newWorkspace("2", workspaceConfig{})
+	ws.output, other.output = &output{}, &output{}
+	dragged, target, elsewhere := &frame{}, &frame{}, &frame{}
+	for _, f := range []*frame{dragged, target} {
+		if err := ws.addFrame(f); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := other.addFrame(elsewhere); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name string
+		d    *drag
+		f    *frame
+		want bool
+	}{
+		{"dragged frame", &drag{f: dragged, kind: dragTile, target: target}, dragged, true},
+		{"drop target", &drag{f: dragged, kind: dragTile, target: target}, target, false},
+		{"boundary of the workspace", &drag{kind: dragBoundary, bounds: []tileBoundary{{ws: ws}}}, target, true},
+		{"boundary of another workspace", &drag{kind: dragBoundary, bounds: []tileBoundary{{ws: ws}}}, elsewhere, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.involves(tt.f); got != tt.want {
+				t.Errorf("involves() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
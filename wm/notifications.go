@@ -0,0 +1,127 @@
+package wm
+
+import (
+	"sort"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// Corners of the workspace area in which the notification windows are stacked, see Config.NotificationCorner
+const (
+	NotificationCornerTopRight    = "top_right"
+	NotificationCornerTopLeft     = "top_left"
+	NotificationCornerBottomRight = "bottom_right"
+	NotificationCornerBottomLeft  = "bottom_left"
+	NotificationCornerNone        = "none" // leave the notifications where their clients put them
+)
+
+// notificationFrames returns the notification windows of the workspace in the order they appeared in
+func (wm *WM) notificationFrames(ws *workspace) []*frame {
+	var frames []*frame
+	for _, f := range ws.floating {
+		if f.cli.Role() == client.RoleNotification && !f.fullscreen {
+			frames = append(frames, f)
+		}
+	}
+	sort.SliceStable(frames, func(i, j int) bool { return frames[i].notified < frames[j].notified })
+	return frames
+}
+
+// placesNotifications returns true if the notification windows are stacked by the WM rather than left
+// where their clients put them
+func (wm *WM) placesNotifications() bool {
+	return wm.config.NotificationCorner != NotificationCornerNone
+}
+
+// queueNotification numbers the new notification window of the workspace and, if there are already as many
+// notifications shown as allowed, keeps it unmapped until older ones are closed
+func (wm *WM) queueNotification(f *frame, ws *workspace) {
+	wm.notifications++
+	f.notified = wm.notifications
+	if !wm.placesNotifications() || wm.config.NotificationMax == 0 {
+		return
+	}
+	shown := 0
+	for _, n := range wm.notificationFrames(ws) {
+		if !n.queued {
+			shown++
+		}
+	}
+	f.queued = shown >= int(wm.config.NotificationMax)
+}
+
+// renderNotifications stacks the notification windows of the workspace in the configured corner of its area,
+// the oldest one closest to the corner, so that they never cover the titlebars of the tiled windows at the top.
+// The windows past the maximum number are queued: unmapped until the ones before them are closed
+func (wm *WM) renderNotifications(ws *workspace) error {
+	var err error
+	var shown []*frame
+	for i, f := range wm.notificationFrames(ws) {
+		queued := wm.config.NotificationMax > 0 && i >= int(wm.config.NotificationMax)
+		if queued != f.queued {
+			f.queued = queued
+			if e := wm.updateQueuedNotification(f, ws); e != nil {
+				err = e
+			}
+		}
+		if !queued {
+			shown = append(shown, f)
+		}
+	}
+	sizes := make([]client.Geom, len(shown))
+	for i, f := range shown {
+		sizes[i] = f.floatGeom
+	}
+	for i, geom := range stackNotifications(sizes, wm.notificationRegion(ws), wm.config.NotificationCorner, ws.innerGap()) {
+		f := shown[i]
+		f.floatGeom = geom
+		if e := wm.renderFrame(f, geom); e != nil {
+			err = e
+		}
+		wm.markRaised(f)
+	}
+	return err
+}
+
+// updateQueuedNotification maps or unmaps the notification window after it left or joined the queue
+func (wm *WM) updateQueuedNotification(f *frame, ws *workspace) error {
+	if ws.output == nil || ws.output.activeWs != ws || f.state.hidden {
+		return nil
+	}
+	if f.queued {
+		return f.cli.Unmap()
+	}
+	return f.cli.Map()
+}
+
+// notificationRegion returns the part of the workspace area given to the notifications, which starts
+// below the titlebars of the tiled windows at the top of the workspace
+func (wm *WM) notificationRegion(ws *workspace) client.Geom {
+	a := ws.area()
+	top := ws.innerGap()*2 + uint16(scaledUint8(wm.config.TitleBarHeight, ws.pixelScale()))
+	if top > a.H {
+		top = a.H
+	}
+	return client.Geom{X: a.X, Y: a.Y + int16(top), W: a.W, H: a.H - top}
+}
+
+// stackNotifications places windows of the given sizes one after the other, separated by the gap, from
+// the corner of the region towards its opposite edge
+func stackNotifications(sizes []client.Geom, region client.Geom, corner string, gap uint16) []client.Geom {
+	right := corner == NotificationCornerTopRight || corner == NotificationCornerBottomRight
+	bottom := corner == NotificationCornerBottomRight || corner == NotificationCornerBottomLeft
+	geoms := make([]client.Geom, len(sizes))
+	offset := int(gap)
+	for i, s := range sizes {
+		g := client.Geom{W: s.W, H: s.H, X: region.X + int16(gap), Y: region.Y + int16(offset)}
+		if right {
+			g.X = region.X + int16(int(region.W)-int(s.W)-int(gap))
+		}
+		if bottom {
+			g.Y = region.Y + int16(int(region.H)-offset-int(s.H))
+		}
+		geoms[i] = g
+		offset += int(s.H) + int(gap)
+	}
+	return geoms
+}
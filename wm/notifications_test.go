@@ -0,0 +1,75 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestStackNotifications(t *testing.T) {
+	region := client.Geom{X: 0, Y: 30, W: 1000, H: 570}
+	sizes := []client.Geom{{W: 300, H: 100}, {W: 200, H: 50}}
+	tests := []struct {
+		corner string
+		want   []client.Geom
+	}{
+		{NotificationCornerTopRight, []client.Geom{{X: 690, Y: 40, W: 300, H: 100}, {X: 790, Y: 150, W: 200, H: 50}}},
+		{NotificationCornerTopLeft, []client.Geom{{X: 10, Y: 40, W: 300, H: 100}, {X: 10, Y: 150, W: 200, H: 50}}},
+		{NotificationCornerBottomRight, []client.Geom{{X: 690, Y: 490, W: 300, H: 100}, {X: 790, Y: 430, W: 200, H: 50}}},
+		{NotificationCornerBottomLeft, []client.Geom{{X: 10, Y: 490, W: 300, H: 100}, {X: 10, Y: 430, W: 200, H: 50}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.corner, func(t *testing.T) {
+			if got := stackNotifications(sizes, region, tt.corner, 10); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stackNotifications() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNotifications(t *testing.T) {
+	dpy := newFakeDisplay()
+	wm := &WM{dpy: dpy, windowConfig: &client.Config{}, config: Config{TitleBarHeight: 18, NotificationCorner: NotificationCornerTopRight, NotificationMax: 2}}
+	o := &output{geom: client.Geom{W: 1000, H: 600}}
+	wm.outputs = []*output{o}
+	ws := newWorkspace("1", workspaceConfig{innerGap: 4})
+	ws.output, o.activeWs = o, ws
+	var frames []*frame
+	for i := 0; i < 3; i++ {
+		f, err := dpy.newFrame(xproto.Window(10+i), client.TypeNormal)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f.cli.SetRole(client.RoleNotification)
+		wm.queueNotification(f, ws)
+		if err := ws.addFloatingFrame(f, client.Geom{X: 50, Y: 50, W: 300, H: 100}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		frames = append(frames, f)
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := []bool{frames[0].queued, frames[1].queued, frames[2].queued}; !reflect.DeepEqual(got, []bool{false, false, true}) {
+		t.Errorf("queued got = %v, want = %v", got, []bool{false, false, true})
+	}
+	want := []client.Geom{{X: 696, Y: 30, W: 300, H: 100}, {X: 696, Y: 134, W: 300, H: 100}}
+	if got := []client.Geom{frames[0].cli.Geom(), frames[1].cli.Geom()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+
+	// the queued notification takes the place of the closed one
+	ws.deleteFrame(frames[0])
+	if err := wm.renderWorkspace(ws); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if frames[2].queued || !frames[2].cli.Mapped() {
+		t.Errorf("expected the queued notification to be shown")
+	}
+	if got := frames[2].cli.Geom(); got != want[1] {
+		t.Errorf("got = %v, want = %v", got, want[1])
+	}
+}
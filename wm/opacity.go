@@ -0,0 +1,49 @@
+package wm
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// minOpacity keeps the windows whose opacity is lowered with the opacity command from disappearing entirely
+const minOpacity = 0.1
+
+// cmdOpacity changes the opacity of the focused window, which then stays the same regardless of the focus:
+// opacity <set|plus|minus> <value> or opacity reset to follow the config again
+func cmdOpacity(wm *WM, args []string) error {
+	usage := fmt.Errorf("usage: opacity <set|plus|minus> <value> | opacity reset")
+	if len(args) != 2 && (len(args) != 1 || args[0] != "reset") {
+		return usage
+	}
+	f := wm.targetFrame()
+	if f == nil {
+		return nil
+	}
+	if args[0] == "reset" {
+		return f.cli.SetOpacity(0)
+	}
+	value, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || value < 0 || value > 1 {
+		return fmt.Errorf("invalid opacity %q, expected a value between 0 and 1", args[1])
+	}
+	switch args[0] {
+	case "set":
+	case "plus":
+		value = f.cli.Opacity() + value
+	case "minus":
+		value = f.cli.Opacity() - value
+	default:
+		return usage
+	}
+	return f.cli.SetOpacity(clampOpacity(value))
+}
+
+func clampOpacity(opacity float64) float64 {
+	if opacity < minOpacity {
+		return minOpacity
+	}
+	if opacity > 1 {
+		return 1
+	}
+	return opacity
+}
@@ -0,0 +1,139 @@
+package wm
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/fonts"
+)
+
+// osd is the on-screen display: a small window in the middle of an output briefly showing a line of text,
+// e.g. the name of the workspace switched to. It doesn't take the input and disappears after the timeout
+type osd struct {
+	win  xproto.Window // 0 until the OSD is shown for the first time
+	geom client.Geom
+	text string
+
+	// shown counts the texts shown so far, so that the timer of a replaced text doesn't hide the new one
+	shown uint64
+
+	fonts   *fonts.Set // fonts of the text with the OSD font in front, nil until first needed, see osdFonts
+	fontKey string     // names of the fonts loaded in fonts
+}
+
+const osdPadding = 16 // space around the text
+
+// cmdOSD shows the text on the current output: osd <text>
+func cmdOSD(wm *WM, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: osd <text>")
+	}
+	return wm.showOSD(wm.currentOutput(), strings.Join(args, " "))
+}
+
+// showOSD shows the text in the middle of the output for the configured timeout, replacing any text
+// still shown
+func (wm *WM) showOSD(o *output, text string) error {
+	if o == nil {
+		return nil
+	}
+	if wm.osd == nil {
+		wm.osd = &osd{}
+	}
+	d := wm.osd
+	ew, eh := wm.osdFonts().Extents(wm.config.OSDFontSize, text)
+	geom := osdGeom(o.geom, ew+2*osdPadding, eh+2*osdPadding)
+	if d.win == 0 {
+		win, err := wm.xc.CreateWindow(wm.xc.GetRootWindow(), geom.X, geom.Y, geom.W, geom.H, 0,
+			xproto.WindowClassInputOutput, xproto.CwBackPixel|xproto.CwOverrideRedirect|xproto.CwEventMask,
+			[]uint32{wm.config.OSDBgColor, 1, xproto.EventMaskExposure})
+		if err != nil {
+			return err
+		}
+		d.win = win
+	}
+	d.geom, d.text = geom, text
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+	values := []uint32{uint32(geom.X), uint32(geom.Y), uint32(geom.W), uint32(geom.H)}
+	if err := xproto.ConfigureWindowChecked(wm.xc.X(), d.win, mask, values).Check(); err != nil {
+		return err
+	}
+	if err := wm.xc.MapWindow(d.win); err != nil {
+		return err
+	}
+	if err := wm.xc.RaiseWindow(d.win); err != nil {
+		return err
+	}
+	d.shown++
+	shown := d.shown
+	time.AfterFunc(time.Duration(wm.config.OSDTimeout)*time.Millisecond, func() {
+		wm.queueTask(func() error { return wm.hideOSD(shown) })
+	})
+	return wm.drawOSD()
+}
+
+// osdFonts returns the fonts of the OSD text: those of all the text, with the configured OSD font in front
+// of them, which are loaded again once the config changes them
+func (wm *WM) osdFonts() *fonts.Set {
+	if wm.config.OSDFont == "" {
+		return wm.windowConfig.Fonts
+	}
+	d := wm.osd
+	names := append([]string{wm.config.OSDFont}, wm.config.Fonts...)
+	if key := strings.Join(names, "\n"); d.fonts == nil || d.fontKey != key {
+		d.fonts, d.fontKey = loadFonts(names), key
+	}
+	return d.fonts
+}
+
+// hideOSD unmaps the OSD window unless another text was shown after the given one
+func (wm *WM) hideOSD(shown uint64) error {
+	d := wm.osd
+	if d == nil || d.win == 0 || d.shown != shown {
+		return nil
+	}
+	d.text = ""
+	return wm.xc.UnmapWindow(d.win)
+}
+
+// drawOSD paints the text of the OSD
+func (wm *WM) drawOSD() error {
+	d := wm.osd
+	if d.text == "" {
+		return nil
+	}
+	img := wm.xc.NewImage(image.Rect(0, 0, int(d.geom.W), int(d.geom.H)))
+	defer img.Destroy()
+	fill(img, img.Bounds(), wm.config.OSDBgColor)
+	fg := rgba(wm.config.OSDFontColor)
+	if _, err := wm.osdFonts().Draw(img, osdPadding, osdPadding, fg, wm.config.OSDFontSize, d.text); err != nil {
+		return err
+	}
+	if err := img.CreatePixmap(); err != nil {
+		return err
+	}
+	img.XDraw()
+	img.XExpPaint(d.win, 0, 0)
+	return nil
+}
+
+// osdGeom centers a window of the given size on the output, shrinking it to the output if it's larger
+func osdGeom(bounds client.Geom, w, h int) client.Geom {
+	if w > int(bounds.W) {
+		w = int(bounds.W)
+	}
+	if h > int(bounds.H) {
+		h = int(bounds.H)
+	}
+	return client.Geom{
+		X: bounds.X + int16((int(bounds.W)-w)/2),
+		Y: bounds.Y + int16((int(bounds.H)-h)/2),
+		W: uint16(w),
+		H: uint16(h),
+	}
+}
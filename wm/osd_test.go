@@ -0,0 +1,26 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestOSDGeom(t *testing.T) {
+	bounds := client.Geom{X: 1920, Y: 0, W: 1280, H: 1024}
+	tests := []struct {
+		name string
+		w, h int
+		want client.Geom
+	}{
+		{"centered", 200, 64, client.Geom{X: 2460, Y: 480, W: 200, H: 64}},
+		{"wider than the output", 2000, 64, client.Geom{X: 1920, Y: 480, W: 1280, H: 64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := osdGeom(bounds, tt.w, tt.h); got != tt.want {
+				t.Errorf("osdGeom() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
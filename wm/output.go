@@ -3,6 +3,7 @@ package wm
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/patrislav/marwind/client"
 
@@ -14,19 +15,36 @@ type dockArea uint8
 const (
 	dockAreaTop    dockArea = 0
 	dockAreaBottom dockArea = 1
+	dockAreaLeft   dockArea = 2
+	dockAreaRight  dockArea = 3
 )
 
 type output struct {
 	xc         *x11.Connection
+	name       string
 	geom       client.Geom
+	primary    bool    // whether it's the RandR primary output
+	mmWidth    uint32  // physical width reported by RandR, in millimeters, 0 if unknown
+	scale      float64 // factor of the pixel values of the config on this output, see outputScale
 	workspaces []*workspace
 	activeWs   *workspace
-	dockAreas  [2][]*frame
+	prevWs     string // name of the workspace shown on this output before the active one, see workspaceTarget
+	dockAreas  [4][]*frame
+	desktops   []*frame // windows drawing the desktop, covering the whole output below everything else
+
+	autoHideDocks bool         // whether the docks are only shown on demand, above the workspace, see updateDocks
+	docksShown    [4]bool      // whether the docks of each area are shown
+	dockLeft      [4]time.Time // when the pointer left the shown auto-hidden docks of each area, zero if it didn't
 }
 
 // newOutput creates a new output from the given geometry
-func newOutput(xc *x11.Connection, geom client.Geom) *output {
-	return &output{xc: xc, geom: geom}
+func newOutput(xc *x11.Connection, name string, geom client.Geom) *output {
+	return &output{xc: xc, name: name, geom: geom, docksShown: [4]bool{true, true, true, true}}
+}
+
+// setGeom changes the geometry of the output, the columns of its workspaces keep their proportions
+func (o *output) setGeom(geom client.Geom) {
+	o.geom = geom
 }
 
 // addWorkspace appends the workspace to this output, sorting them,
@@ -35,7 +53,7 @@ func (o *output) addWorkspace(ws *workspace) error {
 	ws.setOutput(o)
 	o.workspaces = append(o.workspaces, ws)
 	sort.Slice(o.workspaces, func(i, j int) bool {
-		return o.workspaces[i].id < o.workspaces[j].id
+		return workspaceLess(o.workspaces[i].name, o.workspaces[j].name)
 	})
 	if o.activeWs == nil {
 		o.activeWs = ws
@@ -51,15 +69,19 @@ func (o *output) switchWorkspace(next *workspace) error {
 	if ch := o.findWorkspace(func(ws *workspace) bool { return ws == next }); ch == nil {
 		return fmt.Errorf("workspace not part of this output")
 	}
+	if err := o.activeWs.moveStickyFrames(next); err != nil {
+		return fmt.Errorf("failed to move sticky frames: %v", err)
+	}
 	if err := next.show(); err != nil {
 		return fmt.Errorf("failed to show next workspace: %v", err)
 	}
 	if err := o.activeWs.hide(); err != nil {
 		return fmt.Errorf("failed to hide previous workspace: %v", err)
 	}
-	if len(o.activeWs.columns) == 0 {
+	if len(o.activeWs.frames()) == 0 {
 		o.removeWorkspace(o.activeWs)
 	}
+	o.prevWs = o.activeWs.name
 	o.activeWs = next
 	return nil
 }
@@ -83,41 +105,160 @@ func (o *output) removeWorkspace(ws *workspace) {
 	}
 }
 
-// addDock appends the frame as a dock of this output
-func (o *output) addDock(f *frame) error {
+// addDock places the frame as a dock of this output in the given area, reserving the given space at each
+// edge, and sorts the docks of the area in the given order of classes, see sortDocks
+func (o *output) addDock(f *frame, area dockArea, reserved [4]uint16, order []string) error {
+	f.strut, f.reserved = reserved[area], reserved
+	o.dockAreas[area] = append(o.dockAreas[area], f)
+	sortDocks(o.dockAreas[area], order)
+	if !o.docksShown[area] {
+		return nil
+	}
+	return f.cli.Map()
+}
+
+// sortDocks orders the docks of an area from the edge of the output inwards: first those whose class or
+// instance is listed in the order, as listed, then the others in the order they were mapped
+func sortDocks(docks []*frame, order []string) {
+	rank := func(f *frame) int {
+		if len(order) == 0 {
+			return 0
+		}
+		return orderIndex(order, f.cli.Class(), f.cli.Instance())
+	}
+	sort.SliceStable(docks, func(i, j int) bool {
+		ri, rj := rank(docks[i]), rank(docks[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return docks[i].docked < docks[j].docked
+	})
+}
+
+// orderIndex returns the index of the first entry of the order equal to one of the names, the length of
+// the order if there's none
+func orderIndex(order []string, names ...string) int {
+	for i, entry := range order {
+		for _, name := range names {
+			if name != "" && entry == name {
+				return i
+			}
+		}
+	}
+	return len(order)
+}
+
+// dockStrut returns the area of the output at whose edge the dock reserves the most space, in which it's placed,
+// along with the space it reserves at every edge
+func (o *output) dockStrut(f *frame) (dockArea, [4]uint16, error) {
 	struts, err := o.xc.GetWindowStruts(f.cli.Window())
 	if err != nil {
-		return fmt.Errorf("failed to get struts: %v", err)
-	}
-	var area dockArea
-	switch {
-	case struts.Top > struts.Bottom:
-		area = dockAreaTop
-		f.height = uint16(struts.Top)
-	case struts.Bottom > struts.Top:
-		area = dockAreaBottom
-		f.height = uint16(struts.Bottom)
-	default:
-		return fmt.Errorf("could not determine the dock position")
+		return 0, [4]uint16{}, fmt.Errorf("failed to get struts: %v", err)
 	}
-	o.dockAreas[area] = append(o.dockAreas[area], f)
-	// TODO map the dock
-	o.updateTiling()
+	screen := o.xc.Screen()
+	reserved := reservedEdges(struts, o.geom, screen.WidthInPixels, screen.HeightInPixels)
+	area, strut := largestReservation(reserved)
+	if strut == 0 {
+		return 0, [4]uint16{}, fmt.Errorf("could not determine the dock position")
+	}
+	return area, reserved, nil
+}
+
+// largestReservation returns the area with the most space reserved, the top one if there's none
+func largestReservation(reserved [4]uint16) (dockArea, uint16) {
+	area := dockAreaTop
+	for a := range reserved {
+		if reserved[a] > reserved[area] {
+			area = dockArea(a)
+		}
+	}
+	return area, reserved[area]
+}
+
+// dockArea returns the area of the output the dock is placed in, false if it's not a dock of the output
+func (o *output) dockArea(f *frame) (dockArea, bool) {
+	for area := range o.dockAreas {
+		for _, d := range o.dockAreas[area] {
+			if d == f {
+				return dockArea(area), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// setDockStrut moves the dock of the output to the given area if it's placed in another one, sorting it among
+// the docks there, and changes the space it reserves, returning false if it already had both
+func (o *output) setDockStrut(f *frame, area dockArea, reserved [4]uint16, order []string) bool {
+	current, ok := o.dockArea(f)
+	if !ok || current == area && f.reserved == reserved {
+		return false
+	}
+	f.strut, f.reserved = reserved[area], reserved
+	if current != area {
+		o.deleteFrame(f)
+		o.dockAreas[area] = append(o.dockAreas[area], f)
+		sortDocks(o.dockAreas[area], order)
+	}
+	return true
+}
+
+// addDesktop appends the frame as a desktop window of this output
+func (o *output) addDesktop(f *frame) error {
+	o.desktops = append(o.desktops, f)
 	return f.cli.Map()
 }
 
-// dockHeight returns the height of the entire dock area
-func (o *output) dockHeight(area dockArea) uint16 {
-	var height uint16
+// reservedEdges returns the space that the struts, given relative to the edges of the whole screen, reserve
+// at each edge of the output, indexed by the dock area. Reservations are only counted if their range along
+// the edge overlaps the output
+func reservedEdges(s *x11.Struts, geom client.Geom, screenW, screenH uint16) [4]uint16 {
+	x, y := int64(geom.X), int64(geom.Y)
+	right, bottom := x+int64(geom.W), y+int64(geom.H)
+	reserve := func(strut uint32, offset int64, start, end uint32, from, to int64) uint16 {
+		size := int64(strut) - offset
+		if size <= 0 || int64(end) < from || int64(start) >= to {
+			return 0
+		}
+		return uint16(size)
+	}
+	var edges [4]uint16
+	edges[dockAreaTop] = reserve(s.Top, y, s.TopStartX, s.TopEndX, x, right)
+	edges[dockAreaBottom] = reserve(s.Bottom, int64(screenH)-bottom, s.BottomStartX, s.BottomEndX, x, right)
+	edges[dockAreaLeft] = reserve(s.Left, x, s.LeftStartY, s.LeftEndY, y, bottom)
+	edges[dockAreaRight] = reserve(s.Right, int64(screenW)-right, s.RightStartY, s.RightEndY, y, bottom)
+	return edges
+}
+
+// dockSize returns the size of the entire dock area, i.e. the height of the top and bottom areas
+// and the width of the left and right ones
+func (o *output) dockSize(area dockArea) uint16 {
+	var size uint16
 	for _, f := range o.dockAreas[area] {
-		height += f.height
+		size += f.strut
 	}
-	return height
+	return size
 }
 
-func (o *output) workspaceArea() client.Geom {
-	top := o.dockHeight(dockAreaTop)
-	bottom := o.dockHeight(dockAreaBottom)
+// reservedSize returns the space reserved at the edge of the area: the size of its docks and the space
+// the docks of the other areas reserve there too, e.g. a panel along the top and the left edges
+func (o *output) reservedSize(area dockArea) uint16 {
+	size := o.dockSize(area)
+	for a := range o.dockAreas {
+		if dockArea(a) == area {
+			continue
+		}
+		for _, f := range o.dockAreas[a] {
+			size += f.reserved[area]
+		}
+	}
+	return size
+}
+
+// sideDockGeom returns the space between the top and bottom dock areas in which the left and right docks are placed
+func (o *output) sideDockGeom() client.Geom {
+	top := o.dockSize(dockAreaTop)
+	bottom := o.dockSize(dockAreaBottom)
 	return client.Geom{
 		X: o.geom.X,
 		Y: o.geom.Y + int16(top),
@@ -126,12 +267,32 @@ func (o *output) workspaceArea() client.Geom {
 	}
 }
 
+// workspaceArea returns the space of the output left to the workspace by the space reserved at its edges
+func (o *output) workspaceArea() client.Geom {
+	if o.autoHideDocks {
+		return o.geom
+	}
+	top, bottom := o.reservedSize(dockAreaTop), o.reservedSize(dockAreaBottom)
+	left, right := o.reservedSize(dockAreaLeft), o.reservedSize(dockAreaRight)
+	return client.Geom{
+		X: o.geom.X + int16(left),
+		Y: o.geom.Y + int16(top),
+		W: o.geom.W - left - right,
+		H: o.geom.H - top - bottom,
+	}
+}
+
 func (o *output) deleteFrame(frm *frame) bool {
+	for i, f := range o.desktops {
+		if frm == f {
+			o.desktops = append(o.desktops[:i], o.desktops[i+1:]...)
+			return true
+		}
+	}
 	for area := range o.dockAreas {
 		for i, f := range o.dockAreas[area] {
 			if frm == f {
 				o.dockAreas[area] = append(o.dockAreas[area][:i], o.dockAreas[area][i+1:]...)
-				o.updateTiling()
 				return true
 			}
 		}
@@ -143,9 +304,3 @@ func (o *output) deleteFrame(frm *frame) bool {
 	}
 	return false
 }
-
-func (o *output) updateTiling() {
-	for _, ws := range o.workspaces {
-		ws.updateTiling()
-	}
-}
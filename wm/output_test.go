@@ -0,0 +1,216 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/x11"
+)
+
+func TestReservedEdges(t *testing.T) {
+	const max = 0xffffffff
+	left := client.Geom{X: 0, Y: 0, W: 1920, H: 1080}
+	right := client.Geom{X: 1920, Y: 0, W: 1280, H: 1024}
+	tests := []struct {
+		name   string
+		struts x11.Struts
+		geom   client.Geom
+		want   [4]uint16
+	}{
+		{"full top strut", x11.Struts{Top: 20, TopEndX: max}, left, [4]uint16{20, 0, 0, 0}},
+		{"partial top strut on other output", x11.Struts{Top: 20, TopStartX: 1920, TopEndX: 3199}, left, [4]uint16{0, 0, 0, 0}},
+		{"partial top strut on this output", x11.Struts{Top: 20, TopStartX: 1920, TopEndX: 3199}, right, [4]uint16{20, 0, 0, 0}},
+		{"bottom strut of shorter output", x11.Struts{Bottom: 86, BottomStartX: 1920, BottomEndX: 3199}, right, [4]uint16{0, 30, 0, 0}},
+		{"bottom strut not reaching shorter output", x11.Struts{Bottom: 30, BottomEndX: max}, right, [4]uint16{0, 0, 0, 0}},
+		{"left strut", x11.Struts{Left: 48, LeftEndY: 1079}, left, [4]uint16{0, 0, 48, 0}},
+		{"right strut", x11.Struts{Right: 64, RightEndY: max}, right, [4]uint16{0, 0, 0, 64}},
+		{"right strut of other output", x11.Struts{Right: 64, RightEndY: max}, left, [4]uint16{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.struts
+			if got := reservedEdges(&s, tt.geom, 3200, 1080); got != tt.want {
+				t.Errorf("reservedEdges() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindOutput(t *testing.T) {
+	// laid out as: left | middle | right, with top above the middle one
+	left := &output{name: "DP-1", geom: client.Geom{X: 0, Y: 1080, W: 1920, H: 1080}}
+	middle := &output{name: "DP-2", geom: client.Geom{X: 1920, Y: 1080, W: 1920, H: 1080}, primary: true}
+	right := &output{name: "HDMI-1", geom: client.Geom{X: 3840, Y: 1080, W: 1280, H: 1024}}
+	top := &output{name: "eDP-1", geom: client.Geom{X: 1920, Y: 0, W: 1920, H: 1080}}
+	wm := &WM{outputs: []*output{right, top, left, middle}, focusedOutput: middle}
+	tests := []struct {
+		target  string
+		want    *output
+		wantErr bool
+	}{
+		{"left", left, false},
+		{"right", right, false},
+		{"up", top, false},
+		{"down", middle, false},
+		{"next", right, false},
+		{"prev", top, false},
+		{"primary", middle, false},
+		{"HDMI-1", right, false},
+		{"VGA-1", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, err := wm.findOutput(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findOutput() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("findOutput() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkspaceTarget(t *testing.T) {
+	o := &output{activeWs: &workspace{name: "2"}, prevWs: "1"}
+	fresh := &output{activeWs: &workspace{name: "1"}}
+	tests := []struct {
+		name    string
+		o       *output
+		ws      string
+		enabled bool
+		want    string
+	}{
+		{"other workspace", o, "3", true, "3"},
+		{"shown workspace", o, "2", true, "1"},
+		{"disabled", o, "2", false, "2"},
+		{"no previous workspace", fresh, "1", true, "1"},
+		{"no output", nil, "2", true, "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workspaceTarget(tt.o, tt.ws, tt.enabled); got != tt.want {
+				t.Errorf("workspaceTarget() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputSwitchWorkspacePrevious(t *testing.T) {
+	one, two := &workspace{name: "1"}, &workspace{name: "2"}
+	o := &output{workspaces: []*workspace{one, two}, activeWs: one}
+	one.output, two.output = o, o
+	if err := o.switchWorkspace(two); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.prevWs != "1" {
+		t.Errorf("prevWs got = %v, want = 1", o.prevWs)
+	}
+	if err := o.switchWorkspace(two); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.prevWs != "1" {
+		t.Errorf("prevWs after switching to the shown workspace got = %v, want = 1", o.prevWs)
+	}
+}
+
+func TestLargestReservation(t *testing.T) {
+	tests := []struct {
+		reserved  [4]uint16
+		wantArea  dockArea
+		wantStrut uint16
+	}{
+		{[4]uint16{0, 0, 0, 0}, dockAreaTop, 0},
+		{[4]uint16{20, 0, 0, 0}, dockAreaTop, 20},
+		{[4]uint16{0, 30, 0, 0}, dockAreaBottom, 30},
+		{[4]uint16{10, 0, 0, 48}, dockAreaRight, 48},
+	}
+	for _, tt := range tests {
+		area, strut := largestReservation(tt.reserved)
+		if area != tt.wantArea || strut != tt.wantStrut {
+			t.Errorf("largestReservation(%v) got = %v %v, want = %v %v", tt.reserved, area, strut, tt.wantArea, tt.wantStrut)
+		}
+	}
+}
+
+func TestSetDockStrut(t *testing.T) {
+	tests := []struct {
+		name    string
+		area    dockArea
+		strut   uint16
+		want    bool
+		wantTop int // docks left at the top
+	}{
+		{"unchanged", dockAreaTop, 20, false, 2},
+		{"resized", dockAreaTop, 30, true, 2},
+		{"moved", dockAreaBottom, 20, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, other := &frame{strut: 20, reserved: [4]uint16{20, 0, 0, 0}}, &frame{strut: 24}
+			o := &output{}
+			o.dockAreas[dockAreaTop] = []*frame{f, other}
+			var reserved [4]uint16
+			reserved[tt.area] = tt.strut
+			if got := o.setDockStrut(f, tt.area, reserved, nil); got != tt.want {
+				t.Errorf("setDockStrut() got = %v, want = %v", got, tt.want)
+			}
+			if area, _ := o.dockArea(f); area != tt.area || f.strut != tt.strut {
+				t.Errorf("dock got = %v %v, want = %v %v", area, f.strut, tt.area, tt.strut)
+			}
+			if len(o.dockAreas[dockAreaTop]) != tt.wantTop {
+				t.Errorf("top docks got = %v, want = %v", len(o.dockAreas[dockAreaTop]), tt.wantTop)
+			}
+		})
+	}
+	if (&output{}).setDockStrut(&frame{}, dockAreaTop, [4]uint16{20, 0, 0, 0}, nil) {
+		t.Errorf("setDockStrut() of a dock of another output got = true, want = false")
+	}
+}
+
+func TestWorkspaceArea(t *testing.T) {
+	// a panel along the top and the left edges, placed at the top, and a bar at the bottom
+	panel := &frame{strut: 30, reserved: [4]uint16{30, 0, 48, 0}}
+	bar := &frame{strut: 20, reserved: [4]uint16{0, 20, 0, 0}}
+	o := &output{geom: client.Geom{X: 1920, Y: 0, W: 1920, H: 1080}}
+	o.dockAreas[dockAreaTop] = []*frame{panel}
+	o.dockAreas[dockAreaBottom] = []*frame{bar}
+	want := client.Geom{X: 1968, Y: 30, W: 1872, H: 1030}
+	if got := o.workspaceArea(); got != want {
+		t.Errorf("workspaceArea() got = %v, want = %v", got, want)
+	}
+	o.autoHideDocks = true
+	if got := o.workspaceArea(); got != o.geom {
+		t.Errorf("workspaceArea() with hidden docks got = %v, want = %v", got, o.geom)
+	}
+}
+
+func TestOrderIndex(t *testing.T) {
+	order := []string{"Polybar", "tint2"}
+	tests := []struct {
+		name  string
+		names []string
+		want  int
+	}{
+		{"class", []string{"Polybar", "polybar"}, 0},
+		{"instance", []string{"Tint2", "tint2"}, 1},
+		{"unlisted", []string{"Lemonbar", "lemonbar"}, 2},
+		{"empty", []string{"", ""}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderIndex(order, tt.names...); got != tt.want {
+				t.Errorf("orderIndex() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortDocks(t *testing.T) {
+	first, second, third := &frame{docked: 1}, &frame{docked: 2}, &frame{docked: 3}
+	docks := []*frame{third, first, second}
+	sortDocks(docks, nil)
+	if docks[0] != first || docks[1] != second || docks[2] != third {
+		t.Errorf("sortDocks() got = %v %v %v, want = 1 2 3", docks[0].docked, docks[1].docked, docks[2].docked)
+	}
+}
@@ -0,0 +1,342 @@
+package wm
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
+)
+
+// overview is an overlay covering the current output with a grid of thumbnails, either of all the workspaces
+// or of the windows of the current workspace, one of which is picked with the keyboard or the pointer.
+// While it's open the pointer and keyboard are grabbed, like for the menu
+type overview struct {
+	win      xproto.Window
+	geom     client.Geom // of the overlay
+	items    []overviewItem
+	cells    []image.Rectangle // of the items, relative to the overlay
+	cols     int
+	selected int
+
+	shots map[*frame]image.Image // contents of the windows that were shown when the overview was opened
+}
+
+// overviewItem is a thumbnail of the overview, showing the windows within an area of the screen
+type overviewItem struct {
+	label  string
+	area   client.Geom
+	frames []*frame
+	ws     *workspace // workspace switched to when the item is picked, nil for the windows
+	f      *frame     // window focused when the item is picked, nil for the workspaces
+}
+
+const (
+	overviewPadding     = 24 // space between the thumbnails
+	overviewLabelHeight = 20 // below each thumbnail
+	overviewSelection   = 3  // width of the outline of the selected thumbnail
+	overviewBgColor     = 0xff1e1e1e
+)
+
+// cmdOverview shows the overview of the workspaces or of the windows of the current workspace:
+// overview [workspaces|windows]
+func cmdOverview(wm *WM, args []string) error {
+	mode := "workspaces"
+	if len(args) == 1 {
+		mode = args[0]
+	}
+	if len(args) > 1 || mode != "workspaces" && mode != "windows" {
+		return fmt.Errorf("usage: overview [workspaces|windows]")
+	}
+	return wm.openOverview(mode == "windows")
+}
+
+// overviewItems returns the thumbnails of the workspaces, or of the windows shown on the workspace
+func (wm *WM) overviewItems(current *workspace, windows bool) []overviewItem {
+	var items []overviewItem
+	if windows {
+		for _, f := range current.frames() {
+			if f.state.hidden || !f.focusable() {
+				continue
+			}
+			items = append(items, overviewItem{label: f.cli.Title(), area: f.cli.Geom(), frames: []*frame{f}, f: f})
+		}
+		return items
+	}
+	for _, ws := range wm.workspaces {
+		area := current.output.geom
+		if ws.output != nil {
+			area = ws.output.geom
+		}
+		var frames []*frame
+		for _, f := range ws.frames() {
+			if !f.state.hidden {
+				frames = append(frames, f)
+			}
+		}
+		// the frames are drawn in the order they're stacked in
+		stackFrames(frames, nil)
+		items = append(items, overviewItem{label: ws.name, area: area, frames: frames, ws: ws})
+	}
+	return items
+}
+
+// openOverview captures the shown windows, then covers the current output with their thumbnails
+func (wm *WM) openOverview(windows bool) error {
+	if wm.overview != nil || wm.prompt != nil || wm.menu != nil || wm.drag != nil || wm.cycle != nil {
+		return nil
+	}
+	o := wm.currentOutput()
+	if o == nil || o.activeWs == nil {
+		return nil
+	}
+	items := wm.overviewItems(o.activeWs, windows)
+	if len(items) == 0 {
+		return nil
+	}
+	ov := &overview{geom: o.geom, items: items, shots: make(map[*frame]image.Image)}
+	ov.cols, ov.cells = overviewGrid(len(items), int(o.geom.W), int(o.geom.H))
+	for _, item := range items {
+		for _, f := range item.frames {
+			if _, ok := ov.shots[f]; ok || !f.cli.Mapped() {
+				continue
+			}
+			win := f.cli.Parent()
+			if win == 0 {
+				win = f.cli.Window()
+			}
+			shot, err := wm.xc.CaptureWindow(win)
+			if err != nil {
+				logger.Debugf("Failed to capture window %d: %v", f.cli.Window(), err)
+				continue
+			}
+			ov.shots[f] = shot
+		}
+	}
+	for i, item := range items {
+		if item.ws == o.activeWs || item.f != nil && item.f.cli.Window() == wm.activeWin {
+			ov.selected = i
+		}
+	}
+	win, err := wm.xc.CreateWindow(wm.xc.GetRootWindow(), o.geom.X, o.geom.Y, o.geom.W, o.geom.H, 0,
+		xproto.WindowClassInputOutput, xproto.CwBackPixel|xproto.CwOverrideRedirect|xproto.CwEventMask,
+		[]uint32{overviewBgColor, 1, xproto.EventMaskExposure})
+	if err != nil {
+		return err
+	}
+	ov.win = win
+	if err := wm.xc.MapWindow(win); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return err
+	}
+	if err := wm.grabMenuInput(xproto.TimeCurrentTime); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return err
+	}
+	wm.overview = ov
+	return wm.drawOverview()
+}
+
+// closeOverview destroys the overlay and releases the grabs
+func (wm *WM) closeOverview(t xproto.Timestamp) error {
+	ov := wm.overview
+	if ov == nil {
+		return nil
+	}
+	wm.overview = nil
+	if err := xproto.UngrabKeyboardChecked(wm.xc.X(), t).Check(); err != nil {
+		logger.Errorf("Failed to ungrab the keyboard: %v", err)
+	}
+	if err := xproto.UngrabPointerChecked(wm.xc.X(), t).Check(); err != nil {
+		logger.Errorf("Failed to ungrab the pointer: %v", err)
+	}
+	return wm.xc.DestroyWindow(ov.win)
+}
+
+// pickOverview closes the overview, then switches to the workspace or focuses the window of the item.
+// The window is looked up again, as it could have been closed while the overview was open
+func (wm *WM) pickOverview(i int, t xproto.Timestamp) error {
+	item := wm.overview.items[i]
+	if err := wm.closeOverview(t); err != nil {
+		return err
+	}
+	if item.ws != nil {
+		return wm.switchWorkspace(item.ws.name)
+	}
+	win := item.f.cli.Window()
+	f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == win })
+	if f == nil {
+		return nil
+	}
+	return wm.activateFrame(f)
+}
+
+// handleOverviewKey moves the selection with the arrows (or h, j, k, l) and Tab, picks the selected item
+// with Return or space and closes the overview with Escape
+func (wm *WM) handleOverviewKey(e xproto.KeyPressEvent) error {
+	ov := wm.overview
+	i := ov.selected
+	switch wm.keymap.Keysym(e.Detail, keysym.Group(e.State)) {
+	case keysym.XKLeft, keysym.XKh:
+		i = gridMove(i, len(ov.items), ov.cols, -1, 0)
+	case keysym.XKRight, keysym.XKl:
+		i = gridMove(i, len(ov.items), ov.cols, 1, 0)
+	case keysym.XKUp, keysym.XKk:
+		i = gridMove(i, len(ov.items), ov.cols, 0, -1)
+	case keysym.XKDown, keysym.XKj:
+		i = gridMove(i, len(ov.items), ov.cols, 0, 1)
+	case keysym.XKTab:
+		i = (i + 1) % len(ov.items)
+	case keysym.XKReturn, keysym.XKSpace:
+		return wm.pickOverview(i, e.Time)
+	case keysym.XKEscape:
+		return wm.closeOverview(e.Time)
+	}
+	if i == ov.selected {
+		return nil
+	}
+	ov.selected = i
+	return wm.drawOverview()
+}
+
+// overviewItemAt returns the index of the thumbnail at the position relative to the root window, -1 if none
+func (wm *WM) overviewItemAt(x, y int16) int {
+	ov := wm.overview
+	p := image.Pt(int(x-ov.geom.X), int(y-ov.geom.Y))
+	for i, cell := range ov.cells {
+		if p.In(cell) {
+			return i
+		}
+	}
+	return -1
+}
+
+// handleOverviewMotion selects the thumbnail under the pointer
+func (wm *WM) handleOverviewMotion(e xproto.MotionNotifyEvent) error {
+	i := wm.overviewItemAt(e.RootX, e.RootY)
+	if i < 0 || i == wm.overview.selected {
+		return nil
+	}
+	wm.overview.selected = i
+	return wm.drawOverview()
+}
+
+// handleOverviewButtonPress picks the clicked thumbnail, clicking between them closes the overview
+func (wm *WM) handleOverviewButtonPress(e xproto.ButtonPressEvent) error {
+	i := wm.overviewItemAt(e.RootX, e.RootY)
+	if i < 0 {
+		return wm.closeOverview(e.Time)
+	}
+	return wm.pickOverview(i, e.Time)
+}
+
+// drawOverview paints the thumbnails with their labels, the selected one outlined in the focused border color
+func (wm *WM) drawOverview() error {
+	ov := wm.overview
+	cfg := wm.windowConfig
+	img := wm.xc.NewImage(image.Rect(0, 0, int(ov.geom.W), int(ov.geom.H)))
+	defer img.Destroy()
+	fill(img, img.Bounds(), overviewBgColor)
+	for i, item := range ov.items {
+		cell := ov.cells[i]
+		thumb := image.Rect(cell.Min.X, cell.Min.Y, cell.Max.X, cell.Max.Y-overviewLabelHeight)
+		if i == ov.selected {
+			fill(img, thumb.Inset(-overviewSelection), cfg.BorderColor)
+			fill(img, thumb, overviewBgColor)
+		}
+		for _, f := range item.frames {
+			r := thumbRect(f.cli.Geom(), item.area, thumb)
+			if r.Empty() {
+				continue
+			}
+			if shot, ok := ov.shots[f]; ok {
+				draw.Draw(img, r, xgraphics.Scale(shot, r.Dx(), r.Dy()), image.Point{}, draw.Src)
+				continue
+			}
+			// windows that weren't shown are drawn as their titlebars
+			fill(img, r, cfg.BgColorInactive)
+			fill(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+1), cfg.FontColorInactive)
+		}
+		fg := rgba(cfg.FontColorInactive)
+		if i == ov.selected {
+			fg = rgba(cfg.BorderColor)
+		}
+		label := img.SubImage(image.Rect(cell.Min.X, cell.Max.Y-overviewLabelHeight, cell.Max.X, cell.Max.Y))
+		if sub, ok := label.(*xgraphics.Image); ok && item.label != "" {
+			_, eh := cfg.Fonts.Extents(cfg.FontSize, item.label)
+			y := cell.Max.Y - overviewLabelHeight + (overviewLabelHeight-eh)/2
+			if _, err := cfg.Fonts.Draw(sub, cell.Min.X, y, fg, cfg.FontSize, item.label); err != nil {
+				return err
+			}
+		}
+	}
+	if err := img.CreatePixmap(); err != nil {
+		return err
+	}
+	img.XDraw()
+	img.XExpPaint(ov.win, 0, 0)
+	return nil
+}
+
+// fill paints the rectangle of the image with the ARGB color
+func fill(img draw.Image, r image.Rectangle, c uint32) {
+	draw.Draw(img, r, image.NewUniform(rgba(c)), image.Point{}, draw.Src)
+}
+
+// overviewGrid divides the overlay of the given size into cells for n thumbnails, each with a label below it,
+// in as many columns as rows or one more. It returns the number of columns and the cells, row by row
+func overviewGrid(n, width, height int) (int, []image.Rectangle) {
+	if n == 0 {
+		return 0, nil
+	}
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	rows := (n + cols - 1) / cols
+	w := (width - overviewPadding*(cols+1)) / cols
+	h := (height - overviewPadding*(rows+1)) / rows
+	cells := make([]image.Rectangle, n)
+	for i := range cells {
+		x := overviewPadding + (i%cols)*(w+overviewPadding)
+		y := overviewPadding + (i/cols)*(h+overviewPadding)
+		cells[i] = image.Rect(x, y, x+w, y+h)
+	}
+	return cols, cells
+}
+
+// thumbRect scales the geometry of a window within the area of the screen shown by a thumbnail down
+// to the thumbnail, keeping the proportions of the area and centering it
+func thumbRect(geom, area client.Geom, thumb image.Rectangle) image.Rectangle {
+	if area.W == 0 || area.H == 0 {
+		return image.Rectangle{}
+	}
+	scale := math.Min(float64(thumb.Dx())/float64(area.W), float64(thumb.Dy())/float64(area.H))
+	offX := thumb.Min.X + (thumb.Dx()-int(float64(area.W)*scale))/2
+	offY := thumb.Min.Y + (thumb.Dy()-int(float64(area.H)*scale))/2
+	pos := func(v, origin int16, off int) int { return off + int(float64(int(v)-int(origin))*scale) }
+	r := image.Rect(
+		pos(geom.X, area.X, offX),
+		pos(geom.Y, area.Y, offY),
+		pos(geom.X+int16(geom.W), area.X, offX),
+		pos(geom.Y+int16(geom.H), area.Y, offY),
+	)
+	return r.Intersect(thumb)
+}
+
+// gridMove returns the index of the item next to the given one, in the direction of dx or dy, in the grid
+// of n items placed row by row in the given number of columns. The index doesn't change at the edges
+func gridMove(i, n, cols, dx, dy int) int {
+	col, row := i%cols+dx, i/cols+dy
+	if col < 0 || col >= cols || row < 0 {
+		return i
+	}
+	j := row*cols + col
+	if j >= n {
+		return i
+	}
+	return j
+}
@@ -0,0 +1,88 @@
+package wm
+
+import (
+	"image"
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestOverviewGrid(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		wantCols int
+		wantLast image.Rectangle
+	}{
+		{"single", 1, 1, image.Rect(24, 24, 1896, 1056)},
+		{"two", 2, 2, image.Rect(972, 24, 1896, 1056)},
+		{"four", 4, 2, image.Rect(972, 552, 1896, 1056)},
+		{"five", 5, 3, image.Rect(656, 552, 1264, 1056)},
+		{"ten", 10, 4, image.Rect(498, 728, 948, 1056)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, cells := overviewGrid(tt.n, 1920, 1080)
+			if cols != tt.wantCols {
+				t.Errorf("overviewGrid() cols got = %v, want = %v", cols, tt.wantCols)
+			}
+			if len(cells) != tt.n {
+				t.Fatalf("overviewGrid() cells got = %v, want = %v", len(cells), tt.n)
+			}
+			if got := cells[tt.n-1]; got != tt.wantLast {
+				t.Errorf("overviewGrid() last cell got = %v, want = %v", got, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestGridMove(t *testing.T) {
+	// 0 1 2
+	// 3 4
+	tests := []struct {
+		name   string
+		i      int
+		dx, dy int
+		want   int
+	}{
+		{"right", 0, 1, 0, 1},
+		{"left", 4, -1, 0, 3},
+		{"down", 1, 0, 1, 4},
+		{"up", 3, 0, -1, 0},
+		{"past the left edge", 3, -1, 0, 3},
+		{"past the right edge", 2, 1, 0, 2},
+		{"past the top edge", 1, 0, -1, 1},
+		{"below the last row", 4, 0, 1, 4},
+		{"to a missing item", 2, 0, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gridMove(tt.i, 5, 3, tt.dx, tt.dy); got != tt.want {
+				t.Errorf("gridMove() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThumbRect(t *testing.T) {
+	area := client.Geom{X: 1920, Y: 0, W: 1920, H: 1080}
+	thumb := image.Rect(100, 100, 292, 208) // a tenth of the area
+	tests := []struct {
+		name string
+		geom client.Geom
+		want image.Rectangle
+	}{
+		{"whole area", area, thumb},
+		{"left half", client.Geom{X: 1920, Y: 0, W: 960, H: 1080}, image.Rect(100, 100, 196, 208)},
+		{"offset", client.Geom{X: 2120, Y: 100, W: 400, H: 300}, image.Rect(120, 110, 160, 140)},
+		{"partly outside", client.Geom{X: 3640, Y: 980, W: 400, H: 300}, image.Rect(272, 198, 292, 208)},
+		{"outside", client.Geom{X: 0, Y: 0, W: 400, H: 300}, image.Rectangle{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thumbRect(tt.geom, area, thumb); got != tt.want {
+				t.Errorf("thumbRect() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
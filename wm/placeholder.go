@@ -0,0 +1,288 @@
+package wm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/x11"
+	"github.com/patrislav/marwind/xdg"
+)
+
+// A layout saved to a file describes the columns and nested containers of a workspace, each window being
+// replaced by the criteria matching the windows that can take its place. Appending such a layout creates
+// placeholder frames, owning empty windows created by the WM, which are swallowed by the first new window
+// matching their criteria
+
+// layoutFile is the content of a file written by save_layout and read by append_layout
+type layoutFile struct {
+	Columns []sessionColumn `json:"columns"`
+}
+
+// layoutCriteria match the windows that take the place of a placeholder, empty criteria match any window
+type layoutCriteria struct {
+	Class    string `json:"class,omitempty"`    // class part of WM_CLASS
+	Instance string `json:"instance,omitempty"` // instance part of WM_CLASS
+	Title    string `json:"title,omitempty"`    // regular expression matched against the title
+}
+
+// rule converts the criteria to a rule that can be matched against the windows
+func (c layoutCriteria) rule() (Rule, error) {
+	r := Rule{Class: c.Class, Instance: c.Instance}
+	if c.Title != "" {
+		re, err := regexp.Compile(c.Title)
+		if err != nil {
+			return r, fmt.Errorf("invalid title %q: %v", c.Title, err)
+		}
+		r.Title = re
+	}
+	return r, nil
+}
+
+// ownedWindows gives the clients access to the windows created by the WM itself. Such windows cannot
+// be added to the save-set, there's nothing to give back to the root window once the WM exits anyway
+type ownedWindows struct {
+	*x11.Connection
+}
+
+func (ownedWindows) AddToSaveSet(window xproto.Window) error { return nil }
+
+// createPlaceholder creates an empty window, titled after the criteria, and the tiled frame of that window
+func (wm *WM) createPlaceholder(criteria []layoutCriteria) (*frame, error) {
+	for _, c := range criteria {
+		if _, err := c.rule(); err != nil {
+			return nil, err
+		}
+	}
+	mask := uint32(xproto.EventMaskStructureNotify | xproto.EventMaskEnterWindow | xproto.EventMaskPropertyChange)
+	win, err := wm.xc.CreateWindow(wm.xc.GetRootWindow(), 0, 0, 1, 1, 0, xproto.WindowClassInputOutput,
+		xproto.CwBackPixel|xproto.CwEventMask, []uint32{wm.windowConfig.BgColorInactive, mask})
+	if err != nil {
+		return nil, err
+	}
+	if err := wm.xc.SetWindowTitle(win, placeholderTitle(criteria)); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return nil, err
+	}
+	c, err := client.New(ownedWindows{wm.xc}, wm.windowConfig, win, client.TypeNormal)
+	if err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return nil, err
+	}
+	f := &frame{cli: c, placeholder: criteria}
+	if err := wm.grabFocusClick(f); err != nil {
+		logger.Errorf("Failed to grab the pointer buttons of the placeholder: %v", err)
+	}
+	return f, nil
+}
+
+// placeholderTitle describes the windows awaited by a placeholder, e.g. "Waiting for Firefox"
+func placeholderTitle(criteria []layoutCriteria) string {
+	var names []string
+	for _, c := range criteria {
+		switch {
+		case c.Class != "":
+			names = append(names, c.Class)
+		case c.Instance != "":
+			names = append(names, c.Instance)
+		case c.Title != "":
+			names = append(names, c.Title)
+		}
+	}
+	if len(names) == 0 {
+		return "Waiting for a window"
+	}
+	return "Waiting for " + strings.Join(names, " or ")
+}
+
+// matchingPlaceholder returns the first tiled placeholder, in the order of the workspaces, with criteria
+// matching the window
+func (wm *WM) matchingPlaceholder(win xproto.Window) *frame {
+	var info windowInfo
+	info.instance, info.class, _ = wm.xc.GetWMClass(win)
+	info.title, _ = wm.xc.GetWindowTitle(win)
+	return wm.findFrame(func(f *frame) bool {
+		if f.placeholder == nil || f.col == nil {
+			return false
+		}
+		for _, c := range f.placeholder {
+			if r, err := c.rule(); err == nil && wm.ruleMatches(r, info) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// fillPlaceholder puts the new frame in the place of the placeholder, which is destroyed
+func (wm *WM) fillPlaceholder(ph, f *frame) error {
+	ws := ph.workspace()
+	ph.col.replaceFrame(ph, f)
+	f.ws = ws
+	f.floating = false
+	f.marks = ph.marks
+	ws.forgetFocus(ph)
+	if ph.fullscreen {
+		f.fullscreen = true
+		ws.setFullscreenFrame(f)
+	}
+	if err := ph.cli.OnDestroy(); err != nil {
+		return err
+	}
+	if err := wm.drawMarks(f); err != nil {
+		return err
+	}
+	if ws.output.activeWs == ws && !f.state.hidden {
+		return f.cli.Map()
+	}
+	return nil
+}
+
+// destroyPlaceholders removes all the placeholders, e.g. before the WM exits
+func (wm *WM) destroyPlaceholders() {
+	for _, f := range wm.allFrames() {
+		if f.placeholder != nil {
+			wm.destroyPlaceholder(f)
+		}
+	}
+}
+
+func (wm *WM) destroyPlaceholder(f *frame) {
+	if err := wm.deleteFrame(f); err != nil {
+		logger.Errorf("Failed to delete the placeholder: %v", err)
+	}
+	if err := f.cli.OnDestroy(); err != nil {
+		logger.Errorf("Failed to destroy the placeholder: %v", err)
+	}
+}
+
+// saveLayout writes the layout of the tiled frames of the workspace to the file, each window being
+// replaced by its WM_CLASS, so that it can be appended to an empty workspace later on
+func (wm *WM) saveLayout(ws *workspace, path string) error {
+	var lf layoutFile
+	a := ws.area()
+	widths := splitLength(ws.columnWeights(), a.W)
+	for i, col := range ws.columns {
+		sc := saveColumn(col, a)
+		sc.Width = widths[i]
+		wm.swallowWindows(&sc)
+		lf.Columns = append(lf.Columns, sc)
+	}
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// swallowWindows replaces the windows of the saved column by the criteria matching their class and instance
+func (wm *WM) swallowWindows(sc *sessionColumn) {
+	for i := range sc.Frames {
+		sf := &sc.Frames[i]
+		if sf.Split != nil {
+			wm.swallowWindows(sf.Split)
+		}
+		if sf.Window == 0 {
+			continue
+		}
+		var c layoutCriteria
+		c.Instance, c.Class, _ = wm.xc.GetWMClass(xproto.Window(sf.Window))
+		sf.Window = 0
+		sf.Swallows = []layoutCriteria{c}
+	}
+}
+
+// appendLayout reads the layout from the file and adds its columns, made of placeholders, to the empty workspace
+func (wm *WM) appendLayout(ws *workspace, path string) error {
+	if len(ws.frames()) > 0 {
+		return fmt.Errorf("workspace %q is not empty", ws.name)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var lf layoutFile
+	if err := json.Unmarshal(data, &lf); err != nil {
+		return fmt.Errorf("failed to parse the layout: %v", err)
+	}
+	var created []*frame
+	var failed error
+	create := func(sf sessionFrame) *frame {
+		if len(sf.Swallows) == 0 || failed != nil {
+			return nil
+		}
+		f, err := wm.createPlaceholder(sf.Swallows)
+		if err != nil {
+			failed = err
+			return nil
+		}
+		created = append(created, f)
+		return f
+	}
+	for _, sc := range lf.Columns {
+		col := ws.createColumn(false)
+		restoreColumn(col, sc, create)
+		if len(col.frames) == 0 {
+			ws.deleteColumn(col)
+			continue
+		}
+		if sc.Width > 0 {
+			col.weight = float64(sc.Width)
+		}
+	}
+	if failed != nil {
+		for _, f := range created {
+			wm.destroyPlaceholder(f)
+		}
+		return fmt.Errorf("failed to create a placeholder: %v", failed)
+	}
+	if ws.output.activeWs != ws {
+		return nil
+	}
+	return wm.renderWorkspace(ws)
+}
+
+// cmdSaveLayout writes the layout of the current workspace to a file: save_layout <path>
+func cmdSaveLayout(wm *WM, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: save_layout <path>")
+	}
+	path, err := layoutPath(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	return wm.saveLayout(wm.currentOutput().activeWs, path)
+}
+
+// cmdAppendLayout adds the placeholders of a saved layout to the current, empty, workspace: append_layout <path>
+func cmdAppendLayout(wm *WM, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: append_layout <path>")
+	}
+	path, err := layoutPath(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	return wm.appendLayout(wm.currentOutput().activeWs, path)
+}
+
+// layoutPath resolves the path given to the layout commands: a leading ~ is the home directory and
+// a relative path is relative to the layouts directory of the WM in $XDG_STATE_HOME
+// (~/.local/state/marwind/layouts), with the .json extension added if there's none
+func layoutPath(path string) (string, error) {
+	path = xdg.ExpandHome(path)
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	if filepath.Ext(path) == "" {
+		path += ".json"
+	}
+	return xdg.StateFile(filepath.Join("layouts", path))
+}
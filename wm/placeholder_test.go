@@ -0,0 +1,53 @@
+package wm
+
+import "testing"
+
+func TestPlaceholderTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria []layoutCriteria
+		want     string
+	}{
+		{"class", []layoutCriteria{{Class: "Firefox", Instance: "Navigator"}}, "Waiting for Firefox"},
+		{"instance", []layoutCriteria{{Instance: "htop"}}, "Waiting for htop"},
+		{"several", []layoutCriteria{{Class: "XTerm"}, {Title: "^vim"}}, "Waiting for XTerm or ^vim"},
+		{"any window", []layoutCriteria{{}}, "Waiting for a window"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := placeholderTitle(tt.criteria); got != tt.want {
+				t.Errorf("placeholderTitle() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutCriteriaRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria layoutCriteria
+		info     windowInfo
+		want     bool
+		wantErr  bool
+	}{
+		{"class", layoutCriteria{Class: "XTerm"}, windowInfo{class: "XTerm", instance: "xterm"}, true, false},
+		{"other class", layoutCriteria{Class: "XTerm"}, windowInfo{class: "URxvt"}, false, false},
+		{"title", layoutCriteria{Instance: "xterm", Title: "^vim"}, windowInfo{instance: "xterm", title: "vim main.go"}, true, false},
+		{"other title", layoutCriteria{Title: "^vim"}, windowInfo{title: "htop"}, false, false},
+		{"invalid title", layoutCriteria{Title: "("}, windowInfo{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.criteria.rule()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rule() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := (&WM{}).ruleMatches(r, tt.info); got != tt.want {
+				t.Errorf("ruleMatches() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
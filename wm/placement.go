@@ -0,0 +1,42 @@
+package wm
+
+import "fmt"
+
+// Policies of placing the new tiled windows, see Config.Placement
+const (
+	PlacementAuto          = "auto"           // a column of its own for each of the first two windows, then the last column
+	PlacementNewColumn     = "new_column"     // a new column right of the focused window
+	PlacementFocusedColumn = "focused_column" // the end of the column of the focused window
+	PlacementAfterFocused  = "after_focused"  // right below the focused window, in its column
+)
+
+// placeNewFrame adds a new tiled frame to the workspace, after the frame marked with "place here" if it's
+// on the same workspace, otherwise according to the placement policy from the config
+func (wm *WM) placeNewFrame(f *frame, ws *workspace) error {
+	if m := wm.placeMark; m != nil && m.col != nil && m.col.ws == ws {
+		wm.placeMark = nil
+		return ws.placeFrame(f, PlacementAfterFocused, m)
+	}
+	return ws.placeFrame(f, wm.config.Placement, ws.lastFocusedTiled())
+}
+
+// cmdPlace marks the focused tiled window so that the next window opened on its workspace is placed right
+// below it, regardless of the placement policy: place <here|clear>
+func cmdPlace(wm *WM, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: place <here|clear>")
+	}
+	switch args[0] {
+	case "here":
+		f := wm.targetFrame()
+		if f == nil || f.col == nil {
+			return nil
+		}
+		wm.placeMark = f
+	case "clear":
+		wm.placeMark = nil
+	default:
+		return fmt.Errorf("usage: place <here|clear>")
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package wm
+
+import (
+	"image"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
+)
+
+// prompt is a popup window drawn by the WM at the top of the current output: a line of text typed by the user
+// above the entries fuzzy-matching it, the best match first. While it's open the pointer and keyboard are
+// grabbed; Return or a click picks the selected entry, Escape or a click outside of the prompt closes it
+type prompt struct {
+	win      xproto.Window
+	geom     client.Geom
+	label    string // shown before the typed text, e.g. "window"
+	input    []rune
+	items    []promptItem
+	matches  []int // indices of the items matching the input, the best match first
+	selected int   // index in matches
+
+	// pick is called with the index of the picked item after the prompt is closed, and the time of
+	// the key press or click that picked it
+	pick func(i int, t xproto.Timestamp) error
+}
+
+// promptItem is an entry of the prompt
+type promptItem struct {
+	label string // shown in the list
+	text  string // matched against the input
+}
+
+const (
+	promptWidth   = 600 // at most, narrower outputs get a prompt of their width
+	promptRows    = 10  // entries shown at most
+	promptMargin  = 48  // space between the top of the output and the prompt
+	promptPadding = 8   // horizontal space around the text
+)
+
+// openPrompt shows a prompt with the entries on the current output, calling pick with the index of the entry
+// the user picks
+func (wm *WM) openPrompt(label string, items []promptItem, pick func(i int, t xproto.Timestamp) error) error {
+	if wm.prompt != nil || wm.overview != nil || wm.menu != nil || wm.drag != nil || wm.cycle != nil {
+		return nil
+	}
+	o := wm.currentOutput()
+	if o == nil {
+		return nil
+	}
+	p := &prompt{label: label, items: items, pick: pick}
+	p.geom = wm.promptGeom(o.geom, 0)
+	win, err := wm.xc.CreateWindow(wm.xc.GetRootWindow(), p.geom.X, p.geom.Y, p.geom.W, p.geom.H, 0,
+		xproto.WindowClassInputOutput, xproto.CwBackPixel|xproto.CwOverrideRedirect|xproto.CwEventMask,
+		[]uint32{wm.windowConfig.BgColorInactive, 1, xproto.EventMaskExposure})
+	if err != nil {
+		return err
+	}
+	p.win = win
+	if err := wm.xc.MapWindow(win); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return err
+	}
+	if err := wm.grabMenuInput(xproto.TimeCurrentTime); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return err
+	}
+	wm.prompt = p
+	return wm.updatePrompt(o)
+}
+
+// closePrompt destroys the prompt window and releases the grabs
+func (wm *WM) closePrompt(t xproto.Timestamp) error {
+	p := wm.prompt
+	if p == nil {
+		return nil
+	}
+	wm.prompt = nil
+	if err := xproto.UngrabKeyboardChecked(wm.xc.X(), t).Check(); err != nil {
+		logger.Errorf("Failed to ungrab the keyboard: %v", err)
+	}
+	if err := xproto.UngrabPointerChecked(wm.xc.X(), t).Check(); err != nil {
+		logger.Errorf("Failed to ungrab the pointer: %v", err)
+	}
+	return wm.xc.DestroyWindow(p.win)
+}
+
+// pickPrompt closes the prompt, then calls its pick function with the selected entry, if any
+func (wm *WM) pickPrompt(t xproto.Timestamp) error {
+	p := wm.prompt
+	if err := wm.closePrompt(t); err != nil {
+		return err
+	}
+	if p.selected >= len(p.matches) {
+		return nil
+	}
+	return p.pick(p.matches[p.selected], t)
+}
+
+// updatePrompt matches the entries against the input, then resizes the prompt to fit them and redraws it
+func (wm *WM) updatePrompt(o *output) error {
+	p := wm.prompt
+	p.matches = fuzzyFilter(string(p.input), p.items)
+	p.selected = 0
+	if o == nil {
+		o = wm.outputAt(p.geom.X, p.geom.Y)
+	}
+	if o != nil {
+		geom := wm.promptGeom(o.geom, len(p.matches))
+		if geom != p.geom {
+			p.geom = geom
+			mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+			values := []uint32{uint32(geom.X), uint32(geom.Y), uint32(geom.W), uint32(geom.H)}
+			if err := xproto.ConfigureWindowChecked(wm.xc.X(), p.win, mask, values).Check(); err != nil {
+				return err
+			}
+		}
+	}
+	return wm.drawPrompt()
+}
+
+// promptGeom returns the geometry of a prompt listing the given number of entries at the top of the output
+func (wm *WM) promptGeom(bounds client.Geom, matches int) client.Geom {
+	if matches > promptRows {
+		matches = promptRows
+	}
+	w := promptWidth
+	if w > int(bounds.W) {
+		w = int(bounds.W)
+	}
+	h := (matches + 1) * wm.menuItemHeight()
+	return client.Geom{
+		X: bounds.X + int16((int(bounds.W)-w)/2),
+		Y: bounds.Y + promptMargin,
+		W: uint16(w),
+		H: uint16(h),
+	}
+}
+
+// handlePromptKey edits the input with the typed characters and BackSpace, moves the selection with
+// the arrows and Tab, picks the selected entry with Return and closes the prompt with Escape
+func (wm *WM) handlePromptKey(e xproto.KeyPressEvent) error {
+	p := wm.prompt
+	group := keysym.Group(e.State)
+	sym := wm.keymap.Keysym(e.Detail, group)
+	switch sym {
+	case keysym.XKReturn:
+		return wm.pickPrompt(e.Time)
+	case keysym.XKEscape:
+		return wm.closePrompt(e.Time)
+	case keysym.XKUp:
+		if p.selected > 0 {
+			p.selected--
+		}
+		return wm.drawPrompt()
+	case keysym.XKDown, keysym.XKTab:
+		if p.selected < len(p.matches)-1 && p.selected < promptRows-1 {
+			p.selected++
+		}
+		return wm.drawPrompt()
+	case keysym.XKBackSpace:
+		if len(p.input) == 0 {
+			return nil
+		}
+		p.input = p.input[:len(p.input)-1]
+		return wm.updatePrompt(nil)
+	}
+	if e.State&(xproto.ModMaskControl|xproto.ModMask1|xproto.ModMask4) != 0 {
+		return nil
+	}
+	shift := e.State&xproto.ModMaskShift != 0
+	if shift {
+		sym = wm.keymap.ShiftedKeysym(e.Detail, group)
+	}
+	r := keysym.Rune(sym)
+	if r == 0 {
+		return nil
+	}
+	if e.State&xproto.ModMaskLock != 0 {
+		if shift {
+			r = unicode.ToLower(r)
+		} else {
+			r = unicode.ToUpper(r)
+		}
+	}
+	p.input = append(p.input, r)
+	return wm.updatePrompt(nil)
+}
+
+// contains returns true if the position relative to the root window is within the prompt
+func (p *prompt) contains(x, y int16) bool {
+	return x >= p.geom.X && y >= p.geom.Y && int(x) < int(p.geom.X)+int(p.geom.W) && int(y) < int(p.geom.Y)+int(p.geom.H)
+}
+
+// handlePromptButtonPress picks the clicked entry, clicking outside of the prompt closes it
+func (wm *WM) handlePromptButtonPress(e xproto.ButtonPressEvent) error {
+	p := wm.prompt
+	if !p.contains(e.RootX, e.RootY) {
+		return wm.closePrompt(e.Time)
+	}
+	// the first row is the input
+	i := int(e.RootY-p.geom.Y)/wm.menuItemHeight() - 1
+	if i < 0 || i >= len(p.matches) {
+		return nil
+	}
+	p.selected = i
+	return wm.pickPrompt(e.Time)
+}
+
+// drawPrompt paints the input and the matching entries below it, the selected one in the colors of
+// the focused titlebars
+func (wm *WM) drawPrompt() error {
+	p := wm.prompt
+	cfg := wm.windowConfig
+	h := wm.menuItemHeight()
+	img := wm.xc.NewImage(image.Rect(0, 0, int(p.geom.W), int(p.geom.H)))
+	defer img.Destroy()
+	rows := []string{p.label + ": " + string(p.input) + "_"}
+	for i, m := range p.matches {
+		if i == promptRows {
+			break
+		}
+		rows = append(rows, p.items[m].label)
+	}
+	for i, text := range rows {
+		bg, fg := cfg.BgColorInactive, rgba(cfg.FontColorInactive)
+		if i == p.selected+1 {
+			bg, fg = cfg.BgColor, rgba(cfg.FontColor)
+		}
+		row := image.Rect(0, i*h, int(p.geom.W), (i+1)*h)
+		fill(img, row, bg)
+		_, eh := cfg.Fonts.Extents(cfg.FontSize, text)
+		if _, err := cfg.Fonts.Draw(img, promptPadding, row.Min.Y+(h-eh)/2, fg, cfg.FontSize, text); err != nil {
+			return err
+		}
+	}
+	if err := img.CreatePixmap(); err != nil {
+		return err
+	}
+	img.XDraw()
+	img.XExpPaint(p.win, 0, 0)
+	return nil
+}
+
+// fuzzyFilter returns the indices of the items whose text contains the characters of the pattern in order,
+// ignoring the case, the best match first. With an empty pattern all the items match, in their order
+func fuzzyFilter(pattern string, items []promptItem) []int {
+	type match struct{ i, score int }
+	var matches []match
+	for i, item := range items {
+		if score, ok := fuzzyMatch(pattern, item.text); ok {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	indices := make([]int, len(matches))
+	for i, m := range matches {
+		indices[i] = m.i
+	}
+	return indices
+}
+
+// fuzzyMatch checks whether the text contains the characters of the pattern in order, ignoring the case.
+// The score of a match is higher the more of the characters follow one another or start words of the text,
+// the best of the matches starting at each occurrence of the first character is taken
+func fuzzyMatch(pattern, text string) (int, bool) {
+	pat := []rune(strings.ToLower(pattern))
+	runes := []rune(strings.ToLower(text))
+	if len(pat) == 0 {
+		return 0, true
+	}
+	best, found := 0, false
+	for start, r := range runes {
+		if r != pat[0] {
+			continue
+		}
+		if score, ok := fuzzyScore(pat, runes, start); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// fuzzyScore matches the pattern greedily against the text from the given position
+func fuzzyScore(pat, runes []rune, start int) (int, bool) {
+	score, prev, j := 0, -2, 0
+	for i := start; i < len(runes) && j < len(pat); i++ {
+		if runes[i] != pat[j] {
+			continue
+		}
+		switch {
+		case i == prev+1:
+			score += 3 // consecutive characters
+		case i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]):
+			score += 2 // start of a word
+		default:
+			score++
+		}
+		prev = i
+		j++
+	}
+	return score, j == len(pat)
+}
@@ -0,0 +1,70 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          bool
+	}{
+		{"", "Firefox", true},
+		{"ffx", "Firefox", true},
+		{"FIRE", "firefox", true},
+		{"xf", "Firefox", false},
+		{"term", "Alacritty", false},
+		{"kz", "Załącznik", false},
+		{"zł", "Załącznik", true},
+	}
+	for _, tt := range tests {
+		if _, got := fuzzyMatch(tt.pattern, tt.text); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) got = %v, want = %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	items := []promptItem{
+		{text: "mail - Thunderbird"},
+		{text: "Firefox"},
+		{text: "vim main.go - Alacritty"},
+		{text: "GIMP"},
+	}
+	tests := []struct {
+		pattern string
+		want    []int
+	}{
+		{"", []int{0, 1, 2, 3}},
+		{"mai", []int{0, 2}},
+		{"main", []int{2, 0}},
+		{"fi", []int{1}},
+		{"gi", []int{3, 2}},
+		{"qq", []int{}},
+	}
+	for _, tt := range tests {
+		if got := fuzzyFilter(tt.pattern, items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fuzzyFilter(%q) got = %v, want = %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestGotoItem(t *testing.T) {
+	tests := []struct {
+		name                       string
+		ws, class, instance, title string
+		wantLabel, wantText        string
+	}{
+		{"with class", "2", "Firefox", "Navigator", "News", "[2] Firefox - News", "News Firefox Navigator"},
+		{"without class", "web", "", "", "News", "[web] News", "News  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gotoItem(tt.ws, tt.class, tt.instance, tt.title)
+			if got.label != tt.wantLabel || got.text != tt.wantText {
+				t.Errorf("gotoItem() got = %+v, want = {%q %q}", got, tt.wantLabel, tt.wantText)
+			}
+		})
+	}
+}
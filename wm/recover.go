@@ -0,0 +1,166 @@
+package wm
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/logger"
+)
+
+// A window that fails maxFaults times within faultPeriod is no longer managed, see recordFault
+const (
+	maxFaults   = 3
+	faultPeriod = time.Minute
+)
+
+// faultKind classifies the failures of the event loop
+type faultKind int
+
+const (
+	faultPanic   faultKind = iota // a handler panicked
+	faultGone                     // X error about a window or drawable that doesn't exist (anymore)
+	faultRequest                  // any other X error, e.g. about a request with values rejected by the X server
+)
+
+func (k faultKind) String() string {
+	switch k {
+	case faultPanic:
+		return "panic"
+	case faultGone:
+		return "missing window"
+	}
+	return "failed request"
+}
+
+// faults counts the recent failures of a window
+type faults struct {
+	count int
+	since time.Time // when the first of the counted failures happened
+}
+
+// safely runs fn, recovering from a panic so that a bug triggered by a single event doesn't bring down
+// the whole session. The panic is logged with its stack trace and counted against the given window, if any
+func (wm *WM) safely(win xproto.Window, what string, fn func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			logger.Errorf("Recovered from a panic while handling %s: %v\n%s", what, r, debug.Stack())
+			if win != 0 {
+				wm.recordFault(win, faultPanic, time.Now())
+			}
+		}
+	}()
+	fn()
+	return false
+}
+
+// classifyXError tells the kind of an X error reported asynchronously, i.e. of a request whose reply
+// or error wasn't waited for, and the window it's about, 0 if it isn't about a window
+func classifyXError(err xgb.Error) (faultKind, xproto.Window) {
+	switch err.(type) {
+	case xproto.WindowError, xproto.DrawableError:
+		return faultGone, xproto.Window(err.BadId())
+	}
+	return faultRequest, 0
+}
+
+// handleXError logs an X error reported asynchronously, counting it against the managed window it's about.
+// Windows destroyed by their clients while the WM still had requests about them on the way are a common
+// occurrence, their errors are only logged in debug mode
+func (wm *WM) handleXError(err xgb.Error) {
+	kind, win := classifyXError(err)
+	if win == 0 {
+		logger.Errorf("X error (%v): %v", kind, err)
+		return
+	}
+	if wm.faultyFrame(win) == nil {
+		logger.Debugf("X error about an unmanaged window %d: %v", win, err)
+		return
+	}
+	logger.Warnf("X error about window %d (%v): %v", win, kind, err)
+	wm.recordFault(win, kind, time.Now())
+}
+
+// faultyFrame returns the frame of the client window or frame window
+func (wm *WM) faultyFrame(win xproto.Window) *frame {
+	return wm.findFrame(func(f *frame) bool { return f.cli.Window() == win || f.cli.Parent() == win })
+}
+
+// recordFault counts a failure against the frame of the window and stops managing the window once it keeps failing
+func (wm *WM) recordFault(win xproto.Window, kind faultKind, now time.Time) {
+	f := wm.faultyFrame(win)
+	if f == nil {
+		return
+	}
+	if wm.faults == nil {
+		wm.faults = make(map[xproto.Window]*faults)
+	}
+	if !countFault(wm.faults, f.cli.Window(), now) {
+		return
+	}
+	logger.Warnf("Window %d keeps failing (last: %v), no longer managing it", f.cli.Window(), kind)
+	if err := wm.dropFrame(f, kind == faultGone); err != nil {
+		logger.Errorf("Failed to release window %d: %v", f.cli.Window(), err)
+	}
+	if err := wm.updateDesktopHints(); err != nil {
+		logger.Errorf("Failed to update desktop hints: %v", err)
+	}
+}
+
+// countFault adds a failure to the count of the window, returning true once the window failed maxFaults
+// times within faultPeriod. The count starts over after that
+func countFault(counts map[xproto.Window]*faults, win xproto.Window, now time.Time) bool {
+	c := counts[win]
+	if c == nil || now.Sub(c.since) > faultPeriod {
+		c = &faults{since: now}
+		counts[win] = c
+	}
+	c.count++
+	if c.count < maxFaults {
+		return false
+	}
+	delete(counts, win)
+	return true
+}
+
+// dropFrame stops managing the window of the frame, giving it back to the root window unless it's gone
+func (wm *WM) dropFrame(f *frame, gone bool) error {
+	if gone || wm.releaseFrame(f) != nil {
+		if err := f.cli.OnDestroy(); err != nil {
+			return err
+		}
+	}
+	return wm.deleteFrame(f)
+}
+
+// eventWindow returns the window an X event is about, whose frame is blamed when handling the event fails,
+// or 0 for the events not caused by a particular client, e.g. key presses
+func eventWindow(xev xgb.Event) xproto.Window {
+	switch e := xev.(type) {
+	case xproto.EnterNotifyEvent:
+		return e.Event
+	case xproto.FocusInEvent:
+		return e.Event
+	case xproto.FocusOutEvent:
+		return e.Event
+	case xproto.ConfigureRequestEvent:
+		return e.Window
+	case xproto.MapNotifyEvent:
+		return e.Window
+	case xproto.MapRequestEvent:
+		return e.Window
+	case xproto.UnmapNotifyEvent:
+		return e.Window
+	case xproto.DestroyNotifyEvent:
+		return e.Window
+	case xproto.PropertyNotifyEvent:
+		return e.Window
+	case xproto.ClientMessageEvent:
+		return e.Window
+	case xproto.ExposeEvent:
+		return e.Window
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestCountFault(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		offset []time.Duration // of the failures of the window since start
+		want   []bool
+	}{
+		{"below the limit", []time.Duration{0, time.Second}, []bool{false, false}},
+		{"limit reached", []time.Duration{0, time.Second, 2 * time.Second}, []bool{false, false, true}},
+		{"count starts over", []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second}, []bool{false, false, true, false}},
+		{"old failures forgotten", []time.Duration{0, time.Second, 2 * faultPeriod, 2*faultPeriod + time.Second}, []bool{false, false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := make(map[xproto.Window]*faults)
+			var got []bool
+			for _, d := range tt.offset {
+				got = append(got, countFault(counts, 10, start.Add(d)))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyXError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      xgb.Error
+		wantKind faultKind
+		wantWin  xproto.Window
+	}{
+		{"window", xproto.WindowError{BadValue: 10}, faultGone, 10},
+		{"drawable", xproto.DrawableError{BadValue: 11}, faultGone, 11},
+		{"match", xproto.MatchError{BadValue: 12}, faultRequest, 0},
+		{"value", xproto.ValueError{BadValue: 13}, faultRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, win := classifyXError(tt.err)
+			if kind != tt.wantKind || win != tt.wantWin {
+				t.Errorf("got = %v %v, want = %v %v", kind, win, tt.wantKind, tt.wantWin)
+			}
+		})
+	}
+}
+
+func TestSafely(t *testing.T) {
+	wm := &WM{}
+	if got := wm.safely(0, "a test", func() { panic("test") }); !got {
+		t.Errorf("got = %v, want = %v", got, true)
+	}
+	if got := wm.safely(0, "a test", func() {}); got {
+		t.Errorf("got = %v, want = %v", got, false)
+	}
+}
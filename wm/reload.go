@@ -0,0 +1,78 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// reload loads the configuration again and applies it without restarting the WM.
+// If the configuration cannot be loaded, the current one is kept
+func (wm *WM) reload() error {
+	if wm.configLoader == nil {
+		return fmt.Errorf("reloading is not supported")
+	}
+	cfg, err := wm.configLoader()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+	if err := wm.applyConfig(cfg); err != nil {
+		return err
+	}
+	wm.runReloadCommands()
+	return nil
+}
+
+// applyConfig replaces the configuration of the WM, updating the keybindings,
+// gaps and decorations of all the windows
+func (wm *WM) applyConfig(cfg Config) error {
+	autoHide := wm.config.DockAutoHide
+	wm.config = cfg
+	if err := applyLogging(cfg); err != nil {
+		logger.Errorf("Failed to set up logging: %v", err)
+	}
+	*wm.windowConfig = newWindowConfig(cfg)
+	wm.updateClientConfigs()
+	wm.scaleOutputs()
+
+	wm.resetMode()
+	if err := wm.regrabKeys(); err != nil {
+		return err
+	}
+	if err := wm.regrabButtons(); err != nil {
+		return err
+	}
+
+	for _, ws := range wm.workspaces {
+		ws.config.gap = cfg.OuterGap
+		ws.config.innerGap = cfg.InnerGap
+	}
+	if err := wm.updateFocusModel(); err != nil {
+		logger.Errorf("Failed to update the focus model: %v", err)
+	}
+	if cfg.DockAutoHide != autoHide {
+		if err := wm.setDocksAutoHide(cfg.DockAutoHide); err != nil {
+			logger.Errorf("Failed to update the docks: %v", err)
+		}
+	}
+	wm.sortAllDocks()
+	wm.watchPointer()
+	wm.applyDPMS()
+	wm.applyWallpaper()
+	wm.applyCursorTheme()
+	wm.watchIdle()
+	if err := wm.renderOutputs(); err != nil {
+		return fmt.Errorf("failed to render outputs: %v", err)
+	}
+	for _, ws := range wm.workspaces {
+		for _, f := range ws.frames() {
+			if err := f.cli.Draw(); err != nil {
+				logger.Errorf("Failed to draw client %d: %v", f.cli.Window(), err)
+			}
+			if err := wm.drawMarks(f); err != nil {
+				logger.Errorf("Failed to draw the marks of client %d: %v", f.cli.Window(), err)
+			}
+		}
+	}
+	return nil
+}
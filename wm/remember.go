@@ -0,0 +1,126 @@
+package wm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/xdg"
+)
+
+// floatingGeomsFile is the file in the state directory of the WM ($XDG_STATE_HOME/marwind) in which the last
+// geometries of the floating windows are remembered across sessions, see rememberFloatingGeom
+const floatingGeomsFile = "floating.json"
+
+// floatingGeomKey returns the key under which the geometry of a floating frame is remembered: both parts of
+// its WM_CLASS, e.g. "firefox/Dialog", empty for the frames whose geometry isn't remembered
+func floatingGeomKey(f *frame) string {
+	if f.cli.Type() != client.TypeNormal || !f.cli.Role().Focusable() {
+		return ""
+	}
+	if f.cli.Class() == "" && f.cli.Instance() == "" {
+		return ""
+	}
+	return f.cli.Class() + "/" + f.cli.Instance()
+}
+
+// rememberFloatingGeom saves the geometry of the floating frame when it's closed, so that the next window of
+// the same application is placed the same way. Maximized frames leave the geometry they are restored to
+func (wm *WM) rememberFloatingGeom(f *frame) {
+	if !wm.config.FloatingRemember || !f.floating {
+		return
+	}
+	key := floatingGeomKey(f)
+	if key == "" {
+		return
+	}
+	geom := f.floatGeom
+	if old, ok := wm.floatingGeoms[key]; ok && old == geom {
+		return
+	}
+	if wm.floatingGeoms == nil {
+		wm.floatingGeoms = make(map[string]client.Geom)
+	}
+	wm.floatingGeoms[key] = geom
+	path, err := xdg.StateFile(floatingGeomsFile)
+	if err == nil {
+		err = saveFloatingGeoms(path, wm.floatingGeoms)
+	}
+	if err != nil {
+		logger.Errorf("Failed to save the geometry of window %d: %v", f.cli.Window(), err)
+	}
+}
+
+// rememberedFloatingGeom returns the remembered geometry of the floating windows of the same application as
+// the frame, fitted within the workspace it's placed on, false if there's none
+func (wm *WM) rememberedFloatingGeom(f *frame, ws *workspace) (client.Geom, bool) {
+	if !wm.config.FloatingRemember || ws.output == nil {
+		return client.Geom{}, false
+	}
+	key := floatingGeomKey(f)
+	if key == "" {
+		return client.Geom{}, false
+	}
+	geom, ok := wm.floatingGeoms[key]
+	if !ok {
+		return client.Geom{}, false
+	}
+	return fitFloatingGeom(geom, ws.output.geom, ws.area()), true
+}
+
+// fitFloatingGeom places a remembered geometry on the output with the given bounds and workspace area: it's
+// kept where it was if its middle is on the output, otherwise centered in the area, e.g. when it was on another
+// output. Geometries larger than the area are shrunk to fill it along that axis
+func fitFloatingGeom(geom, bounds, area client.Geom) client.Geom {
+	if geom.W > area.W {
+		geom.X, geom.W = area.X, area.W
+	}
+	if geom.H > area.H {
+		geom.Y, geom.H = area.Y, area.H
+	}
+	x, y := int(geom.X)+int(geom.W)/2, int(geom.Y)+int(geom.H)/2
+	if x < int(bounds.X) || y < int(bounds.Y) || x >= int(bounds.X)+int(bounds.W) || y >= int(bounds.Y)+int(bounds.H) {
+		return centerOver(area, geom.W, geom.H)
+	}
+	return geom
+}
+
+// loadRememberedGeoms reads the geometries of the floating windows remembered by the previous sessions, even
+// with remembering disabled, so that enabling it on reload doesn't drop them from the file
+func (wm *WM) loadRememberedGeoms() {
+	path, err := xdg.StateFile(floatingGeomsFile)
+	if err == nil {
+		wm.floatingGeoms, err = loadFloatingGeoms(path)
+	}
+	if err != nil {
+		logger.Errorf("Failed to load the geometries of the floating windows: %v", err)
+	}
+}
+
+// saveFloatingGeoms writes the remembered geometries to the file
+func saveFloatingGeoms(path string, geoms map[string]client.Geom) error {
+	data, err := json.Marshal(geoms)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// loadFloatingGeoms reads the remembered geometries from the file, none if it doesn't exist yet
+func loadFloatingGeoms(path string) (map[string]client.Geom, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var geoms map[string]client.Geom
+	if err := json.Unmarshal(data, &geoms); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return geoms, nil
+}
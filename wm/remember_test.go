@@ -0,0 +1,69 @@
+package wm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestFitFloatingGeom(t *testing.T) {
+	bounds := client.Geom{X: 1920, Y: 0, W: 1920, H: 1080}
+	area := client.Geom{X: 1930, Y: 40, W: 1900, H: 1030}
+	tests := []struct {
+		name string
+		geom client.Geom
+		want client.Geom
+	}{
+		{"on output", client.Geom{X: 2000, Y: 100, W: 600, H: 400}, client.Geom{X: 2000, Y: 100, W: 600, H: 400}},
+		{"partly off", client.Geom{X: 3500, Y: 600, W: 600, H: 400}, client.Geom{X: 3500, Y: 600, W: 600, H: 400}},
+		{"other output", client.Geom{X: 100, Y: 100, W: 600, H: 400}, client.Geom{X: 2580, Y: 355, W: 600, H: 400}},
+		{"too large", client.Geom{X: 1920, Y: 0, W: 2560, H: 1440}, client.Geom{X: 1930, Y: 40, W: 1900, H: 1030}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitFloatingGeom(tt.geom, bounds, area); got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloatingGeoms(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		geoms, err := loadFloatingGeoms(filepath.Join(t.TempDir(), "floating.json"))
+		if err != nil || geoms != nil {
+			t.Errorf("got = %v, %v, want = nil, nil", geoms, err)
+		}
+	})
+
+	t.Run("saved", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "floating.json")
+		want := map[string]client.Geom{
+			"firefox/Dialog": {X: 400, Y: 200, W: 900, H: 600},
+			"mpv/gl":         {X: -10, Y: 0, W: 1280, H: 720},
+		}
+		if err := saveFloatingGeoms(path, want); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := loadFloatingGeoms(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "floating.json")
+		if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadFloatingGeoms(path); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
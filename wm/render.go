@@ -1,17 +1,20 @@
 package wm
 
 import (
-	"github.com/BurntSushi/xgb/xproto"
 	"github.com/patrislav/marwind/client"
 )
 
 func (wm *WM) renderOutput(o *output) error {
 	var err error
-	if e := wm.renderDock(o, dockAreaTop); e != nil {
-		err = e
+	for _, f := range o.desktops {
+		if e := wm.renderFrame(f, o.geom); e != nil {
+			err = e
+		}
 	}
-	if e := wm.renderDock(o, dockAreaBottom); e != nil {
-		err = e
+	for area := range o.dockAreas {
+		if e := wm.renderDock(o, dockArea(area)); e != nil {
+			err = e
+		}
 	}
 	if e := wm.renderWorkspace(o.activeWs); e != nil {
 		err = e
@@ -19,121 +22,338 @@ func (wm *WM) renderOutput(o *output) error {
 	return err
 }
 
+// renderDock places the docks of the area next to each other along the edge of the output. The top and bottom
+// docks span the whole width of the output, the left and right ones fill the height left between them
 func (wm *WM) renderDock(o *output, area dockArea) error {
 	var err error
-	var y int16
+	side := o.sideDockGeom()
+	var x, y int16
 	switch area {
 	case dockAreaTop:
-		y = o.geom.Y
+		x, y = o.geom.X, o.geom.Y
 	case dockAreaBottom:
-		y = int16(o.geom.H - o.dockHeight(area))
+		x, y = o.geom.X, o.geom.Y+int16(o.geom.H-o.dockSize(area))
+	case dockAreaLeft:
+		x, y = side.X, side.Y
+	case dockAreaRight:
+		x, y = side.X+int16(side.W-o.dockSize(area)), side.Y
 	}
 	for _, f := range o.dockAreas[area] {
-		geom := client.Geom{
-			X: o.geom.X,
-			Y: y,
-			W: o.geom.W,
-			H: f.height,
+		geom := client.Geom{X: x, Y: y, W: o.geom.W, H: f.strut}
+		if area == dockAreaLeft || area == dockAreaRight {
+			geom = client.Geom{X: x, Y: y, W: f.strut, H: side.H}
+			x += int16(geom.W)
+		} else {
+			y += int16(geom.H)
+		}
+		if e := wm.renderFrame(f, geom); e != nil {
+			err = e
 		}
-		err = wm.renderFrame(f, geom)
-		y += int16(geom.H)
 	}
 	return err
 }
 
 func (wm *WM) renderWorkspace(ws *workspace) error {
-	var err error
-	if f := ws.singleFrame(); f != nil {
+	err := wm.renderTiling(ws)
+	if e := wm.renderFloating(ws); e != nil {
+		err = e
+	}
+	if wm.placesNotifications() {
+		if e := wm.renderNotifications(ws); e != nil {
+			err = e
+		}
+	}
+	if e := wm.renderFullscreen(ws); e != nil {
+		err = e
+	}
+	if e := wm.restack(); e != nil {
+		err = e
+	}
+	wm.dpy.Sync()
+	return err
+}
+
+func (wm *WM) renderTiling(ws *workspace) error {
+	if err := ws.showUncovered(); err != nil {
+		return err
+	}
+	if f := ws.singleFrame(); f != nil && wm.config.SmartGaps {
+		if f.fullscreen {
+			return nil
+		}
 		return wm.renderFrame(f, ws.fullArea())
 	}
+	if ws.monocle {
+		return wm.renderMonocle(ws)
+	}
+	if f := ws.maximizedFrame(); f != nil {
+		return wm.renderMaximized(ws, f)
+	}
+	return wm.renderColumns(ws)
+}
+
+// renderColumns divides the tiling area of the workspace between its columns, skipping those that are
+// rendered the same way as the last time, see layoutKey
+func (wm *WM) renderColumns(ws *workspace) error {
+	var err error
 	a := ws.area()
 	x := a.X
-	for _, col := range ws.columns {
+	// the space of columns containing only hidden frames is shared by the visible ones
+	cols := ws.visibleColumns()
+	weights := make([]float64, len(cols))
+	for i, col := range cols {
+		weights[i] = col.weight
+	}
+	widths := splitLength(weights, a.W)
+	for i, col := range cols {
+		w := widths[i]
 		geom := client.Geom{
 			X: x,
 			Y: a.Y,
-			W: col.width,
+			W: w,
 			H: a.H,
 		}
+		x += int16(w)
+		if col.rendered.equal(wm.columnKey(col, geom)) {
+			wm.markStackedRaised(col)
+			continue
+		}
 		if e := wm.renderColumn(col, geom); e != nil {
 			err = e
+			col.rendered = nil
+			continue
 		}
-		x += int16(col.width)
+		col.rendered = wm.columnKey(col, geom)
+	}
+	return err
+}
+
+// renderFloating configures the floating frames of the workspace, stacking them in the order they are
+// kept in, see raiseFloating
+func (wm *WM) renderFloating(ws *workspace) error {
+	var err error
+	for _, f := range ws.floating {
+		if f.fullscreen || f.cli.Role() == client.RoleNotification && wm.placesNotifications() {
+			continue
+		}
+		if e := wm.renderFrame(f, maximizedGeom(f, ws)); e != nil {
+			err = e
+			continue
+		}
+		wm.markRaised(f)
 	}
 	return err
 }
 
 func (wm *WM) renderColumn(col *column, geom client.Geom) error {
+	if col.layout == layoutStacked {
+		return wm.renderStackedColumn(col, geom)
+	}
 	var err error
-	y := geom.Y
-	gap := wm.config.InnerGap
-	for _, f := range col.frames {
-		fg := client.Geom{
-			X: geom.X + int16(gap),
-			Y: y + int16(gap),
-			W: geom.W - gap*2,
-			H: f.height - gap*2,
-		}
-		if e := wm.renderFrame(f, fg); e != nil {
+	horizontal := col.orientation == orientHorizontal
+	frames := col.visibleFrames()
+	weights := make([]float64, len(frames))
+	for i, f := range frames {
+		weights[i] = f.weight
+	}
+	total := geom.H
+	if horizontal {
+		total = geom.W
+	}
+	lengths := splitLength(weights, total)
+	pos := geom.Y
+	if horizontal {
+		pos = geom.X
+	}
+	for i, f := range frames {
+		l := lengths[i]
+		slot := client.Geom{X: geom.X, Y: pos, W: geom.W, H: l}
+		if horizontal {
+			slot = client.Geom{X: pos, Y: geom.Y, W: l, H: geom.H}
+		}
+		pos += int16(l)
+		if e := wm.renderItem(f, slot); e != nil {
 			err = e
 		}
-		y += int16(f.height)
 	}
 	return err
 }
 
-func (wm *WM) renderFrame(f *frame, geom client.Geom) error {
-	if !f.cli.Mapped() {
+// renderItem renders a frame of a column in the given slot, less the gaps around it, or recursively
+// the frames of a nested container
+func (wm *WM) renderItem(f *frame, slot client.Geom) error {
+	if f.split != nil {
+		return wm.renderColumn(f.split, slot)
+	}
+	if f.fullscreen {
+		return nil
+	}
+	gap := f.col.ws.innerGap()
+	return wm.renderFrame(f, client.Geom{
+		X: slot.X + int16(gap),
+		Y: slot.Y + int16(gap),
+		W: slot.W - gap*2,
+		H: slot.H - gap*2,
+	})
+}
+
+// renderStackedColumn gives the entire column to its active frame, except for the strip of titlebars
+// of the other frames at the top that serve as tabs. Without titlebars the other frames are kept
+// below the active one
+func (wm *WM) renderStackedColumn(col *column, geom client.Geom) error {
+	var err error
+	gap := col.ws.innerGap()
+	a := client.Geom{
+		X: geom.X + int16(gap),
+		Y: geom.Y + int16(gap),
+		W: geom.W - gap*2,
+		H: geom.H - gap*2,
+	}
+	active := col.activeFrame()
+	var tabs []*frame
+	var stripHeight uint16
+	for _, f := range col.visibleFrames() {
+		if f == active || f.fullscreen {
+			continue
+		}
+		tabs = append(tabs, f)
+		if f.split == nil {
+			stripHeight += uint16(wm.getFrameDecorations(f).Top)
+		}
+	}
+	if stripHeight >= a.H/2 {
+		// too many tabs to fit, hide them under the active frame instead
+		stripHeight = 0
+	}
+	body := client.Geom{X: a.X, Y: a.Y + int16(stripHeight), W: a.W, H: a.H - stripHeight}
+	// the slot of a nested container, whose frames get their own gaps
+	outer := client.Geom{X: body.X - int16(gap), Y: body.Y - int16(gap), W: body.W + gap*2, H: body.H + gap*2}
+	y := a.Y
+	for _, f := range tabs {
+		if f.split != nil {
+			// containers have no titlebar to serve as a tab, they're kept below the active frame
+			if e := wm.renderColumn(f.split, outer); e != nil {
+				err = e
+			}
+			continue
+		}
+		h := uint16(wm.getFrameDecorations(f).Top)
+		if stripHeight == 0 || h == 0 {
+			if e := wm.renderFrame(f, body); e != nil {
+				err = e
+			}
+			continue
+		}
+		if e := wm.renderTab(f, client.Geom{X: a.X, Y: y, W: a.W, H: h}, body); e != nil {
+			err = e
+		}
+		y += int16(h)
+	}
+	if active == nil || active.fullscreen {
+		return err
+	}
+	if active.split != nil {
+		if e := wm.renderColumn(active.split, outer); e != nil {
+			err = e
+		}
+	} else if e := wm.renderFrame(active, body); e != nil {
+		err = e
+	}
+	for _, f := range active.leaves() {
+		if !f.fullscreen {
+			wm.markRaised(f)
+		}
+	}
+	return err
+}
+
+// renderTab shrinks the frame to its titlebar while the client window keeps the size of the given body,
+// so that it doesn't have to resize when its tab gets selected
+func (wm *WM) renderTab(f *frame, geom client.Geom, body client.Geom) error {
+	if !f.cli.Mapped() || f.cli.Parent() == 0 {
 		return nil
 	}
 	f.cli.SetGeom(geom)
-	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
-	parentVals := []uint32{uint32(geom.X), uint32(geom.Y), uint32(geom.W), uint32(geom.H)}
-	clientVals := parentVals
-	if f.cli.Parent() != 0 {
-		if err := xproto.ConfigureWindowChecked(wm.xc.X(), f.cli.Parent(), mask, parentVals).Check(); err != nil {
+	if !f.inMonocle() {
+		// the frame left the monocle layout, e.g. it was floated or moved to another workspace
+		if err := f.cli.SetPosition(0, 0); err != nil {
 			return err
 		}
-		d := wm.getFrameDecorations(f)
-		clientVals = []uint32{d.Left, d.Top, uint32(geom.W) - d.Left - d.Right, uint32(geom.H) - d.Top - d.Bottom}
 	}
-	if err := xproto.ConfigureWindowChecked(wm.xc.X(), f.cli.Window(), mask, clientVals).Check(); err != nil {
+	rescaled := f.cli.SetConfig(wm.clientConfig(f.pixelScale()))
+	if err := wm.updateFrameExtents(f); err != nil {
 		return err
 	}
-	if err := wm.configureNotify(f); err != nil {
+	d := wm.getFrameDecorations(f)
+	inner := client.Geom{X: int16(d.Left), Y: int16(d.Top), W: 1, H: 1}
+	if uint32(body.W) > d.Left+d.Right && uint32(body.H) > d.Top+d.Bottom {
+		inner.W, inner.H = body.W-uint16(d.Left+d.Right), body.H-uint16(d.Top+d.Bottom)
+	}
+	if !wm.placeFrame(f, placement{frame: geom, client: inner}) && !rescaled {
+		return nil
+	}
+	// the titlebar fills the whole frame window
+	return f.cli.Draw()
+}
+
+func (wm *WM) renderFrame(f *frame, geom client.Geom) error {
+	if !f.cli.Mapped() {
+		return nil
+	}
+	rescaled := f.cli.SetConfig(wm.clientConfig(f.pixelScale()))
+	if err := wm.updateFrameExtents(f); err != nil {
 		return err
 	}
+	geom = wm.constrainGeom(f, geom)
+	f.cli.SetGeom(geom)
+	p := placement{frame: geom, client: geom}
+	if f.cli.Parent() != 0 {
+		d := wm.getFrameDecorations(f)
+		p.client = client.Geom{X: int16(d.Left), Y: int16(d.Top), W: geom.W - uint16(d.Left+d.Right), H: geom.H - uint16(d.Top+d.Bottom)}
+	}
+	if wm.placeFrame(f, p) {
+		wm.configureNotify(f)
+	}
+	if rescaled {
+		// the frame moved to an output with another DPI, its decorations are drawn in their new size
+		return f.cli.Draw()
+	}
 	return nil
 }
 
-func (wm *WM) configureNotify(f *frame) error {
+// placement is the geometry of the frame window and of the client window inside it (relative to the frame
+// window), or only of the client window, relative to the root window, for the frames without a frame window
+type placement struct {
+	frame, client client.Geom
+}
+
+// placeFrame moves and resizes the windows of the frame, returning false if they already had the geometry,
+// which is then left alone to spare the X server and the clients the needless configure requests
+func (wm *WM) placeFrame(f *frame, p placement) bool {
+	if f.placed != nil && *f.placed == p {
+		return false
+	}
+	if f.cli.Parent() != 0 {
+		wm.dpy.MoveResizeWindow(f.cli.Parent(), p.frame.X, p.frame.Y, p.frame.W, p.frame.H)
+	}
+	wm.dpy.MoveResizeWindow(f.cli.Window(), p.client.X, p.client.Y, p.client.W, p.client.H)
+	f.placed = &p
+	return true
+}
+
+func (wm *WM) configureNotify(f *frame) {
 	// Hack for Java applications as described here:
 	// https://stackoverflow.com/questions/31646544/xlib-reparenting-a-java-window-with-popups-properly-translated
-	// TODO: when window decorations are added, this should change to include them
+	// The client window is reported at its position on the screen, inside the decorations of the frame
 	geom := f.cli.Geom()
 	if f.cli.Parent() != 0 {
 		d := wm.getFrameDecorations(f)
 		geom = client.Geom{
 			X: geom.X + int16(d.Left),
 			Y: geom.Y + int16(d.Top),
-			W: geom.W - uint16(d.Left-d.Right),
-			H: geom.H - uint16(d.Top-d.Bottom),
-		}
-	}
-	ev := xproto.ConfigureNotifyEvent{
-		Event:            f.cli.Window(),
-		Window:           f.cli.Window(),
-		X:                geom.X,
-		Y:                geom.Y,
-		Width:            geom.W,
-		Height:           geom.H,
-		BorderWidth:      0,
-		AboveSibling:     0,
-		OverrideRedirect: true,
-	}
-	evCookie := xproto.SendEventChecked(wm.xc.X(), false, f.cli.Window(), xproto.EventMaskStructureNotify, string(ev.Bytes()))
-	if err := evCookie.Check(); err != nil {
-		return err
+			W: geom.W - uint16(d.Left+d.Right),
+			H: geom.H - uint16(d.Top+d.Bottom),
+		}
 	}
-	return nil
+	wm.dpy.SendConfigureNotify(f.cli.Window(), geom.X, geom.Y, geom.W, geom.H)
 }
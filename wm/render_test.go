@@ -0,0 +1,193 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestRenderWorkspace(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		gaps   workspaceConfig
+		setup  func(ws *workspace, frames []*frame) error // arranges the frames after the first three were added
+		want   []client.Geom                              // geometries of the frame windows
+	}{
+		{"columns", Config{}, workspaceConfig{}, nil, []client.Geom{
+			{X: 0, Y: 0, W: 500, H: 600}, {X: 500, Y: 0, W: 500, H: 300}, {X: 500, Y: 300, W: 500, H: 300},
+		}},
+		{"gaps", Config{}, workspaceConfig{gap: 6, innerGap: 4}, nil, []client.Geom{
+			{X: 10, Y: 10, W: 486, H: 580}, {X: 504, Y: 10, W: 486, H: 286}, {X: 504, Y: 304, W: 486, H: 286},
+		}},
+		{"moved right", Config{}, workspaceConfig{}, func(ws *workspace, frames []*frame) error {
+			return ws.moveFrame(frames[0], MoveRight)
+		}, []client.Geom{
+			{X: 0, Y: 400, W: 1000, H: 200}, {X: 0, Y: 0, W: 1000, H: 200}, {X: 0, Y: 200, W: 1000, H: 200},
+		}},
+		{"moved down", Config{}, workspaceConfig{}, func(ws *workspace, frames []*frame) error {
+			return ws.moveFrame(frames[1], MoveDown)
+		}, []client.Geom{
+			{X: 0, Y: 0, W: 500, H: 600}, {X: 500, Y: 300, W: 500, H: 300}, {X: 500, Y: 0, W: 500, H: 300},
+		}},
+		{"resized", Config{}, workspaceConfig{}, func(ws *workspace, frames []*frame) error {
+			if err := ws.resizeFrame(frames[0], ResizeHoriz, 10); err != nil {
+				return err
+			}
+			return ws.resizeFrame(frames[2], ResizeVert, -20)
+		}, []client.Geom{
+			{X: 0, Y: 0, W: 600, H: 600}, {X: 600, Y: 0, W: 400, H: 420}, {X: 600, Y: 420, W: 400, H: 180},
+		}},
+		{"horizontal split", Config{}, workspaceConfig{}, func(ws *workspace, frames []*frame) error {
+			ws.splitFrame(frames[2], orientHorizontal)
+			return ws.placeFrame(frames[3], PlacementAfterFocused, frames[2])
+		}, []client.Geom{
+			{X: 0, Y: 0, W: 500, H: 600}, {X: 500, Y: 0, W: 500, H: 300},
+			{X: 500, Y: 300, W: 250, H: 300}, {X: 750, Y: 300, W: 250, H: 300},
+		}},
+		{"stacked", Config{BorderWidth: 1, TitleBarHeight: 18}, workspaceConfig{}, func(ws *workspace, frames []*frame) error {
+			frames[1].col.layout, frames[1].col.active = layoutStacked, frames[1]
+			return nil
+		}, []client.Geom{
+			{X: 0, Y: 0, W: 500, H: 600}, {X: 500, Y: 20, W: 500, H: 580}, {X: 500, Y: 0, W: 500, H: 20},
+		}},
+		{"smart gaps", Config{SmartGaps: true}, workspaceConfig{gap: 6, innerGap: 4}, func(ws *workspace, frames []*frame) error {
+			ws.detachFrame(frames[1])
+			ws.detachFrame(frames[2])
+			return nil
+		}, []client.Geom{
+			{X: 0, Y: 0, W: 1000, H: 600},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpy := newFakeDisplay()
+			wm := &WM{dpy: dpy, windowConfig: &client.Config{}, config: tt.config}
+			o := &output{geom: client.Geom{W: 1000, H: 600}}
+			wm.outputs = []*output{o}
+			ws := newWorkspace("1", tt.gaps)
+			ws.output, o.activeWs = o, ws
+			frames := make([]*frame, 4)
+			for i := range frames {
+				f, err := dpy.newFrame(xproto.Window(10+i), client.TypeNormal)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				frames[i] = f
+			}
+			for _, f := range frames[:3] {
+				if err := ws.addFrame(f); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if tt.setup != nil {
+				if err := tt.setup(ws, frames); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := wm.renderWorkspace(ws); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]client.Geom, len(tt.want))
+			for i := range got {
+				got[i] = dpy.geoms[frames[i].cli.Parent()]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderFrameDecorations(t *testing.T) {
+	dpy := newFakeDisplay()
+	wm := &WM{dpy: dpy, windowConfig: &client.Config{}, config: Config{BorderWidth: 2, TitleBarHeight: 18}}
+	f, err := dpy.newFrame(10, client.TypeNormal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := wm.renderFrame(f, client.Geom{X: 100, Y: 50, W: 400, H: 300}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := []client.Geom{dpy.geoms[f.cli.Parent()], dpy.geoms[f.cli.Window()]}
+	want := []client.Geom{{X: 100, Y: 50, W: 400, H: 300}, {X: 2, Y: 21, W: 396, H: 277}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestRestack(t *testing.T) {
+	dpy := newFakeDisplay()
+	wm := &WM{dpy: dpy, windowConfig: &client.Config{}}
+	o := &output{geom: client.Geom{W: 1000, H: 600}}
+	wm.outputs = []*output{o}
+	ws := newWorkspace("1", workspaceConfig{})
+	ws.output, o.activeWs = o, ws
+	var frames []*frame
+	for i := 0; i < 3; i++ {
+		f, err := dpy.newFrame(xproto.Window(10+i), client.TypeNormal)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		frames = append(frames, f)
+	}
+	if err := ws.addFrame(frames[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range frames[1:] {
+		if err := ws.addFloatingFrame(f, client.Geom{W: 100, H: 100}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := wm.raiseFrame(frames[1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []xproto.Window{frames[0].cli.Parent(), frames[2].cli.Parent(), frames[1].cli.Parent()}
+	if !reflect.DeepEqual(dpy.stack, want) {
+		t.Errorf("got = %v, want = %v", dpy.stack, want)
+	}
+}
+
+func TestRenderUnchanged(t *testing.T) {
+	dpy := newFakeDisplay()
+	wm := &WM{dpy: dpy, windowConfig: &client.Config{}}
+	o := &output{geom: client.Geom{W: 1000, H: 600}}
+	wm.outputs = []*output{o}
+	ws := newWorkspace("1", workspaceConfig{})
+	ws.output, o.activeWs = o, ws
+	var frames []*frame
+	for i := 0; i < 3; i++ {
+		f, err := dpy.newFrame(xproto.Window(10+i), client.TypeNormal)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := ws.addFrame(f); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		frames = append(frames, f)
+	}
+	steps := []struct {
+		name   string
+		change func() error
+		want   int // windows configured
+	}{
+		{"first render", func() error { return nil }, 6},
+		{"nothing changed", func() error { return nil }, 0},
+		{"two frames swapped", func() error { return ws.moveFrame(frames[1], MoveDown) }, 4},
+	}
+	for _, s := range steps {
+		if err := s.change(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		dpy.configured = 0
+		if err := wm.renderWorkspace(ws); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dpy.configured != s.want {
+			t.Errorf("%s: got = %v, want = %v", s.name, dpy.configured, s.want)
+		}
+	}
+}
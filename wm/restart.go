@@ -0,0 +1,104 @@
+package wm
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/x11"
+)
+
+// restart saves the layout and replaces the WM process with a new instance of the same executable.
+// The path of the saved session is handed over in the environment and the new instance adopts
+// the windows back into their workspaces and columns
+func (wm *WM) restart() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the executable: %v", err)
+	}
+	// the swallowed terminals are put back in the layout as the next instance wouldn't know about them
+	wm.unswallowAll()
+	path, err := wm.createSessionFile()
+	if err != nil {
+		return fmt.Errorf("failed to save the session: %v", err)
+	}
+	wm.releaseWindows()
+	wm.Close()
+	env := append(os.Environ(), sessionEnv+"="+path)
+	err = syscall.Exec(exe, os.Args, env)
+	// there's no way back once the windows are released and the connection closed
+	logger.Fatalf("Failed to restart: %v", err)
+	return err
+}
+
+// quit unmanages all the windows, making the hidden ones visible again, and tells the event loop to stop
+// so that Run returns
+func (wm *WM) quit() error {
+	wm.unmanage()
+	if err := wm.xc.ClearHints(); err != nil {
+		logger.Errorf("Failed to clear the root window properties: %v", err)
+	}
+	wm.quitting = true
+	return nil
+}
+
+// handOver stops managing the windows when another WM has claimed the manager selection. The root
+// window properties are left alone as they already belong to the new WM
+func (wm *WM) handOver() {
+	logger.Infof("Another window manager took over, exiting")
+	wm.unmanage()
+	wm.quitting = true
+}
+
+// unmanage releases all the windows, making the hidden ones visible again, and the grabbed keys
+func (wm *WM) unmanage() {
+	wm.destroyPlaceholders()
+	wm.unswallowAll()
+	for _, f := range append(wm.allFrames(), wm.scratchpad...) {
+		if err := wm.xc.MapWindow(f.cli.Window()); err != nil {
+			logger.Errorf("Failed to map window %d: %v", f.cli.Window(), err)
+		}
+	}
+	wm.releaseWindows()
+	if err := wm.ungrabKeys(); err != nil {
+		logger.Errorf("Failed to ungrab keys: %v", err)
+	}
+}
+
+// releaseWindows gives all the client windows back to the root window, keeping them in place
+func (wm *WM) releaseWindows() {
+	frames := append(wm.allFrames(), wm.scratchpad...)
+	for _, f := range frames {
+		if err := wm.releaseFrame(f); err != nil {
+			logger.Errorf("Failed to release window %d: %v", f.cli.Window(), err)
+		}
+	}
+}
+
+// withdrawFrame stops managing the window of a frame withdrawn by its client, giving it back to the root window
+func (wm *WM) withdrawFrame(f *frame) error {
+	// a window unmapped right before being destroyed is already gone, only its frame is left to remove then
+	_ = wm.xc.SetWMState(f.cli.Window(), x11.WithdrawnState)
+	if err := wm.releaseFrame(f); err != nil {
+		if err := f.cli.OnDestroy(); err != nil {
+			return err
+		}
+	}
+	return wm.deleteFrame(f)
+}
+
+// releaseFrame reparents the client window of the frame to the root window and destroys the frame,
+// the window's position on the screen doesn't change
+func (wm *WM) releaseFrame(f *frame) error {
+	parent := f.cli.Parent()
+	if parent == 0 {
+		return nil
+	}
+	geom := f.cli.Geom()
+	d := wm.getFrameDecorations(f)
+	if err := wm.xc.ReparentWindow(f.cli.Window(), wm.xc.GetRootWindow(), geom.X+int16(d.Left), geom.Y+int16(d.Top)); err != nil {
+		return err
+	}
+	return wm.xc.DestroyWindow(parent)
+}
@@ -0,0 +1,127 @@
+package wm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// Rule applies its actions to the new windows that match all of its criteria. Empty criteria match any window
+type Rule struct {
+	Class    string         // class part of WM_CLASS, e.g. "Firefox"
+	Instance string         // instance part of WM_CLASS, e.g. "Navigator"
+	Title    *regexp.Regexp // matched against the title of the window
+	Type     string         // window type, e.g. "dialog" for _NET_WM_WINDOW_TYPE_DIALOG
+	Process  string         // name of the executable of the window's process (from _NET_WM_PID), e.g. "slack"
+
+	Workspace  string  // name of the workspace the window is assigned to, empty to use the current one
+	Floating   bool    // start the window floating
+	NoTitlebar bool    // don't draw the titlebar of the window
+	Opacity    float64 // fixed opacity of the window, regardless of the focus, 0 to use the ones of the config
+	Ignore     bool    // don't manage the window at all
+	Output     string  // RandR name of the output a dock is placed on, instead of the one its window is on
+}
+
+// windowInfo holds the properties of a window that can be matched by the rules
+type windowInfo struct {
+	class, instance string
+	title           string
+	typeAtoms       []xproto.Atom
+	process         string
+}
+
+// matchRules returns the combined actions of all the rules matching the window, in order of declaration
+// (the workspace and opacity of a later rule override the earlier ones)
+func (wm *WM) matchRules(win xproto.Window, typeAtoms []xproto.Atom) Rule {
+	var result Rule
+	if len(wm.config.Rules) == 0 {
+		return result
+	}
+	info := windowInfo{typeAtoms: typeAtoms}
+	info.instance, info.class, _ = wm.xc.GetWMClass(win)
+	info.title, _ = wm.xc.GetWindowTitle(win)
+	for _, r := range wm.config.Rules {
+		if r.Process != "" {
+			info.process = wm.windowProcess(win)
+			break
+		}
+	}
+	for _, r := range wm.config.Rules {
+		if !wm.ruleMatches(r, info) {
+			continue
+		}
+		if r.Workspace != "" {
+			result.Workspace = r.Workspace
+		}
+		if r.Opacity > 0 {
+			result.Opacity = r.Opacity
+		}
+		if r.Output != "" {
+			result.Output = r.Output
+		}
+		result.Floating = result.Floating || r.Floating
+		result.NoTitlebar = result.NoTitlebar || r.NoTitlebar
+		result.Ignore = result.Ignore || r.Ignore
+	}
+	return result
+}
+
+func (wm *WM) ruleMatches(r Rule, info windowInfo) bool {
+	if r.Class != "" && r.Class != info.class {
+		return false
+	}
+	if r.Instance != "" && r.Instance != info.instance {
+		return false
+	}
+	if r.Title != nil && !r.Title.MatchString(info.title) {
+		return false
+	}
+	if r.Process != "" && r.Process != info.process {
+		return false
+	}
+	if r.Type != "" {
+		if len(info.typeAtoms) == 0 {
+			return r.Type == "normal"
+		}
+		atom := wm.xc.Atom("_NET_WM_WINDOW_TYPE_" + strings.ToUpper(r.Type))
+		for _, a := range info.typeAtoms {
+			if a == atom {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// windowProcess returns the name of the executable of the process owning the window,
+// empty if the window doesn't have _NET_WM_PID or the process is not found (e.g. on another host)
+func (wm *WM) windowProcess(win xproto.Window) string {
+	pid, err := wm.xc.GetWindowPID(win)
+	if err != nil {
+		return ""
+	}
+	name, err := processName(pid)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// processName reads the name of the executable of the process from /proc, falling back to the (possibly
+// truncated) command name when the executable can't be resolved, e.g. for the processes of other users
+func processName(pid int) (string, error) {
+	if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil {
+		return filepath.Base(strings.TrimSuffix(exe, " (deleted)")), nil
+	}
+	comm, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(comm)), nil
+}
@@ -0,0 +1,46 @@
+package wm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRuleMatchesProcess(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		info windowInfo
+		want bool
+	}{
+		{"process", Rule{Process: "slack"}, windowInfo{process: "slack"}, true},
+		{"other process", Rule{Process: "slack"}, windowInfo{process: "electron"}, false},
+		{"unknown process", Rule{Process: "slack"}, windowInfo{}, false},
+		{"process and class", Rule{Process: "slack", Class: "Slack"}, windowInfo{class: "Slack", process: "slack"}, true},
+		{"process and other class", Rule{Process: "slack", Class: "Slack"}, windowInfo{class: "Electron", process: "slack"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&WM{}).ruleMatches(tt.rule, tt.info); got != tt.want {
+				t.Errorf("ruleMatches() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessName(t *testing.T) {
+	if _, err := os.Stat("/proc/self"); err != nil {
+		t.Skip("no /proc filesystem")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := processName(os.Getpid())
+	if err != nil {
+		t.Fatalf("processName() error = %v", err)
+	}
+	if want := filepath.Base(exe); got != want {
+		t.Errorf("processName() got = %v, want = %v", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// moveToScratchpad takes the frame off its workspace and keeps it hidden until it's summoned with showScratchpad
+func (wm *WM) moveToScratchpad(f *frame) error {
+	ws := f.workspace()
+	if ws == nil {
+		return nil
+	}
+	if err := wm.setFullscreen(f, false); err != nil {
+		return err
+	}
+	focused := f.cli.Window() == wm.activeWin
+	if !ws.deleteFrame(f) {
+		return fmt.Errorf("frame not contained within workspace %q", ws.name)
+	}
+	ws.forgetFocus(f)
+	f.col, f.ws = nil, nil
+	f.scratchpad = true
+	wm.scratchpad = append(wm.scratchpad, f)
+	if err := f.cli.Unmap(); err != nil {
+		return fmt.Errorf("failed to unmap the frame: %v", err)
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return fmt.Errorf("failed to render workspace: %v", err)
+	}
+	if focused {
+		if err := wm.focusWorkspace(ws); err != nil {
+			return fmt.Errorf("failed to set focus: %v", err)
+		}
+	}
+	return wm.updateDesktopHints()
+}
+
+// showScratchpad toggles the scratchpad on the current workspace: a scratchpad window that's already shown
+// is focused or, if it's focused, hidden again. Otherwise the next hidden scratchpad window is shown floating
+// in the middle of the workspace, cycling through all of them on subsequent calls
+func (wm *WM) showScratchpad() error {
+	ws := wm.currentOutput().activeWs
+	for _, f := range ws.floating {
+		if !f.scratchpad {
+			continue
+		}
+		if f.cli.Window() == wm.activeWin {
+			return wm.moveToScratchpad(f)
+		}
+		if err := wm.raiseFrame(f); err != nil {
+			return err
+		}
+		return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+	}
+	if len(wm.scratchpad) == 0 {
+		return nil
+	}
+	return wm.showScratchpadFrame(ws, wm.scratchpad[0])
+}
+
+// showScratchpadFrame shows the hidden scratchpad frame floating in the middle of the workspace and focuses it
+func (wm *WM) showScratchpadFrame(ws *workspace, f *frame) error {
+	wm.deleteScratchpadFrame(f)
+	w, h := f.floatGeom.W, f.floatGeom.H
+	if w == 0 || h == 0 {
+		w, h = ws.area().W/2, ws.area().H/2
+	}
+	if err := ws.addFloatingFrame(f, ws.centeredGeom(w, h)); err != nil {
+		return fmt.Errorf("failed to show the scratchpad window: %v", err)
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return fmt.Errorf("failed to render workspace: %v", err)
+	}
+	if err := wm.raiseFrame(f); err != nil {
+		return err
+	}
+	if err := wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime); err != nil {
+		return fmt.Errorf("failed to set focus: %v", err)
+	}
+	return wm.updateDesktopHints()
+}
+
+// deleteScratchpadFrame removes the frame from the hidden scratchpad windows
+func (wm *WM) deleteScratchpadFrame(f *frame) bool {
+	for i, frm := range wm.scratchpad {
+		if frm == f {
+			wm.scratchpad = append(wm.scratchpad[:i], wm.scratchpad[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,357 @@
+package wm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/logger"
+	"github.com/patrislav/marwind/xdg"
+)
+
+// session is the serialized layout of the managed windows, saved when the WM restarts
+// and restored once the new instance has taken over the windows
+type session struct {
+	Workspaces []sessionWorkspace `json:"workspaces"`
+	Scratchpad []uint32           `json:"scratchpad,omitempty"`
+	Focused    uint32             `json:"focused,omitempty"`
+}
+
+type sessionWorkspace struct {
+	Name     string          `json:"name"`
+	ID       uint8           `json:"id,omitempty"` // index of the workspace saved by the versions with a fixed number of workspaces
+	Output   string          `json:"output"`
+	Active   bool            `json:"active"`
+	Columns  []sessionColumn `json:"columns"`
+	Floating []sessionFrame  `json:"floating,omitempty"`
+	Monocle  bool            `json:"monocle,omitempty"`
+}
+
+type sessionColumn struct {
+	Width      uint16         `json:"width"`
+	Stacked    bool           `json:"stacked,omitempty"`
+	Horizontal bool           `json:"horizontal,omitempty"` // set for the nested containers placing frames side by side
+	Frames     []sessionFrame `json:"frames"`
+}
+
+type sessionFrame struct {
+	Window     uint32           `json:"window,omitempty"`
+	Height     uint16           `json:"height,omitempty"`   // length along the column or container
+	Split      *sessionColumn   `json:"split,omitempty"`    // nested container in place of a window
+	Swallows   []layoutCriteria `json:"swallows,omitempty"` // windows taking the place of a layout placeholder
+	Geom       *client.Geom     `json:"geom,omitempty"`     // geometry of a floating frame
+	Fullscreen bool             `json:"fullscreen,omitempty"`
+	Marks      []string         `json:"marks,omitempty"`
+}
+
+// sessionEnv is the environment variable through which the path of the saved session is passed
+// to the new instance of the WM on restart
+const sessionEnv = "MARWIND_SESSION"
+
+// createSessionFile saves the current layout in a new temporary file, returning its path
+func (wm *WM) createSessionFile() (string, error) {
+	dir := xdg.RuntimeDir()
+	display := strings.NewReplacer(":", "", "/", "_").Replace(wm.xc.Display())
+	f, err := ioutil.TempFile(dir, fmt.Sprintf("marwind.%s.session.", display))
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := wm.saveSession(path); err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// Restarted returns true if the process was started by a restart of the WM rather than by the user,
+// i.e. it's going to take over the session of its previous instance
+func Restarted() bool {
+	return os.Getenv(sessionEnv) != ""
+}
+
+// inheritedSession loads the session saved by the previous instance of the WM, if it was restarted,
+// or returns nil otherwise
+func inheritedSession() (*session, error) {
+	path := os.Getenv(sessionEnv)
+	if path == "" {
+		return nil, nil
+	}
+	// programs started by this instance shouldn't see the variable
+	if err := os.Unsetenv(sessionEnv); err != nil {
+		return nil, err
+	}
+	return loadSession(path)
+}
+
+// saveSession writes the current layout to the given file
+func (wm *WM) saveSession(path string) error {
+	s := session{Focused: uint32(wm.activeWin)}
+	for _, ws := range wm.workspaces {
+		if ws.output == nil {
+			continue
+		}
+		sw := sessionWorkspace{Name: ws.name, Output: ws.output.name, Active: ws.output.activeWs == ws, Monocle: ws.monocle}
+		a := ws.area()
+		widths := splitLength(ws.columnWeights(), a.W)
+		for i, col := range ws.columns {
+			sc := saveColumn(col, a)
+			sc.Width = widths[i]
+			sw.Columns = append(sw.Columns, sc)
+		}
+		for _, f := range ws.floating {
+			geom := f.floatGeom
+			sw.Floating = append(sw.Floating, sessionFrame{
+				Window:     uint32(f.cli.Window()),
+				Geom:       &geom,
+				Fullscreen: f.fullscreen,
+				Marks:      f.marks,
+			})
+		}
+		s.Workspaces = append(s.Workspaces, sw)
+	}
+	for _, f := range wm.scratchpad {
+		s.Scratchpad = append(s.Scratchpad, uint32(f.cli.Window()))
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// loadSession reads and removes the session file, returning nil if there's no saved session
+func loadSession(path string) (*session, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid session file: %v", err)
+	}
+	return &s, nil
+}
+
+// windows returns the set of all the windows in the session
+func (s *session) windows() map[xproto.Window]bool {
+	wins := make(map[xproto.Window]bool)
+	for _, sw := range s.Workspaces {
+		for _, sc := range sw.Columns {
+			for _, sf := range sc.Frames {
+				wins[xproto.Window(sf.Window)] = true
+			}
+		}
+		for _, sf := range sw.Floating {
+			wins[xproto.Window(sf.Window)] = true
+		}
+	}
+	for _, win := range s.Scratchpad {
+		wins[xproto.Window(win)] = true
+	}
+	return wins
+}
+
+// restoreSession moves the already managed windows to the workspaces, columns and floating positions
+// they had in the saved session
+func (wm *WM) restoreSession(s *session) error {
+	frames := make(map[xproto.Window]*frame)
+	for _, ws := range wm.workspaces {
+		for _, f := range ws.frames() {
+			frames[f.cli.Window()] = f
+		}
+	}
+	take := func(win uint32) *frame {
+		f := frames[xproto.Window(win)]
+		if f == nil {
+			return nil
+		}
+		delete(frames, f.cli.Window())
+		f.workspace().deleteFrame(f)
+		return f
+	}
+	for _, sw := range s.Workspaces {
+		name := sw.Name
+		if name == "" {
+			name = strconv.Itoa(int(sw.ID) + 1)
+		}
+		if ValidWorkspaceName(name) != nil {
+			continue
+		}
+		ws := wm.getWorkspace(name)
+		if ws.output == nil {
+			o := findOutputByName(wm.outputs, nil, sw.Output)
+			if o == nil {
+				o = wm.primaryOutput()
+			}
+			if err := o.addWorkspace(ws); err != nil {
+				return err
+			}
+		}
+		for _, sc := range sw.Columns {
+			col := ws.createColumn(false)
+			restoreColumn(col, sc, func(sf sessionFrame) *frame {
+				if sf.Window == 0 && len(sf.Swallows) > 0 {
+					// the placeholders are destroyed along with the previous instance
+					f, err := wm.createPlaceholder(sf.Swallows)
+					if err != nil {
+						logger.Errorf("Failed to restore the placeholder: %v", err)
+					}
+					return f
+				}
+				return take(sf.Window)
+			})
+			if len(col.frames) == 0 {
+				ws.deleteColumn(col)
+				continue
+			}
+			if sc.Width > 0 {
+				col.weight = float64(sc.Width)
+			}
+		}
+		for _, sf := range sw.Floating {
+			f := take(sf.Window)
+			if f == nil || sf.Geom == nil {
+				continue
+			}
+			f.fullscreen = sf.Fullscreen
+			f.marks = sf.Marks
+			if err := ws.addFloatingFrame(f, *sf.Geom); err != nil {
+				return err
+			}
+		}
+		ws.monocle = sw.Monocle
+		if sw.Active && ws.output.activeWs != ws {
+			if err := ws.output.switchWorkspace(ws); err != nil {
+				return err
+			}
+		}
+	}
+	for _, win := range s.Scratchpad {
+		if f := frames[xproto.Window(win)]; f != nil {
+			delete(frames, f.cli.Window())
+			if err := wm.moveToScratchpad(f); err != nil {
+				return err
+			}
+		}
+	}
+	for _, o := range wm.outputs {
+		for _, ws := range o.workspaces {
+			var err error
+			if ws == o.activeWs {
+				err = ws.show()
+			} else {
+				err = ws.hide()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range wm.allFrames() {
+		if err := wm.updateWindowState(f); err != nil {
+			return err
+		}
+		if err := wm.drawMarks(f); err != nil {
+			return err
+		}
+	}
+	if err := wm.renderOutputs(); err != nil {
+		return err
+	}
+	if s.Focused != 0 {
+		return wm.setFocus(xproto.Window(s.Focused), xproto.TimeCurrentTime)
+	}
+	return nil
+}
+
+// allFrames returns the frames of all the workspaces
+func (wm *WM) allFrames() []*frame {
+	var frames []*frame
+	for _, ws := range wm.workspaces {
+		frames = append(frames, ws.frames()...)
+	}
+	return frames
+}
+
+// saveColumn saves the frames of a column or nested container with their lengths in the workspace area
+func saveColumn(col *column, a client.Geom) sessionColumn {
+	sc := sessionColumn{Stacked: col.layout == layoutStacked, Horizontal: col.orientation == orientHorizontal}
+	total := a.H
+	if sc.Horizontal {
+		total = a.W
+	}
+	lengths := splitLength(col.frameWeights(), total)
+	for i, f := range col.frames {
+		sf := sessionFrame{Height: lengths[i]}
+		if f.split != nil {
+			nested := saveColumn(f.split, a)
+			sf.Split = &nested
+		} else if f.placeholder != nil {
+			sf.Swallows = f.placeholder
+		} else {
+			sf.Window = uint32(f.cli.Window())
+			sf.Fullscreen = f.fullscreen
+			sf.Marks = f.marks
+		}
+		sc.Frames = append(sc.Frames, sf)
+	}
+	return sc
+}
+
+// restoreColumn puts the saved frames, taken from wherever they're currently placed, in the column.
+// The nested containers that would be left with less than two of their frames are not recreated
+func restoreColumn(col *column, sc sessionColumn, take func(sf sessionFrame) *frame) {
+	ws := col.ws
+	if sc.Stacked {
+		col.layout = layoutStacked
+	}
+	if sc.Horizontal && col.parent != nil {
+		col.orientation = orientHorizontal
+	}
+	for _, sf := range sc.Frames {
+		var f *frame
+		if sf.Split != nil {
+			holder := &frame{}
+			holder.split = &column{ws: ws, parent: holder, weight: 1}
+			restoreColumn(holder.split, *sf.Split, take)
+			switch len(holder.split.frames) {
+			case 0:
+				continue
+			case 1:
+				f = holder.split.frames[0]
+			default:
+				f = holder
+			}
+		} else if f = take(sf); f == nil {
+			continue
+		} else {
+			f.ws = ws
+			f.floating = false
+			f.marks = sf.Marks
+			if sf.Fullscreen {
+				f.fullscreen = true
+				ws.setFullscreenFrame(f)
+			}
+		}
+		col.addFrame(f, nil)
+		// the saved lengths in pixels serve as the weights, missing ones keep the default
+		if sf.Height > 0 {
+			f.weight = float64(sf.Height)
+		}
+	}
+}
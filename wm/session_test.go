@@ -0,0 +1,50 @@
+package wm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestLoadSession(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		s, err := loadSession(filepath.Join(t.TempDir(), "session"))
+		if err != nil || s != nil {
+			t.Errorf("got = %v, %v, want = nil, nil", s, err)
+		}
+	})
+
+	t.Run("Saved", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session")
+		data := `{"workspaces":[{"name":"web","output":"HDMI-1","active":true,` +
+			`"columns":[{"width":960,"stacked":true,"frames":[{"window":10,"height":540}]}],` +
+			`"floating":[{"window":11,"geom":{"X":10,"Y":20,"W":300,"H":200}}]}],"focused":10}`
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := loadSession(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := &session{
+			Workspaces: []sessionWorkspace{{
+				Name:     "web",
+				Output:   "HDMI-1",
+				Active:   true,
+				Columns:  []sessionColumn{{Width: 960, Stacked: true, Frames: []sessionFrame{{Window: 10, Height: 540}}}},
+				Floating: []sessionFrame{{Window: 11, Geom: &client.Geom{X: 10, Y: 20, W: 300, H: 200}}},
+			}},
+			Focused: 10,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected the session file to be removed")
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package wm
+
+import (
+	"github.com/patrislav/marwind/client"
+)
+
+// snapLine is an edge that the edges of a floating frame snap to: a vertical line at pos spanning the rows
+// from..to for the left and right edges, or a horizontal line spanning the columns from..to for the others
+type snapLine struct {
+	pos      int
+	from, to int
+}
+
+// snapTargets returns the lines the left and right edges (xs) and the top and bottom edges (ys) of the floating
+// frame snap to: the edges of its output and of the workspace area, those of the other floating windows shown
+// on the workspace and the boundaries between the tiled windows
+func (wm *WM) snapTargets(f *frame) (xs, ys []snapLine) {
+	ws := f.workspace()
+	if ws == nil || ws.output == nil {
+		return nil, nil
+	}
+	add := func(g client.Geom) {
+		left, right, top, bottom := int(g.X), int(g.X)+int(g.W), int(g.Y), int(g.Y)+int(g.H)
+		xs = append(xs, snapLine{left, top, bottom}, snapLine{right, top, bottom})
+		ys = append(ys, snapLine{top, left, right}, snapLine{bottom, left, right})
+	}
+	add(ws.output.geom)
+	add(ws.fullArea())
+	add(ws.area())
+	for _, frm := range ws.floating {
+		if frm == f || frm.fullscreen || frm.state.hidden || !frm.cli.Mapped() || !frm.focusable() {
+			continue
+		}
+		add(maximizedGeom(frm, ws))
+	}
+	for _, b := range wm.tileBoundaries(ws) {
+		if b.horizontal {
+			xs = append(xs, snapLine{b.pos, b.from, b.to})
+		} else {
+			ys = append(ys, snapLine{b.pos, b.from, b.to})
+		}
+	}
+	return xs, ys
+}
+
+// nearestSnap returns the distance by which the edges, spanning from..to across, have to move for the closest
+// one to lie on one of the lines within the given distance of it, false if there's none
+func nearestSnap(edges []int, from, to int, lines []snapLine, distance int) (int, bool) {
+	best, found := 0, false
+	for _, l := range lines {
+		if l.to < from || l.from > to {
+			continue
+		}
+		for _, e := range edges {
+			d := l.pos - e
+			if absInt(d) <= distance && (!found || absInt(d) < absInt(best)) {
+				best, found = d, true
+			}
+		}
+	}
+	return best, found
+}
+
+// firstSnap returns how far the edges, spanning from..to across, move towards the given distance before
+// one of them reaches a line, the whole distance if none is in the way. Lines the edges already lie on are
+// left behind, so that repeated steps go past them
+func firstSnap(edges []int, from, to int, lines []snapLine, delta int) int {
+	for _, l := range lines {
+		if l.to < from || l.from > to {
+			continue
+		}
+		for _, e := range edges {
+			if d := l.pos - e; d != 0 && (d > 0) == (delta > 0) && absInt(d) < absInt(delta) {
+				delta = d
+			}
+		}
+	}
+	return delta
+}
+
+// snapMove moves the geometry of a floating frame so that its closest edge along each axis lies on a line
+// within the given distance, if any
+func snapMove(geom client.Geom, xs, ys []snapLine, distance int) client.Geom {
+	left, top := int(geom.X), int(geom.Y)
+	right, bottom := left+int(geom.W), top+int(geom.H)
+	if d, ok := nearestSnap([]int{left, right}, top, bottom, xs, distance); ok {
+		geom.X += int16(d)
+	}
+	if d, ok := nearestSnap([]int{top, bottom}, left, right, ys, distance); ok {
+		geom.Y += int16(d)
+	}
+	return geom
+}
+
+// snapResize moves the resized edges of the geometry of a floating frame onto the lines within the given
+// distance, if any, as long as the frame stays at least minFloatingSize
+func snapResize(geom client.Geom, edges resizeEdges, xs, ys []snapLine, distance int) client.Geom {
+	left, top := int(geom.X), int(geom.Y)
+	right, bottom := left+int(geom.W), top+int(geom.H)
+	snap := func(edge, from, to int, lines []snapLine) int {
+		if d, ok := nearestSnap([]int{edge}, from, to, lines, distance); ok {
+			return edge + d
+		}
+		return edge
+	}
+	switch {
+	case edges&resizeLeft != 0:
+		left = snap(left, top, bottom, xs)
+	case edges&resizeRight != 0:
+		right = snap(right, top, bottom, xs)
+	}
+	switch {
+	case edges&resizeTop != 0:
+		top = snap(top, left, right, ys)
+	case edges&resizeBottom != 0:
+		bottom = snap(bottom, left, right, ys)
+	}
+	if right-left >= minFloatingSize {
+		geom.X, geom.W = int16(left), uint16(right-left)
+	}
+	if bottom-top >= minFloatingSize {
+		geom.Y, geom.H = int16(top), uint16(bottom-top)
+	}
+	return geom
+}
+
+// snapStep returns the distance the floating frame moves by, or its moved edges are resized by, with a step
+// of the keyboard: the given one, shortened to stop at the first line in the way when snapping is enabled
+func (wm *WM) snapStep(f *frame, geom client.Geom, edges resizeEdges, dx, dy int) (int, int) {
+	if wm.config.SnapDistance == 0 {
+		return dx, dy
+	}
+	xs, ys := wm.snapTargets(f)
+	left, top := int(geom.X), int(geom.Y)
+	right, bottom := left+int(geom.W), top+int(geom.H)
+	var hEdges, vEdges []int
+	if edges&resizeLeft != 0 {
+		hEdges = append(hEdges, left)
+	}
+	if edges&resizeRight != 0 {
+		hEdges = append(hEdges, right)
+	}
+	if edges&resizeTop != 0 {
+		vEdges = append(vEdges, top)
+	}
+	if edges&resizeBottom != 0 {
+		vEdges = append(vEdges, bottom)
+	}
+	if dx != 0 {
+		dx = firstSnap(hEdges, top, bottom, xs, dx)
+	}
+	if dy != 0 {
+		dy = firstSnap(vEdges, left, right, ys, dy)
+	}
+	return dx, dy
+}
@@ -0,0 +1,88 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func TestNearestSnap(t *testing.T) {
+	lines := []snapLine{{pos: 0, from: 0, to: 1080}, {pos: 960, from: 0, to: 500}, {pos: 1920, from: 0, to: 1080}}
+	tests := []struct {
+		name      string
+		edges     []int
+		from, to  int
+		want      int
+		wantFound bool
+	}{
+		{"left edge", []int{6, 406}, 100, 300, -6, true},
+		{"right edge", []int{1510, 1912}, 100, 300, 8, true},
+		{"closest", []int{955, 1915}, 100, 300, 5, true},
+		{"too far", []int{20, 420}, 100, 300, 0, false},
+		{"not across", []int{955, 1355}, 600, 900, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := nearestSnap(tt.edges, tt.from, tt.to, lines, 10)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("nearestSnap() got = %v %v, want = %v %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestFirstSnap(t *testing.T) {
+	lines := []snapLine{{pos: 100, from: 0, to: 1080}, {pos: 500, from: 0, to: 1080}}
+	tests := []struct {
+		name  string
+		edges []int
+		delta int
+		want  int
+	}{
+		{"nothing in the way", []int{200, 300}, 10, 10},
+		{"stops at the line", []int{200, 495}, 10, 5},
+		{"backwards", []int{104, 400}, -10, -4},
+		{"leaves the line", []int{100, 300}, -10, -10},
+		{"first of two", []int{96, 492}, 10, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstSnap(tt.edges, 0, 100, lines, tt.delta); got != tt.want {
+				t.Errorf("firstSnap() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapMove(t *testing.T) {
+	xs := []snapLine{{pos: 0, from: 0, to: 1080}}
+	ys := []snapLine{{pos: 1080, from: 0, to: 1920}}
+	got := snapMove(client.Geom{X: 7, Y: 676, W: 400, H: 400}, xs, ys, 10)
+	if want := (client.Geom{X: 0, Y: 680, W: 400, H: 400}); got != want {
+		t.Errorf("snapMove() got = %v, want = %v", got, want)
+	}
+}
+
+func TestSnapResize(t *testing.T) {
+	xs := []snapLine{{pos: 0, from: 0, to: 1080}, {pos: 960, from: 0, to: 1080}}
+	ys := []snapLine{{pos: 0, from: 0, to: 1920}}
+	tests := []struct {
+		name  string
+		geom  client.Geom
+		edges resizeEdges
+		want  client.Geom
+	}{
+		{"right", client.Geom{X: 100, Y: 100, W: 853, H: 200}, resizeRight, client.Geom{X: 100, Y: 100, W: 860, H: 200}},
+		{"left", client.Geom{X: 4, Y: 100, W: 300, H: 200}, resizeLeft, client.Geom{X: 0, Y: 100, W: 304, H: 200}},
+		{"top left", client.Geom{X: 4, Y: 9, W: 300, H: 200}, resizeTop | resizeLeft, client.Geom{X: 0, Y: 0, W: 304, H: 209}},
+		{"not moved", client.Geom{X: 4, Y: 9, W: 300, H: 200}, resizeBottom, client.Geom{X: 4, Y: 9, W: 300, H: 200}},
+		{"too small", client.Geom{X: 955, Y: 100, W: 50, H: 200}, resizeLeft, client.Geom{X: 955, Y: 100, W: 50, H: 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snapResize(tt.geom, tt.edges, xs, ys, 10); got != tt.want {
+				t.Errorf("snapResize() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
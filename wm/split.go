@@ -0,0 +1,205 @@
+package wm
+
+import (
+	"fmt"
+)
+
+// orientation is the direction in which the frames of a split container are placed
+type orientation uint8
+
+const (
+	orientVertical   orientation = iota // top to bottom, like in the columns of the workspace
+	orientHorizontal                    // side by side
+)
+
+// Any frame of a column can be replaced by a split container: a frame without a client whose split
+// column holds the frames placed in its space, which can be split further. The containers left with
+// a single frame are dissolved, so the tree stays as shallow as the layout allows
+
+// splitFrame puts the frame in a new container of the given orientation in its place, so that the windows
+// opened next to it share its space in that direction. If the frame's container already has that orientation
+// (or only holds the frame) no new container is needed
+func (ws *workspace) splitFrame(f *frame, o orientation) {
+	col := f.col
+	if col.orientation == o {
+		return
+	}
+	if col.parent != nil && len(col.frames) == 1 {
+		col.orientation = o
+		return
+	}
+	holder := &frame{col: col, weight: f.weight}
+	holder.split = &column{ws: ws, parent: holder, orientation: o, weight: 1}
+	i := col.findFrameIndex(func(frm *frame) bool { return frm == f })
+	col.frames[i] = holder
+	if col.active == f {
+		col.active = holder
+	}
+	holder.split.insertFrame(f, 0)
+}
+
+// detachFrame removes the tiled frame from its column, then deletes the columns and containers that
+// were left empty and dissolves the containers left with a single frame
+func (ws *workspace) detachFrame(f *frame) {
+	col := f.col
+	col.deleteFrame(f)
+	ws.cleanupColumn(col)
+}
+
+// cleanupColumn deletes the column if it's empty or, for a nested container with a single frame left,
+// puts that frame in the place of the container
+func (ws *workspace) cleanupColumn(col *column) {
+	holder := col.parent
+	switch {
+	case len(col.frames) == 0 && holder == nil:
+		ws.deleteColumn(col)
+	case len(col.frames) == 0:
+		ws.detachFrame(holder)
+	case len(col.frames) == 1 && holder != nil:
+		holder.col.replaceFrame(holder, col.frames[0])
+	}
+}
+
+// parentColumn returns the column in which the nested container is placed, nil for the columns of the workspace
+func (c *column) parentColumn() *column {
+	if c.parent == nil {
+		return nil
+	}
+	return c.parent.col
+}
+
+// topColumn returns the column of the workspace in which the (possibly nested) container is placed
+func (c *column) topColumn() *column {
+	for c.parent != nil {
+		c = c.parent.col
+	}
+	return c
+}
+
+// leaves returns the frames of the windows in the column, including those of the nested containers
+func (c *column) leaves() []*frame {
+	var frames []*frame
+	for _, f := range c.frames {
+		if f.split != nil {
+			frames = append(frames, f.split.leaves()...)
+		} else {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// leaves returns the frame itself or, for a container, the frames of the windows it holds
+func (f *frame) leaves() []*frame {
+	if f.split != nil {
+		return f.split.leaves()
+	}
+	return []*frame{f}
+}
+
+// focusCandidates returns the frames shown in the column that can be focused in a direction: all the
+// visible ones of a split column and only the active one of a stacked column, including nested containers
+func (c *column) focusCandidates() []*frame {
+	items := c.visibleFrames()
+	if c.layout == layoutStacked {
+		items = nil
+		if active := c.activeFrame(); active != nil {
+			items = []*frame{active}
+		}
+	}
+	var frames []*frame
+	for _, f := range items {
+		if f.split != nil {
+			frames = append(frames, f.split.focusCandidates()...)
+		} else {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// visible checks whether the frame is shown: not minimized or, for a container, holding a frame that is not
+func (f *frame) visible() bool {
+	if f.split != nil {
+		return len(f.split.visibleFrames()) > 0
+	}
+	return !f.state.hidden
+}
+
+// itemIn returns the frame of the column that is or contains the given one, nil if it's not in the column
+func (f *frame) itemIn(col *column) *frame {
+	for item := f; item.col != nil; item = item.col.parent {
+		if item.col == col {
+			return item
+		}
+		if item.col.parent == nil {
+			break
+		}
+	}
+	return nil
+}
+
+// focusTarget returns the window frame that gets the focus when the frame is selected: the frame itself
+// or the most recently focused visible frame of a container
+func (f *frame) focusTarget() *frame {
+	if f.split == nil {
+		return f
+	}
+	ws := f.split.ws
+	for i := len(ws.focusStack) - 1; i >= 0; i-- {
+		if frm := ws.focusStack[i]; frm.visible() && frm.itemIn(f.split) != nil {
+			return frm
+		}
+	}
+	for _, frm := range f.split.leaves() {
+		if frm.visible() {
+			return frm
+		}
+	}
+	return nil
+}
+
+// revealInStacks makes the frame (or the container holding it) the active one of all the stacked columns
+// it's placed in, returning whether any of them changed
+func (f *frame) revealInStacks() bool {
+	changed := false
+	for item := f; item.col != nil; item = item.col.parent {
+		if col := item.col; col.layout == layoutStacked && col.activeFrame() != item {
+			col.active = item
+			changed = true
+		}
+		if item.col.parent == nil {
+			break
+		}
+	}
+	return changed
+}
+
+// cmdSplit places the focused window in a new container (if needed) so that the next window opened
+// on its workspace goes next to it in the given direction: split <horizontal|vertical|toggle>
+func cmdSplit(wm *WM, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: split <horizontal|vertical|toggle>")
+	}
+	f := wm.targetFrame()
+	if f == nil || f.col == nil {
+		return nil
+	}
+	var o orientation
+	switch args[0] {
+	case "horizontal", "h":
+		o = orientHorizontal
+	case "vertical", "v":
+		o = orientVertical
+	case "toggle":
+		o = orientHorizontal
+		if f.col.orientation == orientHorizontal {
+			o = orientVertical
+		}
+	default:
+		return fmt.Errorf("usage: split <horizontal|vertical|toggle>")
+	}
+	f.workspace().splitFrame(f, o)
+	wm.placeMark = f
+	return wm.renderWorkspace(f.workspace())
+}
@@ -0,0 +1,137 @@
+package wm
+
+import (
+	"sort"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// layer is a group of windows stacked together, the layers are stacked from the bottom in the order of the constants
+type layer uint8
+
+const (
+	layerDesktop layer = iota
+	layerBelow         // windows in the _NET_WM_STATE_BELOW state
+	layerTiling
+	layerFloating
+	layerDock
+	layerFullscreen   // the fullscreen window, while no other window of its workspace is focused
+	layerNotification // menus, tooltips and notifications
+)
+
+// frameLayer returns the layer of the frame when the given frame is focused. Transient windows are never
+// stacked below the windows they're transient for
+func frameLayer(f, focused *frame) layer {
+	l := layerTiling
+	switch {
+	case f.cli.Type() == client.TypeDesktop:
+		return layerDesktop
+	case f.cli.Type() == client.TypeDock:
+		return layerDock
+	case f.cli.Role().Above():
+		l = layerNotification
+	case f.fullscreen && (focused == nil || focused == f || focused.workspace() != f.workspace()):
+		l = layerFullscreen
+	case f.state.below:
+		l = layerBelow
+	case f.floating:
+		l = layerFloating
+	}
+	if f.transientFor != nil {
+		if pl := frameLayer(f.transientFor, focused); pl > l {
+			return pl
+		}
+	}
+	return l
+}
+
+// stackFrames sorts the frames from the bottom to the top of the stack: by their layers, then the frames
+// in the "above" state over the rest and the most recently raised ones over the others
+func stackFrames(frames []*frame, focused *frame) {
+	sort.SliceStable(frames, func(i, j int) bool {
+		a, b := frames[i], frames[j]
+		if la, lb := frameLayer(a, focused), frameLayer(b, focused); la != lb {
+			return la < lb
+		}
+		if a.state.above != b.state.above {
+			return b.state.above
+		}
+		return a.raised < b.raised
+	})
+}
+
+// markRaised puts the frame on top of the other frames of its layer the next time the windows are
+// restacked, followed by its transient windows
+func (wm *WM) markRaised(f *frame) {
+	wm.raises++
+	f.raised = wm.raises
+	for _, t := range wm.transients(f) {
+		wm.markRaised(t)
+	}
+}
+
+// raiseFrame puts the frame on top of the other frames of its layer, followed by its transient windows
+func (wm *WM) raiseFrame(f *frame) error {
+	wm.markRaised(f)
+	return wm.restack()
+}
+
+// stackedFrames returns the frames with windows shown on any output
+func (wm *WM) stackedFrames() []*frame {
+	var frames []*frame
+	for _, o := range wm.outputs {
+		frames = append(frames, o.desktops...)
+		for area := range o.dockAreas {
+			frames = append(frames, o.dockAreas[area]...)
+		}
+		if o.activeWs != nil {
+			frames = append(frames, o.activeWs.frames()...)
+		}
+	}
+	shown := frames[:0]
+	for _, f := range frames {
+		if f.cli.Mapped() {
+			shown = append(shown, f)
+		}
+	}
+	return shown
+}
+
+// restack enforces the stacking order of the shown windows with ConfigureWindow. Only the windows from
+// the first one that's out of place since the last restack are moved, each right above the previous one
+func (wm *WM) restack() error {
+	frames := wm.stackedFrames()
+	stackFrames(frames, wm.focusedFrame())
+	wins := make([]xproto.Window, len(frames))
+	for i, f := range frames {
+		wins[i] = wm.frameWindow(f)
+	}
+	start := 0
+	for start < len(wins) && start < len(wm.stack) && wins[start] == wm.stack[start] {
+		start++
+	}
+	if start == len(wins) && len(wins) == len(wm.stack) {
+		return nil
+	}
+	for i := start; i < len(wins); i++ {
+		var sibling xproto.Window
+		if i > 0 {
+			sibling = wins[i-1]
+		}
+		if err := wm.dpy.StackAbove(wins[i], sibling); err != nil {
+			wm.stack = nil
+			return err
+		}
+	}
+	wm.stack = wins
+	clients := make([]xproto.Window, 0, len(frames))
+	for _, f := range frames {
+		// placeholders are not in the client lists
+		if f.placeholder == nil {
+			clients = append(clients, f.cli.Window())
+		}
+	}
+	return wm.dpy.SetClientStacking(clients)
+}
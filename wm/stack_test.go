@@ -0,0 +1,88 @@
+package wm
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+)
+
+func newStackedFrame(t *testing.T, win xproto.Window, typ client.Type, role client.Role) *frame {
+	c, err := client.New(nil, &client.Config{}, win, typ)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.SetRole(role)
+	return &frame{cli: c}
+}
+
+func TestFrameLayer(t *testing.T) {
+	ws := newWorkspace("1", workspaceConfig{})
+	other := newWorkspace("2", workspaceConfig{})
+	tests := []struct {
+		name  string
+		setup func(f *frame) *frame // returns the focused frame
+		typ   client.Type
+		role  client.Role
+		want  layer
+	}{
+		{"tiled", func(f *frame) *frame { return nil }, client.TypeUnknown, client.RoleNormal, layerTiling},
+		{"floating", func(f *frame) *frame { f.floating = true; return nil }, client.TypeUnknown, client.RoleNormal, layerFloating},
+		{"below", func(f *frame) *frame { f.floating, f.state.below = true, true; return nil }, client.TypeUnknown, client.RoleNormal, layerBelow},
+		{"desktop", func(f *frame) *frame { return nil }, client.TypeDesktop, client.RoleNormal, layerDesktop},
+		{"dock", func(f *frame) *frame { return nil }, client.TypeDock, client.RoleNormal, layerDock},
+		{"notification", func(f *frame) *frame { f.floating = true; return nil }, client.TypeUnknown, client.RoleNotification, layerNotification},
+		{"fullscreen focused", func(f *frame) *frame { f.fullscreen = true; return f }, client.TypeUnknown, client.RoleNormal, layerFullscreen},
+		{"fullscreen other workspace focused", func(f *frame) *frame {
+			f.fullscreen, f.ws = true, ws
+			return &frame{ws: other}
+		}, client.TypeUnknown, client.RoleNormal, layerFullscreen},
+		{"fullscreen same workspace focused", func(f *frame) *frame {
+			f.fullscreen, f.ws = true, ws
+			return &frame{ws: ws}
+		}, client.TypeUnknown, client.RoleNormal, layerTiling},
+		{"transient of fullscreen", func(f *frame) *frame {
+			parent := &frame{fullscreen: true, cli: f.cli}
+			f.floating, f.transientFor = true, parent
+			return nil
+		}, client.TypeUnknown, client.RoleDialog, layerFullscreen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newStackedFrame(t, 10, tt.typ, tt.role)
+			focused := tt.setup(f)
+			if got := frameLayer(f, focused); got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStackFrames(t *testing.T) {
+	dock := newStackedFrame(t, 1, client.TypeDock, client.RoleNormal)
+	tiled := newStackedFrame(t, 2, client.TypeUnknown, client.RoleNormal)
+	raised := newStackedFrame(t, 3, client.TypeUnknown, client.RoleNormal)
+	raised.floating, raised.raised = true, 2
+	lower := newStackedFrame(t, 4, client.TypeUnknown, client.RoleNormal)
+	lower.floating, lower.raised = true, 1
+	above := newStackedFrame(t, 5, client.TypeUnknown, client.RoleNormal)
+	above.floating, above.state.above = true, true
+	desktop := newStackedFrame(t, 6, client.TypeDesktop, client.RoleNormal)
+
+	frames := []*frame{dock, above, raised, tiled, lower, desktop}
+	stackFrames(frames, nil)
+	var got []xproto.Window
+	for _, f := range frames {
+		got = append(got, f.cli.Window())
+	}
+	want := []xproto.Window{6, 2, 4, 3, 5, 1}
+	if len(got) != len(want) {
+		t.Fatalf("got = %v, want = %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got = %v, want = %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/patrislav/marwind/client"
+)
+
+// Actions of the _NET_WM_STATE client message
+const (
+	stateRemove = 0
+	stateAdd    = 1
+	stateToggle = 2
+)
+
+// frameState holds the _NET_WM_STATE flags of a frame other than fullscreen, which is kept separately
+// as it changes the way the whole workspace is rendered
+type frameState struct {
+	maximizedVert    bool
+	maximizedHorz    bool
+	hidden           bool
+	above            bool
+	below            bool
+	sticky           bool
+	demandsAttention bool
+
+	// urgencyHint follows the urgency flag of WM_HINTS, the other source of the urgency of the frame, so that
+	// each of the flags is only cleared by its own source
+	urgencyHint bool
+}
+
+// urgent returns true if the frame demands attention through its _NET_WM_STATE or its WM_HINTS
+func (s frameState) urgent() bool {
+	return s.demandsAttention || s.urgencyHint
+}
+
+// handleStateMessage processes the _NET_WM_STATE client message sent by the client of the frame
+func (wm *WM) handleStateMessage(f *frame, data []uint32) error {
+	action := data[0]
+	changed := false
+	for _, prop := range data[1:3] {
+		atom := xproto.Atom(prop)
+		if atom == wm.xc.Atom("_NET_WM_STATE_FULLSCREEN") {
+			if err := wm.setFullscreen(f, applyStateAction(action, f.fullscreen)); err != nil {
+				return err
+			}
+			continue
+		}
+		if atom == wm.xc.Atom("_NET_WM_STATE_STICKY") {
+			if err := wm.setSticky(f, applyStateAction(action, f.state.sticky)); err != nil {
+				return err
+			}
+			continue
+		}
+		if atom == wm.xc.Atom("_NET_WM_STATE_HIDDEN") {
+			if err := wm.setMinimized(f, applyStateAction(action, f.state.hidden)); err != nil {
+				return err
+			}
+			continue
+		}
+		if flag := wm.stateFlag(f, atom); flag != nil {
+			*flag = applyStateAction(action, *flag)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return wm.applyFrameState(f)
+}
+
+// stateFlag returns the flag of the frame corresponding to the given _NET_WM_STATE atom
+// or nil if the state is not supported
+func (wm *WM) stateFlag(f *frame, atom xproto.Atom) *bool {
+	switch atom {
+	case wm.xc.Atom("_NET_WM_STATE_MAXIMIZED_VERT"):
+		return &f.state.maximizedVert
+	case wm.xc.Atom("_NET_WM_STATE_MAXIMIZED_HORZ"):
+		return &f.state.maximizedHorz
+	case wm.xc.Atom("_NET_WM_STATE_HIDDEN"):
+		return &f.state.hidden
+	case wm.xc.Atom("_NET_WM_STATE_ABOVE"):
+		return &f.state.above
+	case wm.xc.Atom("_NET_WM_STATE_BELOW"):
+		return &f.state.below
+	case wm.xc.Atom("_NET_WM_STATE_STICKY"):
+		return &f.state.sticky
+	case wm.xc.Atom("_NET_WM_STATE_DEMANDS_ATTENTION"):
+		return &f.state.demandsAttention
+	}
+	return nil
+}
+
+// setSticky changes whether the frame is shown on every workspace of its output
+func (wm *WM) setSticky(f *frame, sticky bool) error {
+	if f.state.sticky == sticky {
+		return nil
+	}
+	f.state.sticky = sticky
+	if err := wm.applyFrameState(f); err != nil {
+		return err
+	}
+	return wm.updateDesktopHints()
+}
+
+// applyFrameState updates the window property and the workspace layout after the state flags of the frame changed
+func (wm *WM) applyFrameState(f *frame) error {
+	if err := wm.updateWindowState(f); err != nil {
+		return fmt.Errorf("failed to update window state: %v", err)
+	}
+	if f.state.sticky && !f.floating && f.col != nil {
+		// only the floating frames follow the output from one workspace to another
+		if err := wm.toggleFloating(f); err != nil {
+			return err
+		}
+	}
+	if err := f.cli.SetUrgent(f.state.urgent()); err != nil {
+		return err
+	}
+	ws := f.workspace()
+	if ws == nil || ws.output == nil || ws.output.activeWs != ws {
+		return nil
+	}
+	if f.state.hidden && f.cli.Mapped() {
+		if err := f.cli.Unmap(); err != nil {
+			return err
+		}
+	} else if !f.state.hidden && !f.cli.Mapped() {
+		if err := f.cli.Map(); err != nil {
+			return err
+		}
+	}
+	if err := wm.renderWorkspace(ws); err != nil {
+		return err
+	}
+	if f.state.hidden && f.cli.Window() == wm.activeWin {
+		return wm.focusWorkspace(ws)
+	}
+	return nil
+}
+
+// applyStateAction returns the next value of a state flag given its current value
+func applyStateAction(action uint32, current bool) bool {
+	switch action {
+	case stateRemove:
+		return false
+	case stateAdd:
+		return true
+	case stateToggle:
+		return !current
+	}
+	return current
+}
+
+// updateWindowState sets the _NET_WM_STATE property of the client window to reflect the state of the frame
+func (wm *WM) updateWindowState(f *frame) error {
+	var states []xproto.Atom
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{f.fullscreen, "_NET_WM_STATE_FULLSCREEN"},
+		{f.state.maximizedVert, "_NET_WM_STATE_MAXIMIZED_VERT"},
+		{f.state.maximizedHorz, "_NET_WM_STATE_MAXIMIZED_HORZ"},
+		{f.state.hidden, "_NET_WM_STATE_HIDDEN"},
+		{f.state.above, "_NET_WM_STATE_ABOVE"},
+		{f.state.below, "_NET_WM_STATE_BELOW"},
+		{f.state.sticky, "_NET_WM_STATE_STICKY"},
+		{f.state.urgent(), "_NET_WM_STATE_DEMANDS_ATTENTION"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			states = append(states, wm.xc.Atom(flag.name))
+		}
+	}
+	return wm.xc.SetWindowState(f.cli.Window(), states)
+}
+
+// maximizedGeom returns the geometry of the floating frame stretched over the workspace area
+// along the axes in which it's maximized
+func maximizedGeom(f *frame, ws *workspace) client.Geom {
+	geom := f.floatGeom
+	a := ws.area()
+	if f.state.maximizedHorz {
+		geom.X, geom.W = a.X, a.W
+	}
+	if f.state.maximizedVert {
+		geom.Y, geom.H = a.Y, a.H
+	}
+	return geom
+}
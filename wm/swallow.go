@@ -0,0 +1,172 @@
+package wm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// swallowingTerminal returns the focused terminal frame of the workspace if the new window was started
+// from it, i.e. the window's process is a descendant of the terminal's process
+func (wm *WM) swallowingTerminal(win xproto.Window, ws *workspace) *frame {
+	if !wm.config.Swallow {
+		return nil
+	}
+	term := wm.focusedFrame()
+	if term == nil || term.col == nil || term.workspace() != ws || term.fullscreen || !wm.isTerminal(term) {
+		return nil
+	}
+	pid, err := wm.xc.GetWindowPID(win)
+	if err != nil {
+		return nil
+	}
+	termPID, err := wm.xc.GetWindowPID(term.cli.Window())
+	if err != nil || pid == termPID {
+		return nil
+	}
+	if !isDescendant(pid, termPID) {
+		return nil
+	}
+	return term
+}
+
+// isTerminal returns true if the class of the frame's window is one of the configured terminals
+func (wm *WM) isTerminal(f *frame) bool {
+	_, class, err := wm.xc.GetWMClass(f.cli.Window())
+	if err != nil {
+		return false
+	}
+	for _, c := range wm.config.SwallowClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// swallow hides the terminal frame and puts the new frame in its place
+func (wm *WM) swallow(term, f *frame) error {
+	term.col.replaceFrame(term, f)
+	f.ws = term.ws
+	f.floating = false
+	f.swallowed = term
+	term.workspace().forgetFocus(term)
+	if err := term.cli.Unmap(); err != nil {
+		return err
+	}
+	if ws := f.workspace(); ws.output.activeWs == ws && !f.state.hidden {
+		return f.cli.Map()
+	}
+	return nil
+}
+
+// unswallow brings back the terminal swallowed by the frame, right after the frame in its column
+// so that the terminal takes over its place once the frame is gone
+func (wm *WM) unswallow(f *frame) error {
+	term := f.swallowed
+	if term == nil {
+		return nil
+	}
+	f.swallowed = nil
+	ws := f.workspace()
+	if ws == nil {
+		// the frame is in the hidden scratchpad, show the terminal on the current workspace instead
+		ws = wm.currentOutput().activeWs
+	}
+	if f.col != nil {
+		f.col.addFrame(term, f)
+		term.weight = f.weight
+		term.ws = ws
+	} else if err := ws.addFrame(term); err != nil {
+		return err
+	}
+	ws.pushFocus(term)
+	if ws.output != nil && ws.output.activeWs == ws && !term.state.hidden {
+		return term.cli.Map()
+	}
+	return nil
+}
+
+// unswallowAll brings back all the swallowed terminals, e.g. before the WM exits. A terminal may itself
+// have swallowed another one before, so the whole chain is restored
+func (wm *WM) unswallowAll() {
+	for _, f := range append(wm.allFrames(), wm.scratchpad...) {
+		for f.swallowed != nil {
+			term := f.swallowed
+			if err := wm.unswallow(f); err != nil {
+				logger.Errorf("Failed to restore the swallowed window of %d: %v", f.cli.Window(), err)
+			}
+			f = term
+		}
+	}
+}
+
+// swallower returns the frame that swallowed the given one, or nil if it's not swallowed
+func (wm *WM) swallower(term *frame) *frame {
+	for _, f := range append(wm.allFrames(), wm.scratchpad...) {
+		for ; f.swallowed != nil; f = f.swallowed {
+			if f.swallowed == term {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// replaceFrame puts the new frame in the place of the old one, with the same share of the column's height
+func (c *column) replaceFrame(old, f *frame) {
+	i := c.findFrameIndex(func(frm *frame) bool { return frm == old })
+	if i < 0 {
+		return
+	}
+	c.frames[i] = f
+	f.col = c
+	f.weight = old.weight
+	old.col = nil
+	if c.active == old {
+		c.active = f
+	}
+}
+
+// isDescendant returns true if the process is a (not necessarily direct) child of the ancestor
+func isDescendant(pid, ancestor int) bool {
+	for pid > 1 {
+		ppid, err := parentPID(pid)
+		if err != nil {
+			return false
+		}
+		if ppid == ancestor {
+			return true
+		}
+		pid = ppid
+	}
+	return false
+}
+
+// parentPID reads the ID of the parent of the process from /proc
+func parentPID(pid int) (int, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+	return parseStatPPID(string(data))
+}
+
+// parseStatPPID extracts the parent process ID from the contents of /proc/<pid>/stat. The process name
+// in parentheses may itself contain spaces and parentheses, so the fields are read after the last one
+func parseStatPPID(stat string) (int, error) {
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, fmt.Errorf("invalid process stat %q", stat)
+	}
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("invalid process stat %q", stat)
+	}
+	return strconv.Atoi(fields[1])
+}
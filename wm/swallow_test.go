@@ -0,0 +1,37 @@
+package wm
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseStatPPID(t *testing.T) {
+	tests := []struct {
+		name    string
+		stat    string
+		want    int
+		wantErr bool
+	}{
+		{"simple", "1234 (mpv) S 1200 1234 1200 34816", 1200, false},
+		{"name with spaces and parentheses", "42 (Web Content (1)) R 7 42 7", 7, false},
+		{"truncated", "42 (sh) S", 0, true},
+		{"garbage", "nothing here", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatPPID(tt.stat)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseStatPPID() got = %v, %v, want = %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsDescendant(t *testing.T) {
+	if !isDescendant(os.Getpid(), os.Getppid()) {
+		t.Errorf("expected the test process to descend from its parent")
+	}
+	if isDescendant(os.Getppid(), os.Getpid()) {
+		t.Errorf("expected the parent not to descend from the test process")
+	}
+}
@@ -0,0 +1,98 @@
+package wm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// swapFrames exchanges the places of two tiled frames, possibly on different workspaces. Unlike moving
+// a frame, the layout stays as it is: each frame takes the size of the one it's swapped with
+func swapFrames(a, b *frame) {
+	if a == b {
+		return
+	}
+	ai := a.col.findFrameIndex(func(f *frame) bool { return f == a })
+	bi := b.col.findFrameIndex(func(f *frame) bool { return f == b })
+	a.col.frames[ai], b.col.frames[bi] = b, a
+	aActive, bActive := a.col.active == a, b.col.active == b
+	a.col, b.col = b.col, a.col
+	if aActive {
+		b.col.active = b
+	}
+	if bActive {
+		a.col.active = a
+	}
+	a.weight, b.weight = b.weight, a.weight
+	a.ws, b.ws = b.ws, a.ws
+}
+
+// swapWindows exchanges the places of the tiled frame and the other one, showing or hiding both windows
+// when they change workspaces
+func (wm *WM) swapWindows(f, other *frame) error {
+	if f == other {
+		return nil
+	}
+	for _, frm := range []*frame{f, other} {
+		if frm.col == nil || frm.fullscreen {
+			return fmt.Errorf("only tiled windows can be swapped")
+		}
+	}
+	from, to := f.workspace(), other.workspace()
+	focused := f.cli.Window() == wm.activeWin
+	swapFrames(f, other)
+	if from == to {
+		if err := wm.renderWorkspace(from); err != nil {
+			return err
+		}
+		return wm.warpPointerToFrame(f)
+	}
+	from.forgetFocus(f)
+	to.forgetFocus(other)
+	for _, frm := range []*frame{f, other} {
+		ws := frm.workspace()
+		var err error
+		if ws.output != nil && ws.output.activeWs == ws && !frm.state.hidden {
+			err = frm.cli.Map()
+		} else {
+			err = frm.cli.Unmap()
+		}
+		if err != nil {
+			return err
+		}
+		if err := wm.renderWorkspace(ws); err != nil {
+			return err
+		}
+		wm.emitWindowEvent("move", frm)
+	}
+	if focused && (to.output == nil || to.output.activeWs != to) {
+		if err := wm.focusWorkspace(from); err != nil {
+			return fmt.Errorf("failed to set focus: %v", err)
+		}
+	}
+	return wm.updateDesktopHints()
+}
+
+// cmdSwap exchanges the places of the focused window and another one, keeping the sizes of both:
+// swap <left|right|up|down> or swap with mark <name>
+func cmdSwap(wm *WM, args []string) error {
+	if len(args) >= 3 && args[0] == "with" && args[1] == "mark" {
+		name := strings.Join(args[2:], " ")
+		other := wm.markedFrame(name)
+		if other == nil {
+			return fmt.Errorf("no window is marked %q", name)
+		}
+		f := wm.targetFrame()
+		if f == nil {
+			return nil
+		}
+		return wm.swapWindows(f, other)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: swap <left|right|up|down> | swap with mark <name>")
+	}
+	dir, err := parseMoveDirection(args[0])
+	if err != nil {
+		return err
+	}
+	return handleSwapWindow(wm, dir)
+}
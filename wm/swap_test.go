@@ -0,0 +1,38 @@
+package wm
+
+import (
+	"testing"
+)
+
+func TestSwapFrames(t *testing.T) {
+	ws := newWorkspace("1", workspaceConfig{})
+	ws.output = &output{}
+	other := newWorkspace("2", workspaceConfig{})
+	other.output = &output{}
+	frames := []*frame{{}, {}, {}, {}}
+	for _, f := range frames[:3] {
+		if err := ws.addFrame(f); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := other.addFrame(frames[3]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frames[1].weight, frames[2].weight = 3, 1
+
+	swapFrames(frames[0], frames[2])
+	if got, want := describeLayout(ws, frames), "[2] [1 0]"; got != want {
+		t.Errorf("layout got = %v, want = %v", got, want)
+	}
+	if frames[0].weight != 1 || frames[1].weight != 3 {
+		t.Errorf("weights got = %v %v, want = 1 3", frames[0].weight, frames[1].weight)
+	}
+
+	swapFrames(frames[1], frames[3])
+	if got, want := describeLayout(ws, frames), "[2] [3 0]"; got != want {
+		t.Errorf("layout got = %v, want = %v", got, want)
+	}
+	if got := frames[1].workspace(); got != other {
+		t.Errorf("workspace got = %v, want = %v", got.name, other.name)
+	}
+}
@@ -0,0 +1,34 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// handleFrameButtonPress reacts to a click within a frame window: the close button asks the client
+// to close the window (WM_DELETE_WINDOW), the left button over its borders starts resizing it, see startResizeAt,
+// the right button opens the menu of the window over the titlebar, anywhere else the window gets focused
+func (wm *WM) handleFrameButtonPress(e xproto.ButtonPressEvent) error {
+	f := wm.findFrame(func(frm *frame) bool { return frm.cli.Parent() == e.Event })
+	if f == nil {
+		return nil
+	}
+	if e.Detail == xproto.ButtonIndex1 && f.cli.CloseButtonContains(e.EventX, e.EventY) {
+		return wm.closeWindow(f)
+	}
+	if ok, err := wm.runButtonAction(e, f); ok {
+		return err
+	}
+	if ok, err := wm.startResizeAt(e); ok {
+		return err
+	}
+	if err := wm.setFocus(f.cli.Window(), e.Time); err != nil {
+		return err
+	}
+	if e.Detail == xproto.ButtonIndex3 && f.cli.TitlebarContains(e.EventX, e.EventY) {
+		return wm.openMenu(f, e.RootX, e.RootY, e.Time)
+	}
+	if e.Detail == xproto.ButtonIndex1 && f.col != nil && !f.fullscreen && f.cli.TitlebarContains(e.EventX, e.EventY) {
+		return wm.startTileDrag(f, e.RootX, e.RootY, e.Time)
+	}
+	return nil
+}
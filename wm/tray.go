@@ -0,0 +1,173 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// tray is the dedicated window into which the system tray icons (e.g. nm-applet) are embedded using XEmbed
+type tray struct {
+	win   xproto.Window
+	size  uint16          // width and height of each icon, also the height of the tray
+	icons []xproto.Window // embedded icons, in the order they're laid out in
+}
+
+// initTray creates the tray window as a dock at the top of the primary output and takes over the system tray selection
+func (wm *WM) initTray() error {
+	o := wm.primaryOutput()
+	size := wm.config.TrayIconSize
+	win, err := wm.xc.CreateTrayWindow(o.geom.X, o.geom.Y, o.geom.W, size, wm.config.TrayBgColor)
+	if err != nil {
+		return fmt.Errorf("failed to create the tray window: %v", err)
+	}
+	if err := wm.xc.AcquireTraySelection(win); err != nil {
+		_ = wm.xc.DestroyWindow(win)
+		return err
+	}
+	wm.tray = &tray{win: win, size: size}
+	return wm.manageWindow(win)
+}
+
+// placeTray moves the tray to the top of the primary output, e.g. after the outputs changed, and makes it
+// a dock of that output
+func (wm *WM) placeTray() error {
+	t, o := wm.tray, wm.primaryOutput()
+	if t == nil || o == nil {
+		return nil
+	}
+	if err := wm.xc.MoveTrayWindow(t.win, o.geom.X, o.geom.Y, o.geom.W, t.size); err != nil {
+		return fmt.Errorf("failed to move the tray window: %v", err)
+	}
+	f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == t.win })
+	if f == nil {
+		return nil
+	}
+	area, reserved, err := o.dockStrut(f)
+	if err != nil {
+		return err
+	}
+	if _, ok := o.dockArea(f); ok {
+		o.setDockStrut(f, area, reserved, wm.config.DockOrder)
+		return nil
+	}
+	for _, other := range wm.outputs {
+		other.deleteFrame(f)
+	}
+	return o.addDock(f, area, reserved, wm.config.DockOrder)
+}
+
+// dockTrayIcon embeds the icon window into the tray after the icon requested it
+func (wm *WM) dockTrayIcon(icon xproto.Window) error {
+	t := wm.tray
+	if t.index(icon) >= 0 {
+		return nil
+	}
+	mask := []uint32{xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange}
+	if err := xproto.ChangeWindowAttributesChecked(wm.xc.X(), icon, xproto.CwEventMask, mask).Check(); err != nil {
+		return fmt.Errorf("failed to change window attributes: %v", err)
+	}
+	// the icons return to the root window if the WM exits
+	if err := wm.xc.AddToSaveSet(icon); err != nil {
+		return err
+	}
+	if err := wm.xc.ReparentWindow(icon, t.win, t.iconX(len(t.icons)), 0); err != nil {
+		return err
+	}
+	t.add(icon)
+	if err := wm.layoutTray(); err != nil {
+		return err
+	}
+	if err := wm.xc.SendXEmbedNotify(icon, t.win); err != nil {
+		return err
+	}
+	return wm.updateTrayIcon(icon)
+}
+
+// undockTrayIcon removes the icon from the tray, returning false if the window is not a tray icon
+func (wm *WM) undockTrayIcon(icon xproto.Window) (bool, error) {
+	if wm.tray == nil {
+		return false, nil
+	}
+	if !wm.tray.remove(icon) {
+		return false, nil
+	}
+	return true, wm.layoutTray()
+}
+
+// releaseTrayIcon undocks the icon that unmapped itself, while its _XEMBED_INFO still asks for it to be
+// mapped, and gives it back to the root window. The icons hidden through _XEMBED_INFO are unmapped by
+// the WM and stay in the tray. It returns false if the window is not a tray icon
+func (wm *WM) releaseTrayIcon(icon xproto.Window) (bool, error) {
+	if wm.tray == nil || wm.tray.index(icon) < 0 {
+		return false, nil
+	}
+	if !wm.xc.XEmbedMapped(icon) {
+		return true, nil
+	}
+	if _, err := wm.undockTrayIcon(icon); err != nil {
+		return true, err
+	}
+	// the icon could be destroyed already, the DestroyNotify event follows
+	if err := wm.xc.ReparentWindow(icon, wm.xc.GetRootWindow(), 0, 0); err != nil {
+		logger.Debugf("Failed to give the tray icon %d back to the root window: %v", icon, err)
+	}
+	return true, nil
+}
+
+// updateTrayIcon maps or unmaps the icon according to its _XEMBED_INFO
+func (wm *WM) updateTrayIcon(icon xproto.Window) error {
+	if wm.xc.XEmbedMapped(icon) {
+		return wm.xc.MapWindow(icon)
+	}
+	return wm.xc.UnmapWindow(icon)
+}
+
+// layoutTray places the icons next to each other, starting from the left edge of the tray
+func (wm *WM) layoutTray() error {
+	t := wm.tray
+	var err error
+	for i, icon := range t.icons {
+		mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+		values := []uint32{uint32(t.iconX(i)), 0, uint32(t.size), uint32(t.size)}
+		if e := xproto.ConfigureWindowChecked(wm.xc.X(), icon, mask, values).Check(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// iconX returns the position of the icon of the given index within the tray window
+func (t *tray) iconX(i int) int16 {
+	return int16(i) * int16(t.size)
+}
+
+// add appends the icon to the ones laid out in the tray, returning false if it's already there
+func (t *tray) add(icon xproto.Window) bool {
+	if t.index(icon) >= 0 {
+		return false
+	}
+	t.icons = append(t.icons, icon)
+	return true
+}
+
+// remove takes the icon out of the tray, the icons after it move over, returning false if it's not there
+func (t *tray) remove(icon xproto.Window) bool {
+	i := t.index(icon)
+	if i < 0 {
+		return false
+	}
+	t.icons = append(t.icons[:i], t.icons[i+1:]...)
+	return true
+}
+
+func (t *tray) index(icon xproto.Window) int {
+	for i, w := range t.icons {
+		if w == icon {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,53 @@
+package wm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestTrayDockUndock(t *testing.T) {
+	tr := &tray{size: 24}
+	for _, icon := range []xproto.Window{10, 11, 12} {
+		if !tr.add(icon) {
+			t.Errorf("add(%d) got = false, want = true", icon)
+		}
+	}
+	if tr.add(11) {
+		t.Errorf("add() of a docked icon got = true, want = false")
+	}
+	if got, want := tr.iconX(tr.index(12)), int16(48); got != want {
+		t.Errorf("iconX() got = %v, want = %v", got, want)
+	}
+
+	if !tr.remove(11) {
+		t.Errorf("remove() got = false, want = true")
+	}
+	if tr.remove(11) {
+		t.Errorf("remove() of an undocked icon got = true, want = false")
+	}
+	if want := []xproto.Window{10, 12}; !reflect.DeepEqual(tr.icons, want) {
+		t.Errorf("icons got = %v, want = %v", tr.icons, want)
+	}
+	if got, want := tr.iconX(tr.index(12)), int16(24); got != want {
+		t.Errorf("iconX() after undocking got = %v, want = %v", got, want)
+	}
+}
+
+func TestUndockTrayIcon(t *testing.T) {
+	wm := &WM{}
+	if ok, err := wm.undockTrayIcon(10); ok || err != nil {
+		t.Errorf("without a tray got = %v, %v, want = false, nil", ok, err)
+	}
+	wm.tray = &tray{size: 24, icons: []xproto.Window{10}}
+	if ok, err := wm.undockTrayIcon(11); ok || err != nil {
+		t.Errorf("other window got = %v, %v, want = false, nil", ok, err)
+	}
+	if ok, err := wm.undockTrayIcon(10); !ok || err != nil {
+		t.Errorf("icon got = %v, %v, want = true, nil", ok, err)
+	}
+	if len(wm.tray.icons) != 0 {
+		t.Errorf("icons got = %v, want none", wm.tray.icons)
+	}
+}
@@ -0,0 +1,166 @@
+package wm
+
+import (
+	"strconv"
+
+	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/ipc"
+)
+
+// tree returns the representation of all the outputs, workspaces, columns and frames managed by the WM
+func (wm *WM) tree() ipc.Node {
+	root := ipc.Node{Type: "root", Name: "root", Window: uint32(wm.xc.GetRootWindow())}
+	for _, o := range wm.outputs {
+		root.Nodes = append(root.Nodes, wm.outputNode(o))
+	}
+	return root
+}
+
+func (wm *WM) outputNode(o *output) ipc.Node {
+	node := ipc.Node{Type: "output", Name: o.name, Rect: rectFromGeom(o.geom), Visible: true}
+	for area := range o.dockAreas {
+		for _, f := range o.dockAreas[area] {
+			dock := wm.frameNode(f)
+			dock.Type = "dock"
+			node.Nodes = append(node.Nodes, dock)
+		}
+	}
+	for _, f := range o.desktops {
+		desktop := wm.frameNode(f)
+		desktop.Type = "desktop"
+		node.Nodes = append(node.Nodes, desktop)
+	}
+	for _, ws := range o.workspaces {
+		node.Nodes = append(node.Nodes, wm.workspaceNode(ws))
+	}
+	return node
+}
+
+func (wm *WM) workspaceNode(ws *workspace) ipc.Node {
+	node := ipc.Node{
+		Type:    "workspace",
+		Name:    ws.name,
+		Layout:  ws.layoutName(),
+		Rect:    rectFromGeom(ws.fullArea()),
+		Visible: ws.output.activeWs == ws,
+		Urgent:  ws.urgent(),
+	}
+	a := ws.area()
+	x := a.X
+	widths := splitLength(ws.columnWeights(), a.W)
+	for i, col := range ws.columns {
+		node.Nodes = append(node.Nodes, wm.columnNode(col, ipc.Rect{X: x, Y: a.Y, W: widths[i], H: a.H}))
+		x += int16(widths[i])
+	}
+	for _, f := range ws.floating {
+		node.Nodes = append(node.Nodes, wm.frameNode(f))
+	}
+	return node
+}
+
+// columnNode describes a column or, if it's nested, a split container together with the frames inside it
+func (wm *WM) columnNode(col *column, r ipc.Rect) ipc.Node {
+	node := ipc.Node{Type: "column", Layout: "split", Rect: r}
+	if col.parent != nil {
+		node.Type = "split"
+		node.Orientation = "vertical"
+		if col.orientation == orientHorizontal {
+			node.Orientation = "horizontal"
+		}
+	}
+	if col.layout == layoutStacked {
+		node.Layout = "stacked"
+	}
+	total := r.H
+	if col.orientation == orientHorizontal {
+		total = r.W
+	}
+	lengths := splitLength(col.frameWeights(), total)
+	sr := r
+	for i, f := range col.frames {
+		if col.layout != layoutStacked {
+			if col.orientation == orientHorizontal {
+				sr.W = lengths[i]
+			} else {
+				sr.H = lengths[i]
+			}
+		}
+		if f.split != nil {
+			node.Nodes = append(node.Nodes, wm.columnNode(f.split, sr))
+		} else {
+			node.Nodes = append(node.Nodes, wm.frameNode(f))
+		}
+		if col.layout != layoutStacked {
+			if col.orientation == orientHorizontal {
+				sr.X += int16(lengths[i])
+			} else {
+				sr.Y += int16(lengths[i])
+			}
+		}
+	}
+	return node
+}
+
+func (wm *WM) frameNode(f *frame) ipc.Node {
+	typ := "frame"
+	if f.placeholder != nil {
+		typ = "placeholder"
+	}
+	return ipc.Node{
+		Type:       typ,
+		Name:       f.cli.Title(),
+		Window:     uint32(f.cli.Window()),
+		Rect:       rectFromGeom(f.cli.Geom()),
+		Focused:    f.cli.Window() == wm.activeWin,
+		Urgent:     f.state.urgent(),
+		Visible:    f.cli.Mapped(),
+		Floating:   f.floating,
+		Fullscreen: f.fullscreen,
+		Minimized:  f.state.hidden,
+		Marks:      f.marks,
+	}
+}
+
+// workspaceList returns the workspaces shown on the outputs, the numbered ones first
+func (wm *WM) workspaceList() []ipc.Workspace {
+	focused := wm.currentOutput().activeWs
+	list := make([]ipc.Workspace, 0, len(wm.workspaces))
+	for _, ws := range wm.workspaces {
+		if ws.output == nil {
+			continue
+		}
+		num := -1
+		if n, err := strconv.Atoi(ws.name); err == nil {
+			num = n
+		}
+		list = append(list, ipc.Workspace{
+			Name:    ws.name,
+			Num:     num,
+			Output:  ws.output.name,
+			Rect:    rectFromGeom(ws.fullArea()),
+			Visible: ws.output.activeWs == ws,
+			Focused: ws == focused,
+			Urgent:  ws.urgent(),
+			Layout:  ws.layoutName(),
+		})
+	}
+	return list
+}
+
+// outputList returns the outputs with their geometry and the workspace shown on each of them
+func (wm *WM) outputList() []ipc.Output {
+	current := wm.currentOutput()
+	list := make([]ipc.Output, 0, len(wm.outputs))
+	for _, o := range wm.outputs {
+		out := ipc.Output{Name: o.name, Rect: rectFromGeom(o.geom), Primary: o.primary, Focused: o == current}
+		if o.activeWs != nil {
+			out.CurrentWorkspace = o.activeWs.name
+		}
+		list = append(list, out)
+	}
+	return list
+}
+
+func rectFromGeom(geom client.Geom) ipc.Rect {
+	return ipc.Rect{X: geom.X, Y: geom.Y, W: geom.W, H: geom.H}
+}
@@ -0,0 +1,85 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/client"
+)
+
+// setUrgent marks the frame as demanding attention, or clears the mark along with its urgency hint, e.g. when
+// it's focused, updating its state and titlebar
+func (wm *WM) setUrgent(f *frame, urgent bool) error {
+	was := f.state.urgent()
+	f.state.demandsAttention = urgent
+	if !urgent {
+		f.state.urgencyHint = false
+	}
+	return wm.urgencyChanged(f, was)
+}
+
+// updateUrgency follows the urgency flag of the WM_HINTS property after it changed. The focused frame
+// is never marked as urgent as it already has the user's attention
+func (wm *WM) updateUrgency(f *frame) error {
+	urgent := wm.xc.GetUrgencyHint(f.cli.Window())
+	if urgent && f.cli.Window() == wm.activeWin {
+		return nil
+	}
+	was := f.state.urgent()
+	f.state.urgencyHint = urgent
+	return wm.urgencyChanged(f, was)
+}
+
+// restoreUrgencyHint sets the urgency hint read when the frame is managed. As updateWindowState publishes
+// _NET_WM_STATE_DEMANDS_ATTENTION for the hint too, the flag found along with the hint, e.g. after a restart,
+// isn't taken for a demand of its own, so that the frame stops being urgent once the hint is cleared
+func (s *frameState) restoreUrgencyHint(hint bool) {
+	s.urgencyHint = hint
+	if hint {
+		s.demandsAttention = false
+	}
+}
+
+// urgencyChanged updates the state and titlebar of the frame after one of the sources of its urgency changed,
+// if whether it's urgent changed too
+func (wm *WM) urgencyChanged(f *frame, was bool) error {
+	urgent := f.state.urgent()
+	if urgent == was {
+		return nil
+	}
+	if err := f.cli.SetUrgent(urgent); err != nil {
+		return err
+	}
+	wm.emitWindowEvent("urgent", f)
+	return wm.updateWindowState(f)
+}
+
+// focusUrgent switches to the workspace of a frame demanding attention and focuses it
+func (wm *WM) focusUrgent() error {
+	f := wm.findFrame(func(frm *frame) bool {
+		return frm.state.urgent() && frm.cli.Type() == client.TypeNormal && frm.workspace() != nil
+	})
+	if f == nil {
+		return nil
+	}
+	if ws := f.workspace(); ws.output == nil || ws.output.activeWs != ws {
+		if err := wm.switchWorkspace(ws.name); err != nil {
+			return err
+		}
+	}
+	if f.floating {
+		if err := wm.raiseFrame(f); err != nil {
+			return err
+		}
+	}
+	return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+}
+
+// urgent returns true if any of the frames of the workspace demands attention
+func (ws *workspace) urgent() bool {
+	for _, f := range ws.frames() {
+		if f.state.urgent() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package wm
+
+import (
+	"testing"
+)
+
+func TestFrameStateRestoreUrgencyHint(t *testing.T) {
+	tests := []struct {
+		name             string
+		demandsAttention bool
+		hint             bool
+		wantUrgent       bool
+		wantAfterHint    bool
+	}{
+		{"hint only", true, true, true, false},
+		{"demands attention only", true, false, true, true},
+		{"not urgent", false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the state read back from the properties published before the restart
+			s := frameState{demandsAttention: tt.demandsAttention}
+			s.restoreUrgencyHint(tt.hint)
+			if got := s.urgent(); got != tt.wantUrgent {
+				t.Errorf("urgent() got = %v, want = %v", got, tt.wantUrgent)
+			}
+			s.urgencyHint = false
+			if got := s.urgent(); got != tt.wantAfterHint {
+				t.Errorf("urgent() after the hint is cleared got = %v, want = %v", got, tt.wantAfterHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package wm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// initUserTime reads the time of the last user activity in a new frame, from the window set in its
+// _NET_WM_USER_TIME_WINDOW property if any, falling back to the timestamp of its startup notification
+func (wm *WM) initUserTime(f *frame) {
+	win := f.cli.Window()
+	if w := wm.xc.GetUserTimeWindow(win); w != 0 && w != win {
+		f.userTimeWin = w
+		// the changes of the property are only reported if the WM selects them on that window
+		cookie := xproto.ChangeWindowAttributesChecked(wm.xc.X(), w, xproto.CwEventMask,
+			[]uint32{xproto.EventMaskPropertyChange})
+		if err := cookie.Check(); err != nil {
+			logger.Warnf("Failed to select the events of the user time window %d: %v", w, err)
+		}
+	}
+	wm.updateUserTime(f)
+	if !f.hasUserTime {
+		f.userTime, f.hasUserTime = startupTime(wm.xc.GetStartupID(win))
+	}
+}
+
+// updateUserTime reads the _NET_WM_USER_TIME of the frame after it changed
+func (wm *WM) updateUserTime(f *frame) {
+	win := f.cli.Window()
+	if f.userTimeWin != 0 {
+		win = f.userTimeWin
+	}
+	if t, ok := wm.xc.GetUserTime(win); ok {
+		f.userTime, f.hasUserTime = t, true
+	}
+}
+
+// noteUserTime records the user activity in the frame at the given time, unless a later one is known
+func (f *frame) noteUserTime(t uint32) {
+	if !f.hasUserTime || timeAfter(t, f.userTime) {
+		f.userTime, f.hasUserTime = t, true
+	}
+}
+
+// focusNewFrame gives the focus to a newly managed frame on a visible workspace, or marks it as urgent
+// if that would steal the focus from the window the user interacted with more recently
+func (wm *WM) focusNewFrame(f *frame) error {
+	ws := f.workspace()
+	if ws == nil || f.state.hidden || !f.focusable() || ws.output == nil || ws.output.activeWs != ws {
+		return nil
+	}
+	if prev := wm.focusedFrame(); wm.fullscreenLocked() && prev != f && f.transientFor != prev {
+		// the fullscreen window stays focused and thus above the new one
+		return wm.setUrgent(f, true)
+	}
+	if !wm.config.FocusStealingPrevention {
+		return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+	}
+	if f.hasUserTime && f.userTime == 0 {
+		// the client asked not to be focused, e.g. a notification
+		return nil
+	}
+	if prev := wm.focusedFrame(); prev != nil && prev != f && prev.hasUserTime && f.hasUserTime &&
+		!timeAfter(f.userTime, prev.userTime) {
+		return wm.setUrgent(f, true)
+	}
+	return wm.setFocus(f.cli.Window(), xproto.TimeCurrentTime)
+}
+
+// startupTime extracts the timestamp of the user action that launched a program from its startup
+// notification ID, which by convention ends with "_TIME" followed by the timestamp
+func startupTime(id string) (uint32, bool) {
+	i := strings.LastIndex(id, "_TIME")
+	if i < 0 {
+		return 0, false
+	}
+	t, err := strconv.ParseUint(id[i+len("_TIME"):], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(t), true
+}
+
+// timeAfter compares two X server timestamps, taking into account that they wrap around after about 49 days
+func timeAfter(a, b uint32) bool {
+	return int32(a-b) > 0
+}
@@ -0,0 +1,46 @@
+package wm
+
+import "testing"
+
+func TestStartupTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		want   uint32
+		wantOk bool
+	}{
+		{"with time", "xterm-1234-host-0_TIME56789", 56789, true},
+		{"last time wins", "a_TIMEx_TIME42", 42, true},
+		{"without time", "xterm-1234-host-0", 0, false},
+		{"invalid time", "launcher_TIMEnow", 0, false},
+		{"empty", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := startupTime(tt.id)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("startupTime() got = %v, %v, want = %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestTimeAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b uint32
+		want bool
+	}{
+		{"later", 200, 100, true},
+		{"earlier", 100, 200, false},
+		{"equal", 100, 100, false},
+		{"wrapped around", 5, 0xfffffff0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timeAfter(tt.a, tt.b); got != tt.want {
+				t.Errorf("timeAfter() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package wm
+
+import (
+	"image"
+	"image/draw"
+	_ "image/gif" // image formats of the wallpapers
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+
+	"github.com/BurntSushi/xgbutil/xgraphics"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// Wallpaper is the background of an output: an image placed according to the mode over a solid color
+type Wallpaper struct {
+	Color uint32 // as 0xAARRGGBB, shown around the image and through its transparent parts
+	Image string // path of a PNG, JPEG or GIF file, empty for the color alone
+	Mode  string // WallpaperFill (the default), WallpaperFit, WallpaperCenter or WallpaperTile
+}
+
+// Placements of the wallpaper images on their outputs, see Wallpaper.Mode
+const (
+	WallpaperFill   = "fill"   // scaled to cover the whole output, cropping the image
+	WallpaperFit    = "fit"    // scaled to fit within the output, leaving bars of the color
+	WallpaperCenter = "center" // in the middle of the output, not scaled
+	WallpaperTile   = "tile"   // repeated from the top left corner of the output, not scaled
+)
+
+// DefaultWallpaper is the key of the wallpaper of the outputs without their own, see Config.Wallpapers
+const DefaultWallpaper = "*"
+
+// applyWallpaper paints the wallpapers of all the outputs on the background of the root window, if any
+// are configured. It's done again whenever the outputs change
+func (wm *WM) applyWallpaper() {
+	if len(wm.config.Wallpapers) == 0 {
+		return
+	}
+	screen := wm.xc.Screen()
+	img := wm.xc.NewImage(image.Rect(0, 0, int(screen.WidthInPixels), int(screen.HeightInPixels)))
+	images := make(map[string]image.Image)
+	for _, o := range wm.outputs {
+		wp, ok := wm.config.Wallpapers[o.name]
+		if !ok {
+			wp = wm.config.Wallpapers[DefaultWallpaper]
+		}
+		bounds := image.Rect(int(o.geom.X), int(o.geom.Y), int(o.geom.X)+int(o.geom.W), int(o.geom.Y)+int(o.geom.H))
+		fill(img, bounds, wp.Color)
+		if wp.Image == "" {
+			continue
+		}
+		src, ok := images[wp.Image]
+		if !ok {
+			var err error
+			if src, err = loadImage(wp.Image); err != nil {
+				logger.Errorf("Failed to load the wallpaper of output %q: %v", o.name, err)
+			}
+			images[wp.Image] = src
+		}
+		if src != nil {
+			drawWallpaper(img, bounds, src, wp.Mode)
+		}
+	}
+	if err := wm.xc.SetRootBackground(img); err != nil {
+		logger.Errorf("Failed to set the wallpaper: %v", err)
+	}
+}
+
+// loadImage decodes the image file
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// drawWallpaper draws the image within the bounds of an output according to the mode
+func drawWallpaper(dst draw.Image, bounds image.Rectangle, src image.Image, mode string) {
+	size := src.Bounds().Size()
+	if mode == WallpaperTile {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y += size.Y {
+			for x := bounds.Min.X; x < bounds.Max.X; x += size.X {
+				r := image.Rect(x, y, x+size.X, y+size.Y).Intersect(bounds)
+				draw.Draw(dst, r, src, src.Bounds().Min, draw.Over)
+			}
+		}
+		return
+	}
+	r := wallpaperRect(size, bounds, mode)
+	if r.Empty() {
+		return
+	}
+	if r.Size() != size {
+		src = xgraphics.Scale(src, r.Dx(), r.Dy())
+	}
+	visible := r.Intersect(bounds)
+	draw.Draw(dst, visible, src, src.Bounds().Min.Add(visible.Min.Sub(r.Min)), draw.Over)
+}
+
+// wallpaperRect returns where an image of the given size goes on an output with the given bounds, scaled
+// according to the mode and centered. With WallpaperFill and WallpaperCenter, the image can extend past
+// the bounds
+func wallpaperRect(size image.Point, bounds image.Rectangle, mode string) image.Rectangle {
+	w, h := size.X, size.Y
+	if w == 0 || h == 0 {
+		return image.Rectangle{}
+	}
+	switch mode {
+	case WallpaperCenter:
+	case WallpaperFit:
+		// the image is scaled by the smaller of the ratios of the sizes, compared as cross products
+		if bounds.Dx()*h < bounds.Dy()*w {
+			w, h = bounds.Dx(), h*bounds.Dx()/w
+		} else {
+			w, h = w*bounds.Dy()/h, bounds.Dy()
+		}
+	default:
+		if bounds.Dx()*h > bounds.Dy()*w {
+			w, h = bounds.Dx(), h*bounds.Dx()/w
+		} else {
+			w, h = w*bounds.Dy()/h, bounds.Dy()
+		}
+	}
+	x := bounds.Min.X + (bounds.Dx()-w)/2
+	y := bounds.Min.Y + (bounds.Dy()-h)/2
+	return image.Rect(x, y, x+w, y+h)
+}
@@ -0,0 +1,47 @@
+package wm
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWallpaperRect(t *testing.T) {
+	bounds := image.Rect(1920, 0, 3840, 1080)
+	tests := []struct {
+		name string
+		size image.Point
+		mode string
+		want image.Rectangle
+	}{
+		{"fill wider", image.Pt(1000, 500), WallpaperFill, image.Rect(1800, 0, 3960, 1080)},
+		{"fill taller", image.Pt(800, 600), WallpaperFill, image.Rect(1920, -180, 3840, 1260)},
+		{"fit wider", image.Pt(1000, 500), WallpaperFit, image.Rect(1920, 60, 3840, 1020)},
+		{"fit taller", image.Pt(800, 600), WallpaperFit, image.Rect(2160, 0, 3600, 1080)},
+		{"center", image.Pt(800, 600), WallpaperCenter, image.Rect(2480, 240, 3280, 840)},
+		{"empty", image.Pt(0, 0), WallpaperFill, image.Rectangle{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wallpaperRect(tt.size, bounds, tt.mode); got != tt.want {
+				t.Errorf("wallpaperRect() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrawWallpaperTile(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.Set(0, 0, color.White)
+	dst := image.NewRGBA(image.Rect(0, 0, 10, 5))
+	bounds := image.Rect(5, 0, 10, 5)
+	drawWallpaper(dst, bounds, src, WallpaperTile)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 10; x++ {
+			want := x >= 5 && (x-5)%2 == 0 && y%2 == 0
+			if got := dst.RGBAAt(x, y) == (color.RGBA{R: 255, G: 255, B: 255, A: 255}); got != want {
+				t.Errorf("pixel (%d, %d) got = %v, want = %v", x, y, got, want)
+			}
+		}
+	}
+}
@@ -2,42 +2,90 @@ package wm
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/patrislav/marwind/client"
+	"github.com/patrislav/marwind/ipc"
 	"github.com/patrislav/marwind/keysym"
+	"github.com/patrislav/marwind/logger"
 	"github.com/patrislav/marwind/x11"
 )
 
-const maxWorkspaces = 10
+// defaultWorkspaces is the number of the workspaces that have default keybindings
+const defaultWorkspaces = 10
 
 // WM is a struct representing the Window Manager
 type WM struct {
 	xc           *x11.Connection
+	dpy          display // the X connection as seen by the layout, see display
 	outputs      []*output
 	keymap       keysym.Keymap
+	modmap       keysym.ModMap // masks of the lock modifiers in the current modifier mapping
 	actions      []*action
+	buttons      []*buttonAction         // actions of the mouse bindings
+	messages     map[xproto.Atom]message // handlers of the ClientMessage events, keyed by their type
 	config       Config
-	workspaces   [maxWorkspaces]*workspace
+	workspaces   []*workspace // sorted by name, see workspaceLess
 	activeWin    xproto.Window
 	windowConfig *client.Config
+
+	// copies of the window config scaled for the outputs with a higher DPI, keyed by the factor, see clientConfig
+	scaledConfigs map[float64]*client.Config
+
+	// last geometries of the floating windows, keyed by their WM_CLASS, see rememberFloatingGeom
+	floatingGeoms map[string]client.Geom
+
+	drag         *drag
+	menu         *menu     // popup menu currently open, nil if none
+	overview     *overview // overview currently shown, nil if none
+	prompt       *prompt   // prompt currently shown, nil if none
+	osd          *osd      // on-screen display, nil until it's first shown
+	ipc          *ipc.Server
+	ipcRequests  chan ipcRequest
+	tasks        chan func() error // functions to run in the event loop
+	done         chan struct{}     // closed once the event loop has stopped
+	configLoader ConfigLoader
+	scratchpad   []*frame    // hidden scratchpad frames, the next one to show first
+	minimized    []*frame    // minimized frames, the most recently minimized last
+	quitting     bool        // set when the event loop should stop
+	replace      bool        // whether to take over from an already running WM
+	tray         *tray       // system tray, nil if disabled
+	cycle        *focusCycle // focus cycling in progress, nil if there's none
+	keyState     uint16      // modifiers of the key press that triggered the running action
+	mode         string      // name of the active binding mode, empty for the default one
+	restarted    bool        // set when the WM took over the session of its previous instance
+	focusedWs    string      // name of the focused workspace announced in the last IPC event
+
+	focusedOutput *output        // output focused last, used for new windows when no window has the focus
+	pointerCursor *pointerCursor // resize cursor set by the WM, nil if none, see updateResizeCursor
+	placeMark     *frame         // tiled frame after which the next window is placed, see cmdPlace
+	selected      *frame         // window the running command acts on instead of the focused one, see targetFrame
+	edge          *edgeState     // edge of the screen the pointer is at, nil until the pointer is polled, see watchPointer
+	idle          *idleState     // idle hooks executed so far, nil until the idle time is polled, see watchIdle
+
+	stack  []xproto.Window // top-level windows in the order they were last stacked in from the bottom, see restack
+	raises uint64          // number of times a frame was raised, see markRaised
+
+	notifications uint64 // number of notification windows managed so far, see queueNotification
+	launches      uint64 // number of programs started from the launcher, see cmdLauncher
+	docks         uint64 // number of docks managed so far, see frame.docked
+
+	launching map[string]bool // startup IDs of the launches awaiting their window, see launch
+
+	faults map[xproto.Window]*faults // recent failures of the client windows, see recordFault
 }
 
 // New initializes a WM and creates an X11 connection
 func New(config Config) (*WM, error) {
-	wc := &client.Config{
-		BgColor:        config.BorderColor,
-		TitlebarHeight: config.TitleBarHeight,
-		FontColor:      config.TitleBarFontColorActive,
-		FontSize:       config.TitleBarFontSize,
-		BorderWidth:    config.BorderWidth,
+	if err := applyLogging(config); err != nil {
+		logger.Errorf("Failed to set up logging: %v", err)
 	}
+	wc := newWindowConfig(config)
 	xconn, err := x11.Connect()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WM: %v", err)
 	}
-	wm := &WM{xc: xconn, config: config, windowConfig: wc}
+	wm := &WM{xc: xconn, dpy: xconn, config: config, windowConfig: &wc, ipcRequests: make(chan ipcRequest), tasks: make(chan func() error), done: make(chan struct{})}
 	return wm, nil
 }
 
@@ -46,46 +94,66 @@ func (wm *WM) Init() error {
 	if err := wm.xc.Init(); err != nil {
 		return fmt.Errorf("failed to init WM: %v", err)
 	}
+	if err := wm.xc.AcquireSelection(wm.replace); err != nil {
+		return fmt.Errorf("could not become WM: %v", err)
+	}
+	if err := wm.xc.InitRoot(); err != nil {
+		return fmt.Errorf("failed to init WM: %v", err)
+	}
 	if err := wm.becomeWM(); err != nil {
 		if _, ok := err.(xproto.AccessError); ok {
 			return fmt.Errorf("could not become WM, possibly another WM is already running")
 		}
 		return fmt.Errorf("could not become WM: %v", err)
 	}
-	km, err := keysym.LoadKeyMapping(wm.xc.X())
-	if err != nil {
-		return fmt.Errorf("failed to load key mapping: %v", err)
+	wm.applyCursorTheme()
+	if err := wm.loadKeyboard(); err != nil {
+		return err
 	}
-	wm.keymap = *km
 	wm.actions = initActions(wm)
 	if err := wm.grabKeys(); err != nil {
 		return fmt.Errorf("failed to grab keys: %v", err)
 	}
+	wm.buttons = initButtonActions(wm)
+	wm.messages = initMessages(wm)
+	if err := wm.grabButtons(); err != nil {
+		return fmt.Errorf("failed to grab buttons: %v", err)
+	}
 
-	o := newOutput(wm.xc, client.Geom{
-		X: 0, Y: 0,
-		W: wm.xc.Screen().WidthInPixels,
-		H: wm.xc.Screen().HeightInPixels,
-	})
-	for i := 0; i < maxWorkspaces; i++ {
-		wm.workspaces[i] = newWorkspace(uint8(i), workspaceConfig{gap: wm.config.OuterGap})
-	}
-	if err := o.addWorkspace(wm.workspaces[0]); err != nil {
-		return fmt.Errorf("failed to add workspace to output: %v", err)
-	}
-	wm.outputs = append(wm.outputs, o)
+	if err := wm.updateOutputs(); err != nil {
+		return fmt.Errorf("failed to init outputs: %v", err)
+	}
 
 	if err := wm.xc.SetWMName("Marwind"); err != nil {
 		return fmt.Errorf("failed to set WM name: %v", err)
 	}
-	if err := wm.manageExistingClients(); err != nil {
+	wm.loadRememberedGeoms()
+	s, err := inheritedSession()
+	if err != nil {
+		logger.Errorf("Failed to load the saved session: %v", err)
+	}
+	if err := wm.manageExistingClients(s); err != nil {
 		return fmt.Errorf("failed to manage existing clients: %v", err)
 	}
+	if s != nil {
+		wm.restarted = true
+		if err := wm.restoreSession(s); err != nil {
+			logger.Errorf("Failed to restore the saved session: %v", err)
+		}
+	}
+	if wm.config.Tray {
+		if err := wm.initTray(); err != nil {
+			logger.Errorf("Failed to start the system tray: %v", err)
+		}
+	}
 	return nil
 }
 
 // Close cleans up the WM's resources
 func (wm *WM) Close() {
+	if wm.ipc != nil {
+		wm.ipc.Close()
+	}
 	if wm.xc != nil {
 		wm.xc.Close()
 	}
@@ -96,6 +164,13 @@ func (wm *WM) Run() error {
 	if err := wm.updateDesktopHints(); err != nil {
 		return err
 	}
+	if err := wm.startIPC(); err != nil {
+		logger.Errorf("Failed to start IPC server: %v", err)
+	}
+	wm.runStartupCommands()
+	wm.watchPointer()
+	wm.applyDPMS()
+	wm.watchIdle()
 	handler := eventHandler{wm: wm}
 	handler.eventLoop()
 	return nil
@@ -108,6 +183,7 @@ func (wm *WM) becomeWM() error {
 			xproto.EventMaskKeyRelease |
 			xproto.EventMaskButtonPress |
 			xproto.EventMaskButtonRelease |
+			xproto.EventMaskPointerMotion |
 			xproto.EventMaskPropertyChange |
 			xproto.EventMaskFocusChange |
 			xproto.EventMaskStructureNotify |
@@ -116,34 +192,90 @@ func (wm *WM) becomeWM() error {
 	return xproto.ChangeWindowAttributesChecked(wm.xc.X(), wm.xc.GetRootWindow(), xproto.CwEventMask, evtMask).Check()
 }
 
-// grabKeys attempts to get a sole ownership of certain key combinations
+// SetConfigLoader sets the function used for loading the configuration when the WM is reloaded
+func (wm *WM) SetConfigLoader(loader ConfigLoader) {
+	wm.configLoader = loader
+}
+
+// SetReplace makes the WM take over from another WM that is already running instead of failing to start
+func (wm *WM) SetReplace(replace bool) {
+	wm.replace = replace
+}
+
+// grabKeys attempts to get a sole ownership of certain key combinations, with any of the lock modifiers on
 func (wm *WM) grabKeys() error {
 	for _, action := range wm.actions {
 		for _, code := range action.codes {
-			cookie := xproto.GrabKeyChecked(
-				wm.xc.X(),
-				false,
-				wm.xc.GetRootWindow(),
-				uint16(action.modifiers),
-				code,
-				xproto.GrabModeAsync,
-				xproto.GrabModeAsync,
-			)
-			if err := cookie.Check(); err != nil {
-				return err
+			for _, locks := range wm.modmap.LockCombinations() {
+				cookie := xproto.GrabKeyChecked(
+					wm.xc.X(),
+					false,
+					wm.xc.GetRootWindow(),
+					uint16(action.modifiers)|locks,
+					code,
+					xproto.GrabModeAsync,
+					xproto.GrabModeAsync,
+				)
+				if err := cookie.Check(); err != nil {
+					return err
+				}
 			}
 		}
 	}
 	return nil
 }
 
+// ungrabKeys releases all the key combinations grabbed by the WM
+func (wm *WM) ungrabKeys() error {
+	return xproto.UngrabKeyChecked(wm.xc.X(), xproto.GrabAny, wm.xc.GetRootWindow(), xproto.ModMaskAny).Check()
+}
+
+// regrabKeys creates the actions of the keybindings anew and grabs their keys, e.g. after the keybindings
+// or the keyboard mapping changed
+func (wm *WM) regrabKeys() error {
+	if err := wm.ungrabKeys(); err != nil {
+		return fmt.Errorf("failed to ungrab keys: %v", err)
+	}
+	wm.actions = initActions(wm)
+	if err := wm.grabKeys(); err != nil {
+		return fmt.Errorf("failed to grab keys: %v", err)
+	}
+	return nil
+}
+
+// reloadKeymap reads the keyboard and modifier mappings again after they changed, e.g. when another layout
+// was set with setxkbmap, a keyboard was plugged in or the modifiers were remapped with xmodmap, as the keys
+// of the bindings may now have different keycodes and the lock modifiers different masks
+func (wm *WM) reloadKeymap() error {
+	if err := wm.loadKeyboard(); err != nil {
+		return err
+	}
+	if err := wm.regrabButtons(); err != nil {
+		return err
+	}
+	return wm.regrabKeys()
+}
+
+// loadKeyboard reads the keyboard mapping and the modifiers mapped to its keys
+func (wm *WM) loadKeyboard() error {
+	km, err := keysym.LoadKeyMapping(wm.xc.X())
+	if err != nil {
+		return fmt.Errorf("failed to load key mapping: %v", err)
+	}
+	mm, err := keysym.LoadModMap(wm.xc.X(), km)
+	if err != nil {
+		return fmt.Errorf("failed to load modifier mapping: %v", err)
+	}
+	wm.keymap = *km
+	wm.modmap = *mm
+	return nil
+}
+
 func (wm *WM) findFrame(predicate func(*frame) bool) *frame {
 	for _, ws := range wm.workspaces {
-		for _, col := range ws.columns {
-			for _, f := range col.frames {
-				if predicate(f) {
-					return f
-				}
+		for _, f := range ws.frames() {
+			if predicate(f) {
+				return f
 			}
 		}
 	}
@@ -155,59 +287,142 @@ func (wm *WM) findFrame(predicate func(*frame) bool) *frame {
 				}
 			}
 		}
+		for _, f := range o.desktops {
+			if predicate(f) {
+				return f
+			}
+		}
+	}
+	for _, f := range wm.scratchpad {
+		if predicate(f) {
+			return f
+		}
+	}
+	for _, f := range append(wm.allFrames(), wm.scratchpad...) {
+		for s := f.swallowed; s != nil; s = s.swallowed {
+			if predicate(s) {
+				return s
+			}
+		}
 	}
 	return nil
 }
 
 func (wm *WM) deleteFrame(f *frame) error {
+	wm.emitWindowEvent("close", f)
+	delete(wm.faults, f.cli.Window())
+	if err := wm.xc.RemoveClient(f.cli.Window()); err != nil {
+		logger.Errorf("Failed to update the client list: %v", err)
+	}
+	if s := wm.swallower(f); s != nil {
+		// the swallowed terminal was closed while hidden
+		s.swallowed = f.swallowed
+		return nil
+	}
+	if err := wm.unswallow(f); err != nil {
+		logger.Errorf("Failed to restore the swallowed window: %v", err)
+	}
+	wm.deleteMinimized(f)
+	if wm.drag != nil && wm.drag.involves(f) {
+		if err := wm.cancelDrag(); err != nil {
+			logger.Errorf("Failed to cancel the drag of the closed window: %v", err)
+		}
+	}
+	if wm.placeMark == f {
+		wm.placeMark = nil
+	}
+	wm.rememberFloatingGeom(f)
+	ws := f.workspace()
+	focused := f.cli.Window() == wm.activeWin
+	for _, t := range wm.transients(f) {
+		t.transientFor = nil
+	}
+	if wm.deleteScratchpadFrame(f) {
+		return nil
+	}
 	for _, o := range wm.outputs {
 		if o.deleteFrame(f) {
-			if err := wm.removeFocus(); err != nil {
+			if err := wm.renderOutput(o); err != nil {
 				return err
 			}
-			return wm.renderOutput(o)
+			if ws == nil {
+				return nil
+			}
+			ws.forgetFocus(f)
+			if !focused {
+				return nil
+			}
+			return wm.focusWorkspace(ws)
 		}
 	}
 	return fmt.Errorf("could not find frame to delete: %v", f)
 }
 
+// handleKeyPressEvent runs the action bound to the pressed key. The keysyms of the bindings are looked up
+// in the active keyboard group and then in the first one, so that e.g. "mod+q" still works with a Cyrillic layout
 func (wm *WM) handleKeyPressEvent(e xproto.KeyPressEvent) error {
-	sym := wm.keymap[e.Detail][0]
+	if wm.menu != nil {
+		return wm.closeMenu(e.Time)
+	}
+	if wm.overview != nil {
+		return wm.handleOverviewKey(e)
+	}
+	if wm.prompt != nil {
+		return wm.handlePromptKey(e)
+	}
+	if wm.drag != nil && wm.drag.keyboard {
+		return wm.handleDragKey(e)
+	}
+	sym := wm.keymap.Keysym(e.Detail, keysym.Group(e.State))
+	baseSym := wm.keymap.Keysym(e.Detail, 0)
+	locks := wm.modmap.Locks()
+	state := e.State &^ (keysym.GroupMask | locks)
 	for _, action := range wm.actions {
-		if sym == action.sym && e.State == uint16(action.modifiers) {
+		if (sym == action.sym || baseSym == action.sym) && state == uint16(action.modifiers)&^locks {
+			wm.keyState = e.State
+			defer func() { wm.keyState = 0 }()
 			return action.act()
 		}
 	}
 	return nil
 }
 
+// updateDesktopHints first removes the workspaces that are no longer needed, so that the number
+// of desktops always matches the existing workspaces
 // TODO: avoid updating all hints at once
 func (wm *WM) updateDesktopHints() error {
-	out := wm.outputs[0]
-	wsWins := make([][]xproto.Window, len(out.workspaces))
-	names := make([]string, len(out.workspaces))
+	wm.pruneWorkspaces()
+	workspaces := wm.desktopWorkspaces()
+	wsWins := make([][]xproto.Window, len(workspaces))
+	names := make([]string, len(workspaces))
+	var sticky []xproto.Window
 	current := 0
-	for i, ws := range out.workspaces {
-		names[i] = fmt.Sprintf("%d", ws.id+1)
-		for _, col := range ws.columns {
-			for _, f := range col.frames {
+	currentWs := wm.currentOutput().activeWs
+	wm.emitWorkspaceFocus(currentWs)
+	for i, ws := range workspaces {
+		names[i] = ws.name
+		for _, f := range ws.frames() {
+			if f.state.sticky {
+				sticky = append(sticky, f.cli.Window())
+			} else {
 				wsWins[i] = append(wsWins[i], f.cli.Window())
 			}
 		}
-		if ws == out.activeWs {
+		if ws == currentWs {
 			current = i
+		}
+		if out := ws.output; ws == out.activeWs {
 			for area := range out.dockAreas {
 				for _, f := range out.dockAreas[area] {
 					wsWins[i] = append(wsWins[i], f.cli.Window())
 				}
 			}
+			for _, f := range out.desktops {
+				wsWins[i] = append(wsWins[i], f.cli.Window())
+			}
 		}
 	}
-	windows := make([]xproto.Window, 0)
-	for _, wins := range wsWins {
-		windows = append(windows, wins...)
-	}
-	if err := wm.xc.SetDesktopHints(names, current, windows); err != nil {
+	if err := wm.xc.SetDesktopHints(names, current); err != nil {
 		return err
 	}
 	var err error
@@ -218,51 +433,40 @@ func (wm *WM) updateDesktopHints() error {
 			}
 		}
 	}
-	return err
-}
-
-func (wm *WM) handleConfigureRequest(e xproto.ConfigureRequestEvent) error {
-	f := wm.findFrame(func(frm *frame) bool { return frm.cli.Window() == e.Window })
-	if f != nil {
-		if err := wm.configureNotify(f); err != nil {
-			return fmt.Errorf("failed to send ConfigureNotify event to %d: %v", e.Window, err)
+	for _, win := range sticky {
+		if e := wm.xc.SetWindowDesktop(win, x11.AllDesktops); e != nil {
+			err = e
 		}
-		return nil
 	}
-	ev := xproto.ConfigureNotifyEvent{
-		Event:            e.Window,
-		Window:           e.Window,
-		AboveSibling:     0,
-		X:                e.X,
-		Y:                e.Y,
-		Width:            e.Width,
-		Height:           e.Height,
-		BorderWidth:      0,
-		OverrideRedirect: false,
-	}
-	xproto.SendEventChecked(wm.xc.X(), false, e.Window, xproto.EventMaskStructureNotify, string(ev.Bytes()))
-	return nil
+	return err
 }
 
-func (wm *WM) manageExistingClients() error {
+func (wm *WM) manageExistingClients(s *session) error {
 	tree, err := xproto.QueryTree(wm.xc.X(), wm.xc.GetRootWindow()).Reply()
 	if err != nil {
 		return err
 	}
+	// windows of a saved session are managed even if they were hidden by the previous instance
+	var known map[xproto.Window]bool
+	if s != nil {
+		known = s.windows()
+	}
 	for _, win := range tree.Children {
 		attrs, err := xproto.GetWindowAttributes(wm.xc.X(), win).Reply()
 		if err != nil {
 			continue
 		}
-		if attrs.MapState == xproto.MapStateUnmapped || attrs.OverrideRedirect {
+		// the windows iconified by the previous WM are still managed, unlike the withdrawn ones
+		iconic := wm.xc.GetWMState(win) == x11.IconicState
+		if attrs.MapState == xproto.MapStateUnmapped && !known[win] && !iconic || attrs.OverrideRedirect {
 			continue
 		}
 		if err := wm.manageWindow(win); err != nil {
-			log.Println("Failed to manage an existing window:", err)
+			logger.Errorf("Failed to manage an existing window: %v", err)
 		}
 	}
 	if err := wm.updateDesktopHints(); err != nil {
 		return err
 	}
-	return wm.renderOutput(wm.outputs[0])
+	return wm.renderOutputs()
 }
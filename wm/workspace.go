@@ -1,22 +1,62 @@
 package wm
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/patrislav/marwind/client"
 )
 
 type workspaceConfig struct {
-	gap uint16
+	gap      uint16 // outer gap around the workspace area
+	innerGap uint16 // gap around each tiled frame
 }
 
 type workspace struct {
-	id      uint8
-	columns []*column
-	output  *output
-	config  workspaceConfig
+	name     string // as presented to the user, e.g. "1" or "web"
+	columns  []*column
+	floating []*frame
+	output   *output
+
+	fullscreen *frame
+	maximized  *frame   // tiled frame covering the others, see setMaximized
+	monocle    bool     // whether all the tiled frames take the whole workspace, see setMonocle
+	focusStack []*frame // most recently focused frame last
+	config     workspaceConfig
+}
+
+func newWorkspace(name string, config workspaceConfig) *workspace {
+	return &workspace{name: name, config: config}
+}
+
+// ValidWorkspaceName returns an error if the name can't be used for a workspace, e.g. because it's
+// one of the keywords of the workspace command
+func ValidWorkspaceName(name string) error {
+	switch {
+	case strings.TrimSpace(name) != name || name == "":
+		return fmt.Errorf("invalid workspace name %q", name)
+	case name == "next" || name == "prev" || name == "back_and_forth":
+		return fmt.Errorf("workspace name %q is reserved", name)
+	}
+	if n, err := strconv.Atoi(name); err == nil && n < 1 {
+		return fmt.Errorf("invalid workspace number %q", name)
+	}
+	return nil
 }
 
-func newWorkspace(id uint8, config workspaceConfig) *workspace {
-	return &workspace{id: id, config: config}
+// workspaceLess orders the workspaces by their names, the numbered ones first in numerical order
+// followed by the others in alphabetical order
+func workspaceLess(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return na < nb
+	case errA == nil || errB == nil:
+		return errA == nil
+	}
+	return a < b
 }
 
 func (ws *workspace) setOutput(o *output) {
@@ -32,135 +72,254 @@ func (ws *workspace) addFrame(f *frame) error {
 	if col == nil {
 		col = ws.columns[len(ws.columns)-1]
 	}
-	col.addFrame(f, nil)
-	if ws.output.activeWs == ws {
+	return ws.insertFrame(f, col, nil)
+}
+
+// placeFrame adds a new frame to the workspace according to the placement policy (see Config.Placement),
+// relative to the given tiled frame of the workspace. Without one, the frame is added as by addFrame
+func (ws *workspace) placeFrame(f *frame, policy string, rel *frame) error {
+	if rel == nil || rel.col == nil || rel.col.ws != ws {
+		return ws.addFrame(f)
+	}
+	switch policy {
+	case PlacementNewColumn:
+		return ws.insertFrame(f, ws.createColumnAfter(rel.col.topColumn()), nil)
+	case PlacementFocusedColumn:
+		return ws.insertFrame(f, rel.col, nil)
+	case PlacementAfterFocused:
+		return ws.insertFrame(f, rel.col, rel)
+	}
+	return ws.addFrame(f)
+}
+
+// insertFrame puts the frame in a column of the workspace after the given frame, or at the end if it's nil
+func (ws *workspace) insertFrame(f *frame, col *column, after *frame) error {
+	col.addFrame(f, after)
+	f.ws = ws
+	f.floating = false
+	if f.fullscreen {
+		ws.setFullscreenFrame(f)
+	}
+	if ws.output.activeWs == ws && !f.state.hidden {
 		return f.cli.Map()
 	}
 	return nil
 }
 
+// addFloatingFrame puts the frame in the floating layer of the workspace, using the given geometry
+func (ws *workspace) addFloatingFrame(f *frame, geom client.Geom) error {
+	f.col = nil
+	f.ws = ws
+	f.floating = true
+	f.floatGeom = geom
+	ws.floating = append(ws.floating, f)
+	if f.fullscreen {
+		ws.setFullscreenFrame(f)
+	}
+	if ws.output.activeWs == ws && !f.state.hidden && !f.queued {
+		return f.cli.Map()
+	}
+	return nil
+}
+
+// setFullscreenFrame marks the frame as the fullscreen one, dropping the flag of any previous fullscreen frame
+func (ws *workspace) setFullscreenFrame(f *frame) {
+	if ws.fullscreen != nil && ws.fullscreen != f {
+		ws.fullscreen.fullscreen = false
+	}
+	ws.fullscreen = f
+}
+
 // deleteFrame deletes the frame from any column that contains it
 func (ws *workspace) deleteFrame(f *frame) bool {
+	if ws.fullscreen == f {
+		ws.fullscreen = nil
+	}
+	if ws.maximized == f {
+		ws.maximized = nil
+	}
+	if f.floating {
+		return ws.deleteFloatingFrame(f)
+	}
 	if f.col == nil || f.col.ws != ws {
 		return false
 	}
-	col := f.col
-	col.deleteFrame(f)
-	if len(col.frames) == 0 {
-		ws.deleteColumn(col)
-	}
+	ws.detachFrame(f)
 	return true
 }
 
-// moveFrame changes the position of a frame within a column or moves it between columns
-func (ws *workspace) moveFrame(f *frame, dir MoveDirection) error {
-	switch dir {
-	case MoveLeft:
-		i := ws.findColumnIndex(func(c *column) bool { return c == f.col })
-		origCol := f.col
-		origCol.deleteFrame(f)
-		if i == 0 {
-			col := ws.createColumn(true)
-			col.addFrame(f, nil)
-		} else {
-			col := ws.columns[i-1]
-			col.addFrame(f, nil)
-		}
-		if len(origCol.frames) == 0 {
-			ws.deleteColumn(origCol)
-		}
-	case MoveRight:
-		i := ws.findColumnIndex(func(c *column) bool { return c == f.col })
-		origCol := f.col
-		origCol.deleteFrame(f)
-		if i == len(ws.columns)-1 {
-			col := ws.createColumn(false)
-			col.addFrame(f, nil)
-		} else {
-			col := ws.columns[i+1]
-			col.addFrame(f, nil)
+func (ws *workspace) deleteFloatingFrame(f *frame) bool {
+	for i, frm := range ws.floating {
+		if frm == f {
+			ws.floating = append(ws.floating[:i], ws.floating[i+1:]...)
+			return true
 		}
-		if len(origCol.frames) == 0 {
-			ws.deleteColumn(origCol)
-		}
-	case MoveUp:
-		col := f.col
-		i := col.findFrameIndex(func(frm *frame) bool { return f == frm })
-		if i > 0 {
-			other := col.frames[i-1]
-			col.frames[i-1] = f
-			col.frames[i] = other
+	}
+	return false
+}
+
+// raiseFloating moves the floating frame to the top of the floating layer, which is rendered in order
+func (ws *workspace) raiseFloating(f *frame) {
+	if ws.deleteFloatingFrame(f) {
+		ws.floating = append(ws.floating, f)
+	}
+}
+
+// moveFrame changes the position of a frame within its column or container, or moves it out of them.
+// The frame is swapped with its neighbour in the nearest container (or column) placing the frames in
+// the given direction; at the edge of that container, the frame leaves it for the next one up the tree.
+// Moved left or right past its column, the frame goes to the end of the adjacent column (a new one
+// at the edge of the workspace)
+func (ws *workspace) moveFrame(f *frame, dir MoveDirection) error {
+	if f.floating || f.col == nil {
+		return nil
+	}
+	horizontal := dir == MoveLeft || dir == MoveRight
+	forward := dir == MoveRight || dir == MoveDown
+	item, col := f, f.col
+	for {
+		if (col.parent != nil && col.orientation == orientHorizontal) == horizontal {
+			i := col.findFrameIndex(func(frm *frame) bool { return frm == item })
+			j := i - 1
+			if forward {
+				j = i + 1
+			}
+			if j >= 0 && j < len(col.frames) {
+				if item == f {
+					col.frames[i], col.frames[j] = col.frames[j], col.frames[i]
+					return nil
+				}
+				// out of the nested container, next to the one holding it
+				orig := f.col
+				orig.deleteFrame(f)
+				if forward {
+					i++
+				}
+				col.insertFrame(f, i)
+				ws.cleanupColumn(orig)
+				return nil
+			}
 		}
-	case MoveDown:
-		col := f.col
-		i := col.findFrameIndex(func(frm *frame) bool { return f == frm })
-		if i < len(col.frames)-1 {
-			other := col.frames[i+1]
-			col.frames[i+1] = f
-			col.frames[i] = other
+		if col.parent == nil {
+			break
 		}
+		item, col = col.parent, col.parent.col
+	}
+	if !horizontal {
+		return nil
 	}
+	i := ws.findColumnIndex(func(c *column) bool { return c == col })
+	orig := f.col
+	orig.deleteFrame(f)
+	var next *column
+	switch {
+	case !forward && i == 0:
+		next = ws.createColumn(true)
+	case !forward:
+		next = ws.columns[i-1]
+	case i == len(ws.columns)-1:
+		next = ws.createColumn(false)
+	default:
+		next = ws.columns[i+1]
+	}
+	next.addFrame(f, nil)
+	ws.cleanupColumn(orig)
 	return nil
 }
 
-// resizeFrame changes the size of the frame by the given percent
+// resizeFrame changes the size of the frame by the given percent of the workspace area, taking the space from
+// (or giving it to) the neighbouring columns or frames of the same column in proportion to their sizes.
+// The frame is resized along with its container if it's the only one in the direction of the resize
 func (ws *workspace) resizeFrame(f *frame, dir ResizeDirection, pct int) error {
-	switch dir {
-	case ResizeHoriz:
-		if len(ws.columns) < 2 {
+	if f.floating || f.col == nil {
+		return nil
+	}
+	a := ws.area()
+	total := a.H
+	if dir == ResizeHoriz {
+		total = a.W
+	}
+	item, col := f, f.col
+	for {
+		horizontal := col.parent != nil && col.orientation == orientHorizontal
+		if horizontal == (dir == ResizeHoriz) && len(col.frames) > 1 {
+			lengths := splitLength(col.frameWeights(), total)
+			idx := col.findFrameIndex(func(frm *frame) bool { return frm == item })
+			resizeLengths(lengths, idx, int(total)*pct/100, total/10)
+			for i, frm := range col.frames {
+				frm.weight = float64(lengths[i])
+			}
 			return nil
 		}
-		min := uint16(float32(ws.area().W) * 0.1)
-		dwFull := int(float32(ws.area().W) * (float32(pct) / 100))
-		if uint16(int(f.col.width)+dwFull) < min {
-			return nil
+		if col.parent == nil {
+			break
 		}
-		dwPart := dwFull/len(ws.columns) - 1
-		dwFinal := 0
-		for _, col := range ws.columns {
-			if col != f.col {
-				next := uint16(int(col.width) - dwPart)
-				if next >= min {
-					col.width = next
-					dwFinal += dwPart
-				}
-			}
+		item, col = col.parent, col.parent.col
+	}
+	if dir == ResizeHoriz {
+		widths := splitLength(ws.columnWeights(), total)
+		idx := ws.findColumnIndex(func(c *column) bool { return c == col })
+		resizeLengths(widths, idx, int(total)*pct/100, total/10)
+		for i, c := range ws.columns {
+			c.weight = float64(widths[i])
 		}
-		f.col.width = uint16(int(f.col.width) + dwFinal)
-	case ResizeVert:
-		col := f.col
-		if len(col.frames) < 2 {
-			return nil
+	}
+	return nil
+}
+
+// resizeLengths changes the length at the given index by delta, taking the space from (or giving it to)
+// the other lengths in proportion to their sizes so that the total doesn't change and no length falls below min
+func resizeLengths(lengths []uint16, idx int, delta int, min uint16) {
+	if idx < 0 || len(lengths) < 2 {
+		return
+	}
+	var others, spare int
+	for i, l := range lengths {
+		if i != idx {
+			others += int(l)
+			if int(l) > int(min) {
+				spare += int(l) - int(min)
+			}
 		}
-		min := uint16(float32(ws.area().H) * 0.1)
-		dhFull := int(float32(ws.area().H) * (float32(pct) / 100))
-		if uint16(int(f.height)+dhFull) < min {
-			return nil
+	}
+	if delta > spare {
+		delta = spare
+	}
+	if int(lengths[idx])+delta < int(min) {
+		delta = int(min) - int(lengths[idx])
+	}
+	if delta == 0 || others == 0 {
+		return
+	}
+	moved := 0
+	for i, l := range lengths {
+		if i == idx {
+			continue
 		}
-		dhPart := dhFull/len(col.frames) - 1
-		dhFinal := 0
-		for _, other := range col.frames {
-			if f != other {
-				next := uint16(int(other.height) - dhPart)
-				if next >= min {
-					other.height = next
-					dhFinal += dhPart
-				}
+		var d int
+		if delta > 0 {
+			if int(l) <= int(min) {
+				continue
 			}
+			d = -delta * (int(l) - int(min)) / spare
+		} else {
+			d = -delta * int(l) / others
 		}
-		f.height = uint16(int(f.height) + dhFinal)
+		lengths[i] = uint16(int(l) + d)
+		moved -= d
 	}
-	return nil
+	lengths[idx] = uint16(int(lengths[idx]) + moved)
 }
 
-// show maps all the frames of the workspace
+// show maps all the frames of the workspace, other than those covered by its maximized frame
 func (ws *workspace) show() error {
 	var err error
-	for _, col := range ws.columns {
-		for _, f := range col.frames {
-			if e := f.cli.Map(); e != nil {
-				err = e
-			}
+	for _, f := range ws.frames() {
+		if f.state.hidden || f.queued || ws.covered(f) {
+			continue
+		}
+		if e := f.cli.Map(); e != nil {
+			err = e
 		}
 	}
 	return err
@@ -169,36 +328,27 @@ func (ws *workspace) show() error {
 // hide unmaps all the frames of the workspace
 func (ws *workspace) hide() error {
 	var err error
-	for _, col := range ws.columns {
-		for _, f := range col.frames {
-			if e := f.cli.Unmap(); e != nil {
-				err = e
-			}
+	for _, f := range ws.frames() {
+		if e := f.cli.Unmap(); e != nil {
+			err = e
 		}
 	}
 	return err
 }
 
+// frames returns all the frames of the workspace, the tiled ones first followed by the floating layer
+func (ws *workspace) frames() []*frame {
+	frames := make([]*frame, 0, ws.countAllFrames()+len(ws.floating))
+	for _, col := range ws.columns {
+		frames = append(frames, col.leaves()...)
+	}
+	return append(frames, ws.floating...)
+}
+
 // createColumn creates a new empty column either at the start (if the start argument is true)
 // or the end of the workspace area.
 func (ws *workspace) createColumn(start bool) *column {
-	wsWidth := ws.area().W
-	origLen := len(ws.columns)
-	col := &column{ws: ws, width: ws.area().W / uint16(origLen+1)}
-	if origLen > 0 {
-		col.width = wsWidth / uint16(origLen+1)
-		remWidth := float32(wsWidth - col.width)
-		leftWidth := uint16(remWidth)
-		for _, c := range ws.columns {
-			c.width = uint16((float32(c.width) / float32(wsWidth)) * remWidth)
-			leftWidth -= c.width
-		}
-		if leftWidth != 0 {
-			col.width += leftWidth
-		}
-	} else {
-		col.width = wsWidth
-	}
+	col := &column{ws: ws, weight: newWeight(ws.columnWeights())}
 	if start {
 		ws.columns = append([]*column{col}, ws.columns...)
 	} else {
@@ -207,18 +357,29 @@ func (ws *workspace) createColumn(start bool) *column {
 	return col
 }
 
+// createColumnAfter inserts a new column right after the given one
+func (ws *workspace) createColumnAfter(after *column) *column {
+	col := &column{ws: ws, weight: newWeight(ws.columnWeights())}
+	i := ws.findColumnIndex(func(c *column) bool { return c == after })
+	ws.columns = append(ws.columns[:i+1], append([]*column{col}, ws.columns[i+1:]...)...)
+	return col
+}
+
 func (ws *workspace) deleteColumn(col *column) {
 	i := ws.findColumnIndex(func(c *column) bool { return c == col })
 	if i < 0 {
 		return
 	}
-	wsWidth := ws.area().W
-	// TODO: assign the widths proportional to the original width/totalWidth ratio
-	// origLen = len(ws.columns)
 	ws.columns = append(ws.columns[:i], ws.columns[i+1:]...)
-	for _, c := range ws.columns {
-		c.width = wsWidth / uint16(len(ws.columns))
+}
+
+// columnWeights returns the weights of all the columns of the workspace
+func (ws *workspace) columnWeights() []float64 {
+	weights := make([]float64, len(ws.columns))
+	for i, col := range ws.columns {
+		weights[i] = col.weight
 	}
+	return weights
 }
 
 func (ws *workspace) findColumnIndex(predicate func(*column) bool) int {
@@ -230,36 +391,130 @@ func (ws *workspace) findColumnIndex(predicate func(*column) bool) int {
 	return -1
 }
 
-func (ws *workspace) updateTiling() {
-	for _, col := range ws.columns {
-		col.updateTiling()
-	}
-}
-
 func (ws *workspace) fullArea() client.Geom { return ws.output.workspaceArea() }
 
 func (ws *workspace) area() client.Geom {
 	a := ws.fullArea()
+	gap := ws.outerGap()
+	return client.Geom{
+		X: a.X + int16(gap),
+		Y: a.Y + int16(gap),
+		W: a.W - gap*2,
+		H: a.H - gap*2,
+	}
+}
+
+// centeredGeom returns the geometry of the given size placed in the middle of the workspace area
+func (ws *workspace) centeredGeom(w, h uint16) client.Geom {
+	a := ws.area()
+	if w > a.W {
+		w = a.W
+	}
+	if h > a.H {
+		h = a.H
+	}
 	return client.Geom{
-		X: a.X + int16(ws.config.gap),
-		Y: a.Y + int16(ws.config.gap),
-		W: a.W - ws.config.gap*2,
-		H: a.H - ws.config.gap*2,
+		X: a.X + int16((a.W-w)/2),
+		Y: a.Y + int16((a.H-h)/2),
+		W: w,
+		H: h,
 	}
 }
 
-// singleFrame returns a single frame if there's only one in the workspace, nil otherwise
+// singleFrame returns a single frame if there's only one visible in the tiling layer, nil otherwise
 func (ws *workspace) singleFrame() *frame {
-	if ws.countAllFrames() == 1 {
-		return ws.columns[0].frames[0]
+	var single *frame
+	for _, col := range ws.columns {
+		for _, f := range col.leaves() {
+			if f.state.hidden {
+				continue
+			}
+			if single != nil {
+				return nil
+			}
+			single = f
+		}
 	}
-	return nil
+	return single
+}
+
+// visibleColumns returns the columns that contain at least one frame that's not hidden
+func (ws *workspace) visibleColumns() []*column {
+	var cols []*column
+	for _, col := range ws.columns {
+		if len(col.visibleFrames()) > 0 {
+			cols = append(cols, col)
+		}
+	}
+	return cols
 }
 
+// moveStickyFrames passes the sticky floating frames on to the workspace that replaces this one on the output
+func (ws *workspace) moveStickyFrames(next *workspace) error {
+	var err error
+	for _, f := range append([]*frame(nil), ws.floating...) {
+		if !f.state.sticky {
+			continue
+		}
+		ws.deleteFrame(f)
+		ws.forgetFocus(f)
+		if e := next.addFloatingFrame(f, f.floatGeom); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// countAllFrames returns the number of tiled frames in the workspace
 func (ws *workspace) countAllFrames() int {
 	count := 0
 	for _, col := range ws.columns {
-		count += len(col.frames)
+		count += len(col.leaves())
 	}
 	return count
 }
+
+// pushFocus puts the frame on top of the focus stack of the workspace
+func (ws *workspace) pushFocus(f *frame) {
+	ws.forgetFocus(f)
+	ws.focusStack = append(ws.focusStack, f)
+}
+
+// forgetFocus removes the frame from the focus stack of the workspace
+func (ws *workspace) forgetFocus(f *frame) {
+	for i, frm := range ws.focusStack {
+		if frm == f {
+			ws.focusStack = append(ws.focusStack[:i], ws.focusStack[i+1:]...)
+			return
+		}
+	}
+}
+
+// lastFocused returns the most recently focused visible frame of the workspace, falling back to
+// the first visible frame if none of them has been focused yet
+func (ws *workspace) lastFocused() *frame {
+	for i := len(ws.focusStack) - 1; i >= 0; i-- {
+		f := ws.focusStack[i]
+		if f.workspace() == ws && !f.state.hidden {
+			return f
+		}
+	}
+	for _, f := range ws.frames() {
+		if !f.state.hidden {
+			return f
+		}
+	}
+	return nil
+}
+
+// lastFocusedTiled returns the most recently focused tiled frame of the workspace or nil if none
+// of them has been focused yet
+func (ws *workspace) lastFocusedTiled() *frame {
+	for i := len(ws.focusStack) - 1; i >= 0; i-- {
+		f := ws.focusStack[i]
+		if f.col != nil && f.col.ws == ws && !f.state.hidden {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package wm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResizeLengths(t *testing.T) {
+	tests := []struct {
+		name    string
+		lengths []uint16
+		idx     int
+		delta   int
+		min     uint16
+		want    []uint16
+	}{
+		{"grow evenly", []uint16{300, 300, 300}, 0, 100, 50, []uint16{400, 250, 250}},
+		{"shrink evenly", []uint16{400, 250, 250}, 0, -100, 50, []uint16{300, 300, 300}},
+		{"grow proportionally", []uint16{200, 400, 300}, 0, 100, 100, []uint16{300, 340, 260}},
+		{"grow clamped to min of others", []uint16{300, 300, 300}, 1, 1000, 100, []uint16{100, 700, 100}},
+		{"shrink clamped to min", []uint16{300, 600}, 0, -500, 100, []uint16{100, 800}},
+		{"single length", []uint16{900}, 0, 100, 50, []uint16{900}},
+		{"unknown index", []uint16{450, 450}, -1, 100, 50, []uint16{450, 450}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := append([]uint16(nil), tt.lengths...)
+			resizeLengths(got, tt.idx, tt.delta, tt.min)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resizeLengths() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkspaceLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1", "2", true},
+		{"2", "10", true},
+		{"10", "2", false},
+		{"10", "web", true},
+		{"web", "10", false},
+		{"mail", "web", true},
+		{"web", "web", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := workspaceLess(tt.a, tt.b); got != tt.want {
+				t.Errorf("workspaceLess() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjacentCandidates(t *testing.T) {
+	workspaces := []*workspace{{name: "2"}, {name: "web"}, {name: "12"}}
+	tests := []struct {
+		name         string
+		includeEmpty bool
+		want         []string
+	}{
+		{"existing", false, []string{"2", "12", "web"}},
+		{"with empty", true, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "12", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adjacentCandidates(workspaces, tt.includeEmpty); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("adjacentCandidates() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceFrame(t *testing.T) {
+	tests := []struct {
+		policy string
+		rel    int
+		want   [][]int // indexes of the frames in each column, the new frame being 3
+	}{
+		{PlacementAuto, 0, [][]int{{0}, {1, 2, 3}}},
+		{PlacementNewColumn, 0, [][]int{{0}, {3}, {1, 2}}},
+		{PlacementFocusedColumn, 0, [][]int{{0, 3}, {1, 2}}},
+		{PlacementFocusedColumn, 1, [][]int{{0}, {1, 2, 3}}},
+		{PlacementAfterFocused, 1, [][]int{{0}, {1, 3, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s after %d", tt.policy, tt.rel), func(t *testing.T) {
+			ws := newWorkspace("1", workspaceConfig{})
+			ws.output = &output{}
+			frames := []*frame{{}, {}, {}, {}}
+			for _, f := range frames[:3] {
+				if err := ws.addFrame(f); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := ws.placeFrame(frames[3], tt.policy, frames[tt.rel]); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got [][]int
+			for _, col := range ws.columns {
+				var indexes []int
+				for _, f := range col.frames {
+					for i, frm := range frames {
+						if frm == f {
+							indexes = append(indexes, i)
+						}
+					}
+				}
+				got = append(got, indexes)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("placeFrame() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFrame(t *testing.T) {
+	tests := []struct {
+		name   string
+		orient orientation
+		detach int    // index of the frame removed after placing the new one, -1 for none
+		want   string // frame indexes in each column, h(...) for horizontal containers
+	}{
+		{"horizontal", orientHorizontal, -1, "[0] [h(1 3) 2]"},
+		{"same orientation", orientVertical, -1, "[0] [1 3 2]"},
+		{"dissolved", orientHorizontal, 1, "[0] [3 2]"},
+		{"other frame removed", orientHorizontal, 2, "[0] [h(1 3)]"},
+		{"last in column removed", orientHorizontal, 0, "[h(1 3) 2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := newWorkspace("1", workspaceConfig{})
+			ws.output = &output{}
+			frames := []*frame{{}, {}, {}, {}}
+			for _, f := range frames[:3] {
+				if err := ws.addFrame(f); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			ws.splitFrame(frames[1], tt.orient)
+			if err := ws.placeFrame(frames[3], PlacementAfterFocused, frames[1]); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.detach >= 0 {
+				ws.detachFrame(frames[tt.detach])
+			}
+			if got := describeLayout(ws, frames); got != tt.want {
+				t.Errorf("layout got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// describeLayout lists the indexes of the frames in each column of the workspace, h(...) for horizontal containers
+func describeLayout(ws *workspace, frames []*frame) string {
+	var describe func(col *column) string
+	describe = func(col *column) string {
+		var items []string
+		for _, f := range col.frames {
+			if f.split != nil {
+				items = append(items, "h("+describe(f.split)+")")
+				continue
+			}
+			for i, frm := range frames {
+				if frm == f {
+					items = append(items, fmt.Sprint(i))
+				}
+			}
+		}
+		return strings.Join(items, " ")
+	}
+	var cols []string
+	for _, col := range ws.columns {
+		cols = append(cols, "["+describe(col)+"]")
+	}
+	return strings.Join(cols, " ")
+}
@@ -1,9 +1,9 @@
 package x11
 
 import (
-	"log"
-
 	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
 )
 
 // Atom returns the X11 atom of the given name
@@ -13,7 +13,7 @@ func (xc *Connection) Atom(name string) xproto.Atom {
 	}
 	reply, err := xproto.InternAtom(xc.conn, false, uint16(len(name)), name).Reply()
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatalf("%v", err)
 	}
 	if reply == nil {
 		return 0
@@ -0,0 +1,76 @@
+package x11
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// clientList tracks the managed windows for the _NET_CLIENT_LIST (in the order the windows were managed)
+// and _NET_CLIENT_LIST_STACKING (bottom-to-top stacking order) root properties
+type clientList struct {
+	managed  []xproto.Window
+	stacking []xproto.Window
+}
+
+// AddClient registers a newly managed window, placing it on top of the stacking order
+func (xc *Connection) AddClient(win xproto.Window) error {
+	if indexOfWindow(xc.clients.managed, win) < 0 {
+		xc.clients.managed = append(xc.clients.managed, win)
+	}
+	xc.clients.stacking = append(removeWindow(xc.clients.stacking, win), win)
+	return xc.updateClientLists()
+}
+
+// RemoveClient removes a window that's no longer managed from the client lists
+func (xc *Connection) RemoveClient(win xproto.Window) error {
+	xc.clients.managed = removeWindow(xc.clients.managed, win)
+	xc.clients.stacking = removeWindow(xc.clients.stacking, win)
+	return xc.updateClientLists()
+}
+
+// SetClientStacking puts the given managed windows, ordered from the bottom, on top of the stacking order
+func (xc *Connection) SetClientStacking(wins []xproto.Window) error {
+	stacking := make([]xproto.Window, 0, len(xc.clients.stacking))
+	for _, win := range xc.clients.stacking {
+		if indexOfWindow(wins, win) < 0 {
+			stacking = append(stacking, win)
+		}
+	}
+	for _, win := range wins {
+		if indexOfWindow(xc.clients.managed, win) >= 0 {
+			stacking = append(stacking, win)
+		}
+	}
+	xc.clients.stacking = stacking
+	return xc.changeClientListProp("_NET_CLIENT_LIST_STACKING", xc.clients.stacking)
+}
+
+func (xc *Connection) updateClientLists() error {
+	if err := xc.changeClientListProp("_NET_CLIENT_LIST", xc.clients.managed); err != nil {
+		return err
+	}
+	return xc.changeClientListProp("_NET_CLIENT_LIST_STACKING", xc.clients.stacking)
+}
+
+func (xc *Connection) changeClientListProp(prop string, windows []xproto.Window) error {
+	vals := make([]uint32, len(windows))
+	for i, win := range windows {
+		vals[i] = uint32(win)
+	}
+	return xc.changeProp32(xc.screen.Root, prop, xproto.AtomWindow, vals...)
+}
+
+func indexOfWindow(windows []xproto.Window, win xproto.Window) int {
+	for i, w := range windows {
+		if w == win {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeWindow(windows []xproto.Window, win xproto.Window) []xproto.Window {
+	if i := indexOfWindow(windows, win); i >= 0 {
+		return append(windows[:i], windows[i+1:]...)
+	}
+	return windows
+}
@@ -3,8 +3,10 @@ package x11
 import (
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/render"
 	"github.com/BurntSushi/xgb/xproto"
 	"github.com/BurntSushi/xgbutil"
 )
@@ -14,6 +16,26 @@ type Connection struct {
 	util   *xgbutil.XUtil
 	screen xproto.ScreenInfo
 	atoms  map[string]xproto.Atom
+	randr  bool
+
+	screensaver bool // whether the MIT-SCREEN-SAVER extension is available
+	dpms        bool // whether the DPMS extension is available and the server can use it
+
+	display  string
+	clients  clientList
+	checkWin xproto.Window // window holding _NET_SUPPORTING_WM_CHECK
+
+	selectionTime xproto.Timestamp // when the manager selection was acquired
+	rootPixmap    xproto.Pixmap    // background of the root window set by the WM, 0 if none
+
+	cursors     map[uint16]xproto.Cursor // created so far, by shape of the cursor font
+	cursorTheme string                   // XCursor theme of the cursors, empty for the cursor font
+	cursorSize  int
+	argb        render.Pictformat // ARGB32 format of the RENDER extension, 0 until it's needed
+
+	// cursor theme and size of the environment and the resources the WM was started with, see SetCursorTheme
+	defaultCursorTheme string
+	defaultCursorSize  int
 }
 
 func Connect() (*Connection, error) {
@@ -26,12 +48,21 @@ func Connect() (*Connection, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create XUtil connection: %w", err)
 	}
-	return &Connection{conn: xconn, util: xutil, atoms: atoms}, nil
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		display = fmt.Sprintf(":%d", xconn.DisplayNumber)
+	}
+	return &Connection{conn: xconn, util: xutil, atoms: atoms, display: display, cursors: make(map[uint16]xproto.Cursor)}, nil
 }
 
 func (xc *Connection) X() *xgb.Conn              { return xc.conn }
 func (xc *Connection) Screen() xproto.ScreenInfo { return xc.screen }
 
+// Display returns the name of the X display the connection was made to, e.g. ":0"
+func (xc *Connection) Display() string { return xc.display }
+
+// Init reads the screen of the connection and creates the check window, which owns the manager selection,
+// see AcquireSelection. Nothing is changed on the root window until InitRoot
 func (xc *Connection) Init() error {
 	conninfo := xproto.Setup(xc.conn)
 	if conninfo == nil {
@@ -41,7 +72,16 @@ func (xc *Connection) Init() error {
 		return errors.New("wrong number of roots, possibly xinerama did not initialize properly")
 	}
 	xc.screen = conninfo.Roots[0]
+	xc.defaultCursorTheme, xc.defaultCursorSize = xc.cursorDefaults()
+	if err := xc.createCheckWindow(); err != nil {
+		return fmt.Errorf("failed to create the supporting WM check window: %v", err)
+	}
+	return nil
+}
 
+// InitRoot sets the EWMH hints of the root window and sets up the extensions. It's called once the manager
+// selection is acquired, so that a WM failing to start doesn't overwrite the hints of the running one
+func (xc *Connection) InitRoot() error {
 	err := xc.setHints()
 	if err != nil {
 		return err
@@ -50,6 +90,15 @@ func (xc *Connection) Init() error {
 	if err != nil {
 		return err
 	}
+	err = xc.initRandr()
+	if err != nil {
+		return err
+	}
+	err = xc.initXkb()
+	if err != nil {
+		return err
+	}
+	xc.initScreenSaver()
 	return nil
 }
 
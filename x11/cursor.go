@@ -0,0 +1,251 @@
+package x11
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/BurntSushi/xgb/render"
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/patrislav/marwind/logger"
+)
+
+// Shapes of the standard cursor font, see X11/cursorfont.h
+const (
+	CursorBottomLeftCorner  = 12
+	CursorBottomRightCorner = 14
+	CursorBottomSide        = 16
+	CursorFleur             = 52
+	CursorLeftPtr           = 68
+	CursorLeftSide          = 70
+	CursorRightSide         = 96
+	CursorHDoubleArrow      = 108
+	CursorVDoubleArrow      = 116
+	CursorTopLeftCorner     = 134
+	CursorTopRightCorner    = 136
+	CursorTopSide           = 138
+	CursorWatch             = 150
+)
+
+// cursorNames are the names of the images of the shapes in the XCursor themes: the name of the glyph of
+// the cursor font, followed by the CSS name used by the newer themes. The busy cursor is rather the arrow
+// with a watch of the themes, the cursor font only having the watch
+var cursorNames = map[uint16][]string{
+	CursorBottomLeftCorner:  {"bottom_left_corner", "sw-resize"},
+	CursorBottomRightCorner: {"bottom_right_corner", "se-resize"},
+	CursorBottomSide:        {"bottom_side", "s-resize"},
+	CursorFleur:             {"fleur", "move"},
+	CursorLeftPtr:           {"left_ptr", "default"},
+	CursorLeftSide:          {"left_side", "w-resize"},
+	CursorRightSide:         {"right_side", "e-resize"},
+	CursorHDoubleArrow:      {"sb_h_double_arrow", "ew-resize"},
+	CursorVDoubleArrow:      {"sb_v_double_arrow", "ns-resize"},
+	CursorTopLeftCorner:     {"top_left_corner", "nw-resize"},
+	CursorTopRightCorner:    {"top_right_corner", "ne-resize"},
+	CursorTopSide:           {"top_side", "n-resize"},
+	CursorWatch:             {"left_ptr_watch", "progress", "watch"},
+}
+
+// SetCursorTheme makes the cursors of the WM use the images of the XCursor theme closest to the given size,
+// the cursor font standing in for the theme if it's missing a shape. Without a theme or a size, the ones
+// the WM was started with are used, see cursorDefaults. The cursors are created anew and the root window
+// gets its new one
+func (xc *Connection) SetCursorTheme(theme string, size int) error {
+	if theme == "" {
+		theme = xc.defaultCursorTheme
+	}
+	if size <= 0 {
+		size = xc.defaultCursorSize
+	}
+	if theme == xc.cursorTheme && size == xc.cursorSize {
+		return nil
+	}
+	xc.cursorTheme, xc.cursorSize = theme, size
+	// the windows keep the cursors set on them until they get others
+	for shape, cursor := range xc.cursors {
+		xproto.FreeCursor(xc.conn, cursor)
+		delete(xc.cursors, shape)
+	}
+	return xc.setRootCursor()
+}
+
+// cursorDefaults returns the cursor theme and size of XCURSOR_THEME and XCURSOR_SIZE or of the Xcursor.theme
+// and Xcursor.size resources. Like in libXcursor, the theme defaults to "default" and the size to a 48th of
+// the smaller dimension of the screen. They're read once, as the WM exports the configured ones to the
+// programs it starts
+func (xc *Connection) cursorDefaults() (string, int) {
+	theme := os.Getenv("XCURSOR_THEME")
+	if theme == "" {
+		theme = xc.Resource("Xcursor.theme")
+	}
+	if theme == "" {
+		theme = "default"
+	}
+	size, _ := strconv.Atoi(os.Getenv("XCURSOR_SIZE"))
+	if size <= 0 {
+		size, _ = strconv.Atoi(xc.Resource("Xcursor.size"))
+	}
+	if size <= 0 {
+		size = int(xc.screen.HeightInPixels) / 48
+		if w := int(xc.screen.WidthInPixels) / 48; w < size {
+			size = w
+		}
+	}
+	return theme, size
+}
+
+// setRootCursor shows the left_ptr arrow over the root window rather than the X cross
+func (xc *Connection) setRootCursor() error {
+	cursor, err := xc.Cursor(CursorLeftPtr)
+	if err != nil {
+		return err
+	}
+	return xc.SetCursor(xc.screen.Root, cursor)
+}
+
+// Cursor returns the cursor of the given shape of the cursor font, with the image of the cursor theme if it
+// has one, creating it the first time it's needed
+func (xc *Connection) Cursor(shape uint16) (xproto.Cursor, error) {
+	if cursor, ok := xc.cursors[shape]; ok {
+		return cursor, nil
+	}
+	cursor, err := xc.createThemeCursor(shape)
+	if err != nil {
+		logger.Debugf("Using the cursor font for cursor %d: %v", shape, err)
+		if cursor, err = xc.createCursor(shape); err != nil {
+			return 0, err
+		}
+	}
+	xc.cursors[shape] = cursor
+	return cursor, nil
+}
+
+// SetCursor sets the cursor shown while the pointer is within the window, xproto.CursorNone for the one
+// of its parent
+func (xc *Connection) SetCursor(win xproto.Window, cursor xproto.Cursor) error {
+	return xproto.ChangeWindowAttributesChecked(xc.conn, win, xproto.CwCursor, []uint32{uint32(cursor)}).Check()
+}
+
+// createThemeCursor creates the cursor of the shape from its image in the cursor theme, which requires
+// the RENDER extension
+func (xc *Connection) createThemeCursor(shape uint16) (xproto.Cursor, error) {
+	if xc.cursorTheme == "" {
+		return 0, fmt.Errorf("no cursor theme")
+	}
+	var img *xcursorImage
+	var err error
+	for _, name := range cursorNames[shape] {
+		if img, err = loadXcursor(xc.cursorTheme, name, xc.cursorSize); err == nil {
+			break
+		}
+	}
+	if img == nil {
+		return 0, err
+	}
+	format, err := xc.argbFormat()
+	if err != nil {
+		return 0, err
+	}
+	pixmap, err := xproto.NewPixmapId(xc.conn)
+	if err != nil {
+		return 0, err
+	}
+	w, h := uint16(img.width), uint16(img.height)
+	if err := xproto.CreatePixmapChecked(xc.conn, 32, pixmap, xproto.Drawable(xc.screen.Root), w, h).Check(); err != nil {
+		return 0, err
+	}
+	defer xproto.FreePixmap(xc.conn, pixmap)
+	gc, err := xproto.NewGcontextId(xc.conn)
+	if err != nil {
+		return 0, err
+	}
+	if err := xproto.CreateGCChecked(xc.conn, gc, xproto.Drawable(pixmap), 0, nil).Check(); err != nil {
+		return 0, err
+	}
+	defer xproto.FreeGC(xc.conn, gc)
+	// the BGRA bytes of the little-endian ARGB pixels are the layout of the ARGB32 format
+	data := make([]byte, len(img.pixels)*4)
+	for i, p := range img.pixels {
+		binary.LittleEndian.PutUint32(data[i*4:], p)
+	}
+	if err := xproto.PutImageChecked(xc.conn, xproto.ImageFormatZPixmap, xproto.Drawable(pixmap), gc,
+		w, h, 0, 0, 0, 32, data).Check(); err != nil {
+		return 0, err
+	}
+	picture, err := render.NewPictureId(xc.conn)
+	if err != nil {
+		return 0, err
+	}
+	if err := render.CreatePictureChecked(xc.conn, picture, xproto.Drawable(pixmap), format, 0, nil).Check(); err != nil {
+		return 0, err
+	}
+	defer render.FreePicture(xc.conn, picture)
+	cursor, err := xproto.NewCursorId(xc.conn)
+	if err != nil {
+		return 0, err
+	}
+	if err := render.CreateCursorChecked(xc.conn, cursor, picture, uint16(img.xhot), uint16(img.yhot)).Check(); err != nil {
+		return 0, err
+	}
+	return cursor, nil
+}
+
+// argbFormat returns the picture format of the RENDER extension for 32-bit ARGB pixels
+func (xc *Connection) argbFormat() (render.Pictformat, error) {
+	if xc.argb != 0 {
+		return xc.argb, nil
+	}
+	if err := render.Init(xc.conn); err != nil {
+		return 0, fmt.Errorf("no RENDER extension: %v", err)
+	}
+	reply, err := render.QueryPictFormats(xc.conn).Reply()
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range reply.Formats {
+		d := f.Direct
+		if f.Type == render.PictTypeDirect && f.Depth == 32 && d.AlphaShift == 24 && d.AlphaMask == 0xff &&
+			d.RedShift == 16 && d.RedMask == 0xff && d.GreenShift == 8 && d.GreenMask == 0xff &&
+			d.BlueShift == 0 && d.BlueMask == 0xff {
+			xc.argb = f.Id
+			return f.Id, nil
+		}
+	}
+	return 0, fmt.Errorf("no ARGB32 picture format")
+}
+
+// createCursor creates the cursor of the given shape of the cursor font
+func (xc *Connection) createCursor(cursor uint16) (xproto.Cursor, error) {
+	fontID, err := xproto.NewFontId(xc.conn)
+	if err != nil {
+		return 0, err
+	}
+
+	cursorID, err := xproto.NewCursorId(xc.conn)
+	if err != nil {
+		return 0, err
+	}
+
+	err = xproto.OpenFontChecked(xc.conn, fontID,
+		uint16(len("cursor")), "cursor").Check()
+	if err != nil {
+		return 0, err
+	}
+
+	err = xproto.CreateGlyphCursorChecked(xc.conn, cursorID, fontID, fontID,
+		cursor, cursor+1,
+		0, 0, 0,
+		0xffff, 0xffff, 0xffff).Check()
+	if err != nil {
+		return 0, err
+	}
+
+	err = xproto.CloseFontChecked(xc.conn, fontID).Check()
+	if err != nil {
+		return 0, err
+	}
+
+	return cursorID, nil
+}
@@ -2,56 +2,96 @@ package x11
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/BurntSushi/xgb/xproto"
 )
 
-// Struts represents the values of the _NET_WM_STRUT/_NET_WM_STRUT_PARTIAL properties.
-// The extended _NET_WM_STRUT_PARTIAL values are ignored - the WM will fill the entire width of the screen instead.
+// Struts represents the values of the _NET_WM_STRUT_PARTIAL property: the space reserved at each edge of the screen
+// and the range along that edge that the reservation spans
 type Struts struct {
 	Left, Right, Top, Bottom uint32
+
+	LeftStartY, LeftEndY     uint32
+	RightStartY, RightEndY   uint32
+	TopStartX, TopEndX       uint32
+	BottomStartX, BottomEndX uint32
 }
 
-// GetWindowStruts returns the values of the window's _NET_WM_STRUT(_PARTIAL) property
+// GetWindowStruts returns the values of the window's _NET_WM_STRUT_PARTIAL property, falling back to
+// _NET_WM_STRUT, in which case the reservations span the entire edges of the screen
 func (xc *Connection) GetWindowStruts(win xproto.Window) (*Struts, error) {
-	// TODO: support _NET_WM_STRUT_PARTIAL as well
-	propName := "_NET_WM_STRUT"
-	atom := xc.Atom(propName)
-	prop, err := xproto.GetProperty(xc.conn, false, win, atom, xproto.AtomCardinal, 2, 32).Reply()
-	if err != nil {
-		return nil, err
-	}
-	if prop == nil {
-		return nil, fmt.Errorf("xproto.GetProperty returned a nil reply")
-	}
-	values := make([]uint32, len(prop.Value)/4)
-	for v := prop.Value; len(v) >= 4; v = v[4:] {
-		values = append(values, uint32(v[0])|uint32(v[1])<<8|uint32(v[2])<<16|uint32(v[3])<<24)
-	}
-	if len(values) < 4 {
-		return nil, fmt.Errorf("not enough values returned by property %s", propName)
+	values, err := xc.getProps32(win, "_NET_WM_STRUT_PARTIAL")
+	if err != nil || len(values) < 12 {
+		if values, err = xc.getProps32(win, "_NET_WM_STRUT"); err != nil {
+			return nil, err
+		}
+		if len(values) < 4 {
+			return nil, fmt.Errorf("not enough values returned by property _NET_WM_STRUT")
+		}
+		max := uint32(0xffffffff)
+		values = append(values[:4], 0, max, 0, max, 0, max, 0, max)
 	}
 	return &Struts{
-		Left:   values[0],
-		Right:  values[1],
-		Top:    values[2],
-		Bottom: values[3],
+		Left:         values[0],
+		Right:        values[1],
+		Top:          values[2],
+		Bottom:       values[3],
+		LeftStartY:   values[4],
+		LeftEndY:     values[5],
+		RightStartY:  values[6],
+		RightEndY:    values[7],
+		TopStartX:    values[8],
+		TopEndX:      values[9],
+		BottomStartX: values[10],
+		BottomEndX:   values[11],
 	}, nil
 }
 
+// SetWMName sets the name of the WM as seen by the clients, i.e. the _NET_WM_NAME of the check window
 func (xc *Connection) SetWMName(name string) error {
-	buf := make([]byte, 0)
-	buf = append(buf, name...)
-	buf = append(buf, 0)
-	return xc.changeProp(xc.screen.Root, 8, "_NET_WM_NAME", xproto.AtomString, buf)
+	return xc.changeProp(xc.checkWin, 8, "_NET_WM_NAME", xc.Atom("UTF8_STRING"), []byte(name))
+}
+
+// SetWindowTitle sets the _NET_WM_NAME of a window created by the WM
+func (xc *Connection) SetWindowTitle(win xproto.Window, title string) error {
+	return xc.changeProp(win, 8, "_NET_WM_NAME", xc.Atom("UTF8_STRING"), []byte(title))
 }
 
+// GetWindowTitle returns the UTF-8 title of the window from _NET_WM_NAME, falling back to the ICCCM WM_NAME
 func (xc *Connection) GetWindowTitle(window xproto.Window) (string, error) {
-	reply, err := xc.getProp(window, "_NET_WM_NAME")
+	if reply, err := xc.getProp(window, "_NET_WM_NAME"); err == nil && len(reply.Value) > 0 {
+		return trimInvalidUTF8(string(reply.Value)), nil
+	}
+	reply, err := xc.getProp(window, "WM_NAME")
 	if err != nil {
 		return "", err
 	}
-	return string(reply.Value), nil
+	if reply.Type == xproto.AtomString {
+		return latin1ToUTF8(reply.Value), nil
+	}
+	// COMPOUND_TEXT is mostly ASCII in practice and UTF8_STRING is used by some clients despite ICCCM
+	return trimInvalidUTF8(string(reply.Value)), nil
+}
+
+// latin1ToUTF8 converts the ISO 8859-1 text of a STRING property
+func latin1ToUTF8(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// trimInvalidUTF8 drops a partial character left at the end of a property value cut short by the length limit
+func trimInvalidUTF8(s string) string {
+	for i := 0; i < utf8.UTFMax-1 && len(s) > 0; i++ {
+		if r, size := utf8.DecodeLastRuneInString(s); r != utf8.RuneError || size != 1 {
+			break
+		}
+		s = s[:len(s)-1]
+	}
+	return s
 }
 
 func (xc *Connection) SetActiveWindow(win xproto.Window) error {
@@ -83,15 +123,7 @@ func (xc *Connection) SetDesktopNames(names []string) error {
 	return xc.changeProp(xc.screen.Root, 8, "_NET_DESKTOP_NAMES", xc.Atom("UTF8_STRING"), buf)
 }
 
-func (xc *Connection) SetClientList(windows []xproto.Window) error {
-	vals := make([]uint32, len(windows))
-	for i, win := range windows {
-		vals[i] = uint32(win)
-	}
-	return xc.changeProp32(xc.screen.Root, "_NET_CLIENT_LIST", xproto.AtomWindow, vals...)
-}
-
-func (xc *Connection) SetDesktopHints(names []string, index int, windows []xproto.Window) error {
+func (xc *Connection) SetDesktopHints(names []string, index int) error {
 	var err error
 	err = xc.SetNumberOfDesktops(len(names))
 	if err != nil {
@@ -109,21 +141,130 @@ func (xc *Connection) SetDesktopHints(names []string, index int, windows []xprot
 	if err != nil {
 		return err
 	}
-	err = xc.SetClientList(windows)
-	if err != nil {
-		return err
-	}
 	return nil
 }
 
+// AllDesktops is the _NET_WM_DESKTOP of the windows shown on all the desktops
+const AllDesktops = 0xFFFFFFFF
+
 func (xc *Connection) SetWindowDesktop(win xproto.Window, desktop int) error {
 	return xc.changeProp32(win, "_NET_WM_DESKTOP", xproto.AtomCardinal, uint32(desktop))
 }
 
+// SetWindowState replaces the window's _NET_WM_STATE property with the given list of state atoms
+func (xc *Connection) SetWindowState(win xproto.Window, states []xproto.Atom) error {
+	vals := make([]uint32, len(states))
+	for i, state := range states {
+		vals[i] = uint32(state)
+	}
+	return xc.changeProp32(win, "_NET_WM_STATE", xproto.AtomAtom, vals...)
+}
+
+// GetWindowState returns the list of atoms in the window's _NET_WM_STATE property
+func (xc *Connection) GetWindowState(win xproto.Window) ([]xproto.Atom, error) {
+	vals, err := xc.getProps32(win, "_NET_WM_STATE")
+	if err != nil {
+		return nil, err
+	}
+	states := make([]xproto.Atom, len(vals))
+	for i, val := range vals {
+		states[i] = xproto.Atom(val)
+	}
+	return states, nil
+}
+
 func (xc *Connection) setHints() error {
+	if err := xc.changeProp32(xc.screen.Root, "_NET_SUPPORTING_WM_CHECK", xproto.AtomWindow, uint32(xc.checkWin)); err != nil {
+		return err
+	}
 	atoms := make([]uint32, len(ewmhSupported))
 	for i, s := range ewmhSupported {
 		atoms[i] = uint32(xc.Atom(s))
 	}
 	return xc.changeProp32(xc.screen.Root, "_NET_SUPPORTED", xproto.AtomAtom, atoms...)
 }
+
+// createCheckWindow creates the hidden child window of the root that proves to the clients that
+// an EWMH compliant WM is running, as its ID is set in _NET_SUPPORTING_WM_CHECK on both windows. The property
+// of the root window is only set by setHints, once the window owns the manager selection
+func (xc *Connection) createCheckWindow() error {
+	win, err := xc.CreateWindow(xc.screen.Root, -1, -1, 1, 1, 0, xproto.WindowClassInputOnly,
+		xproto.CwOverrideRedirect|xproto.CwEventMask, []uint32{1, xproto.EventMaskPropertyChange})
+	if err != nil {
+		return err
+	}
+	xc.checkWin = win
+	return xc.changeProp32(win, "_NET_SUPPORTING_WM_CHECK", xproto.AtomWindow, uint32(win))
+}
+
+// rootProperties lists the properties of the root window owned by the WM
+var rootProperties = []string{
+	"_NET_SUPPORTED",
+	"_NET_ACTIVE_WINDOW",
+	"_NET_CLIENT_LIST",
+	"_NET_CLIENT_LIST_STACKING",
+	"_NET_NUMBER_OF_DESKTOPS",
+	"_NET_CURRENT_DESKTOP",
+	"_NET_DESKTOP_NAMES",
+	"_NET_DESKTOP_VIEWPORT",
+	"_NET_SUPPORTING_WM_CHECK",
+}
+
+// ClearHints deletes the properties set on the root window by the WM, so that no stale information
+// is left once it exits. The check window itself is destroyed along with the connection, which is
+// what a WM replacing this one waits for
+func (xc *Connection) ClearHints() error {
+	var err error
+	for _, prop := range rootProperties {
+		if e := xproto.DeletePropertyChecked(xc.conn, xc.screen.Root, xc.Atom(prop)).Check(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// SetFrameExtents sets the window's _NET_FRAME_EXTENTS property to the size of the decorations around it
+func (xc *Connection) SetFrameExtents(win xproto.Window, d Dimensions) error {
+	return xc.changeProp32(win, "_NET_FRAME_EXTENTS", xproto.AtomCardinal, d.Left, d.Right, d.Top, d.Bottom)
+}
+
+// GetWindowPID returns the ID of the process owning the window, as set in its _NET_WM_PID property
+func (xc *Connection) GetWindowPID(win xproto.Window) (int, error) {
+	vals, err := xc.getProps32(win, "_NET_WM_PID")
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("empty _NET_WM_PID property on window %d", win)
+	}
+	return int(vals[0]), nil
+}
+
+// GetUserTime returns the time of the last user activity in the window, as set in its _NET_WM_USER_TIME property.
+// A time of 0 means that the window should not be focused when it's mapped
+func (xc *Connection) GetUserTime(win xproto.Window) (uint32, bool) {
+	vals, err := xc.getProps32(win, "_NET_WM_USER_TIME")
+	if err != nil || len(vals) == 0 {
+		return 0, false
+	}
+	return vals[0], true
+}
+
+// GetUserTimeWindow returns the window holding the _NET_WM_USER_TIME property on behalf of the given one,
+// or 0 if _NET_WM_USER_TIME_WINDOW is not set
+func (xc *Connection) GetUserTimeWindow(win xproto.Window) xproto.Window {
+	vals, err := xc.getProps32(win, "_NET_WM_USER_TIME_WINDOW")
+	if err != nil || len(vals) == 0 {
+		return 0
+	}
+	return xproto.Window(vals[0])
+}
+
+// GetStartupID returns the startup notification ID of the window from its _NET_STARTUP_ID property
+func (xc *Connection) GetStartupID(win xproto.Window) string {
+	reply, err := xc.getProp(win, "_NET_STARTUP_ID")
+	if err != nil {
+		return ""
+	}
+	return string(reply.Value)
+}
@@ -1,13 +1,47 @@
 package x11
 
+// ewmhSupported lists the hints of the EWMH specification that the WM handles, published in _NET_SUPPORTED
 var ewmhSupported = []string{
 	"_NET_SUPPORTED",
+	"_NET_SUPPORTING_WM_CHECK",
+	"_NET_WM_NAME",
 	"_NET_ACTIVE_WINDOW",
 	"_NET_CURRENT_DESKTOP",
 	"_NET_DESKTOP_NAMES",
 	"_NET_DESKTOP_VIEWPORT",
 	"_NET_NUMBER_OF_DESKTOPS",
 	"_NET_CLIENT_LIST",
+	"_NET_CLIENT_LIST_STACKING",
+	"_NET_WM_DESKTOP",
+	"_NET_WM_USER_TIME",
+	"_NET_FRAME_EXTENTS",
+	"_NET_WM_MOVERESIZE",
+	"_NET_MOVERESIZE_WINDOW",
+	"_NET_CLOSE_WINDOW",
+	"_NET_REQUEST_FRAME_EXTENTS",
+	"_NET_WM_USER_TIME_WINDOW",
 	"_NET_WM_STRUT",
-	// "_NET_WM_STRUT_PARTIAL",
+	"_NET_WM_STRUT_PARTIAL",
+	"_NET_WM_WINDOW_TYPE",
+	"_NET_WM_WINDOW_TYPE_NORMAL",
+	"_NET_WM_WINDOW_TYPE_DOCK",
+	"_NET_WM_WINDOW_TYPE_DIALOG",
+	"_NET_WM_WINDOW_TYPE_UTILITY",
+	"_NET_WM_WINDOW_TYPE_TOOLBAR",
+	"_NET_WM_WINDOW_TYPE_SPLASH",
+	"_NET_WM_WINDOW_TYPE_MENU",
+	"_NET_WM_WINDOW_TYPE_DROPDOWN_MENU",
+	"_NET_WM_WINDOW_TYPE_POPUP_MENU",
+	"_NET_WM_WINDOW_TYPE_TOOLTIP",
+	"_NET_WM_WINDOW_TYPE_NOTIFICATION",
+	"_NET_WM_WINDOW_TYPE_DESKTOP",
+	"_NET_WM_STATE",
+	"_NET_WM_STATE_FULLSCREEN",
+	"_NET_WM_STATE_MAXIMIZED_VERT",
+	"_NET_WM_STATE_MAXIMIZED_HORZ",
+	"_NET_WM_STATE_HIDDEN",
+	"_NET_WM_STATE_ABOVE",
+	"_NET_WM_STATE_BELOW",
+	"_NET_WM_STATE_STICKY",
+	"_NET_WM_STATE_DEMANDS_ATTENTION",
 }
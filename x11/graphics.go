@@ -3,6 +3,7 @@ package x11
 import (
 	"image"
 
+	"github.com/BurntSushi/xgb/xproto"
 	"github.com/BurntSushi/xgbutil/xgraphics"
 )
 
@@ -13,3 +14,71 @@ type Dimensions struct {
 func (xc *Connection) NewImage(rect image.Rectangle) *xgraphics.Image {
 	return xgraphics.New(xc.util, rect)
 }
+
+// PaintARGBImage draws the image onto a window of depth 32 at the given position. The images are drawn
+// through pixmaps of the depth of the root window otherwise, which can't be copied onto such windows; their
+// BGRA pixels have the layout of the ARGB visuals, so they're sent to the window as they are
+func (xc *Connection) PaintARGBImage(win xproto.Window, img *xgraphics.Image, x, y int) error {
+	gc, err := xproto.NewGcontextId(xc.conn)
+	if err != nil {
+		return err
+	}
+	if err := xproto.CreateGCChecked(xc.conn, gc, xproto.Drawable(win), 0, nil).Check(); err != nil {
+		return err
+	}
+	defer xproto.FreeGC(xc.conn, gc)
+	size := img.Bounds().Size()
+	if size.X <= 0 || size.Y <= 0 {
+		return nil
+	}
+	// the request header takes 24 bytes of the maximum request length, given in 4-byte units
+	rowsPer := (int(xproto.Setup(xc.conn).MaximumRequestLength)*4 - 24) / img.Stride
+	if rowsPer < 1 {
+		rowsPer = 1
+	}
+	for row := 0; row < size.Y; row += rowsPer {
+		rows := rowsPer
+		if row+rows > size.Y {
+			rows = size.Y - row
+		}
+		data := img.Pix[row*img.Stride : (row+rows)*img.Stride]
+		err := xproto.PutImageChecked(xc.conn, xproto.ImageFormatZPixmap, xproto.Drawable(win), gc,
+			uint16(size.X), uint16(rows), int16(x), int16(y+row), 0, 32, data).Check()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CaptureWindow returns the current contents of the window, which must be viewable
+func (xc *Connection) CaptureWindow(win xproto.Window) (*xgraphics.Image, error) {
+	return xgraphics.NewDrawable(xc.util, xproto.Drawable(win))
+}
+
+// SetRootBackground makes the image, of the size of the root window, the background of the root window
+// and publishes its pixmap in _XROOTPMAP_ID and ESETROOT_PMAP_ID for the compositors and the programs
+// with pseudo-transparency. The image must not be destroyed, its pixmap is freed once it's replaced
+func (xc *Connection) SetRootBackground(img *xgraphics.Image) error {
+	if err := img.CreatePixmap(); err != nil {
+		return err
+	}
+	img.XDraw()
+	root := xc.screen.Root
+	err := xproto.ChangeWindowAttributesChecked(xc.conn, root, xproto.CwBackPixmap, []uint32{uint32(img.Pixmap)}).Check()
+	if err != nil {
+		img.Destroy()
+		return err
+	}
+	xproto.ClearArea(xc.conn, false, root, 0, 0, 0, 0)
+	for _, prop := range []string{"_XROOTPMAP_ID", "ESETROOT_PMAP_ID"} {
+		if err := xc.changeProp32(root, prop, xproto.AtomPixmap, uint32(img.Pixmap)); err != nil {
+			return err
+		}
+	}
+	if xc.rootPixmap != 0 {
+		xproto.FreePixmap(xc.conn, xc.rootPixmap)
+	}
+	xc.rootPixmap = img.Pixmap
+	return nil
+}
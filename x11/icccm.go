@@ -0,0 +1,161 @@
+package x11
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// Flags of the WM_NORMAL_HINTS property
+const (
+	sizeHintPMinSize    = 1 << 4
+	sizeHintPMaxSize    = 1 << 5
+	sizeHintPResizeInc  = 1 << 6
+	sizeHintPAspect     = 1 << 7
+	sizeHintPBaseSize   = 1 << 8
+	sizeHintPWinGravity = 1 << 9
+)
+
+// SizeHints represents the size constraints of the WM_NORMAL_HINTS property. Zero values mean no constraint
+type SizeHints struct {
+	MinW, MinH   uint16
+	MaxW, MaxH   uint16
+	IncW, IncH   uint16
+	BaseW, BaseH uint16
+
+	// Minimum and maximum aspect ratios (width/height) given as numerator and denominator
+	MinAspectNum, MinAspectDen uint32
+	MaxAspectNum, MaxAspectDen uint32
+
+	// Gravity is the win_gravity of the window, xproto.GravityNorthWest if not set
+	Gravity byte
+}
+
+// GetNormalHints returns the size hints from the WM_NORMAL_HINTS property of the window
+func (xc *Connection) GetNormalHints(win xproto.Window) (*SizeHints, error) {
+	vals, err := xc.getProps32(win, "WM_NORMAL_HINTS")
+	if err != nil {
+		return nil, err
+	}
+	return parseSizeHints(vals), nil
+}
+
+func parseSizeHints(vals []uint32) *SizeHints {
+	// flags, 4 obsolete position/size fields, min, max, inc, min/max aspect, base and gravity
+	v := make([]uint32, 18)
+	copy(v, vals)
+	flags := v[0]
+	hints := &SizeHints{Gravity: xproto.GravityNorthWest}
+	if flags&sizeHintPMinSize != 0 {
+		hints.MinW, hints.MinH = uint16(v[5]), uint16(v[6])
+	}
+	if flags&sizeHintPMaxSize != 0 {
+		hints.MaxW, hints.MaxH = uint16(v[7]), uint16(v[8])
+	}
+	if flags&sizeHintPResizeInc != 0 {
+		hints.IncW, hints.IncH = uint16(v[9]), uint16(v[10])
+	}
+	if flags&sizeHintPAspect != 0 {
+		hints.MinAspectNum, hints.MinAspectDen = v[11], v[12]
+		hints.MaxAspectNum, hints.MaxAspectDen = v[13], v[14]
+	}
+	if flags&sizeHintPBaseSize != 0 {
+		hints.BaseW, hints.BaseH = uint16(v[15]), uint16(v[16])
+	} else {
+		// ICCCM: the minimum size is used as the base size if the latter is not provided
+		hints.BaseW, hints.BaseH = hints.MinW, hints.MinH
+	}
+	if flags&sizeHintPMinSize == 0 && flags&sizeHintPBaseSize != 0 {
+		hints.MinW, hints.MinH = hints.BaseW, hints.BaseH
+	}
+	if flags&sizeHintPWinGravity != 0 && v[17] != 0 {
+		hints.Gravity = byte(v[17])
+	}
+	return hints
+}
+
+// Constrain returns the size closest to the given one that satisfies the hints
+func (h *SizeHints) Constrain(w, ht uint16) (uint16, uint16) {
+	if w < h.MinW {
+		w = h.MinW
+	}
+	if ht < h.MinH {
+		ht = h.MinH
+	}
+	if h.MaxW > 0 && w > h.MaxW {
+		w = h.MaxW
+	}
+	if h.MaxH > 0 && ht > h.MaxH {
+		ht = h.MaxH
+	}
+	if h.MinAspectDen > 0 && h.MinAspectNum > 0 && uint32(w)*h.MinAspectDen < uint32(ht)*h.MinAspectNum {
+		ht = uint16(uint32(w) * h.MinAspectDen / h.MinAspectNum)
+	}
+	if h.MaxAspectDen > 0 && h.MaxAspectNum > 0 && uint32(w)*h.MaxAspectDen > uint32(ht)*h.MaxAspectNum {
+		w = uint16(uint32(ht) * h.MaxAspectNum / h.MaxAspectDen)
+	}
+	if h.IncW > 1 && w > h.BaseW {
+		w = h.BaseW + (w-h.BaseW)/h.IncW*h.IncW
+	}
+	if h.IncH > 1 && ht > h.BaseH {
+		ht = h.BaseH + (ht-h.BaseH)/h.IncH*h.IncH
+	}
+	return w, ht
+}
+
+// GetTransientFor returns the window for which the given one is transient (e.g. the main window of a dialog)
+// or 0 if the WM_TRANSIENT_FOR property is not set
+func (xc *Connection) GetTransientFor(win xproto.Window) (xproto.Window, error) {
+	vals, err := xc.getProps32(win, "WM_TRANSIENT_FOR")
+	if err != nil || len(vals) == 0 {
+		return 0, err
+	}
+	return xproto.Window(vals[0]), nil
+}
+
+// GetWMClass returns the instance and class names from the WM_CLASS property of the window
+func (xc *Connection) GetWMClass(win xproto.Window) (instance, class string, err error) {
+	reply, err := xc.getProp(win, "WM_CLASS")
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(string(reply.Value), "\x00", 3)
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("invalid WM_CLASS property on window %d", win)
+	}
+	return parts[0], parts[1], nil
+}
+
+// States of the WM_STATE property
+const (
+	WithdrawnState = 0
+	NormalState    = 1
+	IconicState    = 3
+)
+
+// SetWMState sets the WM_STATE property of the window, which has no icon window
+func (xc *Connection) SetWMState(win xproto.Window, state uint32) error {
+	return xc.changeProp32(win, "WM_STATE", xc.Atom("WM_STATE"), state, 0)
+}
+
+// GetWMState returns the state from the WM_STATE property of the window, WithdrawnState if it's not set
+func (xc *Connection) GetWMState(win xproto.Window) uint32 {
+	vals, err := xc.getProps32(win, "WM_STATE")
+	if err != nil || len(vals) == 0 {
+		return WithdrawnState
+	}
+	return vals[0]
+}
+
+// urgencyHint is the flag of the WM_HINTS property set by clients demanding the user's attention
+const urgencyHint = 1 << 8
+
+// GetUrgencyHint returns whether the urgency flag is set in the WM_HINTS property of the window
+func (xc *Connection) GetUrgencyHint(win xproto.Window) bool {
+	vals, err := xc.getProps32(win, "WM_HINTS")
+	if err != nil || len(vals) == 0 {
+		return false
+	}
+	return vals[0]&urgencyHint != 0
+}
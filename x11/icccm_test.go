@@ -0,0 +1,57 @@
+package x11
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+func TestSizeHints_Constrain(t *testing.T) {
+	tests := []struct {
+		name  string
+		hints SizeHints
+		w, h  uint16
+		wantW uint16
+		wantH uint16
+	}{
+		{"no constraints", SizeHints{}, 800, 600, 800, 600},
+		{"min size", SizeHints{MinW: 300, MinH: 200}, 100, 100, 300, 200},
+		{"max size", SizeHints{MaxW: 400, MaxH: 300}, 800, 600, 400, 300},
+		{"increments", SizeHints{IncW: 7, IncH: 15, BaseW: 2, BaseH: 4}, 800, 600, 800, 589},
+		{"min aspect", SizeHints{MinAspectNum: 1, MinAspectDen: 1}, 400, 600, 400, 400},
+		{"max aspect", SizeHints{MaxAspectNum: 16, MaxAspectDen: 9}, 1920, 900, 1600, 900},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := tt.hints.Constrain(tt.w, tt.h)
+			if w != tt.wantW || h != tt.wantH {
+				t.Errorf("got = %vx%v, want = %vx%v", w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestParseSizeHints(t *testing.T) {
+	t.Run("min size as base", func(t *testing.T) {
+		vals := []uint32{sizeHintPMinSize | sizeHintPResizeInc, 0, 0, 0, 0, 20, 30, 0, 0, 5, 10}
+		got := *parseSizeHints(vals)
+		want := SizeHints{MinW: 20, MinH: 30, IncW: 5, IncH: 10, BaseW: 20, BaseH: 30, Gravity: xproto.GravityNorthWest}
+		if got != want {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+	})
+	t.Run("base size as min", func(t *testing.T) {
+		vals := []uint32{sizeHintPBaseSize, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 8, 1}
+		got := *parseSizeHints(vals)
+		want := SizeHints{MinW: 4, MinH: 8, BaseW: 4, BaseH: 8, Gravity: xproto.GravityNorthWest}
+		if got != want {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+	})
+	t.Run("gravity", func(t *testing.T) {
+		vals := []uint32{sizeHintPWinGravity, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, xproto.GravityStatic}
+		if got := parseSizeHints(vals).Gravity; got != xproto.GravityStatic {
+			t.Errorf("got = %v, want = %v", got, xproto.GravityStatic)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package x11
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/randr"
+)
+
+// Monitor represents a single active CRTC, as reported by the RandR extension
+type Monitor struct {
+	Name    string
+	X, Y    int16
+	W, H    uint16
+	Primary bool // whether one of the outputs of the CRTC is the primary output (xrandr --primary)
+
+	// physical size of the monitor in millimeters as reported by its output, matching W and H even when
+	// it's rotated, 0 if unknown
+	MmW, MmH uint32
+}
+
+func (xc *Connection) initRandr() error {
+	if err := randr.Init(xc.conn); err != nil {
+		// RandR is optional, the entire screen will be treated as a single monitor
+		return nil
+	}
+	xc.randr = true
+	mask := uint16(randr.NotifyMaskScreenChange | randr.NotifyMaskCrtcChange | randr.NotifyMaskOutputChange)
+	if err := randr.SelectInputChecked(xc.conn, xc.screen.Root, mask).Check(); err != nil {
+		return fmt.Errorf("failed to select RandR input: %v", err)
+	}
+	return nil
+}
+
+// Monitors returns the list of active monitors. If RandR is not available (or reports no active CRTCs)
+// a single monitor covering the entire screen is returned
+func (xc *Connection) Monitors() ([]Monitor, error) {
+	if !xc.randr {
+		return []Monitor{xc.screenMonitor()}, nil
+	}
+	res, err := randr.GetScreenResourcesCurrent(xc.conn, xc.screen.Root).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screen resources: %v", err)
+	}
+	var primary randr.Output
+	if reply, err := randr.GetOutputPrimary(xc.conn, xc.screen.Root).Reply(); err == nil {
+		primary = reply.Output
+	}
+	monitors := make([]Monitor, 0, len(res.Crtcs))
+	for _, crtc := range res.Crtcs {
+		info, err := randr.GetCrtcInfo(xc.conn, crtc, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CRTC info: %v", err)
+		}
+		if info.NumOutputs == 0 || info.Width == 0 || info.Height == 0 {
+			continue
+		}
+		m := Monitor{X: info.X, Y: info.Y, W: info.Width, H: info.Height}
+		for _, out := range info.Outputs {
+			m.Primary = m.Primary || primary != 0 && out == primary
+		}
+		if out, err := randr.GetOutputInfo(xc.conn, info.Outputs[0], res.ConfigTimestamp).Reply(); err == nil {
+			m.Name = string(out.Name)
+			m.MmW, m.MmH = out.MmWidth, out.MmHeight
+			if info.Rotation&(randr.RotationRotate90|randr.RotationRotate270) != 0 {
+				m.MmW, m.MmH = m.MmH, m.MmW
+			}
+		}
+		if !containsMonitorGeom(monitors, m) {
+			monitors = append(monitors, m)
+		}
+	}
+	if len(monitors) == 0 {
+		return []Monitor{xc.screenMonitor()}, nil
+	}
+	return monitors, nil
+}
+
+// UpdateScreenSize stores the new dimensions of the root window after a RandR screen change
+func (xc *Connection) UpdateScreenSize(w, h uint16) {
+	xc.screen.WidthInPixels = w
+	xc.screen.HeightInPixels = h
+}
+
+func (xc *Connection) screenMonitor() Monitor {
+	return Monitor{
+		W:   xc.screen.WidthInPixels,
+		H:   xc.screen.HeightInPixels,
+		MmW: uint32(xc.screen.WidthInMillimeters),
+		MmH: uint32(xc.screen.HeightInMillimeters),
+	}
+}
+
+// containsMonitorGeom checks whether a monitor with the same geometry is already in the list (e.g. cloned outputs)
+func containsMonitorGeom(monitors []Monitor, m Monitor) bool {
+	for _, other := range monitors {
+		if other.X == m.X && other.Y == m.Y && other.W == m.W && other.H == m.H {
+			return true
+		}
+	}
+	return false
+}
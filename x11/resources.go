@@ -0,0 +1,34 @@
+package x11
+
+import (
+	"strings"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// Resource returns the value of the X resource with the given name, e.g. "Xcursor.theme", loaded by xrdb
+// into the RESOURCE_MANAGER property of the root window. It's empty if the resource isn't set
+func (xc *Connection) Resource(name string) string {
+	reply, err := xproto.GetProperty(xc.conn, false, xc.screen.Root, xc.Atom("RESOURCE_MANAGER"),
+		xproto.AtomString, 0, 1<<20).Reply()
+	if err != nil || reply == nil {
+		return ""
+	}
+	return resourceValue(string(reply.Value), name)
+}
+
+// resourceValue finds the value of the resource in the content of RESOURCE_MANAGER, one "name: value" per
+// line. A loose binding of the whole name ("*name") is also accepted
+func resourceValue(db, name string) string {
+	for _, line := range strings.Split(db, "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:i])
+		if key == name || key == "*"+name {
+			return strings.TrimSpace(line[i+1:])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package x11
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/xgb/dpms"
+	"github.com/BurntSushi/xgb/screensaver"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// initScreenSaver enables the MIT-SCREEN-SAVER and DPMS extensions, both are optional: without them
+// the idle time can't be measured and the power saving of the monitors is left alone
+func (xc *Connection) initScreenSaver() {
+	if err := screensaver.Init(xc.conn); err == nil {
+		if _, err := screensaver.QueryVersion(xc.conn, 1, 1).Reply(); err == nil {
+			xc.screensaver = true
+		}
+	}
+	if err := dpms.Init(xc.conn); err == nil {
+		if reply, err := dpms.Capable(xc.conn).Reply(); err == nil && reply.Capable {
+			xc.dpms = true
+		}
+	}
+}
+
+// ErrNoScreenSaver is returned by IdleTime when the X server lacks the MIT-SCREEN-SAVER extension
+var ErrNoScreenSaver = errors.New("the MIT-SCREEN-SAVER extension is not available")
+
+// IdleTime returns the time since the last input of the user, as reported by the MIT-SCREEN-SAVER extension
+func (xc *Connection) IdleTime() (time.Duration, error) {
+	if !xc.screensaver {
+		return 0, ErrNoScreenSaver
+	}
+	reply, err := screensaver.QueryInfo(xc.conn, xproto.Drawable(xc.screen.Root)).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the screen saver: %v", err)
+	}
+	return time.Duration(reply.MsSinceUserInput) * time.Millisecond, nil
+}
+
+// SuspendScreenSaver keeps the screen saver and the DPMS power saving from activating until resumed
+func (xc *Connection) SuspendScreenSaver(suspend bool) error {
+	if !xc.screensaver {
+		return nil
+	}
+	return screensaver.SuspendChecked(xc.conn, suspend).Check()
+}
+
+// SetDPMS enables the power saving of the monitors with the given standby, suspend and off timeouts,
+// in seconds (0 disables the level)
+func (xc *Connection) SetDPMS(standby, suspend, off uint16) error {
+	if !xc.dpms {
+		return fmt.Errorf("the DPMS extension is not available")
+	}
+	if err := dpms.SetTimeoutsChecked(xc.conn, standby, suspend, off).Check(); err != nil {
+		return fmt.Errorf("failed to set the DPMS timeouts: %v", err)
+	}
+	if err := dpms.EnableChecked(xc.conn).Check(); err != nil {
+		return fmt.Errorf("failed to enable DPMS: %v", err)
+	}
+	return nil
+}
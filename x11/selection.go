@@ -0,0 +1,117 @@
+package x11
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// replaceTimeout is how long to wait for the previous WM to exit after taking over its selection
+const replaceTimeout = 3 * time.Second
+
+// CheckWindow returns the window holding _NET_SUPPORTING_WM_CHECK, which also owns the manager selection
+func (xc *Connection) CheckWindow() xproto.Window { return xc.checkWin }
+
+// ManagerSelection returns the WM_Sn selection atom for the managed screen
+func (xc *Connection) ManagerSelection() xproto.Atom {
+	return xc.Atom(fmt.Sprintf("WM_S%d", xc.conn.DefaultScreen))
+}
+
+// AcquireSelection takes the ownership of the manager selection as described in the ICCCM. If the selection
+// is already owned by another WM, an error is returned unless replace is set, in which case the WM waits for
+// the previous owner to exit
+func (xc *Connection) AcquireSelection(replace bool) error {
+	sel := xc.ManagerSelection()
+	reply, err := xproto.GetSelectionOwner(xc.conn, sel).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to get the selection owner: %v", err)
+	}
+	prev := reply.Owner
+	if prev != 0 {
+		if !replace {
+			return fmt.Errorf("another window manager is already running, use --replace to take over")
+		}
+		mask := []uint32{xproto.EventMaskStructureNotify}
+		if err := xproto.ChangeWindowAttributesChecked(xc.conn, prev, xproto.CwEventMask, mask).Check(); err != nil {
+			// the previous owner is already gone
+			prev = 0
+		}
+	}
+	t, err := xc.serverTime()
+	if err != nil {
+		return fmt.Errorf("failed to get the server time: %v", err)
+	}
+	xc.selectionTime = t
+	if err := xc.setSelectionOwner(xc.checkWin, sel); err != nil {
+		return err
+	}
+	if prev != 0 {
+		if err := xc.waitForDestroy(prev, replaceTimeout); err != nil {
+			return err
+		}
+	}
+	return xc.announceManager(sel, xc.checkWin)
+}
+
+// setSelectionOwner makes the window the owner of the selection, as of the time the manager selection was acquired
+func (xc *Connection) setSelectionOwner(win xproto.Window, sel xproto.Atom) error {
+	if err := xproto.SetSelectionOwnerChecked(xc.conn, win, sel, xc.selectionTime).Check(); err != nil {
+		return fmt.Errorf("failed to set the selection owner: %v", err)
+	}
+	reply, err := xproto.GetSelectionOwner(xc.conn, sel).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to get the selection owner: %v", err)
+	}
+	if reply.Owner != win {
+		return fmt.Errorf("failed to acquire the selection")
+	}
+	return nil
+}
+
+// announceManager sends the MANAGER client message to the root window, letting the clients know about the new owner
+func (xc *Connection) announceManager(sel xproto.Atom, owner xproto.Window) error {
+	data := []uint32{uint32(xc.selectionTime), uint32(sel), uint32(owner), 0, 0}
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: xc.screen.Root,
+		Type:   xc.Atom("MANAGER"),
+		Data:   xproto.ClientMessageDataUnionData32New(data),
+	}
+	return xproto.SendEventChecked(xc.conn, false, xc.screen.Root, xproto.EventMaskStructureNotify, string(ev.Bytes())).Check()
+}
+
+// serverTime returns the current X server timestamp, obtained from the PropertyNotify event generated
+// by appending nothing to a property of the check window
+func (xc *Connection) serverTime() (xproto.Timestamp, error) {
+	prop := xc.Atom("_MARWIND_TIMESTAMP")
+	err := xproto.ChangePropertyChecked(xc.conn, xproto.PropModeAppend, xc.checkWin, prop, xproto.AtomString, 8, 0, nil).Check()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		ev, err := xc.conn.WaitForEvent()
+		if ev == nil && err == nil {
+			return 0, fmt.Errorf("connection closed")
+		}
+		if e, ok := ev.(xproto.PropertyNotifyEvent); ok && e.Window == xc.checkWin && e.Atom == prop {
+			return e.Time, nil
+		}
+	}
+}
+
+// waitForDestroy waits until the window is destroyed, discarding the other events in the meantime
+func (xc *Connection) waitForDestroy(win xproto.Window, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		ev, err := xc.conn.PollForEvent()
+		if ev == nil && err == nil {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		if e, ok := ev.(xproto.DestroyNotifyEvent); ok && e.Window == win {
+			return nil
+		}
+	}
+	return fmt.Errorf("the previous window manager did not exit in time")
+}
@@ -0,0 +1,41 @@
+package x11
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// SendStartupInfo broadcasts a message of the startup notification protocol (e.g. "new: ID=...") to the
+// clients monitoring the launches, split into the 20 bytes of a _NET_STARTUP_INFO_BEGIN client message
+// followed by as many _NET_STARTUP_INFO ones as needed, sent from the check window
+func (xc *Connection) SendStartupInfo(msg string) error {
+	typ := xc.Atom("_NET_STARTUP_INFO_BEGIN")
+	for _, chunk := range startupInfoChunks(msg) {
+		ev := xproto.ClientMessageEvent{
+			Format: 8,
+			Window: xc.checkWin,
+			Type:   typ,
+			Data:   xproto.ClientMessageDataUnionData8New(chunk),
+		}
+		err := xproto.SendEventChecked(xc.conn, false, xc.screen.Root, xproto.EventMaskPropertyChange, string(ev.Bytes())).Check()
+		if err != nil {
+			return err
+		}
+		typ = xc.Atom("_NET_STARTUP_INFO")
+	}
+	return nil
+}
+
+// ScreenNumber returns the number of the screen of the WM, as in the DISPLAY variable
+func (xc *Connection) ScreenNumber() int { return xc.conn.DefaultScreen }
+
+// startupInfoChunks splits the NUL-terminated message into the data of the client messages
+func startupInfoChunks(msg string) [][]byte {
+	data := append([]byte(msg), 0)
+	var chunks [][]byte
+	for len(data) > 0 {
+		chunk := make([]byte, 20)
+		data = data[copy(chunk, data):]
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
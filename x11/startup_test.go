@@ -0,0 +1,31 @@
+package x11
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStartupInfoChunks(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want int
+	}{
+		{"short", "remove: ID=a", 1},
+		{"filling a chunk", "new: ID=abcdefghijk", 1},
+		{"terminator in next chunk", "new: ID=abcdefghijkl", 2},
+		{"long", `new: ID="marwind-1-1_TIME5" NAME="Firefox"`, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := startupInfoChunks(tt.msg)
+			if len(chunks) != tt.want {
+				t.Fatalf("got = %v chunks, want = %v", len(chunks), tt.want)
+			}
+			data := bytes.Join(chunks, nil)
+			if got := string(data[:bytes.IndexByte(data, 0)]); got != tt.msg {
+				t.Errorf("got = %q, want = %q", got, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package x11
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+const (
+	trayRequestDock      = 0 // SYSTEM_TRAY_REQUEST_DOCK opcode of _NET_SYSTEM_TRAY_OPCODE
+	xembedEmbeddedNotify = 0 // XEMBED_EMBEDDED_NOTIFY message
+	xembedVersion        = 0
+	xembedMapped         = 1 << 0 // XEMBED_MAPPED flag of _XEMBED_INFO
+)
+
+// TraySelection returns the _NET_SYSTEM_TRAY_Sn selection atom for the managed screen
+func (xc *Connection) TraySelection() xproto.Atom {
+	return xc.Atom(fmt.Sprintf("_NET_SYSTEM_TRAY_S%d", xc.conn.DefaultScreen))
+}
+
+// CreateTrayWindow creates the window into which the tray icons are embedded. It's a dock reserving
+// the given height at the top of the screen, over the horizontal range of the window
+func (xc *Connection) CreateTrayWindow(x, y int16, width, height uint16, bgColor uint32) (xproto.Window, error) {
+	win, err := xc.CreateWindow(xc.screen.Root, x, y, width, height, 0, xproto.WindowClassInputOutput,
+		xproto.CwBackPixel, []uint32{bgColor})
+	if err != nil {
+		return 0, err
+	}
+	if err := xc.setTrayStrut(win, x, y, width, height); err != nil {
+		return 0, err
+	}
+	if err := xc.changeProp32(win, "_NET_WM_WINDOW_TYPE", xproto.AtomAtom, uint32(xc.Atom("_NET_WM_WINDOW_TYPE_DOCK"))); err != nil {
+		return 0, err
+	}
+	if err := xc.changeProp(win, 8, "WM_CLASS", xproto.AtomString, []byte("marwind-tray\x00Marwind\x00")); err != nil {
+		return 0, err
+	}
+	return win, nil
+}
+
+// MoveTrayWindow moves and resizes the tray window, along with the space it reserves at the top of the screen
+func (xc *Connection) MoveTrayWindow(win xproto.Window, x, y int16, width, height uint16) error {
+	if err := xc.setTrayStrut(win, x, y, width, height); err != nil {
+		return err
+	}
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+	values := []uint32{uint32(x), uint32(y), uint32(width), uint32(height)}
+	return xproto.ConfigureWindowChecked(xc.conn, win, mask, values).Check()
+}
+
+// setTrayStrut reserves the height of the tray window at the top of the screen, over its horizontal range
+func (xc *Connection) setTrayStrut(win xproto.Window, x, y int16, width, height uint16) error {
+	strut := []uint32{0, 0, uint32(y) + uint32(height), 0, 0, 0, 0, 0, uint32(x), uint32(x) + uint32(width) - 1, 0, 0}
+	return xc.changeProp32(win, "_NET_WM_STRUT_PARTIAL", xproto.AtomCardinal, strut...)
+}
+
+// AcquireTraySelection makes the window the system tray of the screen and announces it to the tray icons
+func (xc *Connection) AcquireTraySelection(win xproto.Window) error {
+	sel := xc.TraySelection()
+	reply, err := xproto.GetSelectionOwner(xc.conn, sel).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to get the selection owner: %v", err)
+	}
+	if reply.Owner != 0 {
+		return fmt.Errorf("another system tray is already running")
+	}
+	if err := xc.changeProp32(win, "_NET_SYSTEM_TRAY_ORIENTATION", xproto.AtomCardinal, 0); err != nil {
+		return err
+	}
+	if err := xc.setSelectionOwner(win, sel); err != nil {
+		return err
+	}
+	return xc.announceManager(sel, win)
+}
+
+// TrayDockRequest returns the icon window if the message is a request to dock it in the tray
+func (xc *Connection) TrayDockRequest(e xproto.ClientMessageEvent) (xproto.Window, bool) {
+	if e.Type != xc.Atom("_NET_SYSTEM_TRAY_OPCODE") || e.Format != 32 || e.Data.Data32[1] != trayRequestDock {
+		return 0, false
+	}
+	return xproto.Window(e.Data.Data32[2]), true
+}
+
+// XEmbedMapped returns whether the embedded window asks to be mapped in its _XEMBED_INFO property.
+// Windows without the property are always mapped
+func (xc *Connection) XEmbedMapped(win xproto.Window) bool {
+	info, err := xc.getProps32(win, "_XEMBED_INFO")
+	if err != nil || len(info) < 2 {
+		return true
+	}
+	return info[1]&xembedMapped != 0
+}
+
+// SendXEmbedNotify tells the window that it was embedded into the embedder window
+func (xc *Connection) SendXEmbedNotify(win, embedder xproto.Window) error {
+	data := []uint32{uint32(xc.selectionTime), xembedEmbeddedNotify, 0, uint32(embedder), xembedVersion}
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   xc.Atom("_XEMBED"),
+		Data:   xproto.ClientMessageDataUnionData32New(data),
+	}
+	return xproto.SendEventChecked(xc.conn, false, win, xproto.EventMaskNoEvent, string(ev.Bytes())).Check()
+}
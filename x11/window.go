@@ -7,36 +7,46 @@ import (
 	"github.com/BurntSushi/xgb/xproto"
 )
 
-func (xc *Connection) GracefullyDestroyWindow(win xproto.Window) error {
+// SupportsProtocol returns true if the protocol (e.g. WM_DELETE_WINDOW) is listed in the window's WM_PROTOCOLS
+func (xc *Connection) SupportsProtocol(win xproto.Window, name string) bool {
 	protos, err := xc.getProps32(win, "WM_PROTOCOLS")
 	if err != nil {
-		return fmt.Errorf("could not close window: %v", err)
+		return false
 	}
 	for _, p := range protos {
-		if xproto.Atom(p) == xc.Atom("WM_DELETE_WINDOW") {
-			t := time.Now().Unix()
-			return xproto.SendEventChecked(
-				xc.conn,
-				false,
-				win,
-				xproto.EventMaskNoEvent,
-				string(xproto.ClientMessageEvent{
-					Format: 32,
-					Window: win,
-					Type:   xc.Atom("WM_PROTOCOLS"),
-					Data: xproto.ClientMessageDataUnionData32New([]uint32{
-						uint32(xc.Atom("WM_DELETE_WINDOW")),
-						uint32(t),
-						0,
-						0,
-						0,
-					}),
-				}.Bytes()),
-			).Check()
+		if xproto.Atom(p) == xc.Atom(name) {
+			return true
 		}
 	}
-	// The window does not follow ICCCM - just destroy it
-	return xproto.DestroyWindowChecked(xc.conn, win).Check()
+	return false
+}
+
+// SendDeleteWindow asks the client to close the window using the WM_DELETE_WINDOW protocol
+func (xc *Connection) SendDeleteWindow(win xproto.Window) error {
+	t := time.Now().Unix()
+	return xproto.SendEventChecked(
+		xc.conn,
+		false,
+		win,
+		xproto.EventMaskNoEvent,
+		string(xproto.ClientMessageEvent{
+			Format: 32,
+			Window: win,
+			Type:   xc.Atom("WM_PROTOCOLS"),
+			Data: xproto.ClientMessageDataUnionData32New([]uint32{
+				uint32(xc.Atom("WM_DELETE_WINDOW")),
+				uint32(t),
+				0,
+				0,
+				0,
+			}),
+		}.Bytes()),
+	).Check()
+}
+
+// KillClient forcibly closes the connection of the client that created the window, destroying all its windows
+func (xc *Connection) KillClient(win xproto.Window) error {
+	return xproto.KillClientChecked(xc.conn, uint32(win)).Check()
 }
 
 func (xc *Connection) GetRootWindow() xproto.Window {
@@ -60,6 +70,36 @@ func (xc *Connection) CreateWindow(parent xproto.Window, x, y int16, width, heig
 	return id, nil
 }
 
+// GetWindowVisual returns the visual, the depth and the colormap of the window
+func (xc *Connection) GetWindowVisual(window xproto.Window) (xproto.Visualid, byte, xproto.Colormap, error) {
+	attrs, err := xproto.GetWindowAttributes(xc.conn, window).Reply()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	geom, err := xproto.GetGeometry(xc.conn, xproto.Drawable(window)).Reply()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return attrs.Visual, geom.Depth, attrs.Colormap, nil
+}
+
+// CreateVisualWindow creates a window like CreateWindow, but with the given visual and depth instead of the ones
+// of the root window. Unless they're the same, the values must include a colormap and a border pixel
+func (xc *Connection) CreateVisualWindow(parent xproto.Window, x, y int16, width, height, borderWidth,
+	class uint16, visual xproto.Visualid, depth byte, valueMask uint32, valueList []uint32) (xproto.Window, error) {
+
+	id, err := xproto.NewWindowId(xc.conn)
+	if err != nil {
+		return 0, err
+	}
+	err = xproto.CreateWindowChecked(xc.conn, depth, id, parent, x, y, width, height,
+		borderWidth, class, visual, valueMask, valueList).Check()
+	if err != nil {
+		return 0, fmt.Errorf("could not create window: %s", err)
+	}
+	return id, nil
+}
+
 func (xc *Connection) MapWindow(window xproto.Window) error {
 	return xproto.MapWindowChecked(xc.conn, window).Check()
 }
@@ -75,3 +115,78 @@ func (xc *Connection) DestroyWindow(window xproto.Window) error {
 func (xc *Connection) ReparentWindow(window, parent xproto.Window, x, y int16) error {
 	return xproto.ReparentWindowChecked(xc.conn, window, parent, x, y).Check()
 }
+
+// IsMapped checks whether the window is mapped, even if it's not viewable because of an unmapped ancestor
+func (xc *Connection) IsMapped(window xproto.Window) bool {
+	attrs, err := xproto.GetWindowAttributes(xc.conn, window).Reply()
+	return err == nil && attrs.MapState != xproto.MapStateUnmapped
+}
+
+// SetWindowBackground changes the background color of the window and repaints it
+func (xc *Connection) SetWindowBackground(window xproto.Window, pixel uint32) error {
+	err := xproto.ChangeWindowAttributesChecked(xc.conn, window, xproto.CwBackPixel, []uint32{pixel}).Check()
+	if err != nil {
+		return err
+	}
+	return xproto.ClearAreaChecked(xc.conn, false, window, 0, 0, 0, 0).Check()
+}
+
+// SetWindowOpacity sets the _NET_WM_WINDOW_OPACITY of the window, read by the compositors, to a value between
+// 0 (transparent) and 1. The property is removed when the window is fully opaque
+func (xc *Connection) SetWindowOpacity(window xproto.Window, opacity float64) error {
+	if opacity >= 1 {
+		return xproto.DeletePropertyChecked(xc.conn, window, xc.Atom("_NET_WM_WINDOW_OPACITY")).Check()
+	}
+	if opacity < 0 {
+		opacity = 0
+	}
+	return xc.changeProp32(window, "_NET_WM_WINDOW_OPACITY", xproto.AtomCardinal, uint32(opacity*0xffffffff))
+}
+
+// AddToSaveSet makes the X server reparent the window to the root if the WM's connection is closed
+func (xc *Connection) AddToSaveSet(window xproto.Window) error {
+	return xproto.ChangeSaveSetChecked(xc.conn, xproto.SetModeInsert, window).Check()
+}
+
+// RaiseWindow puts the window on top of the stack of its siblings
+func (xc *Connection) RaiseWindow(window xproto.Window) error {
+	return xproto.ConfigureWindowChecked(xc.conn, window, xproto.ConfigWindowStackMode,
+		[]uint32{xproto.StackModeAbove}).Check()
+}
+
+// MoveResizeWindow changes the position and the size of the window without waiting for the X server,
+// an error is reported asynchronously, like the events
+func (xc *Connection) MoveResizeWindow(window xproto.Window, x, y int16, width, height uint16) {
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+	values := []uint32{uint32(x), uint32(y), uint32(width), uint32(height)}
+	xproto.ConfigureWindow(xc.conn, window, mask, values)
+}
+
+// StackAbove puts the window right above the sibling, or below all of its siblings when the sibling is 0
+func (xc *Connection) StackAbove(window, sibling xproto.Window) error {
+	if sibling == 0 {
+		return xproto.ConfigureWindowChecked(xc.conn, window, xproto.ConfigWindowStackMode,
+			[]uint32{xproto.StackModeBelow}).Check()
+	}
+	return xproto.ConfigureWindowChecked(xc.conn, window, xproto.ConfigWindowSibling|xproto.ConfigWindowStackMode,
+		[]uint32{uint32(sibling), xproto.StackModeAbove}).Check()
+}
+
+// SendConfigureNotify tells the client of the window its geometry relative to the root window, as the real
+// ConfigureNotify of a reparented window is relative to its parent. It doesn't wait for the X server either
+func (xc *Connection) SendConfigureNotify(window xproto.Window, x, y int16, width, height uint16) {
+	ev := xproto.ConfigureNotifyEvent{
+		Event:  window,
+		Window: window,
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+	}
+	xproto.SendEvent(xc.conn, false, window, xproto.EventMaskStructureNotify, string(ev.Bytes()))
+}
+
+// Sync waits until the X server has processed all the requests sent so far
+func (xc *Connection) Sync() {
+	xc.conn.Sync()
+}
@@ -0,0 +1,164 @@
+package x11
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/patrislav/marwind/xdg"
+)
+
+// xcursorImage is an image of an XCursor file, whose pixels are ARGB with premultiplied alpha
+type xcursorImage struct {
+	width, height uint32
+	xhot, yhot    uint32
+	pixels        []uint32
+}
+
+const (
+	xcursorMagic     = "Xcur"
+	xcursorImageType = 0xfffd0002
+	xcursorMaxSize   = 0x7fff // of the images, as limited by libXcursor
+)
+
+// parseXcursor decodes the image of the size closest to the given one from the content of an XCursor file.
+// Animated cursors are given their first frame
+func parseXcursor(data []byte, size int) (*xcursorImage, error) {
+	le := binary.LittleEndian
+	if len(data) < 16 || string(data[:4]) != xcursorMagic {
+		return nil, errors.New("not an XCursor file")
+	}
+	header, ntoc := le.Uint32(data[4:]), le.Uint32(data[12:])
+	if uint64(header)+uint64(ntoc)*12 > uint64(len(data)) {
+		return nil, errors.New("truncated table of contents")
+	}
+	best, bestDist := -1, 0
+	for i := 0; i < int(ntoc); i++ {
+		entry := data[int(header)+i*12:]
+		if le.Uint32(entry) != xcursorImageType {
+			continue
+		}
+		dist := int(le.Uint32(entry[4:])) - size
+		if dist < 0 {
+			dist = -dist
+		}
+		if best < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	if best < 0 {
+		return nil, errors.New("no image")
+	}
+	pos := le.Uint32(data[int(header)+best*12+8:])
+	if uint64(pos)+36 > uint64(len(data)) {
+		return nil, errors.New("truncated image header")
+	}
+	chunk := data[pos:]
+	img := &xcursorImage{
+		width:  le.Uint32(chunk[16:]),
+		height: le.Uint32(chunk[20:]),
+		xhot:   le.Uint32(chunk[24:]),
+		yhot:   le.Uint32(chunk[28:]),
+	}
+	if img.width == 0 || img.height == 0 || img.width > xcursorMaxSize || img.height > xcursorMaxSize ||
+		img.xhot > img.width || img.yhot > img.height {
+		return nil, fmt.Errorf("invalid image of %dx%d", img.width, img.height)
+	}
+	n := int(img.width) * int(img.height)
+	start := int(le.Uint32(chunk))
+	if uint64(pos)+uint64(start)+uint64(n)*4 > uint64(len(data)) {
+		return nil, errors.New("truncated image")
+	}
+	img.pixels = make([]uint32, n)
+	for i := range img.pixels {
+		img.pixels[i] = le.Uint32(chunk[start+i*4:])
+	}
+	return img, nil
+}
+
+// xcursorPath returns the directories the XCursor themes are looked up in, from XCURSOR_PATH or, like
+// libXcursor, the icon directories of the user followed by the system ones
+func xcursorPath() []string {
+	if path := os.Getenv("XCURSOR_PATH"); path != "" {
+		var dirs []string
+		for _, dir := range filepath.SplitList(path) {
+			if dir != "" {
+				dirs = append(dirs, xdg.ExpandHome(dir))
+			}
+		}
+		return dirs
+	}
+	dirs := []string{filepath.Join(xdg.DataHome(), "icons"), xdg.ExpandHome("~/.icons")}
+	for _, dir := range xdg.DataDirs() {
+		dirs = append(dirs, filepath.Join(dir, "icons"))
+	}
+	return append(dirs, "/usr/share/pixmaps")
+}
+
+// findXcursor returns the path of the file of the named cursor in the theme or in the themes it inherits,
+// empty if there's none
+func findXcursor(dirs []string, theme, name string) string {
+	return findThemeXcursor(dirs, theme, name, make(map[string]bool))
+}
+
+func findThemeXcursor(dirs []string, theme, name string, visited map[string]bool) string {
+	if theme == "" || visited[theme] {
+		return ""
+	}
+	visited[theme] = true
+	for _, dir := range dirs {
+		path := filepath.Join(dir, theme, "cursors", name)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
+	}
+	for _, dir := range dirs {
+		for _, parent := range themeInherits(filepath.Join(dir, theme, "index.theme")) {
+			if path := findThemeXcursor(dirs, parent, name, visited); path != "" {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// themeInherits returns the themes listed in the Inherits key of the index.theme file of a theme, if any
+func themeInherits(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, "=")
+		if i < 0 || strings.TrimSpace(line[:i]) != "Inherits" {
+			continue
+		}
+		return strings.FieldsFunc(line[i+1:], func(r rune) bool { return r == ',' || r == ';' || r == ' ' })
+	}
+	return nil
+}
+
+// loadXcursor reads the image of the named cursor of the theme closest to the given size
+func loadXcursor(theme, name string, size int) (*xcursorImage, error) {
+	path := findXcursor(xcursorPath(), theme, name)
+	if path == "" {
+		return nil, fmt.Errorf("no cursor %q in theme %q", name, theme)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := parseXcursor(data, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,120 @@
+package x11
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// xcursorFile encodes an XCursor file with a square image of each of the nominal sizes, whose pixels
+// are all the size
+func xcursorFile(sizes ...uint32) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, 16+12*len(sizes))
+	copy(data, xcursorMagic)
+	le.PutUint32(data[4:], 16)
+	le.PutUint32(data[8:], 0x10000)
+	le.PutUint32(data[12:], uint32(len(sizes)))
+	for i, size := range sizes {
+		toc := data[16+i*12:]
+		le.PutUint32(toc, xcursorImageType)
+		le.PutUint32(toc[4:], size)
+		le.PutUint32(toc[8:], uint32(len(data)))
+		chunk := make([]byte, 36+size*size*4)
+		for j, v := range []uint32{36, xcursorImageType, size, 1, size, size, size / 2, size / 4, 0} {
+			le.PutUint32(chunk[j*4:], v)
+		}
+		for j := uint32(0); j < size*size; j++ {
+			le.PutUint32(chunk[36+j*4:], size)
+		}
+		data = append(data, chunk...)
+	}
+	return data
+}
+
+func TestParseXcursor(t *testing.T) {
+	tests := []struct {
+		name  string
+		sizes []uint32
+		size  int
+		want  uint32
+	}{
+		{"exact", []uint32{24, 32, 48}, 32, 32},
+		{"closest", []uint32{24, 48}, 40, 48},
+		{"smaller", []uint32{24, 48}, 16, 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := parseXcursor(xcursorFile(tt.sizes...), tt.size)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if img.width != tt.want || img.height != tt.want || img.xhot != tt.want/2 || img.yhot != tt.want/4 {
+				t.Errorf("image got = %dx%d at %d,%d, want = %dx%d", img.width, img.height, img.xhot, img.yhot, tt.want, tt.want)
+			}
+			if len(img.pixels) != int(tt.want*tt.want) || img.pixels[0] != tt.want {
+				t.Errorf("pixels got = %d of %d, want = %d of %d", len(img.pixels), img.pixels[0], tt.want*tt.want, tt.want)
+			}
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		data := xcursorFile(24)
+		for _, d := range [][]byte{nil, []byte("Xcur"), data[:len(data)-1]} {
+			if _, err := parseXcursor(d, 24); err == nil {
+				t.Errorf("expected an error for %d bytes", len(d))
+			}
+		}
+	})
+}
+
+func TestFindXcursor(t *testing.T) {
+	user, system := t.TempDir(), t.TempDir()
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(user, "Custom", "cursors", "left_ptr"), "")
+	write(filepath.Join(user, "Custom", "index.theme"), "[Icon Theme]\nInherits=Base\n")
+	write(filepath.Join(system, "Base", "cursors", "fleur"), "")
+	write(filepath.Join(system, "Base", "cursors", "left_ptr"), "")
+	write(filepath.Join(system, "Base", "index.theme"), "[Icon Theme]\nInherits=Custom\n")
+	dirs := []string{user, system}
+	tests := []struct {
+		theme, name string
+		want        string
+	}{
+		{"Custom", "left_ptr", filepath.Join(user, "Custom", "cursors", "left_ptr")},
+		{"Custom", "fleur", filepath.Join(system, "Base", "cursors", "fleur")},
+		{"Custom", "top_side", ""},
+		{"Missing", "left_ptr", ""},
+	}
+	for _, tt := range tests {
+		if got := findXcursor(dirs, tt.theme, tt.name); got != tt.want {
+			t.Errorf("findXcursor(%q, %q) got = %v, want = %v", tt.theme, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResourceValue(t *testing.T) {
+	db := "Xft.dpi:\t144\nXcursor.theme:\tAdwaita\n*Xcursor.size:\t32\n"
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Xcursor.theme", "Adwaita"},
+		{"Xcursor.size", "32"},
+		{"Xft.dpi", "144"},
+		{"Xft.hinting", ""},
+	}
+	for _, tt := range tests {
+		if got := resourceValue(db, tt.name); got != tt.want {
+			t.Errorf("resourceValue(%q) got = %v, want = %v", tt.name, got, tt.want)
+		}
+	}
+}
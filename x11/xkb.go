@@ -0,0 +1,106 @@
+package x11
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgb"
+	"github.com/BurntSushi/xgb/xproto"
+)
+
+// xgb comes without the XKEYBOARD extension, so the few requests and events needed to follow
+// the keyboard layout changes are encoded here by hand
+
+const xkbName = "XKEYBOARD"
+
+// XKB request opcodes
+const (
+	xkbUseExtension = 0
+	xkbSelectEvents = 1
+)
+
+// XKB event types, sent as the second byte of every XKB event
+const (
+	xkbNewKeyboardNotify = 0
+	xkbMapNotify         = 1
+)
+
+const xkbUseCoreKbd = 0x100
+
+// XkbEvent is sent when the keyboard or its mapping was replaced, e.g. with setxkbmap
+type XkbEvent struct {
+	Sequence uint16
+	Type     byte // XKB event type
+	Time     xproto.Timestamp
+}
+
+// Bytes is only needed to satisfy the xgb.Event interface
+func (e XkbEvent) Bytes() []byte {
+	buf := make([]byte, 32)
+	buf[1] = e.Type
+	xgb.Put16(buf[2:], e.Sequence)
+	xgb.Put32(buf[4:], uint32(e.Time))
+	return buf
+}
+
+func (e XkbEvent) String() string {
+	return fmt.Sprintf("XkbEvent {Type: %d, Sequence: %d, Time: %d}", e.Type, e.Sequence, e.Time)
+}
+
+func newXkbEvent(buf []byte) xgb.Event {
+	return XkbEvent{
+		Type:     buf[1],
+		Sequence: xgb.Get16(buf[2:]),
+		Time:     xproto.Timestamp(xgb.Get32(buf[4:])),
+	}
+}
+
+// initXkb enables the XKB extension, which makes the server report the keyboard group in the state
+// of the key events, and selects the events sent when the keyboard mapping changes
+func (xc *Connection) initXkb() error {
+	reply, err := xproto.QueryExtension(xc.conn, uint16(len(xkbName)), xkbName).Reply()
+	if err != nil {
+		return err
+	}
+	if !reply.Present {
+		// without XKB the layout changes are still reported with MappingNotify
+		return nil
+	}
+	xc.conn.ExtLock.Lock()
+	xc.conn.Extensions[xkbName] = reply.MajorOpcode
+	xc.conn.ExtLock.Unlock()
+	xgb.NewEventFuncs[int(reply.FirstEvent)] = newXkbEvent
+
+	buf := make([]byte, 8)
+	buf[0] = reply.MajorOpcode
+	buf[1] = xkbUseExtension
+	xgb.Put16(buf[2:], uint16(len(buf)/4))
+	xgb.Put16(buf[4:], 1) // wanted major version
+	xgb.Put16(buf[6:], 0) // wanted minor version
+	cookie := xc.conn.NewCookie(true, true)
+	xc.conn.NewRequest(buf, cookie)
+	data, err := cookie.Reply()
+	if err != nil {
+		return fmt.Errorf("failed to enable XKB: %v", err)
+	}
+	if data == nil || data[1] == 0 {
+		return fmt.Errorf("XKB version 1.0 is not supported")
+	}
+
+	events := uint16(1<<xkbNewKeyboardNotify | 1<<xkbMapNotify)
+	buf = make([]byte, 16)
+	buf[0] = reply.MajorOpcode
+	buf[1] = xkbSelectEvents
+	xgb.Put16(buf[2:], uint16(len(buf)/4))
+	xgb.Put16(buf[4:], xkbUseCoreKbd)
+	xgb.Put16(buf[6:], events)  // affected events
+	xgb.Put16(buf[8:], 0)       // events to clear
+	xgb.Put16(buf[10:], events) // events selected with all their details
+	xgb.Put16(buf[12:], 0xff)   // affected map parts
+	xgb.Put16(buf[14:], 0xff)   // selected map parts
+	cookie = xc.conn.NewCookie(true, false)
+	xc.conn.NewRequest(buf, cookie)
+	if err := cookie.Check(); err != nil {
+		return fmt.Errorf("failed to select XKB events: %v", err)
+	}
+	return nil
+}
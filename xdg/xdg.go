@@ -0,0 +1,115 @@
+// Package xdg locates the files of the WM according to the XDG Base Directory Specification
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// App is the name of the subdirectories of the WM in the base directories
+const App = "marwind"
+
+// ConfigHome returns $XDG_CONFIG_HOME, falling back to ~/.config
+func ConfigHome() string { return home("XDG_CONFIG_HOME", ".config") }
+
+// StateHome returns $XDG_STATE_HOME, falling back to ~/.local/state
+func StateHome() string { return home("XDG_STATE_HOME", filepath.Join(".local", "state")) }
+
+// CacheHome returns $XDG_CACHE_HOME, falling back to ~/.cache
+func CacheHome() string { return home("XDG_CACHE_HOME", ".cache") }
+
+// DataHome returns $XDG_DATA_HOME, falling back to ~/.local/share
+func DataHome() string { return home("XDG_DATA_HOME", filepath.Join(".local", "share")) }
+
+// DataDirs returns the system-wide data directories of $XDG_DATA_DIRS, the most important one first,
+// falling back to /usr/local/share and /usr/share
+func DataDirs() []string {
+	return dirList("XDG_DATA_DIRS", []string{"/usr/local/share", "/usr/share"})
+}
+
+// ConfigDirs returns the system-wide configuration directories of $XDG_CONFIG_DIRS, the most important
+// one first, falling back to /etc/xdg
+func ConfigDirs() []string {
+	return dirList("XDG_CONFIG_DIRS", []string{"/etc/xdg"})
+}
+
+// RuntimeDir returns $XDG_RUNTIME_DIR, falling back to the temporary directory of the system
+func RuntimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return dir
+	}
+	return os.TempDir()
+}
+
+// ConfigFile returns the path of the config file of the WM with the given name: the first existing one
+// among the user's and the system-wide configuration directories, or the user's one if there's none
+func ConfigFile(name string) string {
+	user := filepath.Join(ConfigHome(), App, name)
+	if _, err := os.Stat(user); err == nil {
+		return user
+	}
+	for _, dir := range ConfigDirs() {
+		path := filepath.Join(dir, App, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return user
+}
+
+// StateFile returns the path of the state file of the WM with the given name, relative to its directory
+// in $XDG_STATE_HOME, creating the directories leading to it
+func StateFile(name string) (string, error) { return file(StateHome(), name) }
+
+// CacheFile returns the path of the cache file of the WM with the given name, relative to its directory
+// in $XDG_CACHE_HOME, creating the directories leading to it
+func CacheFile(name string) (string, error) { return file(CacheHome(), name) }
+
+// ExpandHome replaces the leading ~ of the path with the home directory of the user
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(h, path[1:])
+}
+
+// home returns the value of the environment variable if it's an absolute path, as required by
+// the specification, or the fallback directory within the user's home otherwise
+func home(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(h, fallback)
+}
+
+// dirList returns the absolute paths of the list in the environment variable, or the fallback if there's none
+func dirList(env string, fallback []string) []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv(env)) {
+		// relative paths are invalid according to the specification
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return fallback
+	}
+	return dirs
+}
+
+func file(base, name string) (string, error) {
+	path := filepath.Join(base, App, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,120 @@
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHomes(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	tests := []struct {
+		name string
+		env  string
+		get  func() string
+		set  string
+		want string
+	}{
+		{"config", "XDG_CONFIG_HOME", ConfigHome, "/cfg", "/cfg"},
+		{"config fallback", "XDG_CONFIG_HOME", ConfigHome, "", "/home/user/.config"},
+		{"config relative", "XDG_CONFIG_HOME", ConfigHome, "cfg", "/home/user/.config"},
+		{"state", "XDG_STATE_HOME", StateHome, "/state", "/state"},
+		{"state fallback", "XDG_STATE_HOME", StateHome, "", "/home/user/.local/state"},
+		{"cache", "XDG_CACHE_HOME", CacheHome, "/cache", "/cache"},
+		{"cache fallback", "XDG_CACHE_HOME", CacheHome, "", "/home/user/.cache"},
+		{"data", "XDG_DATA_HOME", DataHome, "/data", "/data"},
+		{"data fallback", "XDG_DATA_HOME", DataHome, "", "/home/user/.local/share"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.set)
+			if got := tt.get(); got != tt.want {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDirs(t *testing.T) {
+	tests := map[string][]string{
+		"":                    {"/etc/xdg"},
+		"/a:/b":               {"/a", "/b"},
+		"relative:/b":         {"/b"},
+		"relative:also/wrong": {"/etc/xdg"},
+	}
+	for env, want := range tests {
+		t.Setenv("XDG_CONFIG_DIRS", env)
+		if got := ConfigDirs(); !reflect.DeepEqual(got, want) {
+			t.Errorf("ConfigDirs() with %q got = %v, want = %v", env, got, want)
+		}
+	}
+}
+
+func TestDataDirs(t *testing.T) {
+	t.Setenv("XDG_DATA_DIRS", "")
+	if got, want := DataDirs(), []string{"/usr/local/share", "/usr/share"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DataDirs() got = %v, want = %v", got, want)
+	}
+	t.Setenv("XDG_DATA_DIRS", "/opt/share:/usr/share")
+	if got, want := DataDirs(), []string{"/opt/share", "/usr/share"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DataDirs() got = %v, want = %v", got, want)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	user, system := filepath.Join(dir, "user"), filepath.Join(dir, "system")
+	t.Setenv("XDG_CONFIG_HOME", user)
+	t.Setenv("XDG_CONFIG_DIRS", system)
+	userFile := filepath.Join(user, App, "config.toml")
+	systemFile := filepath.Join(system, App, "config.toml")
+
+	if got := ConfigFile("config.toml"); got != userFile {
+		t.Errorf("without files got = %v, want = %v", got, userFile)
+	}
+	for _, path := range []string{systemFile, userFile} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := ConfigFile("config.toml"); got != path {
+			t.Errorf("got = %v, want = %v", got, path)
+		}
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	got, err := StateFile(filepath.Join("layouts", "dev.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, App, "layouts", "dev.json"); got != want {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+	if _, err := os.Stat(filepath.Dir(got)); err != nil {
+		t.Errorf("expected the directory to be created: %v", err)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	tests := map[string]string{
+		"~":          "/home/user",
+		"~/dev.json": "/home/user/dev.json",
+		"~other/a":   "~other/a",
+		"/tmp/a":     "/tmp/a",
+		"a/~/b":      "a/~/b",
+		"":           "",
+	}
+	for path, want := range tests {
+		if got := ExpandHome(path); got != want {
+			t.Errorf("ExpandHome(%q) got = %v, want = %v", path, got, want)
+		}
+	}
+}